@@ -74,8 +74,19 @@ type ControlPlaneSpec struct {
 	//
 	// If omitted, Ingress resources will not be supported by the ControlPlane.
 	//
+	// It is rendered into the configuration of the controller along with the
+	// options of the Controller field. The IngressClass object of that name is
+	// created and owned by the ControlPlane, unless it exists already.
+	//
 	// +optional
 	IngressClass *string `json:"ingressClass,omitempty"`
+
+	// DefaultIngressClass marks the IngressClass created for the ControlPlane
+	// as the default IngressClass of the cluster, so that the Ingress resources
+	// which do not specify any class are handled by the ControlPlane too.
+	//
+	// +optional
+	DefaultIngressClass bool `json:"defaultIngressClass,omitempty"`
 }
 
 // ControlPlaneOptions indicates the specific information needed to
@@ -84,12 +95,166 @@ type ControlPlaneOptions struct {
 	// +optional
 	Deployment DeploymentOptions `json:"deployment"`
 
-	// DataPlanes refers to the named DataPlane objects which this ControlPlane
-	// is responsible for. Currently they must be in the same namespace as the
-	// Dataplane.
+	// DataPlane refers to the named DataPlane object which this ControlPlane
+	// is responsible for. It must be in the same namespace as the ControlPlane.
 	//
 	// +optional
 	DataPlane *string `json:"dataplane,omitempty"`
+
+	// DataPlanes refers to additional named DataPlane objects, in the namespace
+	// of the ControlPlane, to which the ControlPlane pushes the configuration
+	// too. The DataPlane referred to by the DataPlane field must be set and is
+	// still the one whose proxy Service is published in the status of the
+	// configured resources.
+	//
+	// +optional
+	// +listType=set
+	DataPlanes []string `json:"dataplanes,omitempty"`
+
+	// DataPlaneSelector selects additional DataPlane objects by their labels,
+	// possibly in other namespaces than the one of the ControlPlane, to which
+	// the ControlPlane pushes the configuration too. The DataPlanes in other
+	// namespaces are only selected when they allow the namespace of the
+	// ControlPlane through their
+	// gateway-operator.konghq.com/allowed-controlplane-namespaces annotation.
+	//
+	// +optional
+	DataPlaneSelector *DataPlaneSelector `json:"dataplaneSelector,omitempty"`
+
+	// WatchNamespaces restricts the namespaces watched by the ControlPlane.
+	// When set, the ControlPlane is granted access to the namespaced resources
+	// of these namespaces and of its own namespace only, through Roles created
+	// in each of them, instead of cluster wide. Only the permissions on
+	// cluster scoped resources are still granted by a ClusterRole.
+	//
+	// +optional
+	// +listType=set
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
+	// Controller configures the Kong Ingress Controller run by the ControlPlane.
+	// The options are rendered into the environment of the controller container,
+	// so that environment variables set through the pod template override them.
+	//
+	// +optional
+	Controller *ControllerOptions `json:"controller,omitempty"`
+}
+
+// ControllerOptions are the typed configuration options of the Kong Ingress
+// Controller run by a ControlPlane. The options which are not supported by the
+// version of the controller image are not applied and are reported in the
+// ControllerOptionsValid condition of the ControlPlane.
+type ControllerOptions struct {
+	// FeatureGates enables or disables the feature gates of the controller,
+	// by their names.
+	//
+	// +optional
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+
+	// SyncPeriod is the period at which the controller relists the resources
+	// it watches.
+	//
+	// +optional
+	SyncPeriod *metav1.Duration `json:"syncPeriod,omitempty"`
+
+	// ProxySyncPeriod is the period at which the controller pushes the
+	// configuration to the DataPlanes.
+	//
+	// +optional
+	ProxySyncPeriod *metav1.Duration `json:"proxySyncPeriod,omitempty"`
+
+	// Konnect enables the synchronization of the configuration with a Konnect
+	// runtime group.
+	//
+	// +optional
+	Konnect *ControllerKonnectOptions `json:"konnect,omitempty"`
+
+	// Metrics configures the Prometheus metrics endpoint of the controller.
+	//
+	// +optional
+	Metrics *ControllerMetricsOptions `json:"metrics,omitempty"`
+
+	// AdmissionWebhook enables the admission webhook of the controller, which
+	// validates the Kong resources before they are admitted.
+	//
+	// +optional
+	AdmissionWebhook *ControllerAdmissionWebhookOptions `json:"admissionWebhook,omitempty"`
+}
+
+// ControllerKonnectOptions configures the synchronization of the configuration
+// with Konnect.
+type ControllerKonnectOptions struct {
+	// RuntimeGroupID is the ID of the Konnect runtime group the configuration
+	// is synchronized with.
+	//
+	// +kubebuilder:validation:MinLength=1
+	RuntimeGroupID string `json:"runtimeGroupID"`
+
+	// Address is the address of the Konnect API. If omitted, the default
+	// address of the controller is used.
+	//
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// TLSClientCertificateSecretName is the name of the kubernetes.io/tls Secret,
+	// in the namespace of the ControlPlane, holding the client certificate the
+	// controller authenticates with to Konnect.
+	//
+	// +kubebuilder:validation:MinLength=1
+	TLSClientCertificateSecretName string `json:"tlsClientCertificateSecretName"`
+}
+
+// ControllerMetricsOptions configures the Prometheus metrics endpoint of the
+// controller.
+type ControllerMetricsOptions struct {
+	// Enabled indicates whether the metrics endpoint is served.
+	// Defaults to true.
+	//
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Port is the port the metrics endpoint is served on. If omitted, the
+	// default port of the controller is used.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+}
+
+// ControllerAdmissionWebhookOptions configures the admission webhook of the
+// controller.
+type ControllerAdmissionWebhookOptions struct {
+	// Port is the port the admission webhook listens on.
+	//
+	// +optional
+	// +kubebuilder:default=8080
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+
+	// CertificateSecretName is the name of the kubernetes.io/tls Secret, in the
+	// namespace of the ControlPlane, holding the serving certificate of the
+	// admission webhook.
+	//
+	// +kubebuilder:validation:MinLength=1
+	CertificateSecretName string `json:"certificateSecretName"`
+}
+
+// DataPlaneSelector selects DataPlane objects by their labels.
+type DataPlaneSelector struct {
+	// Namespaces are the namespaces in which the DataPlanes are selected.
+	// If omitted, the DataPlanes are selected in the namespace of the
+	// ControlPlane. The DataPlanes of other namespaces than the one of the
+	// ControlPlane must list its namespace in their
+	// gateway-operator.konghq.com/allowed-controlplane-namespaces annotation
+	// to be selected.
+	//
+	// +optional
+	// +listType=set
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Selector is the label selector the DataPlanes must match.
+	Selector metav1.LabelSelector `json:"selector"`
 }
 
 // ControlPlaneStatus defines the observed state of ControlPlane
@@ -102,6 +267,52 @@ type ControlPlaneStatus struct {
 	// +kubebuilder:validation:MaxItems=8
 	// +kubebuilder:default={{type: "Scheduled", status: "Unknown", reason:"NotReconciled", message:"Waiting for controller", lastTransitionTime: "1970-01-01T00:00:00Z"}}
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Replicas indicates how many replicas have been set for the ControlPlane.
+	//
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// ReadyReplicas indicates how many replicas have reported to be ready.
+	//
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Leader is the name of the ControlPlane pod which currently holds the
+	// leader election lease, and as such is responsible for updating the
+	// status of the configured resources.
+	//
+	// +optional
+	Leader string `json:"leader,omitempty"`
+
+	// DataPlanes reports whether the ControlPlane is linked to each of the
+	// DataPlanes it is configured to push the configuration to, that is
+	// whether the admin API of the DataPlane is reachable by the ControlPlane.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=name
+	DataPlanes []ControlPlaneDataPlaneStatus `json:"dataplanes,omitempty"`
+}
+
+// ControlPlaneDataPlaneStatus is the link status of a ControlPlane with one of
+// its DataPlanes.
+type ControlPlaneDataPlaneStatus struct {
+	// Namespace is the namespace of the DataPlane.
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the DataPlane.
+	Name string `json:"name"`
+
+	// Linked indicates whether the admin API endpoints of the DataPlane are
+	// provided to the ControlPlane.
+	Linked bool `json:"linked"`
+
+	// Message explains why the DataPlane is not linked.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // GetConditions returns the ControlPlane Status Conditions
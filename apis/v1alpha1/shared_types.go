@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeploymentOptions is a shared type used on objects to indicate that their
@@ -23,6 +24,28 @@ type DeploymentOptions struct {
 	//
 	// +optional
 	PodTemplateSpec *corev1.PodTemplateSpec `json:"podTemplateSpec,omitempty"`
+
+	// PodDisruptionBudget defines the PodDisruptionBudget created for the
+	// Deployment's pods. No PodDisruptionBudget is created when it is not set.
+	//
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+}
+
+// PodDisruptionBudget defines the options of the PodDisruptionBudget created
+// for the deployment's pods. Only one of minAvailable and maxUnavailable can be set.
+type PodDisruptionBudget struct {
+	// MinAvailable is the number of pods which have to remain available after
+	// an eviction. It can be either an absolute number or a percentage.
+	//
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number of pods which can be unavailable after
+	// an eviction. It can be either an absolute number or a percentage.
+	//
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
 // Rollout defines options for rollouts.
@@ -65,8 +88,11 @@ const (
 
 	// BreakBeforePromotion is the same as AutomaticPromotion but with an added breakpoint
 	// to enable manual inspection.
-	// The user must indicate manually when they want the promotion to continue.
-	// TODO: finalizer/annotation?
+	// The user must indicate manually when they want the promotion to continue
+	// by setting the "gateway-operator.konghq.com/promote-when-ready" annotation
+	// to "true" on the DataPlane once its preview resources await the promotion.
+	// The operator removes the annotation when it is set at any other time and
+	// once the promotion is completed, so that every rollout has to be approved.
 	BreakBeforePromotion PromotionStrategy = "BreakBeforePromotion"
 )
 
@@ -26,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
@@ -72,6 +73,21 @@ func (in *ControlPlane) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlPlaneDataPlaneStatus) DeepCopyInto(out *ControlPlaneDataPlaneStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneDataPlaneStatus.
+func (in *ControlPlaneDataPlaneStatus) DeepCopy() *ControlPlaneDataPlaneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlPlaneDataPlaneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlPlaneList) DeepCopyInto(out *ControlPlaneList) {
 	*out = *in
@@ -113,6 +129,26 @@ func (in *ControlPlaneOptions) DeepCopyInto(out *ControlPlaneOptions) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.DataPlanes != nil {
+		in, out := &in.DataPlanes, &out.DataPlanes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DataPlaneSelector != nil {
+		in, out := &in.DataPlaneSelector, &out.DataPlaneSelector
+		*out = new(DataPlaneSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WatchNamespaces != nil {
+		in, out := &in.WatchNamespaces, &out.WatchNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Controller != nil {
+		in, out := &in.Controller, &out.Controller
+		*out = new(ControllerOptions)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneOptions.
@@ -161,6 +197,11 @@ func (in *ControlPlaneStatus) DeepCopyInto(out *ControlPlaneStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DataPlanes != nil {
+		in, out := &in.DataPlanes, &out.DataPlanes
+		*out = make([]ControlPlaneDataPlaneStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneStatus.
@@ -173,6 +214,124 @@ func (in *ControlPlaneStatus) DeepCopy() *ControlPlaneStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerAdmissionWebhookOptions) DeepCopyInto(out *ControllerAdmissionWebhookOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerAdmissionWebhookOptions.
+func (in *ControllerAdmissionWebhookOptions) DeepCopy() *ControllerAdmissionWebhookOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerAdmissionWebhookOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerKonnectOptions) DeepCopyInto(out *ControllerKonnectOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerKonnectOptions.
+func (in *ControllerKonnectOptions) DeepCopy() *ControllerKonnectOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerKonnectOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerMetricsOptions) DeepCopyInto(out *ControllerMetricsOptions) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerMetricsOptions.
+func (in *ControllerMetricsOptions) DeepCopy() *ControllerMetricsOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerMetricsOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerOptions) DeepCopyInto(out *ControllerOptions) {
+	*out = *in
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SyncPeriod != nil {
+		in, out := &in.SyncPeriod, &out.SyncPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ProxySyncPeriod != nil {
+		in, out := &in.ProxySyncPeriod, &out.ProxySyncPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Konnect != nil {
+		in, out := &in.Konnect, &out.Konnect
+		*out = new(ControllerKonnectOptions)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(ControllerMetricsOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdmissionWebhook != nil {
+		in, out := &in.AdmissionWebhook, &out.AdmissionWebhook
+		*out = new(ControllerAdmissionWebhookOptions)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerOptions.
+func (in *ControllerOptions) DeepCopy() *ControllerOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPlaneSelector) DeepCopyInto(out *DataPlaneSelector) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneSelector.
+func (in *DataPlaneSelector) DeepCopy() *DataPlaneSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPlaneSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentOptions) DeepCopyInto(out *DeploymentOptions) {
 	*out = *in
@@ -186,6 +345,11 @@ func (in *DeploymentOptions) DeepCopyInto(out *DeploymentOptions) {
 		*out = new(corev1.PodTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentOptions.
@@ -304,6 +468,31 @@ func (in *GatewayConfigurationStatus) DeepCopy() *GatewayConfigurationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudget) DeepCopyInto(out *PodDisruptionBudget) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudget.
+func (in *PodDisruptionBudget) DeepCopy() *PodDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodsOptions) DeepCopyInto(out *PodsOptions) {
 	*out = *in
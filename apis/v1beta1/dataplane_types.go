@@ -19,6 +19,7 @@ package v1beta1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func init() {
@@ -29,6 +30,7 @@ func init() {
 //+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:subresource:scale:specpath=.spec.deployment.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 //+kubebuilder:resource:shortName=kdp,categories=kong;all
 //+kubebuilder:printcolumn:name="Ready",description="The Resource is ready",type=string,JSONPath=`.status.conditions[?(@.type=='Ready')].status`
 //+kubebuilder:printcolumn:name="Provisioned",description="The Resource is provisioned",type=string,JSONPath=`.status.conditions[?(@.type=='Provisioned')].status`
@@ -64,6 +66,19 @@ type DataPlaneOptions struct {
 
 	// +optional
 	Network DataPlaneNetworkOptions `json:"network"`
+
+	// Database defines the database backing the DataPlane. When it is not set,
+	// the DataPlane runs in DB-less mode.
+	//
+	// +optional
+	Database *DataPlaneDatabaseOptions `json:"database,omitempty"`
+
+	// Cluster configures the DataPlane as a node of a Kong hybrid mode cluster.
+	// The operator issues the cluster certificate from its CA and sets the
+	// KONG_ROLE and KONG_CLUSTER_* environment variables of the proxy container.
+	//
+	// +optional
+	Cluster *DataPlaneClusterOptions `json:"cluster,omitempty"`
 }
 
 // DataPlaneDeploymentOptions specifies options for the Deployments (as in the Kubernetes
@@ -74,9 +89,71 @@ type DataPlaneDeploymentOptions struct {
 	// +optional
 	Rollout *Rollout `json:"rollout,omitempty"`
 
+	// Scaling defines the scaling options for the deployment.
+	//
+	// +optional
+	Scaling *Scaling `json:"scaling,omitempty"`
+
+	// PodDisruptionBudget defines the PodDisruptionBudget created for the
+	// DataPlane's pods. No PodDisruptionBudget is created when it is not set.
+	//
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+
 	DeploymentOptions `json:",inline"`
 }
 
+// DataPlaneDatabaseOptions defines the database options for a DataPlane.
+type DataPlaneDatabaseOptions struct {
+	// Postgres configures the DataPlane to use a PostgreSQL database.
+	// The database schema is bootstrapped and migrated by the operator before
+	// the DataPlane's Deployment is rolled out.
+	//
+	// +optional
+	Postgres *PostgresDatabaseOptions `json:"postgres,omitempty"`
+}
+
+// PostgresDatabaseOptions defines the connection to a PostgreSQL database.
+type PostgresDatabaseOptions struct {
+	// SecretName is the name of the Secret, in the DataPlane's namespace,
+	// holding the database connection details. The Secret has to contain
+	// the "host", "user" and "password" keys, and can optionally contain
+	// the "port" and "database" keys.
+	//
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+}
+
+// DataPlaneClusterRole is the role of a DataPlane in a Kong hybrid mode cluster.
+type DataPlaneClusterRole string
+
+const (
+	// DataPlaneClusterRoleControlPlane is the role of the DataPlanes which hold
+	// the configuration of the cluster and push it to the data plane nodes.
+	DataPlaneClusterRoleControlPlane DataPlaneClusterRole = "control_plane"
+
+	// DataPlaneClusterRoleDataPlane is the role of the DataPlanes which proxy
+	// the traffic according to the configuration received from a control plane.
+	DataPlaneClusterRoleDataPlane DataPlaneClusterRole = "data_plane"
+)
+
+// DataPlaneClusterOptions defines the Kong hybrid mode options for a DataPlane.
+type DataPlaneClusterOptions struct {
+	// Role is the role of the DataPlane in the cluster.
+	// DataPlanes with the control_plane role require a database, and are
+	// exposed to the data plane nodes through a cluster Service.
+	//
+	// +kubebuilder:validation:Enum=control_plane;data_plane
+	Role DataPlaneClusterRole `json:"role"`
+
+	// ControlPlaneName is the name of the DataPlane with the control_plane role,
+	// in the same namespace, which the DataPlane connects to. It is required
+	// for DataPlanes with the data_plane role.
+	//
+	// +optional
+	ControlPlaneName string `json:"controlPlaneName,omitempty"`
+}
+
 // DataPlaneNetworkOptions defines network related options for a DataPlane.
 type DataPlaneNetworkOptions struct {
 	// Services indicates the configuration of Kubernetes Services needed for
@@ -127,6 +204,48 @@ type ServiceOptions struct {
 	//
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty" protobuf:"bytes,12,rep,name=annotations"`
+
+	// Ports defines the list of ports exposed by the Service.
+	// When not set, the Service exposes HTTP on port 80 and HTTPS on port 443.
+	//
+	// When the DataPlane is managed by a Gateway, the ports are set by the
+	// operator from the Gateway's listeners.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=port
+	// +listMapKey=protocol
+	Ports []DataPlaneServicePort `json:"ports,omitempty"`
+}
+
+// DataPlaneServicePort contains information on a port exposed by a DataPlane
+// Service.
+type DataPlaneServicePort struct {
+	// Name is the name of this port within the Service.
+	//
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Port is the port exposed by the Service.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// Protocol is the IP protocol of this port.
+	// Defaults to TCP.
+	//
+	// +optional
+	// +kubebuilder:default=TCP
+	// +kubebuilder:validation:Enum=TCP;UDP
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+
+	// TargetPort is the number or name of the port to access on the pods
+	// targeted by the Service.
+	// Defaults to the value of the port field.
+	//
+	// +optional
+	TargetPort intstr.IntOrString `json:"targetPort,omitempty"`
 }
 
 // DataPlaneStatus defines the observed state of DataPlane
@@ -163,6 +282,13 @@ type DataPlaneStatus struct {
 	// +kubebuilder:default=0
 	Replicas int32 `json:"replicas"`
 
+	// Selector is the label selector, in its string form, of the DataPlane's
+	// live pods. It is used by the scale subresource, so that autoscalers
+	// targeting the DataPlane can find its pods.
+	//
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
 	// RolloutStatus contains information about the rollout.
 	// It is set only if a rollout strategy was configured in the spec.
 	//
@@ -182,6 +308,107 @@ type DataPlaneRolloutStatus struct {
 	// +kubebuilder:validation:MinItems=1
 	// +kubebuilder:validation:MaxItems=8
 	Status []metav1.Condition `json:"status,omitempty"`
+
+	// Promotion contains the information about the last promotion of
+	// the preview resources.
+	//
+	// +optional
+	Promotion *DataPlaneRolloutStatusPromotion `json:"promotion,omitempty"`
+
+	// Rollback contains the information about the last rollback of
+	// the preview resources caused by a failed promotion check.
+	//
+	// +optional
+	Rollback *DataPlaneRolloutStatusRollback `json:"rollback,omitempty"`
+
+	// Canary contains the progress of the Canary rollout in progress.
+	//
+	// +optional
+	Canary *DataPlaneRolloutStatusCanary `json:"canary,omitempty"`
+
+	// PromotionChecks contains the evaluation state of the promotion checks
+	// of the preview resources.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	PromotionChecks []DataPlaneRolloutStatusPromotionCheck `json:"promotionChecks,omitempty"`
+}
+
+// DataPlaneRolloutStatusPromotionCheck describes the evaluation state of
+// a promotion check of the preview resources.
+type DataPlaneRolloutStatusPromotionCheck struct {
+	// Name is the name of the promotion check.
+	Name string `json:"name"`
+
+	// LastProbeTime is the time when the check has last been evaluated.
+	LastProbeTime metav1.Time `json:"lastProbeTime"`
+
+	// ConsecutiveFailures is the number of consecutive failed evaluations
+	// of the check.
+	//
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+}
+
+// DataPlaneRolloutStatusCanary describes the progress of a Canary rollout.
+type DataPlaneRolloutStatusCanary struct {
+	// Revision is the hash of the pod template being rolled out.
+	Revision string `json:"revision"`
+
+	// Step is the index of the current step of the rollout, including
+	// the implied final step.
+	Step int32 `json:"step"`
+
+	// Weight is the weight of the current step.
+	Weight int32 `json:"weight"`
+
+	// CanaryReplicas is the number of replicas the canary Deployment is scaled
+	// to during the rollout. Replicas are moved between the Deployments one at
+	// a time until the weight of the current step is reached.
+	CanaryReplicas int32 `json:"canaryReplicas"`
+
+	// StableReplicas is the number of replicas the live Deployment is scaled
+	// to during the rollout.
+	StableReplicas int32 `json:"stableReplicas"`
+
+	// Replicas is the number of replicas distributed between the live and the
+	// canary Deployments. When horizontal scaling is enabled, it is the number
+	// of replicas the live Deployment had when the rollout started, as the
+	// autoscaler is suspended for the duration of the rollout.
+	//
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// StepTime is the time when the replicas of the current step have become
+	// available, which starts the step's pause.
+	//
+	// +optional
+	StepTime *metav1.Time `json:"stepTime,omitempty"`
+}
+
+// DataPlaneRolloutStatusRollback describes the last rollback of the preview
+// resources during DataPlane rollout.
+type DataPlaneRolloutStatusRollback struct {
+	// Time is the time when the preview resources have been rolled back.
+	Time metav1.Time `json:"time"`
+
+	// Revision is the hash of the pod template which has been rolled back.
+	// This revision is not previewed again until the pod template changes.
+	Revision string `json:"revision"`
+
+	// Check is the name of the promotion check which has failed.
+	Check string `json:"check"`
+}
+
+// DataPlaneRolloutStatusPromotion describes the last promotion of the preview
+// resources during DataPlane rollout.
+type DataPlaneRolloutStatusPromotion struct {
+	// Time is the time when the promotion has been completed.
+	Time metav1.Time `json:"time"`
+
+	// Revision is the hash of the pod template which has been promoted.
+	Revision string `json:"revision"`
 }
 
 // DataPlaneRolloutStatusServices describes the status of the services during
@@ -280,3 +507,13 @@ func (d *DataPlane) GetConditions() []metav1.Condition {
 func (d *DataPlane) SetConditions(conditions []metav1.Condition) {
 	d.Status.Conditions = conditions
 }
+
+// GetConditions retrieves the DataPlane rollout status conditions.
+func (s *DataPlaneRolloutStatus) GetConditions() []metav1.Condition {
+	return s.Status
+}
+
+// SetConditions sets the DataPlane rollout status conditions.
+func (s *DataPlaneRolloutStatus) SetConditions(conditions []metav1.Condition) {
+	s.Status = conditions
+}
@@ -1,7 +1,10 @@
 package v1beta1
 
 import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeploymentOptions is a shared type used on objects to indicate that their
@@ -25,6 +28,65 @@ type DeploymentOptions struct {
 	PodTemplateSpec *corev1.PodTemplateSpec `json:"podTemplateSpec,omitempty"`
 }
 
+// Scaling defines the scaling options for the deployment.
+type Scaling struct {
+	// HorizontalScaling defines horizontal scaling options for the deployment.
+	//
+	// +optional
+	HorizontalScaling *HorizontalScaling `json:"horizontal,omitempty"`
+}
+
+// HorizontalScaling defines horizontal scaling options for the deployment.
+// The operator creates a HorizontalPodAutoscaler targeting the deployment
+// and stops enforcing the deployment's replicas while it is active.
+// It holds all the options from the HorizontalPodAutoscalerSpec besides the
+// ScaleTargetRef which is being controlled by the operator.
+type HorizontalScaling struct {
+	// minReplicas is the lower limit for the number of replicas to which the autoscaler
+	// can scale down. It defaults to 1 pod.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// maxReplicas is the upper limit for the number of replicas to which the autoscaler can scale up.
+	// It cannot be less than minReplicas.
+	//
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// metrics contains the specifications for which to use to calculate the
+	// desired replica count (the maximum replica count across all metrics will
+	// be used). If not set, the default metric will be set to 80% average CPU
+	// utilization.
+	//
+	// +optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+
+	// behavior configures the scaling behavior of the target
+	// in both Up and Down directions (scaleUp and scaleDown fields respectively).
+	// If not set, the default HPAScalingRules for scale up and scale down are used.
+	//
+	// +optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+}
+
+// PodDisruptionBudget defines the options of the PodDisruptionBudget created
+// for the deployment's pods. Only one of minAvailable and maxUnavailable can be set.
+type PodDisruptionBudget struct {
+	// MinAvailable is the number of pods which have to remain available after
+	// an eviction. It can be either an absolute number or a percentage.
+	//
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number of pods which can be unavailable after
+	// an eviction. It can be either an absolute number or a percentage.
+	//
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
 // Rollout defines options for rollouts.
 type Rollout struct {
 	// Strategy contains the deployment strategy for rollout.
@@ -37,18 +99,157 @@ type RolloutStrategy struct {
 	//
 	// +optional
 	BlueGreen *BlueGreenStrategy `json:"blueGreen,omitempty"`
+
+	// Canary holds the options specific for Canary rollouts.
+	// Only one of BlueGreen and Canary can be set.
+	//
+	// +optional
+	Canary *CanaryStrategy `json:"canary,omitempty"`
+}
+
+// CanaryStrategy defines the Canary rollout strategy. The new pod template
+// is deployed as a canary Deployment running side by side with the live one
+// behind the live Services, and the traffic is shifted to it in steps by
+// adjusting the number of replicas of both Deployments. The total number of
+// replicas is kept, so that the rollout does not require additional capacity.
+type CanaryStrategy struct {
+	// Steps contains the steps of the rollout, with strictly increasing weights.
+	// When the weight of the last step is lower than 100, a final step with
+	// the weight of 100 is implied. Once the final step is completed, the live
+	// Deployment gets the new pod template and the replicas are moved back to it.
+	//
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=10
+	Steps []CanaryStep `json:"steps"`
+}
+
+// CanaryStep defines a step of a Canary rollout.
+type CanaryStep struct {
+	// Weight is the percentage of the DataPlane's replicas which run the new pod
+	// template during the step. The number of canary replicas is rounded up.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+
+	// Pause is the duration for which the rollout stays at this step once
+	// the replicas of both Deployments are available, before moving to the next one.
+	//
+	// +optional
+	Pause *metav1.Duration `json:"pause,omitempty"`
 }
 
 // BlueGreenStrategy defines the Blue Green deployment strategy.
 type BlueGreenStrategy struct {
 	// Promotion defines how the operator handles promotion of resources.
 	Promotion Promotion `json:"promotion"`
+
+	// PromotionChecks contains the checks which have to pass before the preview
+	// resources get promoted. The result of each check is reported in the
+	// DataPlane's rollout status as a condition with the check's name as type.
+	// When any of the checks fails, the preview resources are rolled back.
+	// The checks are evaluated until all of them have passed together, and not
+	// anymore for the same preview resources.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=7
+	PromotionChecks []PromotionCheck `json:"promotionChecks,omitempty"`
+}
+
+// PromotionCheck defines a check which has to pass before the preview resources
+// get promoted. Exactly one of the check kinds has to be set.
+type PromotionCheck struct {
+	// Name is the name of the check, used as the type of the condition reporting
+	// its result.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`
+	Name string `json:"name"`
+
+	// HTTP checks the responses to HTTP requests sent to the preview proxy Service.
+	//
+	// +optional
+	HTTP *HTTPPromotionCheck `json:"http,omitempty"`
+
+	// Soak checks that the preview Deployment has been ready for a minimum duration.
+	//
+	// +optional
+	Soak *SoakPromotionCheck `json:"soak,omitempty"`
+
+	// PodRestarts checks that the containers of the preview pods have not restarted
+	// more than allowed. Unlike the other checks, it keeps being evaluated once
+	// all the checks have passed, until the promotion starts.
+	//
+	// +optional
+	PodRestarts *PodRestartsPromotionCheck `json:"podRestarts,omitempty"`
+}
+
+// HTTPPromotionCheck defines a check which sends an HTTP GET request to
+// the preview proxy Service and verifies the response status code.
+type HTTPPromotionCheck struct {
+	// Path is the path of the request.
+	//
+	// +optional
+	// +kubebuilder:default="/"
+	Path string `json:"path,omitempty"`
+
+	// Port is the port of the preview proxy Service the request is sent to.
+	//
+	// +optional
+	// +kubebuilder:default=80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+
+	// Host is the value of the Host header of the request.
+	//
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// ExpectedStatusCodes contains the status codes which make the check pass.
+	//
+	// +kubebuilder:validation:MinItems=1
+	ExpectedStatusCodes []int `json:"expectedStatusCodes"`
+
+	// Interval is the minimum duration between two requests.
+	//
+	// +optional
+	// +kubebuilder:default="10s"
+	Interval metav1.Duration `json:"interval,omitempty"`
+
+	// FailureThreshold is the number of consecutive failed requests after
+	// which the check fails. A successful request resets the count.
+	//
+	// +optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// SoakPromotionCheck defines a check which passes once the preview Deployment
+// has been ready for the configured duration.
+type SoakPromotionCheck struct {
+	// Duration is the minimum duration for which the preview Deployment has to be ready.
+	Duration metav1.Duration `json:"duration"`
+}
+
+// PodRestartsPromotionCheck defines a check which fails when the containers
+// of the preview pods restart more than the allowed number of times.
+type PodRestartsPromotionCheck struct {
+	// MaxRestarts is the maximum number of container restarts allowed across
+	// all the preview pods.
+	//
+	// +optional
+	// +kubebuilder:default=0
+	// +kubebuilder:validation:Minimum=0
+	MaxRestarts int32 `json:"maxRestarts,omitempty"`
 }
 
 type Promotion struct {
 	// Strategy indicates how you want the operator to handle the promotion of
-	// the preview (green) resources (Deployments and Services) after all workflows
-	// and tests succeed, OR if you even want it to break before performing
+	// the preview (green) resources (Deployments and Services) after all promotion
+	// checks pass, OR if you even want it to break before performing
 	// the promotion to allow manual inspection.
 	//
 	// +kubebuilder:validation:Enum=AutomaticPromotion;BreakBeforePromotion
@@ -59,14 +260,17 @@ type Promotion struct {
 type PromotionStrategy string
 
 const (
-	// AutomaticPromotion indicates that once all workflows and tests have completed successfully,
+	// AutomaticPromotion indicates that once all the promotion checks have passed,
 	// the new resources should be promoted and replace the previous resources.
 	AutomaticPromotion PromotionStrategy = "AutomaticPromotion"
 
 	// BreakBeforePromotion is the same as AutomaticPromotion but with an added breakpoint
 	// to enable manual inspection.
-	// The user must indicate manually when they want the promotion to continue.
-	// TODO: finalizer/annotation?
+	// The user must indicate manually when they want the promotion to continue
+	// by setting the "gateway-operator.konghq.com/promote-when-ready" annotation
+	// to "true" on the DataPlane once its preview resources await the promotion.
+	// The operator removes the annotation when it is set at any other time and
+	// once the promotion is completed, so that every rollout has to be approved.
 	BreakBeforePromotion PromotionStrategy = "BreakBeforePromotion"
 )
 
@@ -22,9 +22,11 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -51,6 +53,13 @@ func (in *Address) DeepCopy() *Address {
 func (in *BlueGreenStrategy) DeepCopyInto(out *BlueGreenStrategy) {
 	*out = *in
 	out.Promotion = in.Promotion
+	if in.PromotionChecks != nil {
+		in, out := &in.PromotionChecks, &out.PromotionChecks
+		*out = make([]PromotionCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreenStrategy.
@@ -63,6 +72,48 @@ func (in *BlueGreenStrategy) DeepCopy() *BlueGreenStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStep) DeepCopyInto(out *CanaryStep) {
+	*out = *in
+	if in.Pause != nil {
+		in, out := &in.Pause, &out.Pause
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStep.
+func (in *CanaryStep) DeepCopy() *CanaryStep {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStrategy) DeepCopyInto(out *CanaryStrategy) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]CanaryStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStrategy.
+func (in *CanaryStrategy) DeepCopy() *CanaryStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataPlane) DeepCopyInto(out *DataPlane) {
 	*out = *in
@@ -90,6 +141,41 @@ func (in *DataPlane) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPlaneClusterOptions) DeepCopyInto(out *DataPlaneClusterOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneClusterOptions.
+func (in *DataPlaneClusterOptions) DeepCopy() *DataPlaneClusterOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPlaneClusterOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPlaneDatabaseOptions) DeepCopyInto(out *DataPlaneDatabaseOptions) {
+	*out = *in
+	if in.Postgres != nil {
+		in, out := &in.Postgres, &out.Postgres
+		*out = new(PostgresDatabaseOptions)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneDatabaseOptions.
+func (in *DataPlaneDatabaseOptions) DeepCopy() *DataPlaneDatabaseOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPlaneDatabaseOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataPlaneDeploymentOptions) DeepCopyInto(out *DataPlaneDeploymentOptions) {
 	*out = *in
@@ -98,6 +184,16 @@ func (in *DataPlaneDeploymentOptions) DeepCopyInto(out *DataPlaneDeploymentOptio
 		*out = new(Rollout)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Scaling != nil {
+		in, out := &in.Scaling, &out.Scaling
+		*out = new(Scaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
 	in.DeploymentOptions.DeepCopyInto(&out.DeploymentOptions)
 }
 
@@ -168,6 +264,16 @@ func (in *DataPlaneOptions) DeepCopyInto(out *DataPlaneOptions) {
 	*out = *in
 	in.Deployment.DeepCopyInto(&out.Deployment)
 	in.Network.DeepCopyInto(&out.Network)
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(DataPlaneDatabaseOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(DataPlaneClusterOptions)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneOptions.
@@ -195,6 +301,28 @@ func (in *DataPlaneRolloutStatus) DeepCopyInto(out *DataPlaneRolloutStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Promotion != nil {
+		in, out := &in.Promotion, &out.Promotion
+		*out = new(DataPlaneRolloutStatusPromotion)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(DataPlaneRolloutStatusRollback)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(DataPlaneRolloutStatusCanary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PromotionChecks != nil {
+		in, out := &in.PromotionChecks, &out.PromotionChecks
+		*out = make([]DataPlaneRolloutStatusPromotionCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneRolloutStatus.
@@ -207,6 +335,73 @@ func (in *DataPlaneRolloutStatus) DeepCopy() *DataPlaneRolloutStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPlaneRolloutStatusCanary) DeepCopyInto(out *DataPlaneRolloutStatusCanary) {
+	*out = *in
+	if in.StepTime != nil {
+		in, out := &in.StepTime, &out.StepTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneRolloutStatusCanary.
+func (in *DataPlaneRolloutStatusCanary) DeepCopy() *DataPlaneRolloutStatusCanary {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPlaneRolloutStatusCanary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPlaneRolloutStatusPromotion) DeepCopyInto(out *DataPlaneRolloutStatusPromotion) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneRolloutStatusPromotion.
+func (in *DataPlaneRolloutStatusPromotion) DeepCopy() *DataPlaneRolloutStatusPromotion {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPlaneRolloutStatusPromotion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPlaneRolloutStatusPromotionCheck) DeepCopyInto(out *DataPlaneRolloutStatusPromotionCheck) {
+	*out = *in
+	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneRolloutStatusPromotionCheck.
+func (in *DataPlaneRolloutStatusPromotionCheck) DeepCopy() *DataPlaneRolloutStatusPromotionCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPlaneRolloutStatusPromotionCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPlaneRolloutStatusRollback) DeepCopyInto(out *DataPlaneRolloutStatusRollback) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneRolloutStatusRollback.
+func (in *DataPlaneRolloutStatusRollback) DeepCopy() *DataPlaneRolloutStatusRollback {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPlaneRolloutStatusRollback)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataPlaneRolloutStatusServices) DeepCopyInto(out *DataPlaneRolloutStatusServices) {
 	*out = *in
@@ -232,6 +427,22 @@ func (in *DataPlaneRolloutStatusServices) DeepCopy() *DataPlaneRolloutStatusServ
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPlaneServicePort) DeepCopyInto(out *DataPlaneServicePort) {
+	*out = *in
+	out.TargetPort = in.TargetPort
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneServicePort.
+func (in *DataPlaneServicePort) DeepCopy() *DataPlaneServicePort {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPlaneServicePort)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataPlaneServices) DeepCopyInto(out *DataPlaneServices) {
 	*out = *in
@@ -327,6 +538,114 @@ func (in *DeploymentOptions) DeepCopy() *DeploymentOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPPromotionCheck) DeepCopyInto(out *HTTPPromotionCheck) {
+	*out = *in
+	if in.ExpectedStatusCodes != nil {
+		in, out := &in.ExpectedStatusCodes, &out.ExpectedStatusCodes
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	out.Interval = in.Interval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPPromotionCheck.
+func (in *HTTPPromotionCheck) DeepCopy() *HTTPPromotionCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPPromotionCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HorizontalScaling) DeepCopyInto(out *HorizontalScaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(v2.HorizontalPodAutoscalerBehavior)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HorizontalScaling.
+func (in *HorizontalScaling) DeepCopy() *HorizontalScaling {
+	if in == nil {
+		return nil
+	}
+	out := new(HorizontalScaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudget) DeepCopyInto(out *PodDisruptionBudget) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudget.
+func (in *PodDisruptionBudget) DeepCopy() *PodDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRestartsPromotionCheck) DeepCopyInto(out *PodRestartsPromotionCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRestartsPromotionCheck.
+func (in *PodRestartsPromotionCheck) DeepCopy() *PodRestartsPromotionCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRestartsPromotionCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseOptions) DeepCopyInto(out *PostgresDatabaseOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseOptions.
+func (in *PostgresDatabaseOptions) DeepCopy() *PostgresDatabaseOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Promotion) DeepCopyInto(out *Promotion) {
 	*out = *in
@@ -342,6 +661,36 @@ func (in *Promotion) DeepCopy() *Promotion {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionCheck) DeepCopyInto(out *PromotionCheck) {
+	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPPromotionCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Soak != nil {
+		in, out := &in.Soak, &out.Soak
+		*out = new(SoakPromotionCheck)
+		**out = **in
+	}
+	if in.PodRestarts != nil {
+		in, out := &in.PodRestarts, &out.PodRestarts
+		*out = new(PodRestartsPromotionCheck)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionCheck.
+func (in *PromotionCheck) DeepCopy() *PromotionCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Rollout) DeepCopyInto(out *Rollout) {
 	*out = *in
@@ -386,7 +735,12 @@ func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
 	if in.BlueGreen != nil {
 		in, out := &in.BlueGreen, &out.BlueGreen
 		*out = new(BlueGreenStrategy)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryStrategy)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -400,6 +754,26 @@ func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Scaling) DeepCopyInto(out *Scaling) {
+	*out = *in
+	if in.HorizontalScaling != nil {
+		in, out := &in.HorizontalScaling, &out.HorizontalScaling
+		*out = new(HorizontalScaling)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Scaling.
+func (in *Scaling) DeepCopy() *Scaling {
+	if in == nil {
+		return nil
+	}
+	out := new(Scaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceOptions) DeepCopyInto(out *ServiceOptions) {
 	*out = *in
@@ -410,6 +784,11 @@ func (in *ServiceOptions) DeepCopyInto(out *ServiceOptions) {
 			(*out)[key] = val
 		}
 	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]DataPlaneServicePort, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceOptions.
@@ -421,3 +800,19 @@ func (in *ServiceOptions) DeepCopy() *ServiceOptions {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoakPromotionCheck) DeepCopyInto(out *SoakPromotionCheck) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoakPromotionCheck.
+func (in *SoakPromotionCheck) DeepCopy() *SoakPromotionCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(SoakPromotionCheck)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/samber/lo"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+)
+
+// -----------------------------------------------------------------------------
+// Certificate Issuers
+// -----------------------------------------------------------------------------
+
+// CertManagerIssuerRef references the cert-manager Issuer or ClusterIssuer the
+// certificates of DataPlanes and ControlPlanes are requested from.
+type CertManagerIssuerRef struct {
+	// Name is the name of the issuer. An Issuer with this name has to exist in
+	// the namespace of every DataPlane and ControlPlane.
+	Name string
+	// Kind is the kind of the issuer, Issuer or ClusterIssuer for the issuers
+	// provided by cert-manager.
+	Kind string
+	// Group is the API group of the issuer, cert-manager.io for the issuers
+	// provided by cert-manager.
+	Group string
+}
+
+// certificateRequest describes a certificate issued for a DataPlane or a
+// ControlPlane.
+type certificateRequest struct {
+	owner   client.Object
+	subject string
+	// dnsNames are the DNS names the certificate is valid for along with the subject.
+	dnsNames []string
+	// purpose tells apart the certificates of an owner. The certificate securing
+	// the communication between the operator and the owner has an empty purpose.
+	purpose string
+	usages  []certificatesv1.KeyUsage
+}
+
+// certificateIssuer issues the certificates of DataPlanes and ControlPlanes, and
+// stores each of them in a TLS Secret along with the CA certificates its peers
+// are verified with.
+type certificateIssuer interface {
+	// ensureCertificateSecret ensures that the Secret holding the requested
+	// certificate exists and is up to date. It returns true when the Secret has
+	// been created or updated, or when it is not available yet, in which case
+	// the returned Secret is nil.
+	ensureCertificateSecret(ctx context.Context, req certificateRequest) (bool, *corev1.Secret, error)
+}
+
+// newCertificateIssuer returns the issuer of the certificates configured with
+// the provided options.
+func newCertificateIssuer(k8sClient client.Client, clusterCASecretNN types.NamespacedName, certOpts CertificateOptions) certificateIssuer {
+	if certOpts.CertManagerIssuer != nil {
+		return &certManagerIssuer{
+			client:    k8sClient,
+			issuerRef: *certOpts.CertManagerIssuer,
+			certOpts:  certOpts,
+		}
+	}
+	return &clusterCAIssuer{
+		client:            k8sClient,
+		clusterCASecretNN: clusterCASecretNN,
+		certOpts:          certOpts,
+	}
+}
+
+// clusterCAIssuer issues the certificates from the cluster CA managed by the
+// operator.
+type clusterCAIssuer struct {
+	client            client.Client
+	clusterCASecretNN types.NamespacedName
+	certOpts          CertificateOptions
+}
+
+func (i *clusterCAIssuer) ensureCertificateSecret(ctx context.Context, req certificateRequest) (bool, *corev1.Secret, error) {
+	return maybeCreateCertificateSecret(ctx,
+		req.owner,
+		req.subject,
+		req.dnsNames,
+		req.purpose,
+		i.clusterCASecretNN,
+		req.usages,
+		i.certOpts,
+		i.client)
+}
+
+// certManagerIssuer requests the certificates from a cert-manager issuer through
+// Certificates, owned by the DataPlanes and ControlPlanes, and uses the Secrets
+// issued by cert-manager. cert-manager renews the certificates by itself.
+type certManagerIssuer struct {
+	client    client.Client
+	issuerRef CertManagerIssuerRef
+	certOpts  CertificateOptions
+}
+
+func (i *certManagerIssuer) ensureCertificateSecret(ctx context.Context, req certificateRequest) (bool, *corev1.Secret, error) {
+	generatedCertificate := i.generateCertificate(req)
+	existingCertificate := newCertManagerCertificate()
+	err := i.client.Get(ctx, client.ObjectKeyFromObject(generatedCertificate), existingCertificate)
+	if k8serrors.IsNotFound(err) {
+		if err := i.client.Create(ctx, generatedCertificate); err != nil {
+			return false, nil, fmt.Errorf("failed creating Certificate %s: %w", generatedCertificate.GetName(), err)
+		}
+		return true, nil, nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+	if !k8sutils.IsOwnedByRefUID(existingCertificate, req.owner.GetUID()) {
+		return false, nil, fmt.Errorf("Certificate %s exists already and is not owned by %s %s",
+			existingCertificate.GetName(), req.owner.GetObjectKind().GroupVersionKind().Kind, req.owner.GetName())
+	}
+	if !certManagerCertificateSpecMatches(existingCertificate, generatedCertificate) {
+		existingCertificate.Object["spec"] = generatedCertificate.Object["spec"]
+		if err := i.client.Update(ctx, existingCertificate); err != nil {
+			return false, nil, fmt.Errorf("failed updating Certificate %s: %w", existingCertificate.GetName(), err)
+		}
+		return true, nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	err = i.client.Get(ctx, types.NamespacedName{
+		Namespace: generatedCertificate.GetNamespace(),
+		Name:      certManagerCertificateSecretName(existingCertificate),
+	}, secret)
+	if k8serrors.IsNotFound(err) {
+		// The Certificate status update will trigger reconciliation once issued.
+		return true, nil, nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+	if len(secret.Data[corev1.ServiceAccountRootCAKey]) == 0 {
+		return false, nil, fmt.Errorf("Secret %s issued by %s %s holds no CA certificate, which is required to verify the peers",
+			secret.Name, i.issuerRef.Kind, i.issuerRef.Name)
+	}
+
+	// The Secret is owned and labeled like the Secrets issued from the cluster
+	// CA, so that it is watched and that its pods are rolled when it is renewed.
+	// The labels set by cert-manager are kept.
+	var updated bool
+	if !k8sutils.IsOwnedByRefUID(secret, req.owner.GetUID()) {
+		k8sutils.SetOwnerForObject(secret, req.owner)
+		updated = true
+	}
+	for k, v := range certificateSecretLabels(req) {
+		if secret.Labels[k] != v {
+			if secret.Labels == nil {
+				secret.Labels = make(map[string]string)
+			}
+			secret.Labels[k] = v
+			updated = true
+		}
+	}
+	if updated {
+		if err := i.client.Update(ctx, secret); err != nil {
+			return false, nil, fmt.Errorf("failed updating secret %s: %w", secret.Name, err)
+		}
+		return true, secret, nil
+	}
+
+	// The Secrets issued for the same purpose from the cluster CA, before the
+	// cert-manager issuer has been configured, are not used anymore.
+	deleted, err := i.ensureSupersededSecretsDeleted(ctx, req, secret.Name)
+	if err != nil {
+		return false, nil, err
+	}
+	return deleted, secret, nil
+}
+
+// ensureSupersededSecretsDeleted deletes the certificate Secrets of the owner
+// issued for the requested purpose other than the one issued by cert-manager.
+// It returns true when any Secret has been deleted.
+func (i *certManagerIssuer) ensureSupersededSecretsDeleted(ctx context.Context, req certificateRequest, secretName string) (bool, error) {
+	selectorKey, selectorValue := getManagedLabelForOwner(req.owner)
+	purposeRequirement, err := certificatePurposeRequirement(req.purpose)
+	if err != nil {
+		return false, err
+	}
+	secrets, err := k8sutils.ListSecretsForOwner(
+		ctx,
+		i.client,
+		req.owner.GetUID(),
+		client.InNamespace(req.owner.GetNamespace()),
+		client.MatchingLabelsSelector{
+			Selector: labels.SelectorFromSet(labels.Set{
+				selectorKey: selectorValue,
+			}).Add(*purposeRequirement),
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	var deleted bool
+	for _, secret := range secrets {
+		if secret.Name == secretName {
+			continue
+		}
+		if err := i.client.Delete(ctx, &secret); client.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+		deleted = true
+	}
+	return deleted, nil
+}
+
+// generateCertificate generates the cert-manager Certificate requesting the
+// provided certificate. The Certificate is named after its owner and purpose,
+// and so is the Secret cert-manager stores the certificate in.
+func (i *certManagerIssuer) generateCertificate(req certificateRequest) *unstructured.Unstructured {
+	name := fmt.Sprintf("%s-%s", getPrefixForOwner(req.owner), req.owner.GetName())
+	if req.purpose != "" {
+		name = fmt.Sprintf("%s-%s", name, req.purpose)
+	}
+	secretLabels := certificateSecretLabels(req)
+
+	spec := map[string]interface{}{
+		"secretName": name,
+		"secretTemplate": map[string]interface{}{
+			"labels": lo.MapValues(secretLabels, func(v string, _ string) interface{} { return v }),
+		},
+		"dnsNames": lo.Map(certificateDNSNames(req.subject, req.dnsNames), func(dnsName string, _ int) interface{} {
+			return dnsName
+		}),
+		"usages": lo.Map(req.usages, func(usage certificatesv1.KeyUsage, _ int) interface{} {
+			return string(usage)
+		}),
+		"duration": i.certOpts.validity().String(),
+		"privateKey": map[string]interface{}{
+			"algorithm":      string(i.certOpts.keyAlgorithm()),
+			"size":           int64(i.certOpts.keySize()),
+			"rotationPolicy": "Always",
+		},
+		"issuerRef": map[string]interface{}{
+			"name":  i.issuerRef.Name,
+			"kind":  i.issuerRef.Kind,
+			"group": i.issuerRef.Group,
+		},
+	}
+	// The common name is limited to 64 characters, the subject is among the
+	// DNS names of the certificate anyway.
+	if len(req.subject) <= 64 {
+		spec["commonName"] = req.subject
+	}
+	if i.certOpts.RenewBefore > 0 {
+		spec["renewBefore"] = i.certOpts.RenewBefore.String()
+	}
+
+	certificate := newCertManagerCertificate()
+	certificate.SetNamespace(req.owner.GetNamespace())
+	certificate.SetName(name)
+	certificate.SetLabels(secretLabels)
+	k8sutils.SetOwnerForObject(certificate, req.owner)
+	certificate.Object["spec"] = spec
+	return certificate
+}
+
+// certificateSecretLabels returns the labels of the Secret holding the provided
+// certificate.
+func certificateSecretLabels(req certificateRequest) map[string]string {
+	selectorKey, selectorValue := getManagedLabelForOwner(req.owner)
+	secretLabels := map[string]string{selectorKey: selectorValue}
+	if req.purpose != "" {
+		secretLabels[consts.CertificatePurposeLabel] = req.purpose
+	}
+	return secretLabels
+}
+
+// newCertManagerCertificate returns an empty cert-manager Certificate.
+func newCertManagerCertificate() *unstructured.Unstructured {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   consts.CertManagerGroup,
+		Version: "v1",
+		Kind:    "Certificate",
+	})
+	return certificate
+}
+
+// certManagerCertificateSecretName returns the name of the Secret the provided
+// cert-manager Certificate is stored in.
+func certManagerCertificateSecretName(certificate *unstructured.Unstructured) string {
+	secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	return secretName
+}
+
+// certManagerCertificateSpecMatches returns true when the fields of the spec of
+// the generated Certificate are set to the same values in the existing one,
+// regardless of the fields defaulted by cert-manager.
+func certManagerCertificateSpecMatches(existing, generated *unstructured.Unstructured) bool {
+	existingSpec, _, _ := unstructured.NestedMap(existing.Object, "spec")
+	generatedSpec, _, _ := unstructured.NestedMap(generated.Object, "spec")
+	for field, value := range generatedSpec {
+		if !reflect.DeepEqual(existingSpec[field], value) {
+			return false
+		}
+	}
+	// renewBefore and commonName are not always set.
+	for _, field := range []string{"renewBefore", "commonName"} {
+		if _, ok := generatedSpec[field]; !ok {
+			if _, ok := existingSpec[field]; ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ownsCertManagerCertificates makes the controller built with the provided
+// builder watch the cert-manager Certificates it creates, when the certificates
+// are requested from cert-manager. The Certificates are not watched otherwise,
+// as cert-manager might not be installed.
+func ownsCertManagerCertificates(b *builder.Builder, certOpts CertificateOptions) *builder.Builder {
+	if certOpts.CertManagerIssuer == nil {
+		return b
+	}
+	return b.Owns(newCertManagerCertificate())
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+)
+
+func TestCertManagerIssuer(t *testing.T) {
+	ctx := context.Background()
+	dataplane := &operatorv1beta1.DataPlane{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "gateway-operator.konghq.com/v1beta1",
+			Kind:       "DataPlane",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kong",
+			Namespace: "default",
+			UID:       types.UID(uuid.NewString()),
+		},
+	}
+	req := certificateRequest{
+		owner:   dataplane,
+		subject: "kong.default.svc",
+		usages:  []certificatesv1.KeyUsage{certificatesv1.UsageServerAuth},
+	}
+	certOpts := CertificateOptions{
+		Validity:    time.Hour * 24 * 90,
+		RenewBefore: time.Hour * 24 * 30,
+		CertManagerIssuer: &CertManagerIssuerRef{
+			Name:  "issuer",
+			Kind:  "ClusterIssuer",
+			Group: consts.CertManagerGroup,
+		},
+	}
+	// supersededSecret has been issued from the cluster CA before the
+	// cert-manager issuer has been configured.
+	supersededSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dataplane-kong-abcde",
+			Namespace: "default",
+			Labels:    certificateSecretLabels(req),
+		},
+	}
+	k8sutils.SetOwnerForObject(supersededSecret, dataplane)
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(dataplane, supersededSecret).
+		Build()
+	issuer := newCertificateIssuer(fakeClient, types.NamespacedName{}, certOpts)
+
+	getCertificate := func(t *testing.T) *unstructured.Unstructured {
+		certificate := newCertManagerCertificate()
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "dataplane-kong"}, certificate))
+		return certificate
+	}
+
+	t.Log("the Certificate is created and the Secret is waited for")
+	createdOrUpdated, secret, err := issuer.ensureCertificateSecret(ctx, req)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	require.Nil(t, secret)
+	certificate := getCertificate(t)
+	require.True(t, k8sutils.IsOwnedByRefUID(certificate, dataplane.UID))
+	spec, _, err := unstructured.NestedMap(certificate.Object, "spec")
+	require.NoError(t, err)
+	require.Equal(t, "dataplane-kong", spec["secretName"])
+	require.Equal(t, "kong.default.svc", spec["commonName"])
+	require.Equal(t, "2160h0m0s", spec["duration"])
+	require.Equal(t, "720h0m0s", spec["renewBefore"])
+	require.Equal(t, map[string]interface{}{
+		"name":  "issuer",
+		"kind":  "ClusterIssuer",
+		"group": consts.CertManagerGroup,
+	}, spec["issuerRef"])
+
+	createdOrUpdated, secret, err = issuer.ensureCertificateSecret(ctx, req)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	require.Nil(t, secret)
+
+	t.Log("the Secret issued without a CA certificate is rejected")
+	issuedSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dataplane-kong",
+			Namespace: "default",
+			Labels:    map[string]string{"controller.cert-manager.io/fao": "true"},
+			Annotations: map[string]string{
+				consts.CertManagerCertificateNameAnnotation: "dataplane-kong",
+			},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"tls.crt": []byte("cert"),
+			"tls.key": []byte("key"),
+		},
+	}
+	require.NoError(t, fakeClient.Create(ctx, issuedSecret))
+	_, _, err = issuer.ensureCertificateSecret(ctx, req)
+	require.Error(t, err)
+
+	t.Log("the issued Secret is owned and labeled like the Secrets issued from the cluster CA")
+	issuedSecret.Data["ca.crt"] = []byte("ca")
+	require.NoError(t, fakeClient.Update(ctx, issuedSecret))
+	createdOrUpdated, secret, err = issuer.ensureCertificateSecret(ctx, req)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	require.NotNil(t, secret)
+	require.True(t, k8sutils.IsOwnedByRefUID(secret, dataplane.UID))
+	require.Equal(t, "true", secret.Labels["controller.cert-manager.io/fao"])
+	for k, v := range certificateSecretLabels(req) {
+		require.Equal(t, v, secret.Labels[k])
+	}
+
+	t.Log("the Secret issued from the cluster CA is deleted")
+	createdOrUpdated, secret, err = issuer.ensureCertificateSecret(ctx, req)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	require.Equal(t, "dataplane-kong", secret.Name)
+	require.Error(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(supersededSecret), &corev1.Secret{}))
+
+	createdOrUpdated, secret, err = issuer.ensureCertificateSecret(ctx, req)
+	require.NoError(t, err)
+	require.False(t, createdOrUpdated)
+	require.Equal(t, "dataplane-kong", secret.Name)
+
+	t.Log("the Certificate is updated when the certificate options change")
+	certOpts.Validity = time.Hour * 24 * 60
+	issuer = newCertificateIssuer(fakeClient, types.NamespacedName{}, certOpts)
+	createdOrUpdated, secret, err = issuer.ensureCertificateSecret(ctx, req)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	require.Nil(t, secret)
+	duration, _, err := unstructured.NestedString(getCertificate(t).Object, "spec", "duration")
+	require.NoError(t, err)
+	require.Equal(t, "1440h0m0s", duration)
+
+	t.Log("a Certificate not owned by the DataPlane is not taken over")
+	otherDataPlane := dataplane.DeepCopy()
+	otherDataPlane.UID = types.UID(uuid.NewString())
+	_, _, err = issuer.ensureCertificateSecret(ctx, certificateRequest{
+		owner:   otherDataPlane,
+		subject: req.subject,
+		usages:  req.usages,
+	})
+	require.Error(t, err)
+}
@@ -4,15 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,13 +42,17 @@ import (
 type ControlPlaneReconciler struct {
 	client.Client
 	Scheme                   *runtime.Scheme
+	eventRecorder            record.EventRecorder
 	ClusterCASecretName      string
 	ClusterCASecretNamespace string
+	CertificateOptions       CertificateOptions
 	DevelopmentMode          bool
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.eventRecorder = mgr.GetEventRecorderFor("controlplane")
+
 	// for owned objects we need to check if updates to the objects resulted in the
 	// removal of an OwnerReference to the parent object, and if so we need to
 	// enqueue the parent object so that reconciliation can create a replacement.
@@ -55,7 +65,7 @@ func (r *ControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return r.clusterRoleBindingHasControlplaneOwner(e.ObjectOld)
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		// watch Controlplane objects
 		For(&operatorv1alpha1.ControlPlane{}).
 		// watch for changes in Secrets created by the controlplane controller
@@ -64,6 +74,15 @@ func (r *ControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.ServiceAccount{}).
 		// watch for changes in Deployments created by the controlplane controller
 		Owns(&appsv1.Deployment{}).
+		// watch for changes in the admin Services and their EndpointSlices created
+		// by the controlplane controller
+		Owns(&corev1.Service{}).
+		Owns(&discoveryv1.EndpointSlice{}).
+		// watch for changes in PodDisruptionBudgets created by the controlplane controller
+		Owns(&policyv1.PodDisruptionBudget{}).
+		// watch for changes in Roles and RoleBindings created by the controlplane controller
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
 		// watch for changes in ClusterRoles created by the controlplane controller.
 		// Since the ClusterRoles are cluster-wide but controlplanes are namespaced,
 		// we need to manually detect the owner by means of the UID
@@ -80,15 +99,51 @@ func (r *ControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&rbacv1.ClusterRoleBinding{},
 			handler.EnqueueRequestsFromMapFunc(r.getControlplaneForClusterRoleBinding),
 			builder.WithPredicates(clusterRoleBindingPredicate)).
+		// watch for changes in Roles and RoleBindings created by the controlplane
+		// controller in the namespaces watched by the controlplanes. As they can
+		// live in other namespaces than their controlplane, they are labeled with
+		// its UID instead of referring to it as their owner.
+		Watches(
+			&rbacv1.Role{},
+			handler.EnqueueRequestsFromMapFunc(r.getControlPlaneFromUIDLabel),
+			builder.WithPredicates(predicate.NewPredicateFuncs(hasControlPlaneUIDLabel))).
+		Watches(
+			&rbacv1.RoleBinding{},
+			handler.EnqueueRequestsFromMapFunc(r.getControlPlaneFromUIDLabel),
+			builder.WithPredicates(predicate.NewPredicateFuncs(hasControlPlaneUIDLabel))).
+		// watch for changes in the pods of the controlplane Deployments, whose
+		// failures are reported in the status of the controlplanes.
+		Watches(
+			&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(r.getControlPlaneForPod),
+			builder.WithPredicates(hasManagedLabel(consts.ControlPlaneManagedLabelValue))).
+		// watch for changes in the cluster CA Secret, from which the certificates
+		// of the controlplanes are reissued when the CA is rotated.
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.getControlPlanesForClusterCASecret),
+			builder.WithPredicates(isClusterCASecret(types.NamespacedName{
+				Namespace: r.ClusterCASecretNamespace,
+				Name:      r.ClusterCASecretName,
+			}))).
+		// watch for changes in IngressClasses, which are cluster-wide, to create
+		// the ones claimed by the controlplanes once their owner lets them go.
+		Watches(
+			&networkingv1.IngressClass{},
+			handler.EnqueueRequestsFromMapFunc(r.getControlPlanesForIngressClass)).
 		Watches(
 			&operatorv1beta1.DataPlane{},
 			handler.EnqueueRequestsFromMapFunc(r.getControlPlanesFromDataPlane)).
-		// watch for changes in the DataPlane deployments, as we want to be aware of all
-		// the DataPlane pod changes (every time a new pod gets ready, the deployment
-		// status gets updated accordingly, leading to a reconciliation loop trigger)
+		// watch for changes in the admin API endpoints of the dataplanes, which
+		// are mirrored for the controlplanes linked to several dataplanes and
+		// reported in the status of the controlplanes.
 		Watches(
-			&appsv1.Deployment{},
-			handler.EnqueueRequestsFromMapFunc(r.getControlPlanesFromDataPlaneDeployment)).
+			&discoveryv1.EndpointSlice{},
+			handler.EnqueueRequestsFromMapFunc(r.getControlPlanesFromDataPlaneAdminEndpointSlice),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isDataPlaneAdminEndpointSlice)))
+	// watch for changes in the cert-manager Certificates created by the
+	// controlplane controller, when the certificates are requested from cert-manager.
+	return ownsCertManagerCertificates(b, r.CertificateOptions).
 		Complete(r)
 }
 
@@ -133,6 +188,16 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			return ctrl.Result{}, nil // ClusterRoleBinding deletion will requeue
 		}
 
+		// ensure that the rolebindings which were created in the namespaces watched by the ControlPlane are deleted
+		deletions, err = r.ensureWatchNamespaceRoleBindingsDeleted(ctx, controlplane)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if deletions {
+			debug(log, "watch namespace roleBindings deleted", controlplane)
+			return ctrl.Result{}, nil // RoleBinding deletion will requeue
+		}
+
 		// now that ClusterRoleBindings are cleaned up, remove the relevant finalizer
 		if k8sutils.RemoveFinalizerInMetadata(&newControlplane.ObjectMeta, string(ControlPlaneFinalizerCleanupClusterRoleBinding)) {
 			if err := r.Client.Patch(ctx, newControlplane, client.MergeFrom(controlplane)); err != nil {
@@ -142,6 +207,25 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			return ctrl.Result{}, nil // Controlplane update will requeue
 		}
 
+		// ensure that the ingressclass created for the controlplane is deleted
+		deletions, err = r.ensureOwnedIngressClassesDeleted(ctx, controlplane)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if deletions {
+			debug(log, "ingressClass deleted", controlplane)
+			return ctrl.Result{}, nil // IngressClass deletion will requeue
+		}
+
+		// now that the IngressClass is cleaned up, remove the relevant finalizer
+		if k8sutils.RemoveFinalizerInMetadata(&newControlplane.ObjectMeta, string(ControlPlaneFinalizerCleanupIngressClass)) {
+			if err := r.Client.Patch(ctx, newControlplane, client.MergeFrom(controlplane)); err != nil {
+				return ctrl.Result{}, err
+			}
+			debug(log, "ingressClass finalizer removed", controlplane)
+			return ctrl.Result{}, nil // Controlplane update will requeue
+		}
+
 		// ensure that the clusterroles created for the controlplane are deleted
 		deletions, err = r.ensureOwnedClusterRolesDeleted(ctx, controlplane)
 		if err != nil {
@@ -152,6 +236,16 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			return ctrl.Result{}, nil // ClusterRole deletion will requeue
 		}
 
+		// ensure that the roles created in the namespaces watched by the ControlPlane are deleted
+		deletions, err = r.ensureWatchNamespaceRolesDeleted(ctx, controlplane)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if deletions {
+			debug(log, "watch namespace roles deleted", controlplane)
+			return ctrl.Result{}, nil // Role deletion will requeue
+		}
+
 		// now that ClusterRoles are cleaned up, remove the relevant finalizer
 		if k8sutils.RemoveFinalizerInMetadata(&newControlplane.ObjectMeta, string(ControlPlaneFinalizerCleanupClusterRole)) {
 			if err := r.Client.Patch(ctx, newControlplane, client.MergeFrom(controlplane)); err != nil {
@@ -169,7 +263,8 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	// ensure the controlplane has a finalizer to delete owned cluster wide resources on delete.
 	finalizersChanged := k8sutils.EnsureFinalizersInMetadata(&controlplane.ObjectMeta,
 		string(ControlPlaneFinalizerCleanupClusterRole),
-		string(ControlPlaneFinalizerCleanupClusterRoleBinding))
+		string(ControlPlaneFinalizerCleanupClusterRoleBinding),
+		string(ControlPlaneFinalizerCleanupIngressClass))
 	if finalizersChanged {
 		trace(log, "update metadata of control plane to set finalizer", controlplane)
 		if err := r.Client.Update(ctx, controlplane); err != nil {
@@ -195,7 +290,6 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	trace(log, "retrieving connected dataplane", controlplane)
 	dataplane, err := gatewayutils.GetDataPlaneForControlPlane(ctx, r.Client, controlplane)
 	var dataplaneProxyServiceName, dataplaneAdminServiceName string
-	var dataPlanePodIP string
 	if err != nil {
 		if !errors.Is(err, operatorerrors.ErrDataPlaneNotSet) {
 			return ctrl.Result{}, err
@@ -214,13 +308,23 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			return ctrl.Result{}, err
 		}
 
-		trace(log, "retrieving the newest DataPlane pod", controlplane)
-		dataPlanePod, err := getDataPlanePod(ctx, r.Client, dataplane.Name, dataplane.Namespace)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-		trace(log, "retrieving the newest DataPlane pod", controlplane, "dataplane", dataPlanePod.Name)
-		dataPlanePodIP = dataPlanePod.Status.PodIP
+	}
+
+	var dataplaneAdminTLSServerName string
+	trace(log, "ensuring admin Service for ControlPlane linked to several DataPlanes", controlplane)
+	createdOrUpdated, controlplaneAdminService, err := r.ensureAdminServiceForControlPlane(ctx, controlplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if createdOrUpdated {
+		debug(log, "admin service updated", controlplane)
+		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the owned object
+	}
+	if controlplaneAdminService != nil {
+		// the admin APIs of all the DataPlanes are discovered through the
+		// ControlPlane's own admin Service, and their certificates share a name.
+		dataplaneAdminServiceName = controlplaneAdminService.Name
+		dataplaneAdminTLSServerName = consts.DataPlaneAdminAPITLSServerName
 	}
 
 	trace(log, "validating ControlPlane configuration", controlplane)
@@ -230,40 +334,25 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	trace(log, "configuring ControlPlane resource", controlplane)
-	changed := setControlPlaneDefaults(
+	// the defaults that do not depend on the DataPlane are set at admission time
+	// by the mutating webhook. All the defaults are set here, to configure the
+	// ControlPlane Deployment, but they are not written back to the ControlPlane.
+	_ = setControlPlaneDefaults(
 		&controlplane.Spec.ControlPlaneOptions,
 		nil,
 		controlPlaneDefaultsArgs{
-			dataPlanePodIP:            dataPlanePodIP,
-			namespace:                 controlplane.Namespace,
-			dataplaneProxyServiceName: dataplaneProxyServiceName,
-			dataplaneAdminServiceName: dataplaneAdminServiceName,
+			namespace:                   controlplane.Namespace,
+			dataplaneProxyServiceName:   dataplaneProxyServiceName,
+			dataplaneAdminServiceName:   dataplaneAdminServiceName,
+			dataplaneAdminTLSServerName: dataplaneAdminTLSServerName,
 		})
-	if changed {
-		debug(log, "updating ControlPlane resource after defaults are set since resource has changed", controlplane)
-		err := r.Client.Update(ctx, controlplane)
-		if err != nil {
-			if k8serrors.IsConflict(err) {
-				debug(log, "conflict found when updating ControlPlane resource, retrying", controlplane)
-				return ctrl.Result{Requeue: true, RequeueAfter: requeueWithoutBackoff}, nil
-			}
-			return ctrl.Result{}, fmt.Errorf("failed updating ControlPlane: %w", err)
-		}
-		return ctrl.Result{}, nil // no need to requeue, the update will trigger.
-	}
-
-	trace(log, "validating that the ControlPlane's DataPlane configuration is up to date", controlplane)
-	if err = r.ensureDataPlaneConfiguration(ctx, controlplane, dataplaneProxyServiceName); err != nil {
-		if k8serrors.IsConflict(err) {
-			debug(
-				log,
-				"conflict found when trying to ensure ControlPlane's DataPlane configuration was up to date, retrying",
-				controlplane,
-			)
-			return ctrl.Result{Requeue: true, RequeueAfter: requeueWithoutBackoff}, nil
-		}
-		return ctrl.Result{}, err
-	}
+
+	trace(log, "configuring the ControlPlane's DataPlane configuration", controlplane)
+	_ = setControlPlaneEnvOnDataPlaneChange(
+		&controlplane.Spec.ControlPlaneOptions,
+		controlplane.Namespace,
+		dataplaneProxyServiceName,
+	)
 
 	trace(log, "validating ControlPlane's DataPlane status", controlplane)
 	dataplaneIsSet := r.ensureDataPlaneStatus(controlplane, dataplane)
@@ -303,6 +392,46 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the owned object
 	}
 
+	trace(log, "ensuring Roles for the namespaces watched by the ControlPlane exist", controlplane)
+	createdOrUpdated, watchNamespaceRoleNames, err := r.ensureWatchNamespaceRolesForControlPlane(ctx, controlplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if createdOrUpdated {
+		debug(log, "watch namespace roles updated", controlplane)
+		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the labeled objects
+	}
+
+	trace(log, "ensuring that RoleBindings for the namespaces watched by the ControlPlane exist", controlplane)
+	createdOrUpdated, err = r.ensureWatchNamespaceRoleBindingsForControlPlane(ctx, controlplane, controlplaneServiceAccount.Name, watchNamespaceRoleNames)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if createdOrUpdated {
+		debug(log, "watch namespace roleBindings updated", controlplane)
+		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the labeled objects
+	}
+
+	trace(log, "ensuring Role for ControlPlane leader election exists", controlplane)
+	createdOrUpdated, controlplaneRole, err := r.ensureRoleForControlPlane(ctx, controlplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if createdOrUpdated {
+		debug(log, "role updated", controlplane)
+		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the owned object
+	}
+
+	trace(log, "ensuring that RoleBinding for ControlPlane leader election exists", controlplane)
+	createdOrUpdated, _, err = r.ensureRoleBindingForControlPlane(ctx, controlplane, controlplaneServiceAccount.Name, controlplaneRole.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if createdOrUpdated {
+		debug(log, "roleBinding updated", controlplane)
+		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the owned object
+	}
+
 	trace(log, "creating mTLS certificate", controlplane)
 	created, certSecret, err := r.ensureCertificate(ctx, controlplane)
 	if err != nil {
@@ -330,16 +459,64 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the owned object
 	}
+
+	trace(log, "ensuring ControlPlane pod disruption budget", controlplane)
+	createdOrUpdated, _, err = r.ensurePodDisruptionBudgetForControlPlane(ctx, controlplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if createdOrUpdated {
+		debug(log, "pod disruption budget created/updated", controlplane)
+		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the owned object
+	}
+
+	trace(log, "ensuring the admin API endpoints of the ControlPlane's DataPlanes are provided", controlplane)
+	createdOrUpdated, err = r.ensureDataPlaneLinks(ctx, controlplane, controlplaneAdminService)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if createdOrUpdated {
+		debug(log, "admin endpointSlices updated", controlplane)
+		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the owned objects
+	}
+
+	trace(log, "ensuring the IngressClass claimed by the ControlPlane exists", controlplane)
+	createdOrUpdated, err = r.ensureIngressClassForControlPlane(ctx, controlplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if createdOrUpdated {
+		debug(log, "ingressClass updated", controlplane)
+		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the watched object
+	}
+
+	trace(log, "checking that the ControlPlane's controller options are applied", controlplane)
+	controlplaneImage, err := generateControlPlaneImage(&controlplane.Spec.ControlPlaneOptions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	r.ensureControllerOptionsStatus(controlplane, controlplaneImage)
+
+	trace(log, "scheduling the renewal of ControlPlane certificates", controlplane)
+	renewalDelay, err := certificatesRenewalDelay(ctx, r.Client, controlplane, r.CertificateOptions.RenewBefore)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	trace(log, "checking readiness of ControlPlane deployments", controlplane)
+	r.ensureReplicasStatus(controlplane, controlplaneDeployment)
+	if err := r.ensureLeaderStatus(ctx, controlplane); err != nil {
+		return ctrl.Result{}, err
+	}
 
 	if controlplaneDeployment.Status.Replicas == 0 || controlplaneDeployment.Status.AvailableReplicas < controlplaneDeployment.Status.Replicas {
 		trace(log, "deployment for ControlPlane not ready yet", controlplaneDeployment)
-		// Set Ready to false for controlplane as the underlying deployment is not ready.
-		k8sutils.SetCondition(
-			k8sutils.NewCondition(k8sutils.ReadyType, metav1.ConditionFalse, k8sutils.WaitingToBecomeReadyReason, k8sutils.WaitingToBecomeReadyMessage),
-			controlplane,
-		)
-		return ctrl.Result{}, r.patchStatus(ctx, log, controlplane)
+		// Set Ready to false for controlplane as the underlying deployment is not ready,
+		// with the reason why its pods are failing if they are.
+		if err := setNotReadyForUnavailableDeployment(ctx, r.Client, r.eventRecorder, controlplane, controlplaneDeployment); err != nil {
+			return ctrl.Result{}, err
+		}
+		return requeueBefore(ctrl.Result{RequeueAfter: renewalDelay}, controlPlaneLeaderResyncInterval), r.patchStatus(ctx, log, controlplane)
 	}
 
 	r.ensureIsMarkedProvisioned(controlplane)
@@ -350,7 +527,7 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	debug(log, "reconciliation complete for ControlPlane resource", controlplane)
-	return ctrl.Result{}, nil
+	return requeueBefore(ctrl.Result{RequeueAfter: renewalDelay}, controlPlaneLeaderResyncInterval), nil
 }
 
 // patchStatus Patches the resource status only when there are changes in the Conditions
@@ -362,10 +539,19 @@ func (r *ControlPlaneReconciler) patchStatus(ctx context.Context, log logr.Logge
 		return err
 	}
 
-	if k8sutils.NeedsUpdate(current, updated) {
+	if k8sutils.NeedsUpdate(current, updated) || controlPlaneReplicasChanged(current, updated) ||
+		!reflect.DeepEqual(current.Status.DataPlanes, updated.Status.DataPlanes) {
 		debug(log, "patching ControlPlane status", updated, "status", updated.Status)
 		return r.Client.Status().Patch(ctx, updated, client.MergeFrom(current))
 	}
 
 	return nil
 }
+
+// controlPlaneReplicasChanged returns a boolean indicating whether the replicas
+// or the leader reported in the provided ControlPlane statuses differ.
+func controlPlaneReplicasChanged(current, updated *operatorv1alpha1.ControlPlane) bool {
+	return current.Status.Replicas != updated.Status.Replicas ||
+		current.Status.ReadyReplicas != updated.Status.ReadyReplicas ||
+		current.Status.Leader != updated.Status.Leader
+}
@@ -11,6 +11,17 @@ const (
 	// not all Deployments (or Daemonsets) for the ControlPlane have been provisioned
 	// successfully.
 	ControlPlaneConditionTypeProvisioned k8sutils.ConditionType = "Provisioned"
+
+	// ControlPlaneConditionTypeControllerOptionsValid is a condition type indicating
+	// whether all the typed controller options of the ControlPlane are known to
+	// and supported by its controller, and therefore applied. It is not set for
+	// the ControlPlanes which do not set any controller option.
+	ControlPlaneConditionTypeControllerOptionsValid k8sutils.ConditionType = "ControllerOptionsValid"
+
+	// ControlPlaneConditionTypeIngressClassClaimed is a condition type indicating
+	// whether the IngressClass set for the ControlPlane is handled by it only.
+	// It is not set for the ControlPlanes which do not set any IngressClass.
+	ControlPlaneConditionTypeIngressClassClaimed k8sutils.ConditionType = "IngressClassClaimed"
 )
 
 // -----------------------------------------------------------------------------
@@ -32,4 +43,26 @@ const (
 	// ControlPlaneConditionsReasonNoDataplane is a reason which indicates that no DataPlane
 	// has been provisioned.
 	ControlPlaneConditionReasonNoDataplane k8sutils.ConditionReason = "NoDataplane"
+
+	// ControlPlaneConditionReasonControllerOptionsApplied is a reason which indicates
+	// that all the controller options of a ControlPlane are applied.
+	ControlPlaneConditionReasonControllerOptionsApplied k8sutils.ConditionReason = "ControllerOptionsApplied"
+
+	// ControlPlaneConditionReasonInvalidControllerOptions is a reason which indicates
+	// that some controller options of a ControlPlane are unknown to or unsupported
+	// by its controller, or overridden by its pod template.
+	ControlPlaneConditionReasonInvalidControllerOptions k8sutils.ConditionReason = "InvalidControllerOptions"
+
+	// ControlPlaneConditionReasonIngressClassManaged is a reason which indicates
+	// that the IngressClass of a ControlPlane is created and owned by it.
+	ControlPlaneConditionReasonIngressClassManaged k8sutils.ConditionReason = "IngressClassManaged"
+
+	// ControlPlaneConditionReasonIngressClassUnmanaged is a reason which indicates
+	// that the IngressClass of a ControlPlane existed already, and is left as is.
+	ControlPlaneConditionReasonIngressClassUnmanaged k8sutils.ConditionReason = "IngressClassUnmanaged"
+
+	// ControlPlaneConditionReasonIngressClassConflict is a reason which indicates
+	// that the IngressClass of a ControlPlane is claimed by another ControlPlane
+	// or handled by another controller.
+	ControlPlaneConditionReasonIngressClassConflict k8sutils.ConditionReason = "IngressClassConflict"
 )
@@ -8,8 +8,12 @@ package controllers
 type ControlPlaneFinalizer string
 
 const (
-	// ControlPlaneFinalizerCleanupClusterRole is the finalizer to cleanup clusterroles owned by controlplane on deleting.
+	// ControlPlaneFinalizerCleanupClusterRole is the finalizer to cleanup clusterroles owned by controlplane on deleting,
+	// along with the roles created in the namespaces it watches.
 	ControlPlaneFinalizerCleanupClusterRole ControlPlaneFinalizer = "gateway-operator.konghq.com/cleanup-clusterrole"
-	// ControlPlaneFinalizerCleanupClusterRoleBinding is the finalizer to cleanup clusterrolebindings owned by controlplane on deleting.
+	// ControlPlaneFinalizerCleanupClusterRoleBinding is the finalizer to cleanup clusterrolebindings owned by controlplane on deleting,
+	// along with the rolebindings created in the namespaces it watches.
 	ControlPlaneFinalizerCleanupClusterRoleBinding ControlPlaneFinalizer = "gateway-operator.konghq.com/cleanup-clusterrolebinding"
+	// ControlPlaneFinalizerCleanupIngressClass is the finalizer to cleanup the ingressclass owned by controlplane on deleting.
+	ControlPlaneFinalizerCleanupIngressClass ControlPlaneFinalizer = "gateway-operator.konghq.com/cleanup-ingressclass"
 )
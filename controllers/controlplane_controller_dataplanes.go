@@ -0,0 +1,364 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	operatorerrors "github.com/kong/gateway-operator/internal/errors"
+	gatewayutils "github.com/kong/gateway-operator/internal/utils/gateway"
+	"github.com/kong/gateway-operator/internal/utils/index"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sreduce "github.com/kong/gateway-operator/internal/utils/kubernetes/reduce"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
+)
+
+// -----------------------------------------------------------------------------
+// ControlPlaneReconciler - Multiple DataPlanes
+// -----------------------------------------------------------------------------
+
+// controlPlaneHasAdditionalDataPlanes returns true when the ControlPlane pushes
+// its configuration to other DataPlanes than the one referenced by its
+// DataPlane field. The admin APIs of all its DataPlanes are then provided to
+// the ControlPlane through its own admin Service, whose endpoints are mirrored
+// from the admin Services of the DataPlanes.
+func controlPlaneHasAdditionalDataPlanes(controlplane *operatorv1alpha1.ControlPlane) bool {
+	return len(controlplane.Spec.DataPlanes) > 0 || controlplane.Spec.DataPlaneSelector != nil
+}
+
+// ensureAdminServiceForControlPlane ensures the admin Service of a ControlPlane
+// linked to several DataPlanes exists, and deletes it otherwise. It returns a
+// nil Service when the ControlPlane does not need one.
+func (r *ControlPlaneReconciler) ensureAdminServiceForControlPlane(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+) (createdOrUpdated bool, svc *corev1.Service, err error) {
+	services, err := k8sutils.ListServicesForOwner(
+		ctx,
+		r.Client,
+		controlplane.Namespace,
+		controlplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.ControlPlaneManagedLabelValue,
+		},
+	)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if !controlPlaneHasAdditionalDataPlanes(controlplane) {
+		for _, service := range services {
+			service := service
+			if err := r.Client.Delete(ctx, &service); client.IgnoreNotFound(err) != nil {
+				return false, nil, fmt.Errorf("failed deleting ControlPlane admin Service %s: %w", service.Name, err)
+			}
+		}
+		return len(services) > 0, nil, nil
+	}
+
+	count := len(services)
+	if count > 1 {
+		if err := k8sreduce.ReduceServices(ctx, r.Client, services); err != nil {
+			return false, nil, err
+		}
+		return false, nil, errors.New("number of services reduced")
+	}
+
+	generatedService := k8sresources.GenerateNewAdminServiceForControlPlane(controlplane)
+	addLabelForControlPlane(generatedService)
+	k8sutils.SetOwnerForObject(generatedService, controlplane)
+
+	if count == 1 {
+		var updated bool
+		existingService := &services[0]
+		updated, existingService.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingService.ObjectMeta, generatedService.ObjectMeta)
+
+		if !cmp.Equal(existingService.Spec.Ports, generatedService.Spec.Ports) {
+			existingService.Spec.Ports = generatedService.Spec.Ports
+			updated = true
+		}
+
+		if updated {
+			if err := r.Client.Update(ctx, existingService); err != nil {
+				return false, existingService, fmt.Errorf("failed updating ControlPlane admin Service %s: %w", existingService.Name, err)
+			}
+			return true, existingService, nil
+		}
+		return false, existingService, nil
+	}
+
+	return true, generatedService, r.Client.Create(ctx, generatedService)
+}
+
+// ensureDataPlaneLinks reports in the ControlPlane status whether the admin API
+// of each of its DataPlanes is available, and mirrors the admin API endpoints of
+// the DataPlanes in the EndpointSlices of the provided ControlPlane admin
+// Service. When the admin Service is nil, the mirrored EndpointSlices are deleted.
+// It returns true when any EndpointSlice has been created, updated or deleted.
+func (r *ControlPlaneReconciler) ensureDataPlaneLinks(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+	adminService *corev1.Service,
+) (bool, error) {
+	dataplanes, err := gatewayutils.ListDataPlanesForControlPlane(ctx, r.Client, controlplane)
+	if err != nil {
+		return false, err
+	}
+
+	statuses := make([]operatorv1alpha1.ControlPlaneDataPlaneStatus, 0, len(dataplanes))
+	sources := make([]discoveryv1.EndpointSlice, 0)
+	for _, name := range gatewayutils.ControlPlaneDataPlaneNames(controlplane) {
+		if !lo.ContainsBy(dataplanes, func(dataplane operatorv1beta1.DataPlane) bool {
+			return dataplane.Namespace == controlplane.Namespace && dataplane.Name == name
+		}) {
+			statuses = append(statuses, operatorv1alpha1.ControlPlaneDataPlaneStatus{
+				Namespace: controlplane.Namespace,
+				Name:      name,
+				Message:   "DataPlane not found",
+			})
+		}
+	}
+	for i := range dataplanes {
+		dataplane := &dataplanes[i]
+		status := operatorv1alpha1.ControlPlaneDataPlaneStatus{
+			Namespace: dataplane.Namespace,
+			Name:      dataplane.Name,
+		}
+		endpointSlices, err := r.listDataPlaneAdminEndpointSlices(ctx, dataplane)
+		switch {
+		case err != nil:
+			status.Message = err.Error()
+		case countReadyEndpoints(endpointSlices) == 0:
+			status.Message = "no ready admin API endpoint"
+		default:
+			status.Linked = true
+		}
+		statuses = append(statuses, status)
+		sources = append(sources, endpointSlices...)
+	}
+	controlplane.Status.DataPlanes = nil
+	if len(statuses) > 0 {
+		controlplane.Status.DataPlanes = statuses
+	}
+
+	return r.ensureAdminEndpointSlicesForControlPlane(ctx, controlplane, adminService, sources)
+}
+
+// listDataPlaneAdminEndpointSlices returns the EndpointSlices of the admin
+// Service of the provided DataPlane.
+func (r *ControlPlaneReconciler) listDataPlaneAdminEndpointSlices(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) ([]discoveryv1.EndpointSlice, error) {
+	adminServiceName, err := gatewayutils.GetDataplaneServiceName(ctx, r.Client, dataplane, consts.DataPlaneAdminServiceLabelValue)
+	if err != nil {
+		return nil, err
+	}
+
+	endpointSliceList := &discoveryv1.EndpointSliceList{}
+	if err := r.Client.List(ctx, endpointSliceList,
+		client.InNamespace(dataplane.Namespace),
+		client.MatchingLabels{discoveryv1.LabelServiceName: adminServiceName},
+	); err != nil {
+		return nil, err
+	}
+	return endpointSliceList.Items, nil
+}
+
+// ensureAdminEndpointSlicesForControlPlane mirrors each of the provided
+// EndpointSlices of the DataPlane admin Services in an EndpointSlice of the
+// ControlPlane admin Service, and deletes the outdated mirrored EndpointSlices.
+func (r *ControlPlaneReconciler) ensureAdminEndpointSlicesForControlPlane(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+	adminService *corev1.Service,
+	sources []discoveryv1.EndpointSlice,
+) (bool, error) {
+	endpointSlices, err := k8sutils.ListEndpointSlicesForOwner(
+		ctx,
+		r.Client,
+		controlplane.Namespace,
+		controlplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.ControlPlaneManagedLabelValue,
+			discoveryv1.LabelManagedBy:            consts.ControlPlaneEndpointSliceManagedByLabelValue,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	generatedEndpointSlices := make(map[string]*discoveryv1.EndpointSlice, len(sources))
+	if adminService != nil {
+		for i := range sources {
+			generated := k8sresources.GenerateNewAdminEndpointSliceForControlPlane(controlplane, adminService.Name, &sources[i])
+			addLabelForControlPlane(generated)
+			k8sutils.SetOwnerForObject(generated, controlplane)
+			generatedEndpointSlices[string(sources[i].UID)] = generated
+		}
+	}
+
+	var changed bool
+	for i := range endpointSlices {
+		existing := &endpointSlices[i]
+		sourceUID := existing.Labels[consts.EndpointSliceSourceUIDLabel]
+		generated, ok := generatedEndpointSlices[sourceUID]
+		// The address type of an EndpointSlice is immutable.
+		if !ok || existing.AddressType != generated.AddressType {
+			if err := r.Client.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+				return false, fmt.Errorf("failed deleting ControlPlane admin EndpointSlice %s: %w", existing.Name, err)
+			}
+			changed = true
+			continue
+		}
+		delete(generatedEndpointSlices, sourceUID)
+
+		var updated bool
+		updated, existing.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existing.ObjectMeta, generated.ObjectMeta)
+		if !cmp.Equal(existing.Endpoints, generated.Endpoints) {
+			existing.Endpoints = generated.Endpoints
+			updated = true
+		}
+		if !cmp.Equal(existing.Ports, generated.Ports) {
+			existing.Ports = generated.Ports
+			updated = true
+		}
+		if updated {
+			if err := r.Client.Update(ctx, existing); err != nil {
+				return false, fmt.Errorf("failed updating ControlPlane admin EndpointSlice %s: %w", existing.Name, err)
+			}
+			changed = true
+		}
+	}
+
+	for _, generated := range generatedEndpointSlices {
+		if err := r.Client.Create(ctx, generated); err != nil {
+			return false, fmt.Errorf("failed creating ControlPlane admin EndpointSlice: %w", err)
+		}
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// countReadyEndpoints returns the number of ready endpoints of the provided
+// EndpointSlices.
+func countReadyEndpoints(endpointSlices []discoveryv1.EndpointSlice) int {
+	var count int
+	for _, endpointSlice := range endpointSlices {
+		for _, endpoint := range endpointSlice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// listControlPlanesForDataPlane returns the ControlPlanes pushing their
+// configuration to the provided DataPlane, either referencing it by name or
+// selecting it through their DataPlane selector.
+func listControlPlanesForDataPlane(
+	ctx context.Context,
+	c client.Client,
+	dataplane *operatorv1beta1.DataPlane,
+) ([]operatorv1alpha1.ControlPlane, error) {
+	byName := &operatorv1alpha1.ControlPlaneList{}
+	if err := c.List(ctx, byName,
+		client.InNamespace(dataplane.Namespace),
+		client.MatchingFields{
+			index.DataplaneNameIndex: dataplane.Name,
+		}); err != nil {
+		return nil, err
+	}
+
+	bySelector := &operatorv1alpha1.ControlPlaneList{}
+	if err := c.List(ctx, bySelector,
+		client.MatchingFields{
+			index.DataPlaneSelectorNamespaceIndex: dataplane.Namespace,
+		}); err != nil {
+		return nil, err
+	}
+
+	controlplanes := byName.Items
+	for _, controlplane := range bySelector.Items {
+		controlplane := controlplane
+		if !gatewayutils.ControlPlaneReferencesDataPlane(&controlplane, dataplane) ||
+			lo.ContainsBy(controlplanes, func(cp operatorv1alpha1.ControlPlane) bool { return cp.UID == controlplane.UID }) {
+			continue
+		}
+		controlplanes = append(controlplanes, controlplane)
+	}
+	return controlplanes, nil
+}
+
+// -----------------------------------------------------------------------------
+// ControlPlaneReconciler - Multiple DataPlanes watch
+// -----------------------------------------------------------------------------
+
+// isDataPlaneAdminEndpointSlice filters the EndpointSlices of the Services,
+// excluding the ones mirrored by the controlplane controller, which are watched
+// as owned objects.
+func isDataPlaneAdminEndpointSlice(obj client.Object) bool {
+	labels := obj.GetLabels()
+	_, ok := labels[discoveryv1.LabelServiceName]
+	return ok && labels[discoveryv1.LabelManagedBy] != consts.ControlPlaneEndpointSliceManagedByLabelValue
+}
+
+// getControlPlanesFromDataPlaneAdminEndpointSlice maps the EndpointSlices of the
+// DataPlane admin Services on the ControlPlanes pushing their configuration to
+// the DataPlanes.
+func (r *ControlPlaneReconciler) getControlPlanesFromDataPlaneAdminEndpointSlice(ctx context.Context, obj client.Object) (recs []reconcile.Request) {
+	endpointSlice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		log.FromContext(ctx).Error(
+			operatorerrors.ErrUnexpectedObject,
+			"failed to map ControlPlane on EndpointSlice",
+			"expected", "EndpointSlice", "found", reflect.TypeOf(obj),
+		)
+		return
+	}
+
+	service := &corev1.Service{}
+	if err := r.Client.Get(ctx, types.NamespacedName{
+		Namespace: endpointSlice.Namespace,
+		Name:      endpointSlice.Labels[discoveryv1.LabelServiceName],
+	}, service); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "failed to map ControlPlane on EndpointSlice")
+		}
+		return
+	}
+	if service.Labels[consts.DataPlaneServiceTypeLabel] != string(consts.DataPlaneAdminServiceLabelValue) {
+		return
+	}
+
+	for _, ownRef := range service.OwnerReferences {
+		if ownRef.APIVersion != operatorv1beta1.SchemeGroupVersion.String() || ownRef.Kind != "DataPlane" {
+			continue
+		}
+		dataplane := &operatorv1beta1.DataPlane{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: service.Namespace, Name: ownRef.Name}, dataplane); err != nil {
+			if !k8serrors.IsNotFound(err) {
+				log.FromContext(ctx).Error(err, "failed to map ControlPlane on EndpointSlice")
+			}
+			return
+		}
+		return r.getControlPlanesFromDataPlane(ctx, dataplane)
+	}
+	return
+}
@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	"github.com/kong/gateway-operator/internal/utils/index"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+)
+
+func TestControlPlaneMultipleDataPlanes(t *testing.T) {
+	ctx := context.Background()
+	controlplane := &operatorv1alpha1.ControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			UID:       types.UID(uuid.NewString()),
+		},
+		Spec: operatorv1alpha1.ControlPlaneSpec{
+			ControlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+				DataPlane:  lo.ToPtr("dp-eu"),
+				DataPlanes: []string{"dp-eu-canary", "dp-missing"},
+				DataPlaneSelector: &operatorv1alpha1.DataPlaneSelector{
+					Namespaces: []string{"region-us"},
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"fleet": "regional"},
+					},
+				},
+			},
+		},
+	}
+	newDataPlane := func(namespace, name string, labels map[string]string) *operatorv1beta1.DataPlane {
+		return &operatorv1beta1.DataPlane{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "gateway-operator.konghq.com/v1beta1",
+				Kind:       "DataPlane",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				UID:       types.UID(uuid.NewString()),
+				Labels:    labels,
+			},
+		}
+	}
+	newAdminService := func(dataplane *operatorv1beta1.DataPlane) *corev1.Service {
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      dataplane.Name + "-admin",
+				Namespace: dataplane.Namespace,
+				Labels: map[string]string{
+					consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+					consts.DataPlaneServiceTypeLabel:      string(consts.DataPlaneAdminServiceLabelValue),
+				},
+			},
+		}
+		k8sutils.SetOwnerForObject(service, dataplane)
+		return service
+	}
+	newAdminEndpointSlice := func(service *corev1.Service, ready bool, addresses ...string) *discoveryv1.EndpointSlice {
+		endpointSlice := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      service.Name + "-abcde",
+				Namespace: service.Namespace,
+				UID:       types.UID(uuid.NewString()),
+				Labels: map[string]string{
+					discoveryv1.LabelServiceName: service.Name,
+					discoveryv1.LabelManagedBy:   "endpointslice-controller.k8s.io",
+				},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports: []discoveryv1.EndpointPort{
+				{Name: lo.ToPtr(consts.DataPlaneAdminServicePortName), Port: lo.ToPtr(int32(consts.DataPlaneAdminAPIPort))},
+			},
+		}
+		for _, address := range addresses {
+			endpointSlice.Endpoints = append(endpointSlice.Endpoints, discoveryv1.Endpoint{
+				Addresses:  []string{address},
+				Conditions: discoveryv1.EndpointConditions{Ready: lo.ToPtr(ready)},
+			})
+		}
+		return endpointSlice
+	}
+
+	dataplaneEU := newDataPlane("default", "dp-eu", nil)
+	dataplaneEUCanary := newDataPlane("default", "dp-eu-canary", nil)
+	dataplaneUS := newDataPlane("region-us", "dp-us", map[string]string{"fleet": "regional"})
+	dataplaneUS.Annotations = map[string]string{consts.DataPlaneAllowedControlPlaneNamespacesAnnotation: "kong, default"}
+	dataplaneUSStaging := newDataPlane("region-us", "dp-us-staging", map[string]string{"fleet": "staging"})
+	dataplaneUSStaging.Annotations = dataplaneUS.Annotations
+	dataplaneUSPrivate := newDataPlane("region-us", "dp-us-private", map[string]string{"fleet": "regional"})
+	adminServiceEU := newAdminService(dataplaneEU)
+	adminServiceEUCanary := newAdminService(dataplaneEUCanary)
+	adminServiceUS := newAdminService(dataplaneUS)
+	adminServiceUSStaging := newAdminService(dataplaneUSStaging)
+	adminServiceUSPrivate := newAdminService(dataplaneUSPrivate)
+	endpointSliceEU := newAdminEndpointSlice(adminServiceEU, true, "10.0.0.1", "10.0.0.2")
+	endpointSliceEUCanary := newAdminEndpointSlice(adminServiceEUCanary, false, "10.0.1.1")
+	endpointSliceUS := newAdminEndpointSlice(adminServiceUS, true, "10.1.0.1")
+	endpointSliceUSStaging := newAdminEndpointSlice(adminServiceUSStaging, true, "10.2.0.1")
+	endpointSliceUSPrivate := newAdminEndpointSlice(adminServiceUSPrivate, true, "10.3.0.1")
+
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(
+			controlplane,
+			dataplaneEU, dataplaneEUCanary, dataplaneUS, dataplaneUSStaging, dataplaneUSPrivate,
+			adminServiceEU, adminServiceEUCanary, adminServiceUS, adminServiceUSStaging, adminServiceUSPrivate,
+			endpointSliceEU, endpointSliceEUCanary, endpointSliceUS, endpointSliceUSStaging, endpointSliceUSPrivate,
+		).
+		WithIndex(&operatorv1alpha1.ControlPlane{}, index.DataplaneNameIndex, index.DataPlaneNamesOnControlPlane).
+		WithIndex(&operatorv1alpha1.ControlPlane{}, index.DataPlaneSelectorNamespaceIndex, index.DataPlaneSelectorNamespacesOnControlPlane).
+		Build()
+	reconciler := ControlPlaneReconciler{Client: fakeClient}
+
+	listMirroredEndpointSlices := func(t *testing.T) map[string]discoveryv1.EndpointSlice {
+		endpointSlices, err := k8sutils.ListEndpointSlicesForOwner(ctx, fakeClient, controlplane.Namespace, controlplane.UID)
+		require.NoError(t, err)
+		return lo.KeyBy(endpointSlices, func(endpointSlice discoveryv1.EndpointSlice) string {
+			return endpointSlice.Labels[consts.EndpointSliceSourceUIDLabel]
+		})
+	}
+	ensureDataPlaneLinks := func(t *testing.T, adminService *corev1.Service) {
+		for i := 0; i < 3; i++ {
+			changed, err := reconciler.ensureDataPlaneLinks(ctx, controlplane, adminService)
+			require.NoError(t, err)
+			if !changed {
+				return
+			}
+		}
+		require.Fail(t, "the admin EndpointSlices have not converged")
+	}
+
+	t.Log("the ControlPlane gets its own admin Service")
+	created, adminService, err := reconciler.ensureAdminServiceForControlPlane(ctx, controlplane)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.NotNil(t, adminService)
+	require.Equal(t, corev1.ClusterIPNone, adminService.Spec.ClusterIP)
+	require.Empty(t, adminService.Spec.Selector)
+	updated, _, err := reconciler.ensureAdminServiceForControlPlane(ctx, controlplane)
+	require.NoError(t, err)
+	require.False(t, updated)
+
+	t.Log("the admin API endpoints of all the DataPlanes are mirrored in the admin Service")
+	ensureDataPlaneLinks(t, adminService)
+	mirrored := listMirroredEndpointSlices(t)
+	require.Len(t, mirrored, 3)
+	require.NotContains(t, mirrored, string(endpointSliceUSStaging.UID), "the DataPlanes not matching the selector should be ignored")
+	require.NotContains(t, mirrored, string(endpointSliceUSPrivate.UID),
+		"the DataPlanes of other namespaces not allowing the namespace of the ControlPlane should be ignored")
+	mirroredEU := mirrored[string(endpointSliceEU.UID)]
+	require.Equal(t, adminService.Name, mirroredEU.Labels[discoveryv1.LabelServiceName])
+	require.Equal(t, consts.ControlPlaneEndpointSliceManagedByLabelValue, mirroredEU.Labels[discoveryv1.LabelManagedBy])
+	require.Equal(t, endpointSliceEU.Ports, mirroredEU.Ports)
+	require.Equal(t, endpointSliceEU.Endpoints, mirroredEU.Endpoints)
+	require.Equal(t, endpointSliceUS.Endpoints, mirrored[string(endpointSliceUS.UID)].Endpoints)
+	require.Empty(t, mirrored[string(endpointSliceEUCanary.UID)].Endpoints, "the endpoints which are not ready should not be mirrored")
+
+	t.Log("the link with each DataPlane is reported in the status")
+	require.Equal(t, []operatorv1alpha1.ControlPlaneDataPlaneStatus{
+		{Namespace: "default", Name: "dp-missing", Message: "DataPlane not found"},
+		{Namespace: "default", Name: "dp-eu", Linked: true},
+		{Namespace: "default", Name: "dp-eu-canary", Message: "no ready admin API endpoint"},
+		{Namespace: "region-us", Name: "dp-us", Linked: true},
+	}, controlplane.Status.DataPlanes)
+
+	t.Log("the changes of the DataPlane admin API endpoints are mirrored")
+	endpointSliceEUCanary.Endpoints[0].Conditions.Ready = lo.ToPtr(true)
+	require.NoError(t, fakeClient.Update(ctx, endpointSliceEUCanary))
+	require.NoError(t, fakeClient.Delete(ctx, endpointSliceUS))
+	ensureDataPlaneLinks(t, adminService)
+	mirrored = listMirroredEndpointSlices(t)
+	require.Len(t, mirrored, 2)
+	require.Equal(t, endpointSliceEUCanary.Endpoints, mirrored[string(endpointSliceEUCanary.UID)].Endpoints)
+	require.Contains(t, controlplane.Status.DataPlanes, operatorv1alpha1.ControlPlaneDataPlaneStatus{
+		Namespace: "default", Name: "dp-eu-canary", Linked: true,
+	})
+	require.Contains(t, controlplane.Status.DataPlanes, operatorv1alpha1.ControlPlaneDataPlaneStatus{
+		Namespace: "region-us", Name: "dp-us", Message: "no ready admin API endpoint",
+	})
+
+	t.Log("the DataPlanes and their admin API endpoints are mapped to the ControlPlanes they are linked to")
+	expected := []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(controlplane)}}
+	require.Equal(t, expected, reconciler.getControlPlanesFromDataPlane(ctx, dataplaneUS))
+	require.Equal(t, expected, reconciler.getControlPlanesFromDataPlane(ctx, dataplaneEUCanary))
+	require.Empty(t, reconciler.getControlPlanesFromDataPlane(ctx, dataplaneUSStaging))
+	require.Empty(t, reconciler.getControlPlanesFromDataPlane(ctx, dataplaneUSPrivate))
+	require.Equal(t, expected, reconciler.getControlPlanesFromDataPlaneAdminEndpointSlice(ctx, endpointSliceEU))
+	require.Empty(t, reconciler.getControlPlanesFromDataPlaneAdminEndpointSlice(ctx, endpointSliceUSStaging))
+	require.False(t, isDataPlaneAdminEndpointSlice(&mirroredEU))
+	require.True(t, isDataPlaneAdminEndpointSlice(endpointSliceEU))
+
+	t.Log("going back to a single DataPlane deletes the admin Service and its EndpointSlices")
+	controlplane.Spec.DataPlanes = nil
+	controlplane.Spec.DataPlaneSelector = nil
+	require.NoError(t, fakeClient.Update(ctx, controlplane))
+	deleted, adminService, err := reconciler.ensureAdminServiceForControlPlane(ctx, controlplane)
+	require.NoError(t, err)
+	require.True(t, deleted)
+	require.Nil(t, adminService)
+	ensureDataPlaneLinks(t, adminService)
+	require.Empty(t, listMirroredEndpointSlices(t))
+	require.Equal(t, []operatorv1alpha1.ControlPlaneDataPlaneStatus{
+		{Namespace: "default", Name: "dp-eu", Linked: true},
+	}, controlplane.Status.DataPlanes)
+}
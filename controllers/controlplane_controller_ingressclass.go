@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/samber/lo"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	"github.com/kong/gateway-operator/internal/consts"
+	operatorerrors "github.com/kong/gateway-operator/internal/errors"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
+)
+
+// -----------------------------------------------------------------------------
+// ControlPlaneReconciler - IngressClass
+// -----------------------------------------------------------------------------
+
+// ensureIngressClassForControlPlane ensures that the IngressClass set for the
+// controlplane exists and is owned by it, unless it exists already without
+// being owned by it, and deletes the IngressClasses it owns but does not claim
+// anymore. Whether the IngressClass is handled by the controlplane only is
+// reported in the IngressClassClaimed condition.
+func (r *ControlPlaneReconciler) ensureIngressClassForControlPlane(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+) (createdOrUpdated bool, err error) {
+	ingressClasses, err := k8sutils.ListIngressClassesForOwner(
+		ctx,
+		r.Client,
+		controlplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.ControlPlaneManagedLabelValue,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	claimedIngressClass := lo.FromPtr(controlplane.Spec.IngressClass)
+	var deleted bool
+	for i := range ingressClasses {
+		if ingressClasses[i].Name == claimedIngressClass {
+			continue
+		}
+		if err := r.Client.Delete(ctx, &ingressClasses[i]); err != nil && !k8serrors.IsNotFound(err) {
+			return false, err
+		}
+		deleted = true
+	}
+	if deleted {
+		return true, nil
+	}
+
+	if claimedIngressClass == "" {
+		k8sutils.RemoveCondition(ControlPlaneConditionTypeIngressClassClaimed, controlplane)
+		return false, nil
+	}
+
+	generatedIngressClass := k8sresources.GenerateNewIngressClassForControlPlane(controlplane)
+	k8sutils.SetOwnerForObject(generatedIngressClass, controlplane)
+	addLabelForControlPlane(generatedIngressClass)
+
+	existingIngressClass := &networkingv1.IngressClass{}
+	err = r.Client.Get(ctx, client.ObjectKey{Name: claimedIngressClass}, existingIngressClass)
+	if k8serrors.IsNotFound(err) {
+		return true, r.Client.Create(ctx, generatedIngressClass)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case k8sutils.IsOwnedByRefUID(existingIngressClass, controlplane.UID):
+		var updated bool
+		updated, existingIngressClass.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingIngressClass.ObjectMeta, generatedIngressClass.ObjectMeta)
+		// the controller of an IngressClass is immutable, only the annotation
+		// marking it as the default class can be updated.
+		isDefault, wantDefault := existingIngressClass.Annotations[networkingv1.AnnotationIsDefaultIngressClass],
+			generatedIngressClass.Annotations[networkingv1.AnnotationIsDefaultIngressClass]
+		if isDefault != wantDefault {
+			if wantDefault == "" {
+				delete(existingIngressClass.Annotations, networkingv1.AnnotationIsDefaultIngressClass)
+			} else {
+				if existingIngressClass.Annotations == nil {
+					existingIngressClass.Annotations = make(map[string]string)
+				}
+				existingIngressClass.Annotations[networkingv1.AnnotationIsDefaultIngressClass] = wantDefault
+			}
+			updated = true
+		}
+		if updated {
+			if err := r.Client.Update(ctx, existingIngressClass); err != nil {
+				return false, fmt.Errorf("failed updating ControlPlane's IngressClass %s: %w", existingIngressClass.Name, err)
+			}
+			return true, nil
+		}
+		setIngressClassClaimedCondition(controlplane, metav1.ConditionTrue, ControlPlaneConditionReasonIngressClassManaged,
+			fmt.Sprintf("IngressClass %s is owned by the ControlPlane", claimedIngressClass))
+
+	case existingIngressClass.Labels[consts.GatewayOperatorControlledLabel] == consts.ControlPlaneManagedLabelValue:
+		owner, _ := lo.Find(existingIngressClass.OwnerReferences, func(ref metav1.OwnerReference) bool {
+			return ref.Kind == "ControlPlane"
+		})
+		setIngressClassClaimedCondition(controlplane, metav1.ConditionFalse, ControlPlaneConditionReasonIngressClassConflict,
+			fmt.Sprintf("IngressClass %s is already claimed by ControlPlane %s", claimedIngressClass, owner.Name))
+
+	case existingIngressClass.Spec.Controller != consts.IngressClassControllerName:
+		setIngressClassClaimedCondition(controlplane, metav1.ConditionFalse, ControlPlaneConditionReasonIngressClassConflict,
+			fmt.Sprintf("IngressClass %s is handled by controller %s", claimedIngressClass, existingIngressClass.Spec.Controller))
+
+	default:
+		setIngressClassClaimedCondition(controlplane, metav1.ConditionTrue, ControlPlaneConditionReasonIngressClassUnmanaged,
+			fmt.Sprintf("IngressClass %s exists already and is not managed by the ControlPlane", claimedIngressClass))
+	}
+
+	return false, nil
+}
+
+func setIngressClassClaimedCondition(
+	controlplane *operatorv1alpha1.ControlPlane,
+	status metav1.ConditionStatus,
+	reason k8sutils.ConditionReason,
+	message string,
+) {
+	condition, present := k8sutils.GetCondition(ControlPlaneConditionTypeIngressClassClaimed, controlplane)
+	if present && condition.Status == status && condition.Reason == string(reason) && condition.Message == message {
+		return
+	}
+	k8sutils.SetCondition(
+		k8sutils.NewCondition(ControlPlaneConditionTypeIngressClassClaimed, status, reason, message),
+		controlplane,
+	)
+}
+
+// ensureOwnedIngressClassesDeleted removes the IngressClasses owned by the controlplane.
+// It is called on cleanup of owned cluster resources on controlplane deletion.
+func (r *ControlPlaneReconciler) ensureOwnedIngressClassesDeleted(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+) (deletions bool, err error) {
+	ingressClasses, err := k8sutils.ListIngressClassesForOwner(
+		ctx, r.Client,
+		controlplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.ControlPlaneManagedLabelValue,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		deleted bool
+		errs    []error
+	)
+	for i := range ingressClasses {
+		err = r.Client.Delete(ctx, &ingressClasses[i])
+		if err != nil && !k8serrors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+		deleted = true
+	}
+
+	return deleted, errors.Join(errs...)
+}
+
+// getControlPlanesForIngressClass maps an IngressClass to the controlplanes
+// owning or claiming it, so that another controlplane claiming it takes it
+// over once its owner lets it go.
+func (r *ControlPlaneReconciler) getControlPlanesForIngressClass(ctx context.Context, obj client.Object) (recs []reconcile.Request) {
+	ingressClass, ok := obj.(*networkingv1.IngressClass)
+	if !ok {
+		log.FromContext(ctx).Error(
+			operatorerrors.ErrUnexpectedObject,
+			"failed to run map funcs",
+			"expected", "IngressClass", "found", reflect.TypeOf(obj),
+		)
+		return
+	}
+
+	controlplanes := &operatorv1alpha1.ControlPlaneList{}
+	if err := r.Client.List(ctx, controlplanes); err != nil {
+		log.FromContext(ctx).Error(err, "could not list controlplanes in map func")
+		return
+	}
+
+	for _, controlplane := range controlplanes.Items {
+		if lo.FromPtr(controlplane.Spec.IngressClass) == ingressClass.Name ||
+			k8sutils.IsOwnedByRefUID(ingressClass, controlplane.UID) {
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: controlplane.Namespace,
+					Name:      controlplane.Name,
+				},
+			})
+		}
+	}
+	return recs
+}
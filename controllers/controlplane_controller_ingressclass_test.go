@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+)
+
+func TestControlPlaneIngressClass(t *testing.T) {
+	ctx := context.Background()
+	newControlPlane := func(name, ingressClass string) *operatorv1alpha1.ControlPlane {
+		return &operatorv1alpha1.ControlPlane{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "gateway-operator.konghq.com/v1alpha1",
+				Kind:       "ControlPlane",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				UID:       types.UID(uuid.NewString()),
+			},
+			Spec: operatorv1alpha1.ControlPlaneSpec{
+				IngressClass: lo.ToPtr(ingressClass),
+			},
+		}
+	}
+	controlplaneA := newControlPlane("cp-a", "kong")
+	controlplaneA.Spec.DefaultIngressClass = true
+	controlplaneB := newControlPlane("cp-b", "kong")
+	nginxIngressClass := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+		Spec:       networkingv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+	}
+	manualIngressClass := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "kong-manual"},
+		Spec:       networkingv1.IngressClassSpec{Controller: consts.IngressClassControllerName},
+	}
+
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(controlplaneA, controlplaneB, nginxIngressClass, manualIngressClass).
+		Build()
+	reconciler := ControlPlaneReconciler{Client: fakeClient}
+
+	requireCondition := func(t *testing.T, controlplane *operatorv1alpha1.ControlPlane, status metav1.ConditionStatus, reason k8sutils.ConditionReason, message string) {
+		condition, ok := k8sutils.GetCondition(ControlPlaneConditionTypeIngressClassClaimed, controlplane)
+		require.True(t, ok)
+		require.Equal(t, status, condition.Status)
+		require.Equal(t, string(reason), condition.Reason)
+		require.Equal(t, message, condition.Message)
+	}
+	ensureIngressClass := func(t *testing.T, controlplane *operatorv1alpha1.ControlPlane) {
+		for i := 0; i < 3; i++ {
+			createdOrUpdated, err := reconciler.ensureIngressClassForControlPlane(ctx, controlplane)
+			require.NoError(t, err)
+			if !createdOrUpdated {
+				return
+			}
+		}
+		require.Fail(t, "the IngressClass has not converged")
+	}
+
+	t.Log("the IngressClass claimed by a ControlPlane is created and owned by it")
+	ensureIngressClass(t, controlplaneA)
+	ingressClass := &networkingv1.IngressClass{}
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "kong"}, ingressClass))
+	require.Equal(t, consts.IngressClassControllerName, ingressClass.Spec.Controller)
+	require.Equal(t, "true", ingressClass.Annotations[networkingv1.AnnotationIsDefaultIngressClass])
+	require.True(t, k8sutils.IsOwnedByRefUID(ingressClass, controlplaneA.UID))
+	requireCondition(t, controlplaneA, metav1.ConditionTrue, ControlPlaneConditionReasonIngressClassManaged,
+		"IngressClass kong is owned by the ControlPlane")
+
+	t.Log("another ControlPlane claiming the same IngressClass reports a conflict")
+	ensureIngressClass(t, controlplaneB)
+	requireCondition(t, controlplaneB, metav1.ConditionFalse, ControlPlaneConditionReasonIngressClassConflict,
+		"IngressClass kong is already claimed by ControlPlane cp-a")
+	require.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: client.ObjectKeyFromObject(controlplaneA)},
+		{NamespacedName: client.ObjectKeyFromObject(controlplaneB)},
+	}, reconciler.getControlPlanesForIngressClass(ctx, ingressClass))
+
+	t.Log("the default class annotation follows the ControlPlane")
+	controlplaneA.Spec.DefaultIngressClass = false
+	ensureIngressClass(t, controlplaneA)
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "kong"}, ingressClass))
+	require.NotContains(t, ingressClass.Annotations, networkingv1.AnnotationIsDefaultIngressClass)
+
+	t.Log("the IngressClass is deleted when its owner does not claim it anymore, and taken over")
+	controlplaneA.Spec.IngressClass = nil
+	ensureIngressClass(t, controlplaneA)
+	_, ok := k8sutils.GetCondition(ControlPlaneConditionTypeIngressClassClaimed, controlplaneA)
+	require.False(t, ok)
+	ensureIngressClass(t, controlplaneB)
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "kong"}, ingressClass))
+	require.True(t, k8sutils.IsOwnedByRefUID(ingressClass, controlplaneB.UID))
+	requireCondition(t, controlplaneB, metav1.ConditionTrue, ControlPlaneConditionReasonIngressClassManaged,
+		"IngressClass kong is owned by the ControlPlane")
+
+	t.Log("the IngressClasses which are not managed by the operator are left as is")
+	controlplaneA.Spec.IngressClass = lo.ToPtr("nginx")
+	ensureIngressClass(t, controlplaneA)
+	requireCondition(t, controlplaneA, metav1.ConditionFalse, ControlPlaneConditionReasonIngressClassConflict,
+		"IngressClass nginx is handled by controller k8s.io/ingress-nginx")
+	controlplaneA.Spec.IngressClass = lo.ToPtr("kong-manual")
+	ensureIngressClass(t, controlplaneA)
+	requireCondition(t, controlplaneA, metav1.ConditionTrue, ControlPlaneConditionReasonIngressClassUnmanaged,
+		"IngressClass kong-manual exists already and is not managed by the ControlPlane")
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "kong-manual"}, ingressClass))
+	require.Empty(t, ingressClass.OwnerReferences)
+
+	t.Log("the IngressClass is deleted along with its owner")
+	deleted, err := reconciler.ensureOwnedIngressClassesDeleted(ctx, controlplaneB)
+	require.NoError(t, err)
+	require.True(t, deleted)
+	deleted, err = reconciler.ensureOwnedIngressClassesDeleted(ctx, controlplaneB)
+	require.NoError(t, err)
+	require.False(t, deleted)
+}
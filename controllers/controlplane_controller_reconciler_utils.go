@@ -4,11 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/samber/lo"
 	appsv1 "k8s.io/api/apps/v1"
 	certificatesv1 "k8s.io/api/certificates/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -19,7 +24,6 @@ import (
 	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
 	"github.com/kong/gateway-operator/internal/consts"
-	operatorerrors "github.com/kong/gateway-operator/internal/errors"
 	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
 	k8sreduce "github.com/kong/gateway-operator/internal/utils/kubernetes/reduce"
 	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
@@ -66,6 +70,44 @@ func (r *ControlPlaneReconciler) ensureIsMarkedProvisioned(
 	k8sutils.SetReady(controlplane, controlplane.Generation)
 }
 
+// ensureReplicasStatus reports the replicas of the ControlPlane's Deployment
+// in the ControlPlane status.
+func (r *ControlPlaneReconciler) ensureReplicasStatus(
+	controlplane *operatorv1alpha1.ControlPlane,
+	controlplaneDeployment *appsv1.Deployment,
+) {
+	controlplane.Status.Replicas = controlplaneDeployment.Status.Replicas
+	controlplane.Status.ReadyReplicas = controlplaneDeployment.Status.ReadyReplicas
+}
+
+// controlPlaneLeaderResyncInterval is how often the leader of the ControlPlanes
+// is refreshed in their status. Their leader election Leases are not watched,
+// as they are renewed every few seconds and are not cached.
+const controlPlaneLeaderResyncInterval = 30 * time.Second
+
+// ensureLeaderStatus reports the ControlPlane pod holding the leader election
+// lease in the ControlPlane status.
+func (r *ControlPlaneReconciler) ensureLeaderStatus(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+) error {
+	lease := &coordinationv1.Lease{}
+	err := r.Client.Get(ctx, client.ObjectKey{
+		Namespace: controlplane.Namespace,
+		Name:      k8sresources.ControlPlaneElectionID(controlplane.Name),
+	}, lease)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			controlplane.Status.Leader = ""
+			return nil
+		}
+		return fmt.Errorf("failed getting ControlPlane's leader election Lease: %w", err)
+	}
+
+	controlplane.Status.Leader = leaseHolderPodName(lease)
+	return nil
+}
+
 // ensureDataPlaneStatus ensures that the dataplane is in the correct state
 // to carry on with the controlplane deployments reconciliation.
 // Information about the missing dataplane is stored in the controlplane status.
@@ -96,27 +138,36 @@ func (r *ControlPlaneReconciler) ensureDataPlaneStatus(
 	return dataplaneIsSet
 }
 
-// -----------------------------------------------------------------------------
-// ControlPlaneReconciler - Spec Management
-// -----------------------------------------------------------------------------
-
-func (r *ControlPlaneReconciler) ensureDataPlaneConfiguration(
-	ctx context.Context,
+// ensureControllerOptionsStatus reports in the ControllerOptionsValid condition
+// whether the typed controller options of the controlplane are all applied
+// to its controller, given the image the controller is run with.
+func (r *ControlPlaneReconciler) ensureControllerOptionsStatus(
 	controlplane *operatorv1alpha1.ControlPlane,
-	dataplaneServiceName string,
-) error {
-	changed := setControlPlaneEnvOnDataPlaneChange(
-		&controlplane.Spec.ControlPlaneOptions,
-		controlplane.Namespace,
-		dataplaneServiceName,
+	controlplaneImage string,
+) {
+	if controlplane.Spec.Controller == nil && controlplane.Spec.IngressClass == nil {
+		k8sutils.RemoveCondition(ControlPlaneConditionTypeControllerOptionsValid, controlplane)
+		return
+	}
+
+	newCondition := k8sutils.NewCondition(
+		ControlPlaneConditionTypeControllerOptionsValid,
+		metav1.ConditionTrue,
+		ControlPlaneConditionReasonControllerOptionsApplied,
+		"all the controller options are applied",
 	)
-	if changed {
-		if err := r.Client.Update(ctx, controlplane); err != nil {
-			return fmt.Errorf("failed updating ControlPlane's DataPlane: %w", err)
-		}
-		return nil
+	if problems := controllerOptionsProblems(controlplane, controlplaneImage); len(problems) > 0 {
+		newCondition = k8sutils.NewCondition(
+			ControlPlaneConditionTypeControllerOptionsValid,
+			metav1.ConditionFalse,
+			ControlPlaneConditionReasonInvalidControllerOptions,
+			strings.Join(problems, "; "),
+		)
+	}
+	condition, present := k8sutils.GetCondition(ControlPlaneConditionTypeControllerOptionsValid, controlplane)
+	if !present || condition.Status != newCondition.Status || condition.Message != newCondition.Message {
+		k8sutils.SetCondition(newCondition, controlplane)
 	}
-	return nil
 }
 
 // -----------------------------------------------------------------------------
@@ -168,6 +219,11 @@ func (r *ControlPlaneReconciler) ensureDeploymentForControlPlane(
 
 	k8sutils.SetOwnerForObject(generatedDeployment, controlplane)
 	addLabelForControlPlane(generatedDeployment)
+	checksum, err := certificatesChecksum(ctx, r.Client, controlplane)
+	if err != nil {
+		return false, nil, err
+	}
+	setCertificatesChecksum(generatedDeployment, checksum)
 
 	if count == 1 {
 		var updated bool
@@ -229,6 +285,73 @@ func (r *ControlPlaneReconciler) ensureDeploymentForControlPlane(
 	return true, generatedDeployment, r.Client.Create(ctx, generatedDeployment)
 }
 
+// ensurePodDisruptionBudgetForControlPlane ensures that a PodDisruptionBudget covers
+// the ControlPlane's pods when PodDisruptionBudget options are set, and that it
+// is deleted otherwise. The deletion is reported as an update.
+func (r *ControlPlaneReconciler) ensurePodDisruptionBudgetForControlPlane(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+) (createdOrUpdated bool, pdb *policyv1.PodDisruptionBudget, err error) {
+	pdbs, err := k8sutils.ListPodDisruptionBudgetsForOwner(
+		ctx,
+		r.Client,
+		controlplane.Namespace,
+		controlplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.ControlPlaneManagedLabelValue,
+		},
+	)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if controlplane.Spec.Deployment.PodDisruptionBudget == nil {
+		for _, pdb := range pdbs {
+			pdb := pdb
+			if err := r.Client.Delete(ctx, &pdb); client.IgnoreNotFound(err) != nil {
+				return false, nil, fmt.Errorf("failed deleting ControlPlane's PodDisruptionBudget %s: %w", pdb.Name, err)
+			}
+		}
+		return len(pdbs) > 0, nil, nil
+	}
+
+	count := len(pdbs)
+	if count > 1 {
+		if err := k8sreduce.ReducePodDisruptionBudgets(ctx, r.Client, pdbs); err != nil {
+			return false, nil, err
+		}
+		return false, nil, errors.New("number of PodDisruptionBudgets reduced")
+	}
+
+	generatedPDB, err := k8sresources.GenerateNewPodDisruptionBudgetForControlPlane(controlplane)
+	if err != nil {
+		return false, nil, err
+	}
+	k8sutils.SetOwnerForObject(generatedPDB, controlplane)
+	addLabelForControlPlane(generatedPDB)
+
+	if count == 1 {
+		var updated bool
+		existingPDB := &pdbs[0]
+		updated, existingPDB.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingPDB.ObjectMeta, generatedPDB.ObjectMeta)
+
+		if !cmp.Equal(existingPDB.Spec, generatedPDB.Spec) {
+			existingPDB.Spec = generatedPDB.Spec
+			updated = true
+		}
+
+		if updated {
+			if err := r.Client.Update(ctx, existingPDB); err != nil {
+				return false, existingPDB, fmt.Errorf("failed updating ControlPlane's PodDisruptionBudget %s: %w", existingPDB.Name, err)
+			}
+			return true, existingPDB, nil
+		}
+		return false, existingPDB, nil
+	}
+
+	return true, generatedPDB, r.Client.Create(ctx, generatedPDB)
+}
+
 func (r *ControlPlaneReconciler) ensureServiceAccountForControlPlane(
 	ctx context.Context,
 	controlplane *operatorv1alpha1.ControlPlane,
@@ -303,6 +426,11 @@ func (r *ControlPlaneReconciler) ensureClusterRoleForControlPlane(
 	if err != nil {
 		return false, nil, err
 	}
+	// when the watched namespaces are restricted, the permissions on namespaced
+	// resources are granted by the Roles created in each of them instead.
+	if len(controlplane.Spec.WatchNamespaces) > 0 {
+		generatedClusterRole.Rules, _ = k8sresources.SplitControlPlaneClusterRoleRules(generatedClusterRole.Rules)
+	}
 	k8sutils.SetOwnerForObject(generatedClusterRole, controlplane)
 	addLabelForControlPlane(generatedClusterRole)
 
@@ -310,6 +438,10 @@ func (r *ControlPlaneReconciler) ensureClusterRoleForControlPlane(
 		var updated bool
 		existingClusterRole := &clusterRoles[0]
 		updated, existingClusterRole.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingClusterRole.ObjectMeta, generatedClusterRole.ObjectMeta)
+		if !cmp.Equal(existingClusterRole.Rules, generatedClusterRole.Rules) {
+			existingClusterRole.Rules = generatedClusterRole.Rules
+			updated = true
+		}
 		if updated {
 			if err := r.Client.Update(ctx, existingClusterRole); err != nil {
 				return false, existingClusterRole, fmt.Errorf("failed updating ControlPlane's ClusterRole %s: %w", existingClusterRole.Name, err)
@@ -368,6 +500,283 @@ func (r *ControlPlaneReconciler) ensureClusterRoleBindingForControlPlane(
 	return true, generatedClusterRoleBinding, r.Client.Create(ctx, generatedClusterRoleBinding)
 }
 
+// ensureWatchNamespaceRolesForControlPlane ensures that a Role granting the
+// ControlPlane access to the namespaced resources exists in each namespace it
+// watches when they are restricted, and that no such Role is left in other
+// namespaces. It returns the names of the Roles by namespace.
+func (r *ControlPlaneReconciler) ensureWatchNamespaceRolesForControlPlane(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+) (createdOrUpdated bool, roleNames map[string]string, err error) {
+	roleList := &rbacv1.RoleList{}
+	if err := r.Client.List(ctx, roleList, watchNamespaceLabelsForControlPlane(controlplane)); err != nil {
+		return false, nil, err
+	}
+	rolesByNamespace := lo.GroupBy(roleList.Items, func(role rbacv1.Role) string { return role.Namespace })
+
+	namespaces := controlPlaneWatchNamespaces(controlplane)
+	for namespace, roles := range rolesByNamespace {
+		if lo.Contains(namespaces, namespace) {
+			continue
+		}
+		for i := range roles {
+			if err := r.Client.Delete(ctx, &roles[i]); client.IgnoreNotFound(err) != nil {
+				return false, nil, fmt.Errorf("failed deleting ControlPlane's Role %s/%s: %w", namespace, roles[i].Name, err)
+			}
+		}
+		createdOrUpdated = true
+	}
+	if len(namespaces) == 0 {
+		return createdOrUpdated, nil, nil
+	}
+
+	controlplaneContainer := k8sutils.GetPodContainerByName(&controlplane.Spec.Deployment.PodTemplateSpec.Spec, consts.ControlPlaneControllerContainerName)
+	clusterRole, err := k8sresources.GenerateNewClusterRoleForControlPlane(controlplane.Name, controlplaneContainer.Image)
+	if err != nil {
+		return false, nil, err
+	}
+	_, namespacedRules := k8sresources.SplitControlPlaneClusterRoleRules(clusterRole.Rules)
+
+	roleNames = make(map[string]string, len(namespaces))
+	for _, namespace := range namespaces {
+		roles := rolesByNamespace[namespace]
+		count := len(roles)
+		if count > 1 {
+			if err := k8sreduce.ReduceRoles(ctx, r.Client, roles); err != nil {
+				return false, nil, err
+			}
+			return false, nil, errors.New("number of watch namespace roles reduced")
+		}
+
+		generatedRole := k8sresources.GenerateNewRoleForControlPlaneWatchNamespace(namespace, controlplane.Name, namespacedRules)
+		addWatchNamespaceLabelsForControlPlane(generatedRole, controlplane)
+
+		if count == 1 {
+			var updated bool
+			existingRole := &roles[0]
+			updated, existingRole.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingRole.ObjectMeta, generatedRole.ObjectMeta)
+			if !cmp.Equal(existingRole.Rules, generatedRole.Rules) {
+				existingRole.Rules = generatedRole.Rules
+				updated = true
+			}
+			if updated {
+				if err := r.Client.Update(ctx, existingRole); err != nil {
+					return false, nil, fmt.Errorf("failed updating ControlPlane's Role %s/%s: %w", namespace, existingRole.Name, err)
+				}
+				createdOrUpdated = true
+			}
+			roleNames[namespace] = existingRole.Name
+			continue
+		}
+
+		if err := r.Client.Create(ctx, generatedRole); err != nil {
+			return false, nil, fmt.Errorf("failed creating ControlPlane's Role in namespace %s: %w", namespace, err)
+		}
+		createdOrUpdated = true
+		roleNames[namespace] = generatedRole.Name
+	}
+
+	return createdOrUpdated, roleNames, nil
+}
+
+// ensureWatchNamespaceRoleBindingsForControlPlane ensures that the Roles of the
+// namespaces watched by the ControlPlane are bound to its ServiceAccount, and
+// that no such RoleBinding is left in other namespaces. As the role reference
+// of a RoleBinding cannot be changed, a RoleBinding referencing another Role
+// is deleted, to be recreated on the next reconciliation.
+func (r *ControlPlaneReconciler) ensureWatchNamespaceRoleBindingsForControlPlane(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+	serviceAccountName string,
+	roleNames map[string]string,
+) (createdOrUpdated bool, err error) {
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := r.Client.List(ctx, roleBindingList, watchNamespaceLabelsForControlPlane(controlplane)); err != nil {
+		return false, err
+	}
+	roleBindingsByNamespace := lo.GroupBy(roleBindingList.Items, func(roleBinding rbacv1.RoleBinding) string { return roleBinding.Namespace })
+
+	for namespace, roleBindings := range roleBindingsByNamespace {
+		if _, ok := roleNames[namespace]; ok {
+			continue
+		}
+		for i := range roleBindings {
+			if err := r.Client.Delete(ctx, &roleBindings[i]); client.IgnoreNotFound(err) != nil {
+				return false, fmt.Errorf("failed deleting ControlPlane's RoleBinding %s/%s: %w", namespace, roleBindings[i].Name, err)
+			}
+		}
+		createdOrUpdated = true
+	}
+
+	for namespace, roleName := range roleNames {
+		roleBindings := roleBindingsByNamespace[namespace]
+		count := len(roleBindings)
+		if count > 1 {
+			if err := k8sreduce.ReduceRoleBindings(ctx, r.Client, roleBindings); err != nil {
+				return false, err
+			}
+			return false, errors.New("number of watch namespace roleBindings reduced")
+		}
+
+		generatedRoleBinding := k8sresources.GenerateNewRoleBindingForControlPlaneWatchNamespace(
+			namespace, controlplane.Namespace, controlplane.Name, serviceAccountName, roleName)
+		addWatchNamespaceLabelsForControlPlane(generatedRoleBinding, controlplane)
+
+		if count == 1 {
+			existingRoleBinding := &roleBindings[0]
+			if existingRoleBinding.RoleRef != generatedRoleBinding.RoleRef {
+				if err := r.Client.Delete(ctx, existingRoleBinding); client.IgnoreNotFound(err) != nil {
+					return false, fmt.Errorf("failed deleting ControlPlane's RoleBinding %s/%s: %w", namespace, existingRoleBinding.Name, err)
+				}
+				createdOrUpdated = true
+				continue
+			}
+
+			var updated bool
+			updated, existingRoleBinding.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingRoleBinding.ObjectMeta, generatedRoleBinding.ObjectMeta)
+			if !cmp.Equal(existingRoleBinding.Subjects, generatedRoleBinding.Subjects) {
+				existingRoleBinding.Subjects = generatedRoleBinding.Subjects
+				updated = true
+			}
+			if updated {
+				if err := r.Client.Update(ctx, existingRoleBinding); err != nil {
+					return false, fmt.Errorf("failed updating ControlPlane's RoleBinding %s/%s: %w", namespace, existingRoleBinding.Name, err)
+				}
+				createdOrUpdated = true
+			}
+			continue
+		}
+
+		if err := r.Client.Create(ctx, generatedRoleBinding); err != nil {
+			return false, fmt.Errorf("failed creating ControlPlane's RoleBinding in namespace %s: %w", namespace, err)
+		}
+		createdOrUpdated = true
+	}
+
+	return createdOrUpdated, nil
+}
+
+// ensureRoleForControlPlane ensures that the Role granting the ControlPlane
+// the permissions needed for its leader election exists in its namespace.
+func (r *ControlPlaneReconciler) ensureRoleForControlPlane(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+) (createdOrUpdated bool, role *rbacv1.Role, err error) {
+	roles, err := k8sutils.ListRolesForOwner(
+		ctx,
+		r.Client,
+		controlplane.Namespace,
+		controlplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.ControlPlaneManagedLabelValue,
+		},
+	)
+	if err != nil {
+		return false, nil, err
+	}
+
+	count := len(roles)
+	if count > 1 {
+		if err := k8sreduce.ReduceRoles(ctx, r.Client, roles); err != nil {
+			return false, nil, err
+		}
+		return false, nil, errors.New("number of roles reduced")
+	}
+
+	generatedRole := k8sresources.GenerateNewRoleForControlPlane(controlplane.Namespace, controlplane.Name)
+	k8sutils.SetOwnerForObject(generatedRole, controlplane)
+	addLabelForControlPlane(generatedRole)
+
+	if count == 1 {
+		var updated bool
+		existingRole := &roles[0]
+		updated, existingRole.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingRole.ObjectMeta, generatedRole.ObjectMeta)
+		if !cmp.Equal(existingRole.Rules, generatedRole.Rules) {
+			existingRole.Rules = generatedRole.Rules
+			updated = true
+		}
+		if updated {
+			if err := r.Client.Update(ctx, existingRole); err != nil {
+				return false, existingRole, fmt.Errorf("failed updating ControlPlane's Role %s: %w", existingRole.Name, err)
+			}
+			return true, existingRole, nil
+		}
+		return false, existingRole, nil
+	}
+
+	return true, generatedRole, r.Client.Create(ctx, generatedRole)
+}
+
+// ensureRoleBindingForControlPlane ensures that the leader election Role is
+// bound to the ServiceAccount of the ControlPlane. As the role reference of a
+// RoleBinding cannot be changed, a RoleBinding referencing another Role is
+// deleted and reported as updated, to be recreated on the next reconciliation.
+func (r *ControlPlaneReconciler) ensureRoleBindingForControlPlane(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+	serviceAccountName string,
+	roleName string,
+) (createdOrUpdated bool, rb *rbacv1.RoleBinding, err error) {
+	roleBindings, err := k8sutils.ListRoleBindingsForOwner(
+		ctx,
+		r.Client,
+		controlplane.Namespace,
+		controlplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.ControlPlaneManagedLabelValue,
+		},
+	)
+	if err != nil {
+		return false, nil, err
+	}
+
+	count := len(roleBindings)
+	if count > 1 {
+		if err := k8sreduce.ReduceRoleBindings(ctx, r.Client, roleBindings); err != nil {
+			return false, nil, err
+		}
+		return false, nil, errors.New("number of roleBindings reduced")
+	}
+
+	generatedRoleBinding := k8sresources.GenerateNewRoleBindingForControlPlane(controlplane.Namespace, controlplane.Name, serviceAccountName, roleName)
+	k8sutils.SetOwnerForObject(generatedRoleBinding, controlplane)
+	addLabelForControlPlane(generatedRoleBinding)
+
+	if count == 1 {
+		existingRoleBinding := &roleBindings[0]
+		if existingRoleBinding.RoleRef != generatedRoleBinding.RoleRef {
+			if err := r.Client.Delete(ctx, existingRoleBinding); client.IgnoreNotFound(err) != nil {
+				return false, existingRoleBinding, fmt.Errorf("failed deleting ControlPlane's RoleBinding %s: %w", existingRoleBinding.Name, err)
+			}
+			return true, existingRoleBinding, nil
+		}
+
+		var updated bool
+		updated, existingRoleBinding.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingRoleBinding.ObjectMeta, generatedRoleBinding.ObjectMeta)
+		if !cmp.Equal(existingRoleBinding.Subjects, generatedRoleBinding.Subjects) {
+			existingRoleBinding.Subjects = generatedRoleBinding.Subjects
+			updated = true
+		}
+		if updated {
+			if err := r.Client.Update(ctx, existingRoleBinding); err != nil {
+				return false, existingRoleBinding, fmt.Errorf("failed updating ControlPlane's RoleBinding %s: %w", existingRoleBinding.Name, err)
+			}
+			return true, existingRoleBinding, nil
+		}
+		return false, existingRoleBinding, nil
+	}
+
+	return true, generatedRoleBinding, r.Client.Create(ctx, generatedRoleBinding)
+}
+
+// certificateIssuer returns the issuer of the certificates of the ControlPlanes.
+func (r *ControlPlaneReconciler) certificateIssuer() certificateIssuer {
+	return newCertificateIssuer(r.Client, types.NamespacedName{
+		Namespace: r.ClusterCASecretNamespace,
+		Name:      r.ClusterCASecretName,
+	}, r.CertificateOptions)
+}
+
 func (r *ControlPlaneReconciler) ensureCertificate(
 	ctx context.Context,
 	controlplane *operatorv1alpha1.ControlPlane,
@@ -378,15 +787,11 @@ func (r *ControlPlaneReconciler) ensureCertificate(
 	}
 	// this subject is arbitrary. data planes only care that client certificates are signed by the trusted CA, and will
 	// accept a certificate with any subject
-	return maybeCreateCertificateSecret(ctx,
-		controlplane,
-		fmt.Sprintf("%s.%s", controlplane.Name, controlplane.Namespace),
-		types.NamespacedName{
-			Namespace: r.ClusterCASecretNamespace,
-			Name:      r.ClusterCASecretName,
-		},
-		usages,
-		r.Client)
+	return r.certificateIssuer().ensureCertificateSecret(ctx, certificateRequest{
+		owner:   controlplane,
+		subject: fmt.Sprintf("%s.%s", controlplane.Name, controlplane.Namespace),
+		usages:  usages,
+	})
 }
 
 // ensureOwnedClusterRolesDeleted removes all the owned ClusterRoles of the controlplane.
@@ -455,25 +860,58 @@ func (r *ControlPlaneReconciler) ensureOwnedClusterRoleBindingsDeleted(
 	return deleted, errors.Join(errs...)
 }
 
-// getDataPlanePod returns the IP of the newest DataPlane pod.
-func getDataPlanePod(ctx context.Context, cl client.Reader, dataplaneName, namespace string) (*corev1.Pod, error) {
-	podList := corev1.PodList{}
-	if err := cl.List(ctx, &podList, client.InNamespace(namespace), client.MatchingLabels{
-		"app": dataplaneName,
-	}); err != nil {
-		return nil, err
-	}
-	if len(podList.Items) == 0 {
-		return nil, operatorerrors.ErrNoDataPlanePods
+// ensureWatchNamespaceRolesDeleted removes the Roles created for the controlplane
+// in the namespaces it watches. It is called on cleanup of owned cluster
+// resources on controlplane deletion, as these Roles cannot refer to their
+// controlplane as owner to be garbage collected.
+func (r *ControlPlaneReconciler) ensureWatchNamespaceRolesDeleted(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+) (deletions bool, err error) {
+	roleList := &rbacv1.RoleList{}
+	if err := r.Client.List(ctx, roleList, watchNamespaceLabelsForControlPlane(controlplane)); err != nil {
+		return false, err
 	}
-	newestDataPlanePod := podList.Items[0]
-	for _, pod := range podList.Items[1:] {
-		if pod.DeletionTimestamp != nil || pod.Status.PodIP == "" {
-			continue
+
+	var errs []error
+	for i := range roleList.Items {
+		if err := r.Client.Delete(ctx, &roleList.Items[i]); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, err)
 		}
-		if pod.CreationTimestamp.After(newestDataPlanePod.CreationTimestamp.Time) {
-			newestDataPlanePod = pod
+	}
+
+	return len(roleList.Items) > 0, errors.Join(errs...)
+}
+
+// ensureWatchNamespaceRoleBindingsDeleted removes the RoleBindings created for
+// the controlplane in the namespaces it watches. It is called on cleanup of
+// owned cluster resources on controlplane deletion.
+func (r *ControlPlaneReconciler) ensureWatchNamespaceRoleBindingsDeleted(
+	ctx context.Context,
+	controlplane *operatorv1alpha1.ControlPlane,
+) (deletions bool, err error) {
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := r.Client.List(ctx, roleBindingList, watchNamespaceLabelsForControlPlane(controlplane)); err != nil {
+		return false, err
+	}
+
+	var errs []error
+	for i := range roleBindingList.Items {
+		if err := r.Client.Delete(ctx, &roleBindingList.Items[i]); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, err)
 		}
 	}
-	return &newestDataPlanePod, nil
+
+	return len(roleBindingList.Items) > 0, errors.Join(errs...)
+}
+
+// leaseHolderPodName returns the name of the pod holding the provided leader
+// election Lease. The holder identities are made of the pod name followed by
+// an underscore and a unique suffix.
+func leaseHolderPodName(lease *coordinationv1.Lease) string {
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	podName, _, _ := strings.Cut(*lease.Spec.HolderIdentity, "_")
+	return podName
 }
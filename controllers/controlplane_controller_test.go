@@ -7,11 +7,16 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
 	controllerruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -23,6 +28,7 @@ import (
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
 	"github.com/kong/gateway-operator/internal/consts"
 	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
 	"github.com/kong/gateway-operator/test/helpers"
 )
 
@@ -84,6 +90,7 @@ func TestControlPlaneReconciler_Reconcile(t *testing.T) {
 					Finalizers: []string{
 						string(ControlPlaneFinalizerCleanupClusterRole),
 						string(ControlPlaneFinalizerCleanupClusterRoleBinding),
+						string(ControlPlaneFinalizerCleanupIngressClass),
 					},
 				},
 				Spec: operatorv1alpha1.ControlPlaneSpec{
@@ -231,12 +238,8 @@ func TestControlPlaneReconciler_Reconcile(t *testing.T) {
 			testBody: func(t *testing.T, reconciler ControlPlaneReconciler, controlplaneReq reconcile.Request) {
 				ctx := context.Background()
 
-				// first reconcile loop to allow the reconciler to set the controlplane defaults
 				_, err := reconciler.Reconcile(ctx, controlplaneReq)
 				require.NoError(t, err)
-
-				_, err = reconciler.Reconcile(ctx, controlplaneReq)
-				require.NoError(t, err)
 			},
 		},
 		{
@@ -259,6 +262,7 @@ func TestControlPlaneReconciler_Reconcile(t *testing.T) {
 					Finalizers: []string{
 						string(ControlPlaneFinalizerCleanupClusterRole),
 						string(ControlPlaneFinalizerCleanupClusterRoleBinding),
+						string(ControlPlaneFinalizerCleanupIngressClass),
 					},
 				},
 				Spec: operatorv1alpha1.ControlPlaneSpec{
@@ -456,3 +460,255 @@ func TestControlPlaneReconciler_Reconcile(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsurePodDisruptionBudgetForControlPlane(t *testing.T) {
+	ctx := context.Background()
+	controlplane := &operatorv1alpha1.ControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			UID:       types.UID(uuid.NewString()),
+		},
+		Spec: operatorv1alpha1.ControlPlaneSpec{
+			ControlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+				Deployment: operatorv1alpha1.DeploymentOptions{
+					PodDisruptionBudget: &operatorv1alpha1.PodDisruptionBudget{
+						MaxUnavailable: lo.ToPtr(intstr.FromInt(1)),
+					},
+				},
+			},
+		},
+	}
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(controlplane).
+		Build()
+	reconciler := ControlPlaneReconciler{Client: fakeClient}
+
+	createdOrUpdated, pdb, err := reconciler.ensurePodDisruptionBudgetForControlPlane(ctx, controlplane)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	require.Equal(t, lo.ToPtr(intstr.FromInt(1)), pdb.Spec.MaxUnavailable)
+	require.Equal(t, map[string]string{"app": "test"}, pdb.Spec.Selector.MatchLabels)
+
+	createdOrUpdated, _, err = reconciler.ensurePodDisruptionBudgetForControlPlane(ctx, controlplane)
+	require.NoError(t, err)
+	require.False(t, createdOrUpdated, "an up to date PodDisruptionBudget should not be updated")
+
+	controlplane.Spec.Deployment.PodDisruptionBudget = nil
+	createdOrUpdated, _, err = reconciler.ensurePodDisruptionBudgetForControlPlane(ctx, controlplane)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated, "the deletion of the PodDisruptionBudget should be reported")
+
+	var pdbs policyv1.PodDisruptionBudgetList
+	require.NoError(t, fakeClient.List(ctx, &pdbs))
+	require.Empty(t, pdbs.Items)
+}
+
+func TestControlPlaneLeaderElection(t *testing.T) {
+	ctx := context.Background()
+	controlplane := &operatorv1alpha1.ControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			UID:       types.UID(uuid.NewString()),
+		},
+		Spec: operatorv1alpha1.ControlPlaneSpec{
+			ControlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+				Deployment: operatorv1alpha1.DeploymentOptions{
+					Replicas: pointer.Int32(3),
+				},
+			},
+		},
+	}
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(controlplane).
+		Build()
+	reconciler := ControlPlaneReconciler{Client: fakeClient}
+
+	t.Log("the leader election Role is bound to the ControlPlane's ServiceAccount")
+	createdOrUpdated, role, err := reconciler.ensureRoleForControlPlane(ctx, controlplane)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	require.Equal(t, controlplane.Namespace, role.Namespace)
+	require.Contains(t, role.Rules, rbacv1.PolicyRule{
+		APIGroups: []string{"coordination.k8s.io"},
+		Resources: []string{"leases"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	})
+	createdOrUpdated, _, err = reconciler.ensureRoleForControlPlane(ctx, controlplane)
+	require.NoError(t, err)
+	require.False(t, createdOrUpdated, "an up to date Role should not be updated")
+
+	createdOrUpdated, roleBinding, err := reconciler.ensureRoleBindingForControlPlane(ctx, controlplane, "test-sa", role.Name)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	require.Equal(t, rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: role.Name}, roleBinding.RoleRef)
+	require.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Name: "test-sa", Namespace: controlplane.Namespace}}, roleBinding.Subjects)
+	createdOrUpdated, _, err = reconciler.ensureRoleBindingForControlPlane(ctx, controlplane, "test-sa", role.Name)
+	require.NoError(t, err)
+	require.False(t, createdOrUpdated, "an up to date RoleBinding should not be updated")
+
+	t.Log("a RoleBinding referencing another Role is replaced")
+	createdOrUpdated, _, err = reconciler.ensureRoleBindingForControlPlane(ctx, controlplane, "test-sa", "another-role")
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	var roleBindings rbacv1.RoleBindingList
+	require.NoError(t, fakeClient.List(ctx, &roleBindings))
+	require.Empty(t, roleBindings.Items)
+
+	t.Log("the replicas and the leader are reported in the ControlPlane status")
+	deployment, err := k8sresources.GenerateNewDeploymentForControlPlane(controlplane, consts.DefaultControlPlaneImage, "test-sa", "test-secret")
+	require.NoError(t, err)
+	require.Contains(t, deployment.Spec.Template.Spec.Containers[0].Env,
+		corev1.EnvVar{Name: "CONTROLLER_ELECTION_ID", Value: k8sresources.ControlPlaneElectionID(controlplane.Name)})
+	require.Contains(t, deployment.Spec.Template.Spec.Containers[0].Env,
+		corev1.EnvVar{Name: "CONTROLLER_ELECTION_NAMESPACE", Value: controlplane.Namespace})
+	deployment.Status = appsv1.DeploymentStatus{Replicas: 3, ReadyReplicas: 2}
+	reconciler.ensureReplicasStatus(controlplane, deployment)
+	require.EqualValues(t, 3, controlplane.Status.Replicas)
+	require.EqualValues(t, 2, controlplane.Status.ReadyReplicas)
+
+	require.NoError(t, reconciler.ensureLeaderStatus(ctx, controlplane))
+	require.Empty(t, controlplane.Status.Leader, "no leader should be reported before the election")
+
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      k8sresources.ControlPlaneElectionID(controlplane.Name),
+			Namespace: controlplane.Namespace,
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity: pointer.String("controlplane-test-7d9f8b6c5-x2x7k_3f1e9a0c-7a8b-4c2d-9e0f-1a2b3c4d5e6f"),
+		},
+	}
+	require.NoError(t, fakeClient.Create(ctx, lease))
+	require.NoError(t, reconciler.ensureLeaderStatus(ctx, controlplane))
+	require.Equal(t, "controlplane-test-7d9f8b6c5-x2x7k", controlplane.Status.Leader)
+}
+
+func TestControlPlaneWatchNamespaces(t *testing.T) {
+	ctx := context.Background()
+	controlplane := &operatorv1alpha1.ControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			UID:       types.UID(uuid.NewString()),
+		},
+		Spec: operatorv1alpha1.ControlPlaneSpec{
+			ControlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+				Deployment: operatorv1alpha1.DeploymentOptions{
+					PodTemplateSpec: &corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  consts.ControlPlaneControllerContainerName,
+									Image: consts.DefaultControlPlaneImage,
+								},
+							},
+						},
+					},
+				},
+				WatchNamespaces: []string{"tenant-a", "tenant-b"},
+			},
+		},
+	}
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(controlplane).
+		Build()
+	reconciler := ControlPlaneReconciler{Client: fakeClient}
+
+	listWatchNamespaceRBAC := func(t *testing.T) (map[string]rbacv1.Role, map[string]rbacv1.RoleBinding) {
+		var roles rbacv1.RoleList
+		require.NoError(t, fakeClient.List(ctx, &roles, watchNamespaceLabelsForControlPlane(controlplane)))
+		var roleBindings rbacv1.RoleBindingList
+		require.NoError(t, fakeClient.List(ctx, &roleBindings, watchNamespaceLabelsForControlPlane(controlplane)))
+		return lo.KeyBy(roles.Items, func(role rbacv1.Role) string { return role.Namespace }),
+			lo.KeyBy(roleBindings.Items, func(roleBinding rbacv1.RoleBinding) string { return roleBinding.Namespace })
+	}
+	ensureWatchNamespaceRBAC := func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			rolesUpdated, roleNames, err := reconciler.ensureWatchNamespaceRolesForControlPlane(ctx, controlplane)
+			require.NoError(t, err)
+			roleBindingsUpdated, err := reconciler.ensureWatchNamespaceRoleBindingsForControlPlane(ctx, controlplane, "test-sa", roleNames)
+			require.NoError(t, err)
+			if !rolesUpdated && !roleBindingsUpdated {
+				return
+			}
+		}
+		require.Fail(t, "the watch namespaces RBAC has not converged")
+	}
+
+	t.Log("the ClusterRole only grants access to cluster scoped resources")
+	_, clusterRole, err := reconciler.ensureClusterRoleForControlPlane(ctx, controlplane)
+	require.NoError(t, err)
+	for _, rule := range clusterRole.Rules {
+		for _, resource := range rule.Resources {
+			require.NotContains(t, []string{"secrets", "services", "ingresses", "httproutes", "kongplugins"}, resource)
+		}
+	}
+	require.Contains(t, clusterRole.Rules, rbacv1.PolicyRule{
+		APIGroups: []string{"gateway.networking.k8s.io"},
+		Resources: []string{"gatewayclasses"},
+		Verbs:     []string{"get", "list", "watch"},
+	})
+
+	t.Log("the watched namespaces and the ControlPlane namespace are granted access to")
+	ensureWatchNamespaceRBAC(t)
+	roles, roleBindings := listWatchNamespaceRBAC(t)
+	require.ElementsMatch(t, []string{"default", "tenant-a", "tenant-b"}, lo.Keys(roles))
+	require.ElementsMatch(t, []string{"default", "tenant-a", "tenant-b"}, lo.Keys(roleBindings))
+	for namespace, role := range roles {
+		require.Empty(t, role.OwnerReferences, "Roles in other namespaces cannot be owned by the ControlPlane")
+		require.Contains(t, role.Rules, rbacv1.PolicyRule{
+			APIGroups: []string{"gateway.networking.k8s.io"},
+			Resources: []string{"httproutes"},
+			Verbs:     []string{"get", "list", "watch"},
+		})
+		roleBinding := roleBindings[namespace]
+		require.Equal(t, role.Name, roleBinding.RoleRef.Name)
+		require.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Name: "test-sa", Namespace: controlplane.Namespace}}, roleBinding.Subjects)
+		require.Equal(t,
+			[]reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: controlplane.Namespace, Name: controlplane.Name}}},
+			reconciler.getControlPlaneFromUIDLabel(ctx, &role),
+		)
+	}
+
+	deployment, err := k8sresources.GenerateNewDeploymentForControlPlane(controlplane, consts.DefaultControlPlaneImage, "test-sa", "test-secret")
+	require.NoError(t, err)
+	require.Contains(t, deployment.Spec.Template.Spec.Containers[0].Env,
+		corev1.EnvVar{Name: "CONTROLLER_WATCH_NAMESPACE", Value: "tenant-a,tenant-b"})
+
+	t.Log("a namespace which is no longer watched is no longer granted access to")
+	controlplane.Spec.WatchNamespaces = []string{"tenant-a"}
+	ensureWatchNamespaceRBAC(t)
+	roles, roleBindings = listWatchNamespaceRBAC(t)
+	require.ElementsMatch(t, []string{"default", "tenant-a"}, lo.Keys(roles))
+	require.ElementsMatch(t, []string{"default", "tenant-a"}, lo.Keys(roleBindings))
+
+	t.Log("the Roles and RoleBindings are cleaned up on ControlPlane deletion")
+	deletions, err := reconciler.ensureWatchNamespaceRoleBindingsDeleted(ctx, controlplane)
+	require.NoError(t, err)
+	require.True(t, deletions)
+	deletions, err = reconciler.ensureWatchNamespaceRolesDeleted(ctx, controlplane)
+	require.NoError(t, err)
+	require.True(t, deletions)
+	roles, roleBindings = listWatchNamespaceRBAC(t)
+	require.Empty(t, roles)
+	require.Empty(t, roleBindings)
+
+	t.Log("the ClusterRole grants access to all the resources when the namespaces are not restricted")
+	controlplane.Spec.WatchNamespaces = nil
+	createdOrUpdated, clusterRole, err := reconciler.ensureClusterRoleForControlPlane(ctx, controlplane)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	require.Contains(t, clusterRole.Rules, rbacv1.PolicyRule{
+		APIGroups: []string{"gateway.networking.k8s.io"},
+		Resources: []string{"httproutes"},
+		Verbs:     []string{"get", "list", "watch"},
+	})
+	ensureWatchNamespaceRBAC(t)
+	roles, _ = listWatchNamespaceRBAC(t)
+	require.Empty(t, roles)
+}
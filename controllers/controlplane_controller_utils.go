@@ -4,14 +4,16 @@ import (
 	"fmt"
 	"os"
 	"reflect"
-	"strings"
+	"sort"
 
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
 	"github.com/kong/gateway-operator/internal/consts"
 	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
 	"github.com/kong/gateway-operator/internal/versions"
 	"github.com/kong/gateway-operator/pkg/vars"
 )
@@ -19,9 +21,12 @@ import (
 // controlPlaneDefaultsArgs contains the parameters to pass to setControlPlaneDefaults
 type controlPlaneDefaultsArgs struct {
 	namespace                 string
-	dataPlanePodIP            string
 	dataplaneProxyServiceName string
 	dataplaneAdminServiceName string
+	// dataplaneAdminTLSServerName is the name the ControlPlane verifies the
+	// DataPlane admin API certificates against, when it differs from the
+	// name of the admin API endpoints.
+	dataplaneAdminTLSServerName string
 }
 
 // -----------------------------------------------------------------------------
@@ -38,6 +43,42 @@ func validateControlPlane(controlplane *operatorv1alpha1.ControlPlane, devMode b
 	return err
 }
 
+// controllerOptionsProblems returns the reasons why some typed controller
+// options of the control plane are not applied: the feature gates unknown to
+// the controller, the feature gates and options not supported by the version
+// of its image, and the options overridden by the env of the pod template.
+func controllerOptionsProblems(controlplane *operatorv1alpha1.ControlPlane, controlplaneImage string) []string {
+	var problems []string
+	if opts := controlplane.Spec.Controller; opts != nil {
+		gates := lo.Keys(opts.FeatureGates)
+		sort.Strings(gates)
+		for _, gate := range gates {
+			switch {
+			case !versions.IsControllerFeatureGateKnown(gate):
+				problems = append(problems, fmt.Sprintf("unknown feature gate %s", gate))
+			case !versions.IsControllerFeatureGateSupported(controlplaneImage, gate):
+				problems = append(problems, fmt.Sprintf("feature gate %s is not supported by %s", gate, controlplaneImage))
+			}
+		}
+		if opts.Konnect != nil && !versions.IsControllerOptionSupported(controlplaneImage, "konnect") {
+			problems = append(problems, fmt.Sprintf("konnect is not supported by %s", controlplaneImage))
+		}
+	}
+
+	if controlplane.Spec.Deployment.PodTemplateSpec != nil {
+		container := k8sutils.GetPodContainerByName(&controlplane.Spec.Deployment.PodTemplateSpec.Spec, consts.ControlPlaneControllerContainerName)
+		if container != nil {
+			for _, envVar := range k8sresources.GenerateControllerOptionsEnv(controlplane, controlplaneImage) {
+				if lo.ContainsBy(container.Env, func(e corev1.EnvVar) bool { return e.Name == envVar.Name }) {
+					problems = append(problems, fmt.Sprintf("%s is overridden by the pod template", envVar.Name))
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
 // setControlPlaneDefaults updates the environment variables of control plane
 // and returns true if env field is changed.
 func setControlPlaneDefaults(
@@ -99,16 +140,43 @@ func setControlPlaneDefaults(
 		}
 	}
 
-	if args.dataPlanePodIP != "" && args.dataplaneAdminServiceName != "" {
-		adminURL := controllerKongAdminURL(args.dataPlanePodIP, args.dataplaneAdminServiceName, args.namespace)
+	// The admin API of every DataPlane replica is discovered by the controller
+	// through the endpoints of the headless admin Service, instead of being
+	// configured with the URL of a single pod.
+	if args.namespace != "" && args.dataplaneAdminServiceName != "" {
+		adminService := controllerKongAdminService(args.dataplaneAdminServiceName, args.namespace)
+		if _, isOverrideDisabled := dontOverride["CONTROLLER_KONG_ADMIN_SVC"]; !isOverrideDisabled {
+			if envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_SVC") != adminService {
+				container.Env = updateEnv(container.Env, "CONTROLLER_KONG_ADMIN_SVC", adminService)
+				changed = true
+			}
+		}
+		if _, isOverrideDisabled := dontOverride["CONTROLLER_KONG_ADMIN_SVC_PORT_NAMES"]; !isOverrideDisabled {
+			if envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_SVC_PORT_NAMES") != consts.DataPlaneAdminServicePortName {
+				container.Env = updateEnv(container.Env, "CONTROLLER_KONG_ADMIN_SVC_PORT_NAMES", consts.DataPlaneAdminServicePortName)
+				changed = true
+			}
+		}
 		if _, isOverrideDisabled := dontOverride["CONTROLLER_KONG_ADMIN_URL"]; !isOverrideDisabled {
-			if envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_URL") != adminURL {
-				container.Env = updateEnv(container.Env, "CONTROLLER_KONG_ADMIN_URL", adminURL)
+			if envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_URL") != "" {
+				container.Env = rejectEnvByName(container.Env, "CONTROLLER_KONG_ADMIN_URL")
 				changed = true
 			}
 		}
 	}
 
+	if _, isOverrideDisabled := dontOverride["CONTROLLER_KONG_ADMIN_TLS_SERVER_NAME"]; !isOverrideDisabled {
+		tlsServerName := envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_TLS_SERVER_NAME")
+		switch {
+		case args.dataplaneAdminTLSServerName != "" && tlsServerName != args.dataplaneAdminTLSServerName:
+			container.Env = updateEnv(container.Env, "CONTROLLER_KONG_ADMIN_TLS_SERVER_NAME", args.dataplaneAdminTLSServerName)
+			changed = true
+		case args.dataplaneAdminTLSServerName == "" && tlsServerName == consts.DataPlaneAdminAPITLSServerName:
+			container.Env = rejectEnvByName(container.Env, "CONTROLLER_KONG_ADMIN_TLS_SERVER_NAME")
+			changed = true
+		}
+	}
+
 	if _, isOverrideDisabled := dontOverride["CONTROLLER_KONG_ADMIN_TLS_CLIENT_CERT_FILE"]; !isOverrideDisabled {
 		container.Env = updateEnv(container.Env, "CONTROLLER_KONG_ADMIN_TLS_CLIENT_CERT_FILE", "/var/cluster-certificate/tls.crt")
 	}
@@ -147,9 +215,8 @@ func setControlPlaneEnvOnDataPlaneChange(
 	return changed
 }
 
-func controllerKongAdminURL(podIP, adminServiceName, podNamespace string) string {
-	return fmt.Sprintf("https://%s.%s.%s.svc:%d",
-		strings.ReplaceAll(podIP, ".", "-"), adminServiceName, podNamespace, consts.DataPlaneAdminAPIPort)
+func controllerKongAdminService(adminServiceName, adminServiceNamespace string) string {
+	return fmt.Sprintf("%s/%s", adminServiceNamespace, adminServiceName)
 }
 
 func controllerPublishService(dataplaneName, dataplaneNamespace string) string {
@@ -260,13 +327,46 @@ func addLabelForControlPlane(obj client.Object) {
 	obj.SetLabels(labels)
 }
 
+// addWatchNamespaceLabelsForControlPlane labels the objects created in the
+// namespaces watched by the controlplane, which cannot refer to it as their
+// owner when they live in another namespace.
+func addWatchNamespaceLabelsForControlPlane(obj client.Object, controlplane *operatorv1alpha1.ControlPlane) {
+	addLabelForControlPlane(obj)
+	labels := obj.GetLabels()
+	labels[consts.ControlPlaneUIDLabel] = string(controlplane.UID)
+	obj.SetLabels(labels)
+}
+
+// watchNamespaceLabelsForControlPlane returns the labels selecting the objects
+// created in the namespaces watched by the controlplane.
+func watchNamespaceLabelsForControlPlane(controlplane *operatorv1alpha1.ControlPlane) client.MatchingLabels {
+	return client.MatchingLabels{
+		consts.GatewayOperatorControlledLabel: consts.ControlPlaneManagedLabelValue,
+		consts.ControlPlaneUIDLabel:           string(controlplane.UID),
+	}
+}
+
+// controlPlaneWatchNamespaces returns the namespaces the controlplane has to be
+// granted access to when its watched namespaces are restricted. Its own
+// namespace is always included, as it holds the Services of its DataPlane.
+func controlPlaneWatchNamespaces(controlplane *operatorv1alpha1.ControlPlane) []string {
+	if len(controlplane.Spec.WatchNamespaces) == 0 {
+		return nil
+	}
+	return lo.Uniq(append([]string{controlplane.Namespace}, controlplane.Spec.WatchNamespaces...))
+}
+
 // -----------------------------------------------------------------------------
 // ControlPlane - Private Functions - Equality Checks
 // -----------------------------------------------------------------------------
 
 func controlplaneSpecDeepEqual(spec1, spec2 *operatorv1alpha1.ControlPlaneOptions, envVarsToIgnore ...string) bool {
 	if !alphaDeploymentOptionsDeepEqual(&spec1.Deployment, &spec2.Deployment, envVarsToIgnore...) ||
-		!reflect.DeepEqual(spec1.DataPlane, spec2.DataPlane) {
+		!reflect.DeepEqual(spec1.DataPlane, spec2.DataPlane) ||
+		!reflect.DeepEqual(spec1.DataPlanes, spec2.DataPlanes) ||
+		!reflect.DeepEqual(spec1.DataPlaneSelector, spec2.DataPlaneSelector) ||
+		!reflect.DeepEqual(spec1.WatchNamespaces, spec2.WatchNamespaces) ||
+		!reflect.DeepEqual(spec1.Controller, spec2.Controller) {
 		return false
 	}
 
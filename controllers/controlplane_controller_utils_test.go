@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
 	"github.com/kong/gateway-operator/internal/consts"
@@ -100,8 +101,12 @@ func TestSetControlPlaneDefaults(t *testing.T) {
 											Value: "test-ns/kong-proxy",
 										},
 										{
-											Name:  "CONTROLLER_KONG_ADMIN_URL",
-											Value: "https://1-2-3-4.kong-admin.test-ns.svc:8444",
+											Name:  "CONTROLLER_KONG_ADMIN_SVC",
+											Value: "test-ns/kong-admin",
+										},
+										{
+											Name:  "CONTROLLER_KONG_ADMIN_SVC_PORT_NAMES",
+											Value: "admin",
 										},
 										{
 											Name:  "CONTROLLER_KONG_ADMIN_TLS_CLIENT_CERT_FILE",
@@ -182,8 +187,12 @@ func TestSetControlPlaneDefaults(t *testing.T) {
 											Value: "test-ns/kong-proxy",
 										},
 										{
-											Name:  "CONTROLLER_KONG_ADMIN_URL",
-											Value: "https://1-2-3-4.kong-admin.test-ns.svc:8444",
+											Name:  "CONTROLLER_KONG_ADMIN_SVC",
+											Value: "test-ns/kong-admin",
+										},
+										{
+											Name:  "CONTROLLER_KONG_ADMIN_SVC_PORT_NAMES",
+											Value: "admin",
 										},
 										{
 											Name:  "CONTROLLER_KONG_ADMIN_TLS_CLIENT_CERT_FILE",
@@ -239,8 +248,12 @@ func TestSetControlPlaneDefaults(t *testing.T) {
 											Value: "test-ns/kong-proxy",
 										},
 										{
-											Name:  "CONTROLLER_KONG_ADMIN_URL",
-											Value: "https://1-2-3-4.kong-admin.test-ns.svc:8444",
+											Name:  "CONTROLLER_KONG_ADMIN_SVC",
+											Value: "test-ns/kong-admin",
+										},
+										{
+											Name:  "CONTROLLER_KONG_ADMIN_SVC_PORT_NAMES",
+											Value: "admin",
 										},
 										{
 											Name:  "CONTROLLER_KONG_ADMIN_TLS_CLIENT_CERT_FILE",
@@ -296,8 +309,12 @@ func TestSetControlPlaneDefaults(t *testing.T) {
 											Value: "test-ns/kong-proxy",
 										},
 										{
-											Name:  "CONTROLLER_KONG_ADMIN_URL",
-											Value: "https://1-2-3-4.kong-admin.test-ns.svc:8444",
+											Name:  "CONTROLLER_KONG_ADMIN_SVC",
+											Value: "test-ns/kong-admin",
+										},
+										{
+											Name:  "CONTROLLER_KONG_ADMIN_SVC_PORT_NAMES",
+											Value: "admin",
 										},
 										{
 											Name:  "CONTROLLER_KONG_ADMIN_TLS_CLIENT_CERT_FILE",
@@ -326,7 +343,6 @@ func TestSetControlPlaneDefaults(t *testing.T) {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			changed := setControlPlaneDefaults(tc.spec, map[string]struct{}{}, controlPlaneDefaultsArgs{
-				dataPlanePodIP:            "1.2.3.4",
 				namespace:                 tc.namespace,
 				dataplaneProxyServiceName: tc.dataplaneProxyServiceName,
 				dataplaneAdminServiceName: "kong-admin",
@@ -610,6 +626,54 @@ func TestControlPlaneSpecDeepEqual(t *testing.T) {
 			},
 			equal: false,
 		},
+		{
+			name: "different controller options",
+			spec1: &operatorv1alpha1.ControlPlaneOptions{
+				Controller: &operatorv1alpha1.ControllerOptions{
+					FeatureGates: map[string]bool{"GatewayAlpha": true},
+				},
+			},
+			spec2: &operatorv1alpha1.ControlPlaneOptions{
+				Controller: &operatorv1alpha1.ControllerOptions{
+					FeatureGates: map[string]bool{"GatewayAlpha": false},
+				},
+			},
+			equal: false,
+		},
+		{
+			name: "different dataplanes",
+			spec1: &operatorv1alpha1.ControlPlaneOptions{
+				DataPlanes: []string{"dp-1"},
+			},
+			spec2: &operatorv1alpha1.ControlPlaneOptions{
+				DataPlanes: []string{"dp-1", "dp-2"},
+			},
+			equal: false,
+		},
+		{
+			name: "different dataplane selectors",
+			spec1: &operatorv1alpha1.ControlPlaneOptions{
+				DataPlaneSelector: &operatorv1alpha1.DataPlaneSelector{
+					Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "kong"}},
+				},
+			},
+			spec2: &operatorv1alpha1.ControlPlaneOptions{
+				DataPlaneSelector: &operatorv1alpha1.DataPlaneSelector{
+					Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+				},
+			},
+			equal: false,
+		},
+		{
+			name: "different watch namespaces",
+			spec1: &operatorv1alpha1.ControlPlaneOptions{
+				WatchNamespaces: []string{"ns-1"},
+			},
+			spec2: &operatorv1alpha1.ControlPlaneOptions{
+				WatchNamespaces: []string{"ns-1", "ns-2"},
+			},
+			equal: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -619,3 +683,121 @@ func TestControlPlaneSpecDeepEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestSetControlPlaneDefaultsAdminServiceDiscovery(t *testing.T) {
+	spec := &operatorv1alpha1.ControlPlaneOptions{
+		Deployment: operatorv1alpha1.DeploymentOptions{
+			PodTemplateSpec: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: consts.ControlPlaneControllerContainerName,
+							Env: []corev1.EnvVar{
+								{
+									Name:  "CONTROLLER_KONG_ADMIN_URL",
+									Value: "https://1-2-3-4.kong-admin.test-ns.svc:8444",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	args := controlPlaneDefaultsArgs{
+		namespace:                 "test-ns",
+		dataplaneProxyServiceName: "kong-proxy",
+		dataplaneAdminServiceName: "kong-admin",
+	}
+
+	require.True(t, setControlPlaneDefaults(spec, nil, args))
+	container := k8sutils.GetPodContainerByName(&spec.Deployment.PodTemplateSpec.Spec, consts.ControlPlaneControllerContainerName)
+	require.Equal(t, "test-ns/kong-admin", envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_SVC"))
+	require.Equal(t, "admin", envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_SVC_PORT_NAMES"))
+	require.Empty(t, envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_URL"),
+		"the admin API URL of a single DataPlane pod should be dropped in favor of the discovery")
+
+	require.False(t, setControlPlaneDefaults(spec, nil, args), "the defaults should be stable")
+	require.Empty(t, envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_TLS_SERVER_NAME"))
+
+	t.Log("the admin APIs of several DataPlanes are discovered through the ControlPlane admin Service")
+	args.dataplaneAdminServiceName = "controlplane-admin"
+	args.dataplaneAdminTLSServerName = consts.DataPlaneAdminAPITLSServerName
+	require.True(t, setControlPlaneDefaults(spec, nil, args))
+	container = k8sutils.GetPodContainerByName(&spec.Deployment.PodTemplateSpec.Spec, consts.ControlPlaneControllerContainerName)
+	require.Equal(t, "test-ns/controlplane-admin", envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_SVC"))
+	require.Equal(t, consts.DataPlaneAdminAPITLSServerName, envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_TLS_SERVER_NAME"))
+	require.False(t, setControlPlaneDefaults(spec, nil, args), "the defaults should be stable")
+
+	t.Log("the TLS server name is dropped when going back to a single DataPlane")
+	args.dataplaneAdminServiceName = "kong-admin"
+	args.dataplaneAdminTLSServerName = ""
+	require.True(t, setControlPlaneDefaults(spec, nil, args))
+	container = k8sutils.GetPodContainerByName(&spec.Deployment.PodTemplateSpec.Spec, consts.ControlPlaneControllerContainerName)
+	require.Equal(t, "test-ns/kong-admin", envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_SVC"))
+	require.Empty(t, envValueByName(container.Env, "CONTROLLER_KONG_ADMIN_TLS_SERVER_NAME"))
+}
+
+func TestControllerOptionsProblems(t *testing.T) {
+	controlplane := &operatorv1alpha1.ControlPlane{
+		Spec: operatorv1alpha1.ControlPlaneSpec{
+			ControlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+				Controller: &operatorv1alpha1.ControllerOptions{
+					FeatureGates: map[string]bool{
+						"GatewayAlpha":     true,
+						"ExpressionRoutes": true,
+						"GatewayAplha":     true,
+					},
+					Konnect: &operatorv1alpha1.ControllerKonnectOptions{
+						RuntimeGroupID:                 "rg-id",
+						TLSClientCertificateSecretName: "konnect-client",
+					},
+				},
+				Deployment: operatorv1alpha1.DeploymentOptions{
+					PodTemplateSpec: &corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{
+								Name: consts.ControlPlaneControllerContainerName,
+								Env: []corev1.EnvVar{
+									{Name: "CONTROLLER_KONNECT_RUNTIME_GROUP_ID", Value: "other-rg-id"},
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	require.Equal(t, []string{
+		"unknown feature gate GatewayAplha",
+		"CONTROLLER_KONNECT_RUNTIME_GROUP_ID is overridden by the pod template",
+	}, controllerOptionsProblems(controlplane, "kong/kubernetes-ingress-controller:2.10.4"))
+
+	require.Equal(t, []string{
+		"feature gate ExpressionRoutes is not supported by kong/kubernetes-ingress-controller:2.8.1",
+		"unknown feature gate GatewayAplha",
+		"konnect is not supported by kong/kubernetes-ingress-controller:2.8.1",
+	}, controllerOptionsProblems(controlplane, "kong/kubernetes-ingress-controller:2.8.1"))
+
+	t.Log("the problems are reported in the ControllerOptionsValid condition")
+	r := ControlPlaneReconciler{}
+	r.ensureControllerOptionsStatus(controlplane, "kong/kubernetes-ingress-controller:2.10.4")
+	condition, ok := k8sutils.GetCondition(ControlPlaneConditionTypeControllerOptionsValid, controlplane)
+	require.True(t, ok)
+	require.Equal(t, metav1.ConditionFalse, condition.Status)
+	require.Equal(t, string(ControlPlaneConditionReasonInvalidControllerOptions), condition.Reason)
+	require.Equal(t, "unknown feature gate GatewayAplha; CONTROLLER_KONNECT_RUNTIME_GROUP_ID is overridden by the pod template", condition.Message)
+
+	controlplane.Spec.Controller.FeatureGates = nil
+	controlplane.Spec.Deployment.PodTemplateSpec.Spec.Containers[0].Env = nil
+	r.ensureControllerOptionsStatus(controlplane, "kong/kubernetes-ingress-controller:2.10.4")
+	condition, ok = k8sutils.GetCondition(ControlPlaneConditionTypeControllerOptionsValid, controlplane)
+	require.True(t, ok)
+	require.Equal(t, metav1.ConditionTrue, condition.Status)
+
+	controlplane.Spec.Controller = nil
+	r.ensureControllerOptionsStatus(controlplane, "kong/kubernetes-ingress-controller:2.10.4")
+	_, ok = k8sutils.GetCondition(ControlPlaneConditionTypeControllerOptionsValid, controlplane)
+	require.False(t, ok)
+}
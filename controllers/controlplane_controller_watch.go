@@ -4,7 +4,7 @@ import (
 	"context"
 	"reflect"
 
-	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -14,8 +14,8 @@ import (
 
 	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
 	operatorerrors "github.com/kong/gateway-operator/internal/errors"
-	"github.com/kong/gateway-operator/internal/utils/index"
 	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
 )
 
@@ -74,6 +74,13 @@ func (r *ControlPlaneReconciler) objHasControlplaneOwner(ctx context.Context, ob
 	return false
 }
 
+// hasControlPlaneUIDLabel filters the objects created by the controlplane
+// controller in other namespaces than the one of their controlplane.
+func hasControlPlaneUIDLabel(obj client.Object) bool {
+	_, ok := obj.GetLabels()[consts.ControlPlaneUIDLabel]
+	return ok
+}
+
 // -----------------------------------------------------------------------------
 // ControlplaneReconciler - Watch Map Funcs
 // -----------------------------------------------------------------------------
@@ -129,36 +136,32 @@ func (r *ControlPlaneReconciler) getControlplaneRequestFromRefUID(ctx context.Co
 	return
 }
 
-func (r *ControlPlaneReconciler) getControlPlanesFromDataPlaneDeployment(ctx context.Context, obj client.Object) (recs []reconcile.Request) {
-	deployment, ok := obj.(*appsv1.Deployment)
-	if !ok {
-		log.FromContext(ctx).Error(
-			operatorerrors.ErrUnexpectedObject,
-			"failed to map ControlPlane on DataPlane Deployment",
-			"expected", "Deployment", "found", reflect.TypeOf(obj),
-		)
+func (r *ControlPlaneReconciler) getControlPlaneFromUIDLabel(ctx context.Context, obj client.Object) (recs []reconcile.Request) {
+	uid := types.UID(obj.GetLabels()[consts.ControlPlaneUIDLabel])
+	if uid == "" {
 		return
 	}
 
-	var dataPlaneOwnerName string
-	for _, ownRef := range deployment.OwnerReferences {
-		if ownRef.APIVersion == operatorv1beta1.SchemeGroupVersion.String() && ownRef.Kind == "DataPlane" {
-			dataPlaneOwnerName = ownRef.Name
-			break
-		}
-	}
-	if dataPlaneOwnerName == "" {
+	controlplanes := &operatorv1alpha1.ControlPlaneList{}
+	if err := r.Client.List(ctx, controlplanes); err != nil {
+		log.FromContext(ctx).Error(err, "could not list controlplanes in map func")
 		return
 	}
 
-	dataPlane := &operatorv1beta1.DataPlane{}
-	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: deployment.Namespace, Name: dataPlaneOwnerName}, dataPlane); err != nil {
-		if !k8serrors.IsNotFound(err) {
-			log.FromContext(ctx).Error(err, "failed to map ControlPlane on DataPlane Deployment")
+	for _, controlplane := range controlplanes.Items {
+		if controlplane.UID == uid {
+			return []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Namespace: controlplane.Namespace,
+						Name:      controlplane.Name,
+					},
+				},
+			}
 		}
-		return
 	}
-	return r.getControlPlanesFromDataPlane(ctx, dataPlane)
+
+	return
 }
 
 func (r *ControlPlaneReconciler) getControlPlanesFromDataPlane(ctx context.Context, obj client.Object) (recs []reconcile.Request) {
@@ -172,18 +175,14 @@ func (r *ControlPlaneReconciler) getControlPlanesFromDataPlane(ctx context.Conte
 		return
 	}
 
-	controlPlaneList := &operatorv1alpha1.ControlPlaneList{}
-	if err := r.Client.List(ctx, controlPlaneList,
-		client.InNamespace(dataplane.Namespace),
-		client.MatchingFields{
-			index.DataplaneNameIndex: dataplane.Name,
-		}); err != nil {
+	controlplanes, err := listControlPlanesForDataPlane(ctx, r.Client, dataplane)
+	if err != nil {
 		log.FromContext(ctx).Error(err, "failed to map ControlPlane on DataPlane")
 		return
 	}
 
-	recs = make([]reconcile.Request, 0, len(controlPlaneList.Items))
-	for _, cp := range controlPlaneList.Items {
+	recs = make([]reconcile.Request, 0, len(controlplanes))
+	for _, cp := range controlplanes {
 		recs = append(recs, reconcile.Request{
 			NamespacedName: types.NamespacedName{
 				Namespace: cp.Namespace,
@@ -193,3 +192,60 @@ func (r *ControlPlaneReconciler) getControlPlanesFromDataPlane(ctx context.Conte
 	}
 	return recs
 }
+
+// getControlPlaneForPod maps the pods of the controlplane Deployments to their
+// controlplane, as the failures of the pods are reported in its status while
+// they do not change the status of the Deployment.
+func (r *ControlPlaneReconciler) getControlPlaneForPod(ctx context.Context, obj client.Object) (recs []reconcile.Request) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		log.FromContext(ctx).Error(
+			operatorerrors.ErrUnexpectedObject,
+			"failed to run map funcs",
+			"expected", "Pod", "found", reflect.TypeOf(obj),
+		)
+		return
+	}
+
+	name, ok := pod.Labels["app"]
+	if !ok {
+		return
+	}
+	controlplane := &operatorv1alpha1.ControlPlane{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: name}, controlplane); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "failed to map ControlPlane on Pod")
+		}
+		return
+	}
+
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Namespace: controlplane.Namespace,
+				Name:      controlplane.Name,
+			},
+		},
+	}
+}
+
+// getControlPlanesForClusterCASecret maps the cluster CA Secret to all the
+// controlplanes, whose certificates are all issued from it.
+func (r *ControlPlaneReconciler) getControlPlanesForClusterCASecret(ctx context.Context, obj client.Object) (recs []reconcile.Request) {
+	controlplanes := &operatorv1alpha1.ControlPlaneList{}
+	if err := r.Client.List(ctx, controlplanes); err != nil {
+		log.FromContext(ctx).Error(err, "failed to map ControlPlanes on the cluster CA Secret")
+		return
+	}
+
+	recs = make([]reconcile.Request, 0, len(controlplanes.Items))
+	for _, controlplane := range controlplanes.Items {
+		recs = append(recs, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: controlplane.Namespace,
+				Name:      controlplane.Name,
+			},
+		})
+	}
+	return recs
+}
@@ -2,14 +2,23 @@ package controllers
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"net/http"
 
+	"github.com/go-logr/logr"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	dataplaneutils "github.com/kong/gateway-operator/internal/utils/dataplane"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
+	"github.com/kong/gateway-operator/internal/versions"
 )
 
 // -----------------------------------------------------------------------------
@@ -17,16 +26,37 @@ import (
 // -----------------------------------------------------------------------------
 
 // DataPlaneBlueGreenReconciler reconciles a DataPlane objects for purposes
-// of Blue Green rollouts.
+// of Blue Green and Canary rollouts.
 type DataPlaneBlueGreenReconciler struct {
 	client.Client
 	DataPlaneReconciler reconcile.Reconciler
 	DevelopmentMode     bool
+
+	// ClusterCASecretName and ClusterCASecretNamespace refer to the cluster CA
+	// Secret, whose changes trigger the reconciliation of the DataPlanes.
+	ClusterCASecretName      string
+	ClusterCASecretNamespace string
+	// CertificateOptions configures the certificates of the DataPlanes, which
+	// might be requested from cert-manager through Certificates to watch.
+	CertificateOptions CertificateOptions
+
+	// promotionCheckHTTPClient is the client used by the HTTP promotion checks.
+	// When nil, a client with a default timeout is used.
+	promotionCheckHTTPClient *http.Client
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *DataPlaneBlueGreenReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return DataPlaneWatchBuilder(mgr).
+	// the wrapped DataPlaneReconciler is not set up with the manager on its own,
+	// its event recorder is set here.
+	if dataplaneReconciler, ok := r.DataPlaneReconciler.(*DataPlaneReconciler); ok {
+		dataplaneReconciler.eventRecorder = mgr.GetEventRecorderFor("dataplane")
+	}
+
+	return DataPlaneWatchBuilder(mgr, types.NamespacedName{
+		Namespace: r.ClusterCASecretNamespace,
+		Name:      r.ClusterCASecretName,
+	}, r.CertificateOptions).
 		Complete(r)
 }
 
@@ -46,11 +76,282 @@ func (r *DataPlaneBlueGreenReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 	log := getLogger(ctx, "dataplaneBlueGreen", r.DevelopmentMode)
 
-	// Blue Gree rollout strategy is not enabled, delegate to DataPlane controller.
-	if dataplane.Spec.Deployment.Rollout == nil || dataplane.Spec.Deployment.Rollout.Strategy.BlueGreen == nil {
-		trace(log, "no Rollout with BlueGreen strategy specified, delegating to DataPlaneReconciler", req)
+	// No rollout strategy is enabled, delegate to DataPlane controller.
+	if dataplane.Spec.Deployment.Rollout == nil ||
+		(dataplane.Spec.Deployment.Rollout.Strategy.BlueGreen == nil && dataplane.Spec.Deployment.Rollout.Strategy.Canary == nil) {
+		trace(log, "no Rollout with BlueGreen or Canary strategy specified, delegating to DataPlaneReconciler", req)
+		deleted, err := r.ensurePreviewResourcesDeleted(ctx, &dataplane)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if deleted {
+			debug(log, "preview resources of a previous rollout deleted", dataplane)
+		}
+		deleted, err = r.ensureCanaryResourcesDeleted(ctx, &dataplane)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if deleted {
+			debug(log, "canary resources of a previous rollout deleted", dataplane)
+		}
+		if dataplane.Status.RolloutStatus != nil {
+			dataplane.Status.RolloutStatus = nil
+			return ctrl.Result{}, r.patchRolloutStatus(ctx, log, &dataplane)
+		}
 		return r.DataPlaneReconciler.Reconcile(ctx, req)
 	}
 
-	return ctrl.Result{}, errors.New("not implemented")
+	// The live resources are managed by the DataPlane controller, which holds
+	// the changes to the live Deployment's pod template until the promotion.
+	trace(log, "reconciling live DataPlane resources", req)
+	liveRes, err := r.DataPlaneReconciler.Reconcile(ctx, req)
+	if err != nil || liveRes.Requeue {
+		return liveRes, err
+	}
+
+	// The renewal of the certificates the DataPlane controller requeues the
+	// reconciliation for has to be kept along with the rollout requeues.
+	res, err := r.reconcileRollout(ctx, log, req)
+	return requeueBefore(res, liveRes.RequeueAfter), err
+}
+
+// reconcileRollout moves the current state of the rollout resources of the
+// DataPlane to the intended state, once its live resources are reconciled.
+func (r *DataPlaneBlueGreenReconciler) reconcileRollout(ctx context.Context, log logr.Logger, req ctrl.Request) (ctrl.Result, error) {
+	var dataplane operatorv1beta1.DataPlane
+	if err := r.Client.Get(ctx, req.NamespacedName, &dataplane); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// An approval only applies to the preview awaiting the promotion: given at
+	// any other time, it would promote the next rollout before it is reviewed.
+	if !isRolloutAwaitingPromotion(&dataplane) {
+		if err := r.ensurePromotionApprovalRemoved(ctx, &dataplane); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// The defaults are set by the DataPlane controller on the live Deployment
+	// without being written back, so they must be set on the preview and canary
+	// Deployments too for them to match the live one once promoted.
+	dataplaneutils.SetDataPlaneDefaults(&dataplane.Spec.DataPlaneOptions)
+
+	// The preview and canary pods run the new configuration, which requires the
+	// database migrations to be up.
+	if !isDatabaseMigrated(&dataplane) {
+		trace(log, "waiting for the DataPlane database migrations to be up", dataplane)
+		return ctrl.Result{}, nil // the migrations Job status update will trigger reconciliation
+	}
+
+	trace(log, "looking for the live Deployment of the DataPlane", dataplane)
+	liveDeployment, err := r.getLiveDeployment(ctx, &dataplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if liveDeployment == nil {
+		trace(log, "live Deployment not available yet, waiting", dataplane)
+		return ctrl.Result{}, nil // the creation of the live Deployment will trigger reconciliation
+	}
+
+	versionValidationOptions := make([]versions.VersionValidationOption, 0)
+	if !r.DevelopmentMode {
+		versionValidationOptions = append(versionValidationOptions, versions.IsDataPlaneImageVersionSupported)
+	}
+	dataplaneImage, err := generateDataPlaneImage(&dataplane, versionValidationOptions...)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	certSecretName := clusterCertificateSecretName(liveDeployment)
+	desiredDeployment, err := k8sresources.GenerateNewDeploymentForDataPlane(&dataplane, dataplaneImage, certSecretName)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	checksum, err := certificatesChecksum(ctx, r.Client, &dataplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	setCertificatesChecksum(desiredDeployment, checksum)
+
+	if podTemplateSpecsEqual(liveDeployment.Spec.Template, desiredDeployment.Spec.Template) {
+		if isRolloutPromotionInProgress(&dataplane) {
+			if !k8sutils.IsDeploymentRolledOut(liveDeployment) {
+				trace(log, "waiting for the live Deployment to roll out the promoted changes", dataplane)
+				return ctrl.Result{}, nil // the live Deployment status update will trigger reconciliation
+			}
+
+			moved, err := r.reconcileCanaryPromotion(ctx, log, &dataplane, liveDeployment, dataplaneImage, certSecretName)
+			if err != nil || !moved {
+				return ctrl.Result{}, err
+			}
+
+			revision, err := podTemplateSpecHash(liveDeployment.Spec.Template)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			debug(log, "DataPlane rollout promoted", dataplane, "revision", revision)
+			dataplane.Status.RolloutStatus.Promotion = &operatorv1beta1.DataPlaneRolloutStatusPromotion{
+				Time:     metav1.Now(),
+				Revision: revision,
+			}
+			dataplane.Status.RolloutStatus.Services = nil
+			dataplane.Status.RolloutStatus.PromotionChecks = nil
+			dataplane.Status.RolloutStatus.Canary = nil
+			// The preview resources are deleted once the live Services, which
+			// select the preview pods until the promotion is done, select the
+			// live pods again.
+			setRolloutCondition(&dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionTrue,
+				DataPlaneConditionReasonRolloutPromotionDone, "live resources are up to date")
+			return ctrl.Result{}, r.patchRolloutStatus(ctx, log, &dataplane)
+		}
+
+		waiting, err := r.ensureCanaryRolloutAborted(ctx, log, &dataplane, liveDeployment)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if waiting {
+			trace(log, "waiting for the live Deployment to be scaled back before deleting the canary one", dataplane)
+			return ctrl.Result{}, nil // the live Deployment status update will trigger reconciliation
+		}
+
+		trace(log, "no changes to roll out, ensuring preview and canary resources are deleted", dataplane)
+		deleted, err := r.ensurePreviewResourcesDeleted(ctx, &dataplane)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if deleted {
+			debug(log, "preview resources deleted", dataplane)
+			return ctrl.Result{}, nil // the deletion of the owned objects will trigger reconciliation
+		}
+		deleted, err = r.ensureCanaryResourcesDeleted(ctx, &dataplane)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if deleted {
+			debug(log, "canary resources deleted", dataplane)
+			return ctrl.Result{}, nil // the deletion of the owned objects will trigger reconciliation
+		}
+
+		if dataplane.Status.RolloutStatus == nil {
+			return ctrl.Result{}, nil
+		}
+
+		dataplane.Status.RolloutStatus.Services = nil
+		dataplane.Status.RolloutStatus.PromotionChecks = nil
+		dataplane.Status.RolloutStatus.Canary = nil
+		setRolloutCondition(&dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionTrue,
+			DataPlaneConditionReasonRolloutPromotionDone, "live resources are up to date")
+		return ctrl.Result{}, r.patchRolloutStatus(ctx, log, &dataplane)
+	}
+
+	revision, err := podTemplateSpecHash(desiredDeployment.Spec.Template)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if dataplane.Spec.Deployment.Rollout.Strategy.Canary != nil {
+		return r.reconcileCanaryRollout(ctx, log, &dataplane, liveDeployment, revision, dataplaneImage, certSecretName)
+	}
+
+	deleted, err := r.ensureCanaryResourcesDeleted(ctx, &dataplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if deleted {
+		debug(log, "canary resources of a previous Canary rollout deleted", dataplane)
+		return ctrl.Result{}, nil // the deletion of the owned objects will trigger reconciliation
+	}
+
+	if isRevisionRolledBack(&dataplane, revision) {
+		trace(log, "changes to preview have already been rolled back, ensuring preview resources are deleted", dataplane)
+		deleted, err := r.ensurePreviewResourcesDeleted(ctx, &dataplane)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if deleted {
+			debug(log, "preview resources of a rolled back revision deleted", dataplane, "revision", revision)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	trace(log, "exposing DataPlane preview deployment admin API via headless service", dataplane)
+	createdOrUpdated, previewAdminService, err := r.ensurePreviewAdminService(ctx, &dataplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if createdOrUpdated {
+		debug(log, "DataPlane preview admin service created/updated", dataplane, "service", previewAdminService.Name)
+		return ctrl.Result{}, nil // preview admin service creation/update will trigger reconciliation
+	}
+
+	trace(log, "exposing DataPlane preview deployment proxy via service", dataplane)
+	createdOrUpdated, previewProxyService, err := r.ensurePreviewProxyService(ctx, &dataplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if createdOrUpdated {
+		debug(log, "DataPlane preview proxy service created/updated", dataplane, "service", previewProxyService.Name)
+		return ctrl.Result{}, nil // preview proxy service creation/update will trigger reconciliation
+	}
+
+	if err := ensureRolloutStatusServices(&dataplane, previewProxyService, previewAdminService); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	trace(log, "ensuring DataPlane preview deployment", dataplane)
+	deploymentRes, previewDeployment, err := r.ensurePreviewDeployment(ctx, &dataplane, dataplaneImage, certSecretName)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if deploymentRes != Noop || !k8sutils.IsDeploymentRolledOut(previewDeployment) {
+		debug(log, "DataPlane preview deployment is not ready yet", dataplane, "deployment", previewDeployment.Name)
+		setRolloutCondition(&dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+			DataPlaneConditionReasonRolloutProgressing, "preview deployment is not ready yet")
+		resetPromotionChecksConditions(&dataplane)
+		return ctrl.Result{}, r.patchRolloutStatus(ctx, log, &dataplane)
+	}
+
+	if isRolloutPromotionInProgress(&dataplane) {
+		trace(log, "waiting for the live Deployment to be updated with the promoted changes", dataplane)
+		return ctrl.Result{}, nil // the live Deployment update will trigger reconciliation
+	}
+
+	trace(log, "running DataPlane promotion checks", dataplane)
+	checks, err := r.runPromotionChecks(ctx, &dataplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if checks.failedCheck != "" {
+		debug(log, "DataPlane promotion check failed, rolling back preview resources", dataplane,
+			"check", checks.failedCheck, "revision", revision)
+		if err := r.rollbackPreview(ctx, &dataplane, revision, checks.failedCheck); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.patchRolloutStatus(ctx, log, &dataplane)
+	}
+	if checks.pending {
+		trace(log, "DataPlane promotion checks are pending", dataplane)
+		setRolloutCondition(&dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+			DataPlaneConditionReasonRolloutProgressing, "waiting for promotion checks to pass")
+		return ctrl.Result{RequeueAfter: checks.requeueAfter}, r.patchRolloutStatus(ctx, log, &dataplane)
+	}
+
+	if dataplane.Spec.Deployment.Rollout.Strategy.BlueGreen.Promotion.Strategy == operatorv1beta1.AutomaticPromotion ||
+		isPromotionApproved(&dataplane) {
+		// The DataPlane controller switches the live Services to the preview
+		// pods, then applies the promoted pod template to the live Deployment.
+		debug(log, "promoting DataPlane preview resources", dataplane)
+		setRolloutCondition(&dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+			DataPlaneConditionReasonRolloutPromotionInProgress, "live traffic is switched to the preview deployment while it is being promoted")
+	} else {
+		trace(log, "DataPlane preview resources are waiting to be promoted", dataplane)
+		setRolloutCondition(&dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+			DataPlaneConditionReasonRolloutAwaitingPromotion,
+			fmt.Sprintf("preview deployment is ready, set the %s annotation to %q to promote it",
+				consts.DataPlanePromoteWhenReadyAnnotationKey, consts.DataPlanePromoteWhenReadyAnnotationTrue),
+		)
+	}
+
+	return ctrl.Result{}, r.patchRolloutStatus(ctx, log, &dataplane)
 }
@@ -0,0 +1,345 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/exp/slices"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+)
+
+// -----------------------------------------------------------------------------
+// DataPlaneBlueGreenReconciler - Canary Rollouts
+// -----------------------------------------------------------------------------
+
+// reconcileCanaryRollout moves the Canary rollout of the provided DataPlane
+// forward, one step at a time. Within a step the replicas are moved between
+// the live and the canary Deployments one at a time, always scaling up before
+// scaling down, so that the DataPlane's capacity is preserved while requiring
+// a single additional replica at most.
+func (r *DataPlaneBlueGreenReconciler) reconcileCanaryRollout(
+	ctx context.Context,
+	log logr.Logger,
+	dataplane *operatorv1beta1.DataPlane,
+	liveDeployment *appsv1.Deployment,
+	revision string,
+	dataplaneImage string,
+	certSecretName string,
+) (ctrl.Result, error) {
+	deleted, err := r.ensurePreviewResourcesDeleted(ctx, dataplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if deleted {
+		debug(log, "preview resources of a previous Blue Green rollout deleted", dataplane)
+		return ctrl.Result{}, nil // the deletion of the owned objects will trigger reconciliation
+	}
+
+	if isRolloutPromotionInProgress(dataplane) {
+		trace(log, "waiting for the live Deployment to be updated with the promoted changes", dataplane)
+		return ctrl.Result{}, nil // the live Deployment update will trigger reconciliation
+	}
+
+	if dataplane.Status.RolloutStatus == nil {
+		dataplane.Status.RolloutStatus = &operatorv1beta1.DataPlaneRolloutStatus{}
+	}
+	canary := dataplane.Status.RolloutStatus.Canary
+	if canary == nil || canary.Revision != revision {
+		// A new revision starts over from the first step, keeping the live
+		// Deployment's replicas until the canary ones are scaled.
+		next := &operatorv1beta1.DataPlaneRolloutStatusCanary{
+			Revision:       revision,
+			StableReplicas: k8sutils.DeploymentReplicas(liveDeployment),
+			Replicas:       k8sutils.DeploymentReplicas(liveDeployment),
+		}
+		if canary != nil {
+			next.CanaryReplicas = canary.CanaryReplicas
+			next.StableReplicas = canary.StableReplicas
+			next.Replicas = canary.Replicas
+		}
+		debug(log, "starting DataPlane canary rollout", dataplane, "revision", revision)
+		canary = next
+		dataplane.Status.RolloutStatus.Canary = canary
+	}
+
+	steps := canaryRolloutSteps(dataplane.Spec.Deployment.Rollout.Strategy.Canary)
+	if int(canary.Step) >= len(steps) {
+		canary.Step = int32(len(steps) - 1)
+	}
+	step := steps[canary.Step]
+	canary.Weight = step.Weight
+	canary.Replicas = canaryRolloutReplicas(dataplane, canary)
+	replicas := canary.Replicas
+	targetCanaryReplicas, targetStableReplicas := canaryStepReplicas(replicas, step.Weight)
+	canaryReplicas, stableReplicas := canaryNextReplicas(replicas,
+		canary.CanaryReplicas, canary.StableReplicas, targetCanaryReplicas, targetStableReplicas)
+
+	if stableReplicas > canary.StableReplicas {
+		trace(log, "scaling up DataPlane live deployment", dataplane, "replicas", stableReplicas)
+		canary.StableReplicas = stableReplicas
+		canary.StepTime = nil
+		setRolloutCondition(dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+			DataPlaneConditionReasonRolloutCanaryProgressing,
+			fmt.Sprintf("scaling live deployment to %d replicas", stableReplicas))
+		return ctrl.Result{}, r.patchRolloutStatus(ctx, log, dataplane)
+	}
+
+	if k8sutils.DeploymentReplicas(liveDeployment) != canary.StableReplicas || !k8sutils.IsDeploymentRolledOut(liveDeployment) {
+		trace(log, "waiting for DataPlane live deployment replicas to be available", dataplane)
+		setRolloutCondition(dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+			DataPlaneConditionReasonRolloutCanaryProgressing,
+			fmt.Sprintf("waiting for live deployment to have %d available replicas", canary.StableReplicas))
+		return ctrl.Result{}, r.patchRolloutStatus(ctx, log, dataplane)
+	}
+
+	trace(log, "ensuring DataPlane canary deployment", dataplane, "replicas", canaryReplicas)
+	canary.CanaryReplicas = canaryReplicas
+	deploymentRes, canaryDeployment, err := r.ensureCanaryDeployment(ctx, dataplane, dataplaneImage, certSecretName, canaryReplicas)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if deploymentRes != Noop || !k8sutils.IsDeploymentRolledOut(canaryDeployment) {
+		debug(log, "DataPlane canary deployment is not ready yet", dataplane, "deployment", canaryDeployment.Name)
+		canary.StepTime = nil
+		setRolloutCondition(dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+			DataPlaneConditionReasonRolloutCanaryProgressing,
+			fmt.Sprintf("waiting for canary deployment to have %d available replicas", canaryReplicas))
+		return ctrl.Result{}, r.patchRolloutStatus(ctx, log, dataplane)
+	}
+
+	if stableReplicas < canary.StableReplicas {
+		trace(log, "scaling down DataPlane live deployment", dataplane, "replicas", stableReplicas)
+		canary.StableReplicas = stableReplicas
+		canary.StepTime = nil
+		setRolloutCondition(dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+			DataPlaneConditionReasonRolloutCanaryProgressing,
+			fmt.Sprintf("scaling live deployment to %d replicas", stableReplicas))
+		return ctrl.Result{}, r.patchRolloutStatus(ctx, log, dataplane)
+	}
+
+	if canaryReplicas != targetCanaryReplicas || stableReplicas != targetStableReplicas {
+		trace(log, "DataPlane canary rollout step has more replicas to move", dataplane, "step", canary.Step)
+		return ctrl.Result{Requeue: true}, r.patchRolloutStatus(ctx, log, dataplane)
+	}
+
+	if canary.StepTime == nil {
+		now := metav1.Now()
+		canary.StepTime = &now
+	}
+	if step.Pause != nil {
+		if remaining := step.Pause.Duration - time.Since(canary.StepTime.Time); remaining > 0 {
+			trace(log, "DataPlane canary rollout step is paused", dataplane, "step", canary.Step, "remaining", remaining)
+			setRolloutCondition(dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+				DataPlaneConditionReasonRolloutCanaryPaused,
+				fmt.Sprintf("step %d with weight %d%% completed, paused for %s", canary.Step, step.Weight, step.Pause.Duration))
+			return ctrl.Result{RequeueAfter: remaining}, r.patchRolloutStatus(ctx, log, dataplane)
+		}
+	}
+
+	if int(canary.Step) < len(steps)-1 {
+		debug(log, "DataPlane canary rollout step completed", dataplane, "step", canary.Step, "weight", step.Weight)
+		canary.Step++
+		canary.StepTime = nil
+		setRolloutCondition(dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+			DataPlaneConditionReasonRolloutCanaryProgressing,
+			fmt.Sprintf("step %d with weight %d%% completed", canary.Step-1, step.Weight))
+		return ctrl.Result{}, r.patchRolloutStatus(ctx, log, dataplane)
+	}
+
+	// All the replicas run the new pod template: the live Deployment, which
+	// has been scaled down to zero replicas, gets it applied by the DataPlane
+	// controller, and the replicas are then moved back to it.
+	debug(log, "promoting DataPlane canary deployment", dataplane, "deployment", canaryDeployment.Name)
+	setRolloutCondition(dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+		DataPlaneConditionReasonRolloutPromotionInProgress, "live deployment is being updated with the canary pod template")
+	return ctrl.Result{}, r.patchRolloutStatus(ctx, log, dataplane)
+}
+
+// reconcileCanaryPromotion moves the replicas of the canary Deployment of the
+// provided DataPlane back to the live one, once the live Deployment runs the
+// promoted pod template. As within the rollout steps, the replicas are moved
+// one at a time, scaling the live Deployment up before scaling the canary one
+// down. It returns true once all the replicas have been moved.
+func (r *DataPlaneBlueGreenReconciler) reconcileCanaryPromotion(
+	ctx context.Context,
+	log logr.Logger,
+	dataplane *operatorv1beta1.DataPlane,
+	liveDeployment *appsv1.Deployment,
+	dataplaneImage string,
+	certSecretName string,
+) (bool, error) {
+	canary := dataplane.Status.RolloutStatus.Canary
+	if canary == nil || canary.CanaryReplicas == 0 {
+		return true, nil
+	}
+
+	replicas := canaryRolloutReplicas(dataplane, canary)
+	canaryReplicas, stableReplicas := canaryNextReplicas(replicas, canary.CanaryReplicas, canary.StableReplicas, 0, replicas)
+
+	if stableReplicas > canary.StableReplicas {
+		trace(log, "scaling up DataPlane live deployment", dataplane, "replicas", stableReplicas)
+		canary.StableReplicas = stableReplicas
+		setRolloutCondition(dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+			DataPlaneConditionReasonRolloutPromotionInProgress,
+			fmt.Sprintf("scaling live deployment to %d replicas", stableReplicas))
+		return false, r.patchRolloutStatus(ctx, log, dataplane)
+	}
+
+	if k8sutils.DeploymentReplicas(liveDeployment) != canary.StableReplicas || !k8sutils.IsDeploymentRolledOut(liveDeployment) {
+		trace(log, "waiting for DataPlane live deployment replicas to be available", dataplane)
+		return false, nil // the live Deployment status update will trigger reconciliation
+	}
+
+	trace(log, "scaling down DataPlane canary deployment", dataplane, "replicas", canaryReplicas)
+	if _, _, err := r.ensureCanaryDeployment(ctx, dataplane, dataplaneImage, certSecretName, canaryReplicas); err != nil {
+		return false, err
+	}
+	canary.CanaryReplicas = canaryReplicas
+	setRolloutCondition(dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+		DataPlaneConditionReasonRolloutPromotionInProgress,
+		fmt.Sprintf("scaling canary deployment to %d replicas", canaryReplicas))
+	return false, r.patchRolloutStatus(ctx, log, dataplane)
+}
+
+// ensureCanaryRolloutAborted scales the live Deployment back to the DataPlane's
+// replicas when a Canary rollout in progress is not needed anymore, so that
+// the canary Deployment can be deleted without reducing the DataPlane's capacity.
+// It returns true when the live Deployment is not ready yet.
+func (r *DataPlaneBlueGreenReconciler) ensureCanaryRolloutAborted(
+	ctx context.Context,
+	log logr.Logger,
+	dataplane *operatorv1beta1.DataPlane,
+	liveDeployment *appsv1.Deployment,
+) (bool, error) {
+	if dataplane.Status.RolloutStatus == nil ||
+		dataplane.Status.RolloutStatus.Canary == nil ||
+		isRolloutPromotionInProgress(dataplane) {
+		return false, nil
+	}
+
+	canary := dataplane.Status.RolloutStatus.Canary
+	replicas := canaryRolloutReplicas(dataplane, canary)
+	if canary.StableReplicas != replicas {
+		debug(log, "DataPlane canary rollout aborted, scaling live deployment back", dataplane, "replicas", replicas)
+		canary.StableReplicas = replicas
+		setRolloutCondition(dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+			DataPlaneConditionReasonRolloutCanaryProgressing,
+			fmt.Sprintf("canary rollout aborted, scaling live deployment to %d replicas", replicas))
+		return true, r.patchRolloutStatus(ctx, log, dataplane)
+	}
+	return k8sutils.DeploymentReplicas(liveDeployment) != replicas || !k8sutils.IsDeploymentRolledOut(liveDeployment), nil
+}
+
+// ensureCanaryResourcesDeleted deletes the canary Deployment of the provided DataPlane.
+func (r *DataPlaneBlueGreenReconciler) ensureCanaryResourcesDeleted(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (bool, error) {
+	deployments, err := k8sutils.ListDeploymentsForOwner(
+		ctx,
+		r.Client,
+		dataplane.Namespace,
+		dataplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+			consts.DataPlaneDeploymentStateLabel:  consts.DataPlaneStateLabelValueCanary,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range deployments {
+		if err := r.Client.Delete(ctx, &deployments[i]); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("failed deleting DataPlane canary Deployment %s: %w", deployments[i].Name, err)
+		}
+	}
+
+	return len(deployments) > 0, nil
+}
+
+// canaryRolloutSteps returns the steps of the provided Canary strategy,
+// including the implied final step.
+func canaryRolloutSteps(strategy *operatorv1beta1.CanaryStrategy) []operatorv1beta1.CanaryStep {
+	steps := strategy.Steps
+	if len(steps) == 0 || steps[len(steps)-1].Weight < 100 {
+		steps = append(slices.Clone(steps), operatorv1beta1.CanaryStep{Weight: 100})
+	}
+	return steps
+}
+
+// canaryStepReplicas splits the provided number of replicas between the canary
+// and the live Deployments according to the provided weight. The number of
+// canary replicas is rounded up, so that every step runs at least one of them.
+func canaryStepReplicas(replicas, weight int32) (canaryReplicas, stableReplicas int32) {
+	canaryReplicas = (replicas*weight + 99) / 100
+	if canaryReplicas < 1 {
+		canaryReplicas = 1
+	}
+	if canaryReplicas > replicas {
+		canaryReplicas = replicas
+	}
+	return canaryReplicas, replicas - canaryReplicas
+}
+
+// canaryNextReplicas returns the replicas of the canary and the live Deployments
+// for the next move towards the provided target replicas. A single replica is
+// moved at a time: the Deployment which has to be scaled up gets one more
+// replica first, and then the other one gets scaled down.
+func canaryNextReplicas(
+	replicas, canaryReplicas, stableReplicas, targetCanaryReplicas, targetStableReplicas int32,
+) (int32, int32) {
+	switch {
+	case canaryReplicas+stableReplicas > replicas:
+		// A move is in progress, scale down the Deployment which has more
+		// replicas than its target.
+		if stableReplicas > targetStableReplicas {
+			stableReplicas = maxReplicas(targetStableReplicas, replicas-canaryReplicas)
+		}
+		if canaryReplicas > targetCanaryReplicas {
+			canaryReplicas = maxReplicas(targetCanaryReplicas, replicas-stableReplicas)
+		}
+	case canaryReplicas < targetCanaryReplicas:
+		canaryReplicas++
+	case stableReplicas < targetStableReplicas:
+		stableReplicas++
+	default:
+		canaryReplicas, stableReplicas = targetCanaryReplicas, targetStableReplicas
+	}
+	return canaryReplicas, stableReplicas
+}
+
+func maxReplicas(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// dataplaneReplicas returns the number of replicas of the provided DataPlane.
+func dataplaneReplicas(dataplane *operatorv1beta1.DataPlane) int32 {
+	if dataplane.Spec.Deployment.Replicas == nil {
+		return 1
+	}
+	return *dataplane.Spec.Deployment.Replicas
+}
+
+// canaryRolloutReplicas returns the number of replicas distributed between the
+// live and the canary Deployments. With horizontal scaling enabled, these are
+// the replicas the live Deployment had when the rollout started, as the
+// autoscaler is suspended until the rollout completes.
+func canaryRolloutReplicas(dataplane *operatorv1beta1.DataPlane, canary *operatorv1beta1.DataPlaneRolloutStatusCanary) int32 {
+	if isHorizontalScalingEnabled(dataplane) && canary.Replicas > 0 {
+		return canary.Replicas
+	}
+	return dataplaneReplicas(dataplane)
+}
@@ -0,0 +1,298 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	dataplaneutils "github.com/kong/gateway-operator/internal/utils/dataplane"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
+)
+
+func TestCanaryStepReplicas(t *testing.T) {
+	testCases := []struct {
+		replicas       int32
+		weight         int32
+		canaryReplicas int32
+		stableReplicas int32
+	}{
+		{replicas: 10, weight: 10, canaryReplicas: 1, stableReplicas: 9},
+		{replicas: 10, weight: 50, canaryReplicas: 5, stableReplicas: 5},
+		{replicas: 10, weight: 100, canaryReplicas: 10, stableReplicas: 0},
+		{replicas: 4, weight: 10, canaryReplicas: 1, stableReplicas: 3},
+		{replicas: 3, weight: 50, canaryReplicas: 2, stableReplicas: 1},
+		{replicas: 1, weight: 10, canaryReplicas: 1, stableReplicas: 0},
+	}
+
+	for _, tc := range testCases {
+		canaryReplicas, stableReplicas := canaryStepReplicas(tc.replicas, tc.weight)
+		assert.Equal(t, tc.canaryReplicas, canaryReplicas, "replicas: %d, weight: %d", tc.replicas, tc.weight)
+		assert.Equal(t, tc.stableReplicas, stableReplicas, "replicas: %d, weight: %d", tc.replicas, tc.weight)
+	}
+}
+
+func TestCanaryNextReplicas(t *testing.T) {
+	type replicas struct{ canary, stable int32 }
+
+	// Moving from 1 canary and 3 live replicas to 4 canary replicas.
+	current := replicas{canary: 1, stable: 3}
+	var moves []replicas
+	for i := 0; i < 10 && current != (replicas{canary: 4, stable: 0}); i++ {
+		canary, stable := canaryNextReplicas(4, current.canary, current.stable, 4, 0)
+		current = replicas{canary: canary, stable: stable}
+		moves = append(moves, current)
+	}
+	assert.Equal(t, []replicas{
+		{canary: 2, stable: 3},
+		{canary: 2, stable: 2},
+		{canary: 3, stable: 2},
+		{canary: 3, stable: 1},
+		{canary: 4, stable: 1},
+		{canary: 4, stable: 0},
+	}, moves)
+
+	// Moving back from 4 canary replicas to 1 canary and 3 live replicas.
+	moves = nil
+	for i := 0; i < 10 && current != (replicas{canary: 1, stable: 3}); i++ {
+		canary, stable := canaryNextReplicas(4, current.canary, current.stable, 1, 3)
+		current = replicas{canary: canary, stable: stable}
+		moves = append(moves, current)
+	}
+	assert.Equal(t, []replicas{
+		{canary: 4, stable: 1},
+		{canary: 3, stable: 1},
+		{canary: 3, stable: 2},
+		{canary: 2, stable: 2},
+		{canary: 2, stable: 3},
+		{canary: 1, stable: 3},
+	}, moves)
+}
+
+func TestCanaryRolloutSteps(t *testing.T) {
+	steps := canaryRolloutSteps(&operatorv1beta1.CanaryStrategy{
+		Steps: []operatorv1beta1.CanaryStep{{Weight: 10}, {Weight: 50}},
+	})
+	assert.Equal(t, []operatorv1beta1.CanaryStep{{Weight: 10}, {Weight: 50}, {Weight: 100}}, steps,
+		"final step with the weight of 100 should be implied")
+
+	steps = canaryRolloutSteps(&operatorv1beta1.CanaryStrategy{
+		Steps: []operatorv1beta1.CanaryStep{{Weight: 10}, {Weight: 100}},
+	})
+	assert.Equal(t, []operatorv1beta1.CanaryStep{{Weight: 10}, {Weight: 100}}, steps)
+}
+
+func TestCanaryRolloutReplicas(t *testing.T) {
+	dataplane := &operatorv1beta1.DataPlane{}
+	dataplane.Spec.Deployment.Replicas = lo.ToPtr(int32(4))
+	canary := &operatorv1beta1.DataPlaneRolloutStatusCanary{Replicas: 7}
+
+	assert.EqualValues(t, 4, canaryRolloutReplicas(dataplane, canary),
+		"without horizontal scaling the DataPlane's replicas should be rolled out")
+
+	dataplane.Spec.Deployment.Scaling = &operatorv1beta1.Scaling{
+		HorizontalScaling: &operatorv1beta1.HorizontalScaling{MaxReplicas: 10},
+	}
+	assert.EqualValues(t, 7, canaryRolloutReplicas(dataplane, canary),
+		"with horizontal scaling the live deployment's replicas at the start of the rollout should be rolled out")
+}
+
+func TestDataPlaneBlueGreenReconciler_CanaryRollout(t *testing.T) {
+	const (
+		liveImage    = "kong:3.2"
+		canaryImage  = "kong:3.3"
+		replicas     = int32(4)
+		maxSurge     = int32(1)
+		certSecret   = "dataplane-tls-secret"
+		reconcileMax = 50
+	)
+
+	dataplane := &operatorv1beta1.DataPlane{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "gateway-operator.konghq.com/v1beta1",
+			Kind:       "DataPlane",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dataplane-canary",
+			Namespace: "default",
+			UID:       types.UID(uuid.NewString()),
+		},
+		Spec: operatorv1beta1.DataPlaneSpec{
+			DataPlaneOptions: operatorv1beta1.DataPlaneOptions{
+				Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+					Rollout: &operatorv1beta1.Rollout{
+						Strategy: operatorv1beta1.RolloutStrategy{
+							Canary: &operatorv1beta1.CanaryStrategy{
+								Steps: []operatorv1beta1.CanaryStep{
+									{Weight: 25},
+									{Weight: 50},
+								},
+							},
+						},
+					},
+					DeploymentOptions: operatorv1beta1.DeploymentOptions{
+						Replicas: lo.ToPtr(replicas),
+						PodTemplateSpec: &corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{
+									{
+										Name:  consts.DataPlaneProxyContainerName,
+										Image: canaryImage,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// The live deployment has been created before the image got changed in the spec.
+	liveDataPlane := dataplane.DeepCopy()
+	liveDataPlane.Spec.Deployment.PodTemplateSpec.Spec.Containers[0].Image = liveImage
+	// The DataPlane controller sets the defaults which are not written back.
+	dataplaneutils.SetDataPlaneDefaults(&liveDataPlane.Spec.DataPlaneOptions)
+	liveDeployment, err := k8sresources.GenerateNewDeploymentForDataPlane(liveDataPlane, liveImage, certSecret)
+	require.NoError(t, err)
+	liveDeployment.Name = "dataplane-canary-live"
+	k8sutils.SetOwnerForObject(liveDeployment, dataplane)
+	addLabelForDataplane(liveDeployment)
+	liveDeployment.Status = appsv1.DeploymentStatus{
+		Replicas:          replicas,
+		UpdatedReplicas:   replicas,
+		AvailableReplicas: replicas,
+		ReadyReplicas:     replicas,
+	}
+
+	fakeClient := fakectrlruntimeclient.
+		NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(dataplane, liveDeployment).
+		WithStatusSubresource(dataplane, &appsv1.Deployment{}).
+		Build()
+
+	ctx := context.Background()
+	nn := types.NamespacedName{Namespace: dataplane.Namespace, Name: dataplane.Name}
+
+	listDeployments := func(t *testing.T) []appsv1.Deployment {
+		var deployments appsv1.DeploymentList
+		require.NoError(t, fakeClient.List(ctx, &deployments))
+		return deployments.Items
+	}
+
+	// simulateCluster mimics the DataPlane controller, which scales the live
+	// Deployment and applies the promoted pod template to it, and the Deployment
+	// controller, which makes the replicas available.
+	simulateCluster := reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		dp := &operatorv1beta1.DataPlane{}
+		require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, dp))
+		for _, deployment := range listDeployments(t) {
+			deployment := deployment
+			if _, ok := deployment.Labels[consts.DataPlaneDeploymentStateLabel]; !ok {
+				desired := dataplaneReplicas(dp)
+				if r, ok := liveDeploymentReplicasForRollout(dp); ok {
+					desired = r
+				}
+				updated := false
+				if k8sutils.DeploymentReplicas(&deployment) != desired {
+					deployment.Spec.Replicas = lo.ToPtr(desired)
+					updated = true
+				}
+				if !isLiveDeploymentHeldByRollout(dp) {
+					dpWithDefaults := dp.DeepCopy()
+					dataplaneutils.SetDataPlaneDefaults(&dpWithDefaults.Spec.DataPlaneOptions)
+					generated, err := k8sresources.GenerateNewDeploymentForDataPlane(dpWithDefaults, canaryImage, certSecret)
+					require.NoError(t, err)
+					if !podTemplateSpecsEqual(deployment.Spec.Template, generated.Spec.Template) {
+						deployment.Spec.Template = generated.Spec.Template
+						updated = true
+					}
+				}
+				if updated {
+					require.NoError(t, fakeClient.Update(ctx, &deployment))
+				}
+			}
+			r := k8sutils.DeploymentReplicas(&deployment)
+			deployment.Status = appsv1.DeploymentStatus{
+				Replicas:          r,
+				UpdatedReplicas:   r,
+				AvailableReplicas: r,
+				ReadyReplicas:     r,
+			}
+			require.NoError(t, fakeClient.Status().Update(ctx, &deployment))
+		}
+		return reconcile.Result{}, nil
+	})
+
+	reconciler := DataPlaneBlueGreenReconciler{
+		Client:              fakeClient,
+		DataPlaneReconciler: simulateCluster,
+		DevelopmentMode:     true,
+	}
+
+	var (
+		done          bool
+		observedSteps []int32
+	)
+	for i := 0; i < reconcileMax && !done; i++ {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+		require.NoError(t, err)
+
+		var available int32
+		for _, deployment := range listDeployments(t) {
+			available += deployment.Status.AvailableReplicas
+		}
+		assert.GreaterOrEqual(t, available, replicas, "the DataPlane's capacity must not be reduced")
+		assert.LessOrEqual(t, available, replicas+maxSurge, "the rollout must not require more than a single additional replica")
+
+		dp := &operatorv1beta1.DataPlane{}
+		require.NoError(t, fakeClient.Get(ctx, nn, dp))
+		if dp.Status.RolloutStatus == nil {
+			continue
+		}
+		if canary := dp.Status.RolloutStatus.Canary; canary != nil && !lo.Contains(observedSteps, canary.Weight) {
+			observedSteps = append(observedSteps, canary.Weight)
+		}
+		condition, ok := k8sutils.GetCondition(consts.DataPlaneConditionTypeRolledOut, dp.Status.RolloutStatus)
+		done = ok && condition.Reason == string(DataPlaneConditionReasonRolloutPromotionDone)
+	}
+	require.True(t, done, "canary rollout should be completed")
+	assert.Equal(t, []int32{25, 50, 100}, observedSteps)
+
+	// The canary Deployment is deleted once the rollout is done.
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+	require.NoError(t, err)
+
+	deployments := listDeployments(t)
+	require.Len(t, deployments, 1, "only the live deployment should be left")
+	promoted := deployments[0]
+	assert.Equal(t, liveDeployment.Name, promoted.Name)
+	assert.Equal(t, liveDeployment.Spec.Selector, promoted.Spec.Selector)
+	assert.NotContains(t, promoted.Spec.Template.Labels, consts.DataPlaneDeploymentStateLabel)
+	assert.Equal(t, replicas, k8sutils.DeploymentReplicas(&promoted))
+	container := k8sutils.GetPodContainerByName(&promoted.Spec.Template.Spec, consts.DataPlaneProxyContainerName)
+	require.NotNil(t, container)
+	assert.Equal(t, canaryImage, container.Image)
+
+	dp := &operatorv1beta1.DataPlane{}
+	require.NoError(t, fakeClient.Get(ctx, nn, dp))
+	assert.Nil(t, dp.Status.RolloutStatus.Canary)
+	require.NotNil(t, dp.Status.RolloutStatus.Promotion)
+	expectedRevision, err := podTemplateSpecHash(promoted.Spec.Template)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRevision, dp.Status.RolloutStatus.Promotion.Revision)
+}
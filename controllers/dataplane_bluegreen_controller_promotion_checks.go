@@ -0,0 +1,361 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
+)
+
+// -----------------------------------------------------------------------------
+// DataPlaneBlueGreenReconciler - Promotion Checks
+// -----------------------------------------------------------------------------
+
+const (
+	// promotionCheckHTTPTimeout is the timeout of the requests sent by the HTTP
+	// promotion checks.
+	promotionCheckHTTPTimeout = 5 * time.Second
+
+	// promotionCheckHTTPDefaultInterval is the minimum duration between two
+	// requests sent by an HTTP promotion check with no interval set.
+	promotionCheckHTTPDefaultInterval = 10 * time.Second
+
+	// promotionCheckHTTPDefaultFailureThreshold is the number of consecutive
+	// failed requests after which an HTTP promotion check with no failure
+	// threshold set fails.
+	promotionCheckHTTPDefaultFailureThreshold = 3
+)
+
+type promotionCheckOutcome byte
+
+const (
+	promotionCheckPending promotionCheckOutcome = iota
+	promotionCheckPassed
+	promotionCheckFailed
+)
+
+// promotionChecksResult aggregates the outcomes of all the promotion checks
+// configured for a DataPlane.
+type promotionChecksResult struct {
+	// failedCheck is the name of the first check which has failed, if any.
+	failedCheck string
+	// pending is true when at least one check has not passed yet.
+	pending bool
+	// requeueAfter is the time after which the pending checks should be re-evaluated.
+	requeueAfter time.Duration
+}
+
+// runPromotionChecks runs the promotion checks configured for the provided
+// DataPlane against its preview resources and reports their outcomes as
+// conditions in the DataPlane's rollout status.
+// Once all the checks have passed together, they are not run anymore, except
+// for the pod restarts checks which keep running until the promotion starts,
+// as the preview pods may restart while the promotion is awaited: the other
+// checks start over only when the preview resources change.
+func (r *DataPlaneBlueGreenReconciler) runPromotionChecks(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (promotionChecksResult, error) {
+	var result promotionChecksResult
+	checks := dataplane.Spec.Deployment.Rollout.Strategy.BlueGreen.PromotionChecks
+	passed := promotionChecksPassed(dataplane, checks)
+
+	now := time.Now()
+	for _, check := range checks {
+		if passed && check.PodRestarts == nil {
+			continue
+		}
+
+		var (
+			outcome      promotionCheckOutcome
+			message      string
+			requeueAfter time.Duration
+			err          error
+		)
+		switch {
+		case check.HTTP != nil:
+			outcome, message, requeueAfter = r.runHTTPPromotionCheck(ctx, dataplane, check.Name, check.HTTP, now)
+		case check.Soak != nil:
+			outcome, message, requeueAfter = runSoakPromotionCheck(dataplane, check.Name, check.Soak, now)
+		case check.PodRestarts != nil:
+			outcome, message, err = r.runPodRestartsPromotionCheck(ctx, dataplane, check.PodRestarts)
+			if err != nil {
+				return result, err
+			}
+		default:
+			return result, fmt.Errorf("promotion check %s has no check kind set", check.Name)
+		}
+
+		switch outcome {
+		case promotionCheckPassed:
+			setRolloutCondition(dataplane, k8sutils.ConditionType(check.Name), metav1.ConditionTrue,
+				DataPlaneConditionReasonPromotionCheckPassed, message)
+		case promotionCheckPending:
+			setRolloutCondition(dataplane, k8sutils.ConditionType(check.Name), metav1.ConditionFalse,
+				DataPlaneConditionReasonPromotionCheckPending, message)
+			result.pending = true
+			if requeueAfter > 0 && (result.requeueAfter == 0 || requeueAfter < result.requeueAfter) {
+				result.requeueAfter = requeueAfter
+			}
+		case promotionCheckFailed:
+			setRolloutCondition(dataplane, k8sutils.ConditionType(check.Name), metav1.ConditionFalse,
+				DataPlaneConditionReasonPromotionCheckFailed, message)
+			if result.failedCheck == "" {
+				result.failedCheck = check.Name
+			}
+		}
+	}
+	return result, nil
+}
+
+// promotionChecksPassed returns true if all the provided promotion checks
+// have passed, according to the rollout status of the provided DataPlane.
+func promotionChecksPassed(dataplane *operatorv1beta1.DataPlane, checks []operatorv1beta1.PromotionCheck) bool {
+	if dataplane.Status.RolloutStatus == nil {
+		return len(checks) == 0
+	}
+	for _, check := range checks {
+		c, ok := k8sutils.GetCondition(k8sutils.ConditionType(check.Name), dataplane.Status.RolloutStatus)
+		if !ok || c.Reason != string(DataPlaneConditionReasonPromotionCheckPassed) {
+			return false
+		}
+	}
+	return true
+}
+
+// resetPromotionChecksConditions removes the promotion checks conditions and
+// evaluation state from the rollout status of the provided DataPlane, so that
+// the checks start over.
+func resetPromotionChecksConditions(dataplane *operatorv1beta1.DataPlane) {
+	if dataplane.Status.RolloutStatus == nil {
+		return
+	}
+	dataplane.Status.RolloutStatus.PromotionChecks = nil
+	conditions := make([]metav1.Condition, 0, 1)
+	for _, c := range dataplane.Status.RolloutStatus.Status {
+		if c.Type == consts.DataPlaneConditionTypeRolledOut {
+			conditions = append(conditions, c)
+		}
+	}
+	dataplane.Status.RolloutStatus.Status = conditions
+}
+
+// runHTTPPromotionCheck sends a request to the preview proxy Service address
+// published in the rollout status of the provided DataPlane, at most once per
+// the check's interval. The check fails once the configured number of
+// consecutive requests have failed.
+func (r *DataPlaneBlueGreenReconciler) runHTTPPromotionCheck(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+	name string,
+	check *operatorv1beta1.HTTPPromotionCheck,
+	now time.Time,
+) (promotionCheckOutcome, string, time.Duration) {
+	if dataplane.Status.RolloutStatus == nil ||
+		dataplane.Status.RolloutStatus.Services == nil ||
+		dataplane.Status.RolloutStatus.Services.Proxy == nil ||
+		len(dataplane.Status.RolloutStatus.Services.Proxy.Addresses) == 0 {
+		return promotionCheckPending, "preview proxy service has no addresses yet", 0
+	}
+	address := dataplane.Status.RolloutStatus.Services.Proxy.Addresses[0].Value
+
+	interval := check.Interval.Duration
+	if interval == 0 {
+		interval = promotionCheckHTTPDefaultInterval
+	}
+	failureThreshold := check.FailureThreshold
+	if failureThreshold == 0 {
+		failureThreshold = promotionCheckHTTPDefaultFailureThreshold
+	}
+
+	status := promotionCheckStatus(dataplane, name)
+	if !status.LastProbeTime.IsZero() {
+		// The outcome of the last request holds until the next one is due.
+		if remaining := interval - now.Sub(status.LastProbeTime.Time); remaining > 0 {
+			c, _ := k8sutils.GetCondition(k8sutils.ConditionType(name), dataplane.Status.RolloutStatus)
+			if c.Reason == string(DataPlaneConditionReasonPromotionCheckPassed) {
+				return promotionCheckPassed, c.Message, 0
+			}
+			return promotionCheckPending, c.Message, remaining
+		}
+	}
+
+	passed, message := r.sendHTTPPromotionCheckRequest(ctx, address, check)
+	status.LastProbeTime = metav1.NewTime(now)
+	if passed {
+		status.ConsecutiveFailures = 0
+		return promotionCheckPassed, message, 0
+	}
+
+	status.ConsecutiveFailures++
+	message = fmt.Sprintf("%s (%d/%d consecutive failures)", message, status.ConsecutiveFailures, failureThreshold)
+	if status.ConsecutiveFailures >= failureThreshold {
+		return promotionCheckFailed, message, 0
+	}
+	return promotionCheckPending, message, interval
+}
+
+// sendHTTPPromotionCheckRequest sends the request of the provided HTTP promotion
+// check to the provided address. It returns true if the response status code
+// is expected, along with a message describing the outcome.
+func (r *DataPlaneBlueGreenReconciler) sendHTTPPromotionCheckRequest(
+	ctx context.Context,
+	address string,
+	check *operatorv1beta1.HTTPPromotionCheck,
+) (bool, string) {
+	port := check.Port
+	if port == 0 {
+		port = consts.DefaultHTTPPort
+	}
+	path := check.Path
+	if path == "" {
+		path = "/"
+	}
+	url := fmt.Sprintf("http://%s%s", net.JoinHostPort(address, strconv.Itoa(int(port))), path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Sprintf("failed creating request to %s: %v", url, err)
+	}
+	if check.Host != "" {
+		req.Host = check.Host
+	}
+
+	resp, err := r.getPromotionCheckHTTPClient().Do(req)
+	if err != nil {
+		return false, fmt.Sprintf("request to %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if !slices.Contains(check.ExpectedStatusCodes, resp.StatusCode) {
+		return false, fmt.Sprintf("request to %s returned unexpected status code %d", url, resp.StatusCode)
+	}
+	return true, fmt.Sprintf("request to %s returned status code %d", url, resp.StatusCode)
+}
+
+// promotionCheckStatus returns the evaluation state of the promotion check
+// with the provided name in the rollout status of the provided DataPlane,
+// adding it when missing.
+func promotionCheckStatus(dataplane *operatorv1beta1.DataPlane, name string) *operatorv1beta1.DataPlaneRolloutStatusPromotionCheck {
+	for i := range dataplane.Status.RolloutStatus.PromotionChecks {
+		if dataplane.Status.RolloutStatus.PromotionChecks[i].Name == name {
+			return &dataplane.Status.RolloutStatus.PromotionChecks[i]
+		}
+	}
+	dataplane.Status.RolloutStatus.PromotionChecks = append(dataplane.Status.RolloutStatus.PromotionChecks,
+		operatorv1beta1.DataPlaneRolloutStatusPromotionCheck{Name: name})
+	return &dataplane.Status.RolloutStatus.PromotionChecks[len(dataplane.Status.RolloutStatus.PromotionChecks)-1]
+}
+
+func (r *DataPlaneBlueGreenReconciler) getPromotionCheckHTTPClient() *http.Client {
+	if r.promotionCheckHTTPClient != nil {
+		return r.promotionCheckHTTPClient
+	}
+	return &http.Client{Timeout: promotionCheckHTTPTimeout}
+}
+
+// runSoakPromotionCheck checks for how long the preview Deployment has been ready.
+// The soak starts when the check is evaluated for the first time, which is
+// recorded as the last transition time of the check's pending condition.
+func runSoakPromotionCheck(
+	dataplane *operatorv1beta1.DataPlane,
+	name string,
+	check *operatorv1beta1.SoakPromotionCheck,
+	now time.Time,
+) (promotionCheckOutcome, string, time.Duration) {
+	since := now
+	if dataplane.Status.RolloutStatus != nil {
+		if c, ok := k8sutils.GetCondition(k8sutils.ConditionType(name), dataplane.Status.RolloutStatus); ok {
+			switch c.Reason {
+			case string(DataPlaneConditionReasonPromotionCheckPassed):
+				return promotionCheckPassed, c.Message, 0
+			case string(DataPlaneConditionReasonPromotionCheckPending):
+				since = c.LastTransitionTime.Time
+			}
+		}
+	}
+
+	remaining := check.Duration.Duration - now.Sub(since)
+	if remaining > 0 {
+		return promotionCheckPending,
+			fmt.Sprintf("preview deployment has to be ready for %s", check.Duration.Duration),
+			remaining
+	}
+	return promotionCheckPassed,
+		fmt.Sprintf("preview deployment has been ready for %s", check.Duration.Duration),
+		0
+}
+
+func (r *DataPlaneBlueGreenReconciler) runPodRestartsPromotionCheck(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+	check *operatorv1beta1.PodRestartsPromotionCheck,
+) (promotionCheckOutcome, string, error) {
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods,
+		client.InNamespace(dataplane.Namespace),
+		client.MatchingLabels(k8sresources.PreviewSelectorForDataPlane(dataplane)),
+	); err != nil {
+		return promotionCheckPending, "", fmt.Errorf("failed listing preview pods: %w", err)
+	}
+
+	var restarts int32
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			restarts += status.RestartCount
+		}
+	}
+
+	if restarts > check.MaxRestarts {
+		return promotionCheckFailed,
+			fmt.Sprintf("preview pods containers restarted %d times, at most %d allowed", restarts, check.MaxRestarts),
+			nil
+	}
+	return promotionCheckPassed,
+		fmt.Sprintf("preview pods containers restarted %d times", restarts),
+		nil
+}
+
+// isRevisionRolledBack returns true if the provided pod template revision has
+// already been rolled back.
+func isRevisionRolledBack(dataplane *operatorv1beta1.DataPlane, revision string) bool {
+	return dataplane.Status.RolloutStatus != nil &&
+		dataplane.Status.RolloutStatus.Rollback != nil &&
+		dataplane.Status.RolloutStatus.Rollback.Revision == revision
+}
+
+// rollbackPreview deletes the preview resources of the provided DataPlane and
+// records the rollback of the provided revision in its rollout status.
+func (r *DataPlaneBlueGreenReconciler) rollbackPreview(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+	revision string,
+	failedCheck string,
+) error {
+	if _, err := r.ensurePreviewResourcesDeleted(ctx, dataplane); err != nil {
+		return err
+	}
+	dataplane.Status.RolloutStatus.Services = nil
+	dataplane.Status.RolloutStatus.PromotionChecks = nil
+	dataplane.Status.RolloutStatus.Rollback = &operatorv1beta1.DataPlaneRolloutStatusRollback{
+		Time:     metav1.Now(),
+		Revision: revision,
+		Check:    failedCheck,
+	}
+	setRolloutCondition(dataplane, consts.DataPlaneConditionTypeRolledOut, metav1.ConditionFalse,
+		DataPlaneConditionReasonRolloutRolledBack,
+		fmt.Sprintf("promotion check %s failed, preview resources rolled back", failedCheck))
+	return nil
+}
@@ -0,0 +1,476 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sreduce "github.com/kong/gateway-operator/internal/utils/kubernetes/reduce"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
+)
+
+// -----------------------------------------------------------------------------
+// DataPlaneBlueGreenReconciler - Status Management
+// -----------------------------------------------------------------------------
+
+// setRolloutCondition sets the condition of the provided type in the rollout
+// status of the provided DataPlane, preserving the last transition time when
+// the condition status does not change.
+func setRolloutCondition(
+	dataplane *operatorv1beta1.DataPlane,
+	conditionType k8sutils.ConditionType,
+	status metav1.ConditionStatus,
+	reason k8sutils.ConditionReason,
+	message string,
+) {
+	if dataplane.Status.RolloutStatus == nil {
+		dataplane.Status.RolloutStatus = &operatorv1beta1.DataPlaneRolloutStatus{}
+	}
+	condition := k8sutils.NewConditionWithGeneration(conditionType, status, reason, message, dataplane.Generation)
+	if current, ok := k8sutils.GetCondition(conditionType, dataplane.Status.RolloutStatus); ok && current.Status == status {
+		condition.LastTransitionTime = current.LastTransitionTime
+	}
+	k8sutils.SetCondition(condition, dataplane.Status.RolloutStatus)
+}
+
+// isRolloutPromotionInProgress returns true if the preview resources of the
+// provided DataPlane are being promoted.
+func isRolloutPromotionInProgress(dataplane *operatorv1beta1.DataPlane) bool {
+	if dataplane.Status.RolloutStatus == nil {
+		return false
+	}
+	c, ok := k8sutils.GetCondition(consts.DataPlaneConditionTypeRolledOut, dataplane.Status.RolloutStatus)
+	return ok && c.Reason == string(DataPlaneConditionReasonRolloutPromotionInProgress)
+}
+
+// isRolloutAwaitingPromotion returns true if the preview resources of the
+// provided DataPlane are waiting for the promotion to be approved.
+func isRolloutAwaitingPromotion(dataplane *operatorv1beta1.DataPlane) bool {
+	if dataplane.Status.RolloutStatus == nil {
+		return false
+	}
+	c, ok := k8sutils.GetCondition(consts.DataPlaneConditionTypeRolledOut, dataplane.Status.RolloutStatus)
+	return ok && c.Reason == string(DataPlaneConditionReasonRolloutAwaitingPromotion)
+}
+
+// isLiveDeploymentHeldByRollout returns true if the changes to the live
+// Deployment's pod template should not be applied, because a rollout is
+// configured and the new pod template has not been promoted yet.
+// Blue Green promotions apply the changes once the live Services select the
+// preview pods, see liveServicesSelectorForRollout.
+// Canary promotions apply the changes once the live Deployment has been scaled
+// down to zero replicas, before the replicas are moved back to it.
+func isLiveDeploymentHeldByRollout(dataplane *operatorv1beta1.DataPlane) bool {
+	if dataplane.Spec.Deployment.Rollout == nil ||
+		(dataplane.Spec.Deployment.Rollout.Strategy.BlueGreen == nil && dataplane.Spec.Deployment.Rollout.Strategy.Canary == nil) {
+		return false
+	}
+	return !isRolloutPromotionInProgress(dataplane)
+}
+
+// liveServicesSelectorForRollout returns the selector of the live Services set
+// by the Blue Green rollout in progress, if any. While the preview resources
+// are being promoted, the live Services select the preview pods: the traffic
+// is switched to them, and the live Deployment gets the promoted pod template
+// applied without receiving any. The live Services select the live pods again
+// once the promotion is done.
+func liveServicesSelectorForRollout(dataplane *operatorv1beta1.DataPlane) (map[string]string, bool) {
+	if dataplane.Spec.Deployment.Rollout == nil ||
+		dataplane.Spec.Deployment.Rollout.Strategy.BlueGreen == nil ||
+		!isRolloutPromotionInProgress(dataplane) {
+		return nil, false
+	}
+	return k8sresources.PreviewSelectorForDataPlane(dataplane), true
+}
+
+// liveDeploymentReplicasForRollout returns the number of replicas of the live
+// Deployment set by the Canary rollout in progress, if any, including while it
+// is being promoted.
+func liveDeploymentReplicasForRollout(dataplane *operatorv1beta1.DataPlane) (int32, bool) {
+	if dataplane.Spec.Deployment.Rollout == nil ||
+		dataplane.Spec.Deployment.Rollout.Strategy.Canary == nil ||
+		dataplane.Status.RolloutStatus == nil ||
+		dataplane.Status.RolloutStatus.Canary == nil {
+		return 0, false
+	}
+	return dataplane.Status.RolloutStatus.Canary.StableReplicas, true
+}
+
+// isPromotionApproved returns true if the user approved the promotion of the
+// preview resources of the provided DataPlane.
+func isPromotionApproved(dataplane *operatorv1beta1.DataPlane) bool {
+	return dataplane.Annotations[consts.DataPlanePromoteWhenReadyAnnotationKey] == consts.DataPlanePromoteWhenReadyAnnotationTrue
+}
+
+// ensurePromotionApprovalRemoved removes the promotion approval annotation
+// from the provided DataPlane.
+func (r *DataPlaneBlueGreenReconciler) ensurePromotionApprovalRemoved(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) error {
+	if _, ok := dataplane.Annotations[consts.DataPlanePromoteWhenReadyAnnotationKey]; !ok {
+		return nil
+	}
+	old := dataplane.DeepCopy()
+	delete(dataplane.Annotations, consts.DataPlanePromoteWhenReadyAnnotationKey)
+	if err := r.Client.Patch(ctx, dataplane, client.MergeFrom(old)); err != nil {
+		return fmt.Errorf("failed removing promotion approval from DataPlane %s: %w", dataplane.Name, err)
+	}
+	return nil
+}
+
+// ensureRolloutStatusServices sets the names and addresses of the preview
+// Services in the rollout status of the provided DataPlane.
+func ensureRolloutStatusServices(
+	dataplane *operatorv1beta1.DataPlane,
+	previewProxyService *corev1.Service,
+	previewAdminService *corev1.Service,
+) error {
+	proxyAddresses, err := addressesFromService(previewProxyService)
+	if err != nil {
+		return fmt.Errorf("failed getting addresses for service %s: %w", previewProxyService.Name, err)
+	}
+	adminAddresses, err := addressesFromService(previewAdminService)
+	if err != nil {
+		return fmt.Errorf("failed getting addresses for service %s: %w", previewAdminService.Name, err)
+	}
+
+	if dataplane.Status.RolloutStatus == nil {
+		dataplane.Status.RolloutStatus = &operatorv1beta1.DataPlaneRolloutStatus{}
+	}
+	dataplane.Status.RolloutStatus.Services = &operatorv1beta1.DataPlaneRolloutStatusServices{
+		Proxy: &operatorv1beta1.RolloutStatusService{
+			Name:      previewProxyService.Name,
+			Addresses: proxyAddresses,
+		},
+		AdminAPI: &operatorv1beta1.RolloutStatusService{
+			Name:      previewAdminService.Name,
+			Addresses: adminAddresses,
+		},
+	}
+	return nil
+}
+
+// patchRolloutStatus patches the rollout status of the DataPlane only when it
+// differs from the persisted one.
+func (r *DataPlaneBlueGreenReconciler) patchRolloutStatus(ctx context.Context, log logr.Logger, updated *operatorv1beta1.DataPlane) error {
+	current := &operatorv1beta1.DataPlane{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(updated), current); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !rolloutStatusChanged(current.Status.RolloutStatus, updated.Status.RolloutStatus) {
+		return nil
+	}
+
+	// Only the rollout status is owned by this reconciler, leave the rest
+	// of the status as persisted.
+	patched := current.DeepCopy()
+	patched.Status.RolloutStatus = updated.Status.RolloutStatus
+	debug(log, "patching DataPlane rollout status", updated, "status", updated.Status.RolloutStatus)
+	return r.Client.Status().Patch(ctx, patched, client.MergeFrom(current))
+}
+
+func rolloutStatusChanged(current, updated *operatorv1beta1.DataPlaneRolloutStatus) bool {
+	if current == nil || updated == nil {
+		return current != updated
+	}
+	return k8sutils.NeedsUpdate(current, updated) ||
+		!cmp.Equal(current.Services, updated.Services) ||
+		rolloutPromotionChanged(current.Promotion, updated.Promotion) ||
+		rolloutRollbackChanged(current.Rollback, updated.Rollback) ||
+		!cmp.Equal(current.Canary, updated.Canary) ||
+		!cmp.Equal(current.PromotionChecks, updated.PromotionChecks)
+}
+
+func rolloutPromotionChanged(current, updated *operatorv1beta1.DataPlaneRolloutStatusPromotion) bool {
+	if current == nil || updated == nil {
+		return current != updated
+	}
+	return current.Revision != updated.Revision || !current.Time.Equal(&updated.Time)
+}
+
+func rolloutRollbackChanged(current, updated *operatorv1beta1.DataPlaneRolloutStatusRollback) bool {
+	if current == nil || updated == nil {
+		return current != updated
+	}
+	return current.Revision != updated.Revision ||
+		current.Check != updated.Check ||
+		!current.Time.Equal(&updated.Time)
+}
+
+// -----------------------------------------------------------------------------
+// DataPlaneBlueGreenReconciler - Owned Resource Management
+// -----------------------------------------------------------------------------
+
+// getLiveDeployment returns the live Deployment of the provided DataPlane
+// or nil if there's no single live Deployment.
+func (r *DataPlaneBlueGreenReconciler) getLiveDeployment(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (*appsv1.Deployment, error) {
+	selector, err := dataplaneLiveResourcesSelector(consts.DataPlaneDeploymentStateLabel, map[string]string{
+		consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := k8sutils.ListDeploymentsForOwner(ctx, r.Client, dataplane.Namespace, dataplane.UID, selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(deployments) != 1 {
+		return nil, nil
+	}
+	return &deployments[0], nil
+}
+
+// clusterCertificateSecretName returns the name of the Secret mounted as
+// the cluster certificate in the provided Deployment's pods.
+func clusterCertificateSecretName(deployment *appsv1.Deployment) string {
+	for _, v := range deployment.Spec.Template.Spec.Volumes {
+		if v.Name == k8sresources.ClusterCertificateVolumeName && v.Secret != nil {
+			return v.Secret.SecretName
+		}
+	}
+	return ""
+}
+
+func (r *DataPlaneBlueGreenReconciler) ensurePreviewDeployment(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+	dataplaneImage string,
+	certSecretName string,
+) (CreatedUpdatedOrNoop, *appsv1.Deployment, error) {
+	return r.ensureRolloutDeployment(ctx, dataplane, consts.DataPlaneStateLabelValuePreview, func() (*appsv1.Deployment, error) {
+		return k8sresources.GenerateNewPreviewDeploymentForDataPlane(dataplane, dataplaneImage, certSecretName)
+	})
+}
+
+func (r *DataPlaneBlueGreenReconciler) ensureCanaryDeployment(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+	dataplaneImage string,
+	certSecretName string,
+	replicas int32,
+) (CreatedUpdatedOrNoop, *appsv1.Deployment, error) {
+	return r.ensureRolloutDeployment(ctx, dataplane, consts.DataPlaneStateLabelValueCanary, func() (*appsv1.Deployment, error) {
+		return k8sresources.GenerateNewCanaryDeploymentForDataPlane(dataplane, dataplaneImage, certSecretName, replicas)
+	})
+}
+
+// ensureRolloutDeployment ensures that the Deployment with the provided rollout
+// state, created during DataPlane rollouts next to the live one, is up to date.
+func (r *DataPlaneBlueGreenReconciler) ensureRolloutDeployment(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+	state string,
+	generate func() (*appsv1.Deployment, error),
+) (CreatedUpdatedOrNoop, *appsv1.Deployment, error) {
+	deployments, err := k8sutils.ListDeploymentsForOwner(
+		ctx,
+		r.Client,
+		dataplane.Namespace,
+		dataplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+			consts.DataPlaneDeploymentStateLabel:  state,
+		},
+	)
+	if err != nil {
+		return Noop, nil, err
+	}
+
+	count := len(deployments)
+	if count > 1 {
+		if err := k8sreduce.ReduceDeployments(ctx, r.Client, deployments); err != nil {
+			return Noop, nil, err
+		}
+		return Updated, nil, fmt.Errorf("number of %s deployments reduced", state)
+	}
+
+	generatedDeployment, err := generate()
+	if err != nil {
+		return Noop, nil, err
+	}
+	k8sutils.SetOwnerForObject(generatedDeployment, dataplane)
+	addLabelForDataplane(generatedDeployment)
+	checksum, err := certificatesChecksum(ctx, r.Client, dataplane)
+	if err != nil {
+		return Noop, nil, err
+	}
+	setCertificatesChecksum(generatedDeployment, checksum)
+
+	if count == 1 {
+		var updated bool
+		existingDeployment := &deployments[0]
+		updated, existingDeployment.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingDeployment.ObjectMeta, generatedDeployment.ObjectMeta)
+
+		if !podTemplateSpecsEqual(existingDeployment.Spec.Template, generatedDeployment.Spec.Template) {
+			existingDeployment.Spec.Template = generatedDeployment.Spec.Template
+			updated = true
+		}
+		if !cmp.Equal(existingDeployment.Spec.Strategy, generatedDeployment.Spec.Strategy) {
+			existingDeployment.Spec.Strategy = generatedDeployment.Spec.Strategy
+			updated = true
+		}
+		if !cmp.Equal(existingDeployment.Spec.Replicas, generatedDeployment.Spec.Replicas) {
+			existingDeployment.Spec.Replicas = generatedDeployment.Spec.Replicas
+			updated = true
+		}
+
+		if updated {
+			if err := r.Client.Update(ctx, existingDeployment); err != nil {
+				return Noop, existingDeployment, fmt.Errorf("failed updating DataPlane %s Deployment %s: %w", state, existingDeployment.Name, err)
+			}
+			return Updated, existingDeployment, nil
+		}
+		return Noop, existingDeployment, nil
+	}
+
+	return Created, generatedDeployment, r.Client.Create(ctx, generatedDeployment)
+}
+
+func (r *DataPlaneBlueGreenReconciler) ensurePreviewProxyService(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (createdOrUpdated bool, svc *corev1.Service, err error) {
+	return r.ensurePreviewService(ctx, dataplane, consts.DataPlaneProxyServiceLabelValue,
+		k8sresources.GenerateNewPreviewProxyServiceForDataPlane)
+}
+
+func (r *DataPlaneBlueGreenReconciler) ensurePreviewAdminService(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (createdOrUpdated bool, svc *corev1.Service, err error) {
+	return r.ensurePreviewService(ctx, dataplane, consts.DataPlaneAdminServiceLabelValue,
+		k8sresources.GenerateNewPreviewAdminServiceForDataPlane)
+}
+
+func (r *DataPlaneBlueGreenReconciler) ensurePreviewService(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+	serviceType consts.ServiceType,
+	generate func(*operatorv1beta1.DataPlane) (*corev1.Service, error),
+) (createdOrUpdated bool, svc *corev1.Service, err error) {
+	services, err := k8sutils.ListServicesForOwner(
+		ctx,
+		r.Client,
+		dataplane.Namespace,
+		dataplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+			consts.DataPlaneServiceTypeLabel:      string(serviceType),
+			consts.DataPlaneServiceStateLabel:     consts.DataPlaneStateLabelValuePreview,
+		},
+	)
+	if err != nil {
+		return false, nil, err
+	}
+
+	count := len(services)
+	if count > 1 {
+		if err := k8sreduce.ReduceServices(ctx, r.Client, services); err != nil {
+			return false, nil, err
+		}
+		return false, nil, fmt.Errorf("number of dataplane preview %s services reduced", serviceType)
+	}
+
+	generatedService, err := generate(dataplane)
+	if err != nil {
+		return false, nil, err
+	}
+	addLabelForDataplane(generatedService)
+	k8sutils.SetOwnerForObject(generatedService, dataplane)
+
+	if count == 1 {
+		var updated bool
+		existingService := &services[0]
+		updated, existingService.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingService.ObjectMeta, generatedService.ObjectMeta)
+
+		if existingService.Spec.Type != generatedService.Spec.Type {
+			existingService.Spec.Type = generatedService.Spec.Type
+			updated = true
+		}
+		if !cmp.Equal(existingService.Spec.Selector, generatedService.Spec.Selector) {
+			existingService.Spec.Selector = generatedService.Spec.Selector
+			updated = true
+		}
+		if ensureServicePortsAreUpdated(existingService, generatedService) {
+			updated = true
+		}
+
+		if updated {
+			if err := r.Client.Update(ctx, existingService); err != nil {
+				return false, existingService, fmt.Errorf("failed updating DataPlane preview Service %s: %w", existingService.Name, err)
+			}
+			return true, existingService, nil
+		}
+		return false, existingService, nil
+	}
+
+	return true, generatedService, r.Client.Create(ctx, generatedService)
+}
+
+// ensurePreviewResourcesDeleted deletes the preview Deployment and Services
+// of the provided DataPlane. It returns true if any object has been deleted.
+func (r *DataPlaneBlueGreenReconciler) ensurePreviewResourcesDeleted(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (bool, error) {
+	deployments, err := k8sutils.ListDeploymentsForOwner(
+		ctx,
+		r.Client,
+		dataplane.Namespace,
+		dataplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+			consts.DataPlaneDeploymentStateLabel:  consts.DataPlaneStateLabelValuePreview,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	services, err := k8sutils.ListServicesForOwner(
+		ctx,
+		r.Client,
+		dataplane.Namespace,
+		dataplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+			consts.DataPlaneServiceStateLabel:     consts.DataPlaneStateLabelValuePreview,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	objects := make([]client.Object, 0, len(deployments)+len(services))
+	for i := range deployments {
+		objects = append(objects, &deployments[i])
+	}
+	for i := range services {
+		objects = append(objects, &services[i])
+	}
+
+	for _, obj := range objects {
+		if err := r.Client.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("failed deleting DataPlane preview resource %s: %w", obj.GetName(), err)
+		}
+	}
+
+	return len(objects) > 0, nil
+}
@@ -0,0 +1,649 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	controllerruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	dataplaneutils "github.com/kong/gateway-operator/internal/utils/dataplane"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
+)
+
+func TestDataPlaneBlueGreenReconciler_Reconcile(t *testing.T) {
+	const (
+		liveImage    = "kong:3.2"
+		previewImage = "kong:3.3"
+	)
+
+	// The HTTP promotion checks are sent to this server through the preview proxy service.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	serverPort, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+
+	newDataPlane := func(
+		promotionStrategy operatorv1beta1.PromotionStrategy,
+		promotionChecks []operatorv1beta1.PromotionCheck,
+	) *operatorv1beta1.DataPlane {
+		return &operatorv1beta1.DataPlane{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "gateway-operator.konghq.com/v1beta1",
+				Kind:       "DataPlane",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dataplane-bluegreen",
+				Namespace: "default",
+				UID:       types.UID(uuid.NewString()),
+			},
+			Spec: operatorv1beta1.DataPlaneSpec{
+				DataPlaneOptions: operatorv1beta1.DataPlaneOptions{
+					Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+						Rollout: &operatorv1beta1.Rollout{
+							Strategy: operatorv1beta1.RolloutStrategy{
+								BlueGreen: &operatorv1beta1.BlueGreenStrategy{
+									Promotion: operatorv1beta1.Promotion{
+										Strategy: promotionStrategy,
+									},
+									PromotionChecks: promotionChecks,
+								},
+							},
+						},
+						DeploymentOptions: operatorv1beta1.DeploymentOptions{
+							PodTemplateSpec: &corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name:  consts.DataPlaneProxyContainerName,
+											Image: previewImage,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	newLiveDeployment := func(t *testing.T, dataplane *operatorv1beta1.DataPlane) *appsv1.Deployment {
+		// The live deployment has been created before the image got changed in the spec.
+		liveDataPlane := dataplane.DeepCopy()
+		liveDataPlane.Spec.Deployment.PodTemplateSpec.Spec.Containers[0].Image = liveImage
+		// The DataPlane controller sets the defaults which are not written back.
+		dataplaneutils.SetDataPlaneDefaults(&liveDataPlane.Spec.DataPlaneOptions)
+		deployment, err := k8sresources.GenerateNewDeploymentForDataPlane(liveDataPlane, liveImage, "dataplane-tls-secret")
+		require.NoError(t, err)
+		deployment.Name = "dataplane-bluegreen-live"
+		deployment.Status = appsv1.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			AvailableReplicas: 1,
+			ReadyReplicas:     1,
+		}
+		return deployment
+	}
+
+	getRolledOutCondition := func(t *testing.T, c controllerruntimeclient.Client, nn types.NamespacedName) metav1.Condition {
+		dataplane := &operatorv1beta1.DataPlane{}
+		require.NoError(t, c.Get(context.Background(), nn, dataplane))
+		require.NotNil(t, dataplane.Status.RolloutStatus)
+		condition, ok := k8sutils.GetCondition(consts.DataPlaneConditionTypeRolledOut, dataplane.Status.RolloutStatus)
+		require.True(t, ok)
+		return condition
+	}
+
+	listPreviewDeployments := func(t *testing.T, c controllerruntimeclient.Client) []appsv1.Deployment {
+		var deployments appsv1.DeploymentList
+		require.NoError(t, c.List(context.Background(), &deployments, controllerruntimeclient.MatchingLabels{
+			consts.DataPlaneDeploymentStateLabel: consts.DataPlaneStateLabelValuePreview,
+		}))
+		return deployments.Items
+	}
+
+	markDeploymentRolledOut := func(t *testing.T, c controllerruntimeclient.Client, deployment *appsv1.Deployment) {
+		deployment.Status = appsv1.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			AvailableReplicas: 1,
+			ReadyReplicas:     1,
+		}
+		require.NoError(t, c.Status().Update(context.Background(), deployment))
+	}
+
+	// setPreviewProxyServiceClusterIP makes the preview proxy service point
+	// to the test server, as there's no cluster IP allocation with the fake client.
+	setPreviewProxyServiceClusterIP := func(t *testing.T, c controllerruntimeclient.Client) {
+		var services corev1.ServiceList
+		require.NoError(t, c.List(context.Background(), &services, controllerruntimeclient.MatchingLabels{
+			consts.DataPlaneServiceStateLabel: consts.DataPlaneStateLabelValuePreview,
+			consts.DataPlaneServiceTypeLabel:  string(consts.DataPlaneProxyServiceLabelValue),
+		}))
+		require.Len(t, services.Items, 1)
+		service := services.Items[0]
+		service.Spec.ClusterIP = serverURL.Hostname()
+		service.Spec.ClusterIPs = []string{serverURL.Hostname()}
+		require.NoError(t, c.Update(context.Background(), &service))
+	}
+
+	getDataPlane := func(t *testing.T, c controllerruntimeclient.Client, nn types.NamespacedName) *operatorv1beta1.DataPlane {
+		dataplane := &operatorv1beta1.DataPlane{}
+		require.NoError(t, c.Get(context.Background(), nn, dataplane))
+		require.NotNil(t, dataplane.Status.RolloutStatus)
+		return dataplane
+	}
+
+	testCases := []struct {
+		name              string
+		promotionStrategy operatorv1beta1.PromotionStrategy
+		promotionChecks   []operatorv1beta1.PromotionCheck
+		testBody          func(t *testing.T, reconciler DataPlaneBlueGreenReconciler, dataplane *operatorv1beta1.DataPlane, liveDeployment *appsv1.Deployment)
+	}{
+		{
+			name:              "preview resources are created and wait for promotion with BreakBeforePromotion",
+			promotionStrategy: operatorv1beta1.BreakBeforePromotion,
+			testBody: func(t *testing.T, reconciler DataPlaneBlueGreenReconciler, dataplane *operatorv1beta1.DataPlane, liveDeployment *appsv1.Deployment) {
+				ctx := context.Background()
+				nn := types.NamespacedName{Namespace: dataplane.Namespace, Name: dataplane.Name}
+				req := reconcile.Request{NamespacedName: nn}
+
+				// preview admin service, preview proxy service and preview deployment.
+				for i := 0; i < 3; i++ {
+					_, err := reconciler.Reconcile(ctx, req)
+					require.NoError(t, err)
+				}
+
+				condition := getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutProgressing), condition.Reason)
+				assert.Equal(t, metav1.ConditionFalse, condition.Status)
+
+				dp := &operatorv1beta1.DataPlane{}
+				require.NoError(t, reconciler.Client.Get(ctx, nn, dp))
+				require.NotNil(t, dp.Status.RolloutStatus.Services)
+				require.NotNil(t, dp.Status.RolloutStatus.Services.Proxy)
+				require.NotNil(t, dp.Status.RolloutStatus.Services.AdminAPI)
+
+				previewDeployments := listPreviewDeployments(t, reconciler.Client)
+				require.Len(t, previewDeployments, 1)
+				previewDeployment := previewDeployments[0]
+				container := k8sutils.GetPodContainerByName(&previewDeployment.Spec.Template.Spec, consts.DataPlaneProxyContainerName)
+				require.NotNil(t, container)
+				assert.Equal(t, previewImage, container.Image)
+				assert.NotContains(t, previewDeployment.Spec.Template.Labels, "app",
+					"preview pods must not be selected by the live services")
+
+				markDeploymentRolledOut(t, reconciler.Client, &previewDeployment)
+				_, err := reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+
+				condition = getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutAwaitingPromotion), condition.Reason)
+
+				live := &appsv1.Deployment{}
+				require.NoError(t, reconciler.Client.Get(ctx, controllerruntimeclient.ObjectKeyFromObject(liveDeployment), live))
+				container = k8sutils.GetPodContainerByName(&live.Spec.Template.Spec, consts.DataPlaneProxyContainerName)
+				require.NotNil(t, container)
+				assert.Equal(t, liveImage, container.Image, "live deployment must not be changed before promotion")
+			},
+		},
+		{
+			name:              "preview resources are promoted and deleted with AutomaticPromotion",
+			promotionStrategy: operatorv1beta1.AutomaticPromotion,
+			testBody: func(t *testing.T, reconciler DataPlaneBlueGreenReconciler, dataplane *operatorv1beta1.DataPlane, liveDeployment *appsv1.Deployment) {
+				ctx := context.Background()
+				nn := types.NamespacedName{Namespace: dataplane.Namespace, Name: dataplane.Name}
+				req := reconcile.Request{NamespacedName: nn}
+
+				for i := 0; i < 3; i++ {
+					_, err := reconciler.Reconcile(ctx, req)
+					require.NoError(t, err)
+				}
+				previewDeployments := listPreviewDeployments(t, reconciler.Client)
+				require.Len(t, previewDeployments, 1)
+				markDeploymentRolledOut(t, reconciler.Client, &previewDeployments[0])
+
+				_, err := reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+				condition := getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutPromotionInProgress), condition.Reason)
+
+				// The promotion is now up to the DataPlane reconciler which switches the
+				// live services to the preview pods and applies the new pod template
+				// to the live deployment: simulate it.
+				dp := &operatorv1beta1.DataPlane{}
+				require.NoError(t, reconciler.Client.Get(ctx, nn, dp))
+				selector, ok := liveServicesSelectorForRollout(dp)
+				require.True(t, ok, "live services must select the preview pods during the promotion")
+				assert.Equal(t, k8sresources.PreviewSelectorForDataPlane(dp), selector)
+				assert.False(t, isLiveDeploymentHeldByRollout(dp))
+				promotedDataPlane := dp.DeepCopy()
+				dataplaneutils.SetDataPlaneDefaults(&promotedDataPlane.Spec.DataPlaneOptions)
+				promoted, err := k8sresources.GenerateNewDeploymentForDataPlane(promotedDataPlane, previewImage, "dataplane-tls-secret")
+				require.NoError(t, err)
+				live := &appsv1.Deployment{}
+				require.NoError(t, reconciler.Client.Get(ctx, controllerruntimeclient.ObjectKeyFromObject(liveDeployment), live))
+				live.Spec.Template = promoted.Spec.Template
+				require.NoError(t, reconciler.Client.Update(ctx, live))
+
+				_, err = reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+				condition = getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutPromotionDone), condition.Reason)
+				assert.Equal(t, metav1.ConditionTrue, condition.Status)
+				require.Len(t, listPreviewDeployments(t, reconciler.Client), 1,
+					"preview pods must be kept until the live services select the live pods again")
+
+				require.NoError(t, reconciler.Client.Get(ctx, nn, dp))
+				_, ok = liveServicesSelectorForRollout(dp)
+				assert.False(t, ok, "live services must select the live pods once the promotion is done")
+				assert.Nil(t, dp.Status.RolloutStatus.Services)
+				assert.True(t, isLiveDeploymentHeldByRollout(dp))
+
+				_, err = reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+				require.Empty(t, listPreviewDeployments(t, reconciler.Client))
+			},
+		},
+		{
+			name:              "preview resources are promoted with BreakBeforePromotion once approved",
+			promotionStrategy: operatorv1beta1.BreakBeforePromotion,
+			testBody: func(t *testing.T, reconciler DataPlaneBlueGreenReconciler, dataplane *operatorv1beta1.DataPlane, liveDeployment *appsv1.Deployment) {
+				ctx := context.Background()
+				nn := types.NamespacedName{Namespace: dataplane.Namespace, Name: dataplane.Name}
+				req := reconcile.Request{NamespacedName: nn}
+
+				for i := 0; i < 3; i++ {
+					_, err := reconciler.Reconcile(ctx, req)
+					require.NoError(t, err)
+				}
+				previewDeployments := listPreviewDeployments(t, reconciler.Client)
+				require.Len(t, previewDeployments, 1)
+				markDeploymentRolledOut(t, reconciler.Client, &previewDeployments[0])
+
+				_, err := reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+				condition := getRolledOutCondition(t, reconciler.Client, nn)
+				require.Equal(t, string(DataPlaneConditionReasonRolloutAwaitingPromotion), condition.Reason)
+
+				dp := &operatorv1beta1.DataPlane{}
+				require.NoError(t, reconciler.Client.Get(ctx, nn, dp))
+				dp.Annotations = map[string]string{
+					consts.DataPlanePromoteWhenReadyAnnotationKey: consts.DataPlanePromoteWhenReadyAnnotationTrue,
+				}
+				require.NoError(t, reconciler.Client.Update(ctx, dp))
+
+				_, err = reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+				condition = getRolledOutCondition(t, reconciler.Client, nn)
+				require.Equal(t, string(DataPlaneConditionReasonRolloutPromotionInProgress), condition.Reason)
+
+				// Simulate the DataPlane reconciler applying the promoted pod template.
+				require.NoError(t, reconciler.Client.Get(ctx, nn, dp))
+				promotedDataPlane := dp.DeepCopy()
+				dataplaneutils.SetDataPlaneDefaults(&promotedDataPlane.Spec.DataPlaneOptions)
+				promoted, err := k8sresources.GenerateNewDeploymentForDataPlane(promotedDataPlane, previewImage, "dataplane-tls-secret")
+				require.NoError(t, err)
+				live := &appsv1.Deployment{}
+				require.NoError(t, reconciler.Client.Get(ctx, controllerruntimeclient.ObjectKeyFromObject(liveDeployment), live))
+				live.Spec.Template = promoted.Spec.Template
+				require.NoError(t, reconciler.Client.Update(ctx, live))
+
+				for i := 0; i < 2; i++ {
+					_, err = reconciler.Reconcile(ctx, req)
+					require.NoError(t, err)
+				}
+
+				require.Empty(t, listPreviewDeployments(t, reconciler.Client))
+				condition = getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutPromotionDone), condition.Reason)
+
+				require.NoError(t, reconciler.Client.Get(ctx, nn, dp))
+				assert.NotContains(t, dp.Annotations, consts.DataPlanePromoteWhenReadyAnnotationKey,
+					"promotion approval should be removed once the promotion is done")
+				require.NotNil(t, dp.Status.RolloutStatus.Promotion)
+				expectedRevision, err := podTemplateSpecHash(promoted.Spec.Template)
+				require.NoError(t, err)
+				assert.Equal(t, expectedRevision, dp.Status.RolloutStatus.Promotion.Revision)
+				assert.False(t, dp.Status.RolloutStatus.Promotion.Time.IsZero())
+			},
+		},
+		{
+			name:              "promotion approval given before the preview awaits promotion is discarded",
+			promotionStrategy: operatorv1beta1.BreakBeforePromotion,
+			testBody: func(t *testing.T, reconciler DataPlaneBlueGreenReconciler, dataplane *operatorv1beta1.DataPlane, liveDeployment *appsv1.Deployment) {
+				ctx := context.Background()
+				nn := types.NamespacedName{Namespace: dataplane.Namespace, Name: dataplane.Name}
+				req := reconcile.Request{NamespacedName: nn}
+
+				dp := &operatorv1beta1.DataPlane{}
+				require.NoError(t, reconciler.Client.Get(ctx, nn, dp))
+				dp.Annotations = map[string]string{
+					consts.DataPlanePromoteWhenReadyAnnotationKey: consts.DataPlanePromoteWhenReadyAnnotationTrue,
+				}
+				require.NoError(t, reconciler.Client.Update(ctx, dp))
+
+				for i := 0; i < 3; i++ {
+					_, err := reconciler.Reconcile(ctx, req)
+					require.NoError(t, err)
+				}
+				previewDeployments := listPreviewDeployments(t, reconciler.Client)
+				require.Len(t, previewDeployments, 1)
+				markDeploymentRolledOut(t, reconciler.Client, &previewDeployments[0])
+
+				_, err := reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+				condition := getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutAwaitingPromotion), condition.Reason,
+					"the preview must not be promoted without an approval given while it awaits the promotion")
+
+				require.NoError(t, reconciler.Client.Get(ctx, nn, dp))
+				assert.NotContains(t, dp.Annotations, consts.DataPlanePromoteWhenReadyAnnotationKey)
+			},
+		},
+		{
+			name:              "preview resources are rolled back when an HTTP promotion check fails",
+			promotionStrategy: operatorv1beta1.AutomaticPromotion,
+			promotionChecks: []operatorv1beta1.PromotionCheck{
+				{
+					Name: "status",
+					HTTP: &operatorv1beta1.HTTPPromotionCheck{
+						Path:                "/fail",
+						Port:                int32(serverPort),
+						ExpectedStatusCodes: []int{http.StatusOK},
+						Interval:            metav1.Duration{Duration: time.Millisecond},
+						FailureThreshold:    2,
+					},
+				},
+			},
+			testBody: func(t *testing.T, reconciler DataPlaneBlueGreenReconciler, dataplane *operatorv1beta1.DataPlane, liveDeployment *appsv1.Deployment) {
+				ctx := context.Background()
+				nn := types.NamespacedName{Namespace: dataplane.Namespace, Name: dataplane.Name}
+				req := reconcile.Request{NamespacedName: nn}
+
+				for i := 0; i < 3; i++ {
+					_, err := reconciler.Reconcile(ctx, req)
+					require.NoError(t, err)
+				}
+				setPreviewProxyServiceClusterIP(t, reconciler.Client)
+				previewDeployments := listPreviewDeployments(t, reconciler.Client)
+				require.Len(t, previewDeployments, 1)
+				markDeploymentRolledOut(t, reconciler.Client, &previewDeployments[0])
+
+				t.Log("a failed request below the failure threshold is retried")
+				res, err := reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+				assert.Equal(t, time.Millisecond, res.RequeueAfter)
+				require.Len(t, listPreviewDeployments(t, reconciler.Client), 1)
+				dp := getDataPlane(t, reconciler.Client, nn)
+				checkCondition, ok := k8sutils.GetCondition("status", dp.Status.RolloutStatus)
+				require.True(t, ok)
+				assert.Equal(t, string(DataPlaneConditionReasonPromotionCheckPending), checkCondition.Reason)
+				require.Len(t, dp.Status.RolloutStatus.PromotionChecks, 1)
+				assert.EqualValues(t, 1, dp.Status.RolloutStatus.PromotionChecks[0].ConsecutiveFailures)
+
+				time.Sleep(2 * time.Millisecond)
+				_, err = reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+
+				require.Empty(t, listPreviewDeployments(t, reconciler.Client))
+				condition := getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutRolledBack), condition.Reason)
+				assert.Equal(t, metav1.ConditionFalse, condition.Status)
+
+				dp = getDataPlane(t, reconciler.Client, nn)
+				checkCondition, ok = k8sutils.GetCondition("status", dp.Status.RolloutStatus)
+				require.True(t, ok)
+				assert.Equal(t, string(DataPlaneConditionReasonPromotionCheckFailed), checkCondition.Reason)
+				assert.Equal(t, metav1.ConditionFalse, checkCondition.Status)
+				require.NotNil(t, dp.Status.RolloutStatus.Rollback)
+				assert.Equal(t, "status", dp.Status.RolloutStatus.Rollback.Check)
+				assert.Nil(t, dp.Status.RolloutStatus.Services)
+
+				// The rolled back revision must not be deployed as preview again.
+				for i := 0; i < 2; i++ {
+					_, err = reconciler.Reconcile(ctx, req)
+					require.NoError(t, err)
+				}
+				require.Empty(t, listPreviewDeployments(t, reconciler.Client))
+
+				live := &appsv1.Deployment{}
+				require.NoError(t, reconciler.Client.Get(ctx, controllerruntimeclient.ObjectKeyFromObject(liveDeployment), live))
+				container := k8sutils.GetPodContainerByName(&live.Spec.Template.Spec, consts.DataPlaneProxyContainerName)
+				require.NotNil(t, container)
+				assert.Equal(t, liveImage, container.Image)
+				assert.True(t, isLiveDeploymentHeldByRollout(dp))
+			},
+		},
+		{
+			name:              "preview resources are rolled back when preview pods restart",
+			promotionStrategy: operatorv1beta1.AutomaticPromotion,
+			promotionChecks: []operatorv1beta1.PromotionCheck{
+				{
+					Name: "restarts",
+					PodRestarts: &operatorv1beta1.PodRestartsPromotionCheck{
+						MaxRestarts: 1,
+					},
+				},
+			},
+			testBody: func(t *testing.T, reconciler DataPlaneBlueGreenReconciler, dataplane *operatorv1beta1.DataPlane, liveDeployment *appsv1.Deployment) {
+				ctx := context.Background()
+				nn := types.NamespacedName{Namespace: dataplane.Namespace, Name: dataplane.Name}
+				req := reconcile.Request{NamespacedName: nn}
+
+				for i := 0; i < 3; i++ {
+					_, err := reconciler.Reconcile(ctx, req)
+					require.NoError(t, err)
+				}
+				previewDeployments := listPreviewDeployments(t, reconciler.Client)
+				require.Len(t, previewDeployments, 1)
+				markDeploymentRolledOut(t, reconciler.Client, &previewDeployments[0])
+
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "dataplane-preview-pod",
+						Namespace: dataplane.Namespace,
+						Labels:    k8sresources.PreviewSelectorForDataPlane(dataplane),
+					},
+					Status: corev1.PodStatus{
+						ContainerStatuses: []corev1.ContainerStatus{
+							{Name: consts.DataPlaneProxyContainerName, RestartCount: 2},
+						},
+					},
+				}
+				require.NoError(t, reconciler.Client.Create(ctx, pod))
+
+				_, err := reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+
+				require.Empty(t, listPreviewDeployments(t, reconciler.Client))
+				condition := getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutRolledBack), condition.Reason)
+
+				dp := getDataPlane(t, reconciler.Client, nn)
+				require.NotNil(t, dp.Status.RolloutStatus.Rollback)
+				assert.Equal(t, "restarts", dp.Status.RolloutStatus.Rollback.Check)
+			},
+		},
+		{
+			name:              "preview resources wait for promotion once all promotion checks pass",
+			promotionStrategy: operatorv1beta1.BreakBeforePromotion,
+			promotionChecks: []operatorv1beta1.PromotionCheck{
+				{
+					Name: "status",
+					HTTP: &operatorv1beta1.HTTPPromotionCheck{
+						Path:                "/",
+						Port:                int32(serverPort),
+						ExpectedStatusCodes: []int{http.StatusOK},
+					},
+				},
+				{
+					Name:        "restarts",
+					PodRestarts: &operatorv1beta1.PodRestartsPromotionCheck{},
+				},
+			},
+			testBody: func(t *testing.T, reconciler DataPlaneBlueGreenReconciler, dataplane *operatorv1beta1.DataPlane, liveDeployment *appsv1.Deployment) {
+				ctx := context.Background()
+				nn := types.NamespacedName{Namespace: dataplane.Namespace, Name: dataplane.Name}
+				req := reconcile.Request{NamespacedName: nn}
+
+				for i := 0; i < 3; i++ {
+					_, err := reconciler.Reconcile(ctx, req)
+					require.NoError(t, err)
+				}
+				setPreviewProxyServiceClusterIP(t, reconciler.Client)
+				previewDeployments := listPreviewDeployments(t, reconciler.Client)
+				require.Len(t, previewDeployments, 1)
+				markDeploymentRolledOut(t, reconciler.Client, &previewDeployments[0])
+
+				_, err := reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+
+				condition := getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutAwaitingPromotion), condition.Reason)
+
+				dp := getDataPlane(t, reconciler.Client, nn)
+				for _, name := range []string{"status", "restarts"} {
+					checkCondition, ok := k8sutils.GetCondition(k8sutils.ConditionType(name), dp.Status.RolloutStatus)
+					require.True(t, ok, name)
+					assert.Equal(t, string(DataPlaneConditionReasonPromotionCheckPassed), checkCondition.Reason, name)
+					assert.Equal(t, metav1.ConditionTrue, checkCondition.Status, name)
+				}
+				assert.Nil(t, dp.Status.RolloutStatus.Rollback)
+
+				t.Log("the checks are not run anymore once they have all passed")
+				dp.Spec.Deployment.Rollout.Strategy.BlueGreen.PromotionChecks[0].HTTP.Path = "/fail"
+				dp.Spec.Deployment.Rollout.Strategy.BlueGreen.PromotionChecks[0].HTTP.FailureThreshold = 1
+				dp.Spec.Deployment.Rollout.Strategy.BlueGreen.PromotionChecks[0].HTTP.Interval = metav1.Duration{Duration: time.Millisecond}
+				require.NoError(t, reconciler.Client.Update(ctx, dp))
+				time.Sleep(2 * time.Millisecond)
+				_, err = reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+
+				condition = getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutAwaitingPromotion), condition.Reason)
+				require.Len(t, listPreviewDeployments(t, reconciler.Client), 1)
+				dp = getDataPlane(t, reconciler.Client, nn)
+				assert.Nil(t, dp.Status.RolloutStatus.Rollback)
+
+				t.Log("the pod restarts checks keep running while the promotion is awaited")
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "dataplane-preview-pod",
+						Namespace: dataplane.Namespace,
+						Labels:    k8sresources.PreviewSelectorForDataPlane(dataplane),
+					},
+					Status: corev1.PodStatus{
+						ContainerStatuses: []corev1.ContainerStatus{
+							{Name: consts.DataPlaneProxyContainerName, RestartCount: 1},
+						},
+					},
+				}
+				require.NoError(t, reconciler.Client.Create(ctx, pod))
+				_, err = reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+
+				require.Empty(t, listPreviewDeployments(t, reconciler.Client))
+				condition = getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutRolledBack), condition.Reason)
+				dp = getDataPlane(t, reconciler.Client, nn)
+				require.NotNil(t, dp.Status.RolloutStatus.Rollback)
+				assert.Equal(t, "restarts", dp.Status.RolloutStatus.Rollback.Check)
+			},
+		},
+		{
+			name:              "promotion is held until the soak promotion check passes",
+			promotionStrategy: operatorv1beta1.AutomaticPromotion,
+			promotionChecks: []operatorv1beta1.PromotionCheck{
+				{
+					Name: "soak",
+					Soak: &operatorv1beta1.SoakPromotionCheck{
+						Duration: metav1.Duration{Duration: time.Hour},
+					},
+				},
+			},
+			testBody: func(t *testing.T, reconciler DataPlaneBlueGreenReconciler, dataplane *operatorv1beta1.DataPlane, liveDeployment *appsv1.Deployment) {
+				ctx := context.Background()
+				nn := types.NamespacedName{Namespace: dataplane.Namespace, Name: dataplane.Name}
+				req := reconcile.Request{NamespacedName: nn}
+
+				for i := 0; i < 3; i++ {
+					_, err := reconciler.Reconcile(ctx, req)
+					require.NoError(t, err)
+				}
+				previewDeployments := listPreviewDeployments(t, reconciler.Client)
+				require.Len(t, previewDeployments, 1)
+				markDeploymentRolledOut(t, reconciler.Client, &previewDeployments[0])
+
+				res, err := reconciler.Reconcile(ctx, req)
+				require.NoError(t, err)
+				assert.Greater(t, res.RequeueAfter, time.Duration(0))
+				assert.LessOrEqual(t, res.RequeueAfter, time.Hour)
+
+				condition := getRolledOutCondition(t, reconciler.Client, nn)
+				assert.Equal(t, string(DataPlaneConditionReasonRolloutProgressing), condition.Reason)
+
+				dp := getDataPlane(t, reconciler.Client, nn)
+				checkCondition, ok := k8sutils.GetCondition("soak", dp.Status.RolloutStatus)
+				require.True(t, ok)
+				assert.Equal(t, string(DataPlaneConditionReasonPromotionCheckPending), checkCondition.Reason)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dataplane := newDataPlane(tc.promotionStrategy, tc.promotionChecks)
+			liveDeployment := newLiveDeployment(t, dataplane)
+			k8sutils.SetOwnerForObject(liveDeployment, dataplane)
+			addLabelForDataplane(liveDeployment)
+
+			fakeClient := fakectrlruntimeclient.
+				NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(dataplane, liveDeployment).
+				WithStatusSubresource(dataplane, liveDeployment).
+				Build()
+
+			reconciler := DataPlaneBlueGreenReconciler{
+				Client: fakeClient,
+				// The live resources are not the subject of this test.
+				DataPlaneReconciler: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+					return reconcile.Result{}, nil
+				}),
+				DevelopmentMode:          true,
+				promotionCheckHTTPClient: server.Client(),
+			}
+
+			tc.testBody(t, reconciler, dataplane, liveDeployment)
+		})
+	}
+}
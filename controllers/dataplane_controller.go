@@ -2,13 +2,12 @@ package controllers
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,6 +29,7 @@ type DataPlaneReconciler struct {
 	eventRecorder            record.EventRecorder
 	ClusterCASecretName      string
 	ClusterCASecretNamespace string
+	CertificateOptions       CertificateOptions
 	DevelopmentMode          bool
 }
 
@@ -37,7 +37,10 @@ type DataPlaneReconciler struct {
 func (r *DataPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.eventRecorder = mgr.GetEventRecorderFor("dataplane")
 
-	return DataPlaneWatchBuilder(mgr).
+	return DataPlaneWatchBuilder(mgr, types.NamespacedName{
+		Namespace: r.ClusterCASecretNamespace,
+		Name:      r.ClusterCASecretName,
+	}, r.CertificateOptions).
 		Complete(r)
 }
 
@@ -70,17 +73,10 @@ func (r *DataPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	trace(log, "validating DataPlane configuration", dataplane)
-	updated := dataplaneutils.SetDataPlaneDefaults(&dataplane.Spec.DataPlaneOptions)
-	if updated {
+	// the defaults are set at admission time by the mutating webhook, they are
+	// set here for the DataPlanes admitted without it, but not written back.
+	if dataplaneutils.SetDataPlaneDefaults(&dataplane.Spec.DataPlaneOptions) {
 		trace(log, "setting default ENVs", dataplane)
-		if err := r.Client.Update(ctx, dataplane); err != nil {
-			if k8serrors.IsConflict(err) {
-				debug(log, "conflict found when updating DataPlane resource, retrying", dataplane)
-				return ctrl.Result{Requeue: true, RequeueAfter: requeueWithoutBackoff}, nil
-			}
-			return ctrl.Result{}, fmt.Errorf("failed updating DataPlane's environment variables: %w", err)
-		}
-		return ctrl.Result{}, nil // no need to requeue, the update will trigger.
 	}
 
 	// validate dataplane
@@ -132,6 +128,13 @@ func (r *DataPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the owned object
 	}
 
+	trace(log, "ensuring DataPlane hybrid mode cluster configuration", dataplane)
+	if wait, err := r.ensureClusterConfiguration(ctx, log, dataplane); err != nil {
+		return ctrl.Result{}, err
+	} else if wait {
+		return ctrl.Result{}, nil // the owned objects, the DataPlane or the control plane changes will trigger reconciliation
+	}
+
 	trace(log, "checking readiness of DataPlane service", dataplaneProxyService)
 	if dataplaneProxyService.Spec.ClusterIP == "" {
 		return ctrl.Result{}, nil // no need to requeue, the update will trigger.
@@ -145,6 +148,14 @@ func (r *DataPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil // no need to requeue, the update will trigger.
 	}
 
+	trace(log, "ensuring DataPlane database migrations are up", dataplane)
+	if migrated, err := r.ensureDatabaseMigrationsUp(ctx, log, dataplane, certSecret.Name); err != nil {
+		return ctrl.Result{}, err
+	} else if !migrated {
+		debug(log, "waiting for DataPlane database migrations", dataplane)
+		return ctrl.Result{}, nil // the migrations Job status update will trigger reconciliation
+	}
+
 	trace(log, "looking for existing deployments for DataPlane resource", dataplane)
 	res, dataplaneDeployment, err := r.ensureDeploymentForDataPlane(ctx, dataplane, certSecret.Name)
 	if err != nil {
@@ -161,18 +172,49 @@ func (r *DataPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		debug(log, "no need for deployment update", dataplane)
 	}
 
+	trace(log, "ensuring DataPlane horizontal pod autoscaler", dataplane)
+	res, _, err = r.ensureHPAForDataPlane(ctx, dataplane, dataplaneDeployment.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if res != Noop {
+		debug(log, "horizontal pod autoscaler created/updated", dataplane)
+		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the owned object
+	}
+
+	trace(log, "ensuring DataPlane pod disruption budget", dataplane)
+	createdOrUpdated, _, err = r.ensurePodDisruptionBudgetForDataPlane(ctx, dataplane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if createdOrUpdated {
+		debug(log, "pod disruption budget created/updated", dataplane)
+		return ctrl.Result{}, nil // requeue will be triggered by the creation or update of the owned object
+	}
+
+	trace(log, "scheduling the renewal of DataPlane certificates", dataplane)
+	renewalDelay, err := certificatesRenewalDelay(ctx, r.Client, dataplane, r.CertificateOptions.RenewBefore)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	trace(log, "checking readiness of DataPlane deployments", dataplane)
 
 	if dataplaneDeployment.Status.Replicas == 0 || dataplaneDeployment.Status.AvailableReplicas < dataplaneDeployment.Status.Replicas {
 		trace(log, "deployment for DataPlane not ready yet", dataplane)
 
-		// Set Ready to false for dataplane as the underlying deployment is not ready.
-		k8sutils.SetCondition(
-			k8sutils.NewCondition(k8sutils.ReadyType, metav1.ConditionFalse, k8sutils.WaitingToBecomeReadyReason, k8sutils.WaitingToBecomeReadyMessage),
-			dataplane,
-		)
+		// Set Ready to false for dataplane as the underlying deployment is not ready,
+		// with the reason why its pods are failing if they are.
+		if err := setNotReadyForUnavailableDeployment(ctx, r.Client, r.eventRecorder, dataplane, dataplaneDeployment); err != nil {
+			return ctrl.Result{}, err
+		}
 		r.ensureReadinessStatus(dataplane, dataplaneDeployment)
-		return ctrl.Result{}, r.patchStatus(ctx, log, dataplane)
+		return ctrl.Result{RequeueAfter: renewalDelay}, r.patchStatus(ctx, log, dataplane)
+	}
+
+	trace(log, "ensuring DataPlane database migrations are finished", dataplane)
+	if err := r.ensureDatabaseMigrationsFinished(ctx, dataplane, dataplaneDeployment, certSecret.Name); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	r.ensureIsMarkedProvisioned(dataplane)
@@ -184,7 +226,7 @@ func (r *DataPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	debug(log, "reconciliation complete for DataPlane resource", dataplane)
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: renewalDelay}, nil
 }
 
 // patchStatus Patches the resource status only when there are changes in the Conditions
@@ -217,5 +259,6 @@ func addressesChanged(current, updated *operatorv1beta1.DataPlane) bool {
 func readinessChanged(current, updated *operatorv1beta1.DataPlane) bool {
 	return current.Status.Ready != updated.Status.Ready ||
 		current.Status.ReadyReplicas != updated.Status.ReadyReplicas ||
-		current.Status.Replicas != updated.Status.Replicas
+		current.Status.Replicas != updated.Status.Replicas ||
+		current.Status.Selector != updated.Status.Selector
 }
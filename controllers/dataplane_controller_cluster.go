@@ -0,0 +1,492 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/samber/lo"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	operatorerrors "github.com/kong/gateway-operator/internal/errors"
+	"github.com/kong/gateway-operator/internal/utils/index"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sreduce "github.com/kong/gateway-operator/internal/utils/kubernetes/reduce"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
+)
+
+// -----------------------------------------------------------------------------
+// DataPlaneReconciler - Hybrid mode cluster
+// -----------------------------------------------------------------------------
+
+// ensureClusterConfiguration ensures the hybrid mode cluster resources of the
+// DataPlane and sets its cluster configuration in the DataPlane's spec.
+// It returns true when the reconciliation has to wait for owned objects to be
+// created or updated, for the DataPlane to be updated, or for its control plane
+// to be exposed.
+func (r *DataPlaneReconciler) ensureClusterConfiguration(
+	ctx context.Context,
+	log logr.Logger,
+	dataplane *operatorv1beta1.DataPlane,
+) (bool, error) {
+	createdOrUpdated, clusterService, err := r.ensureClusterServiceForDataPlane(ctx, dataplane)
+	if err != nil {
+		return false, err
+	}
+	if createdOrUpdated {
+		debug(log, "DataPlane cluster service created/updated/deleted", dataplane)
+		return true, nil // the cluster service creation/update/deletion will trigger reconciliation
+	}
+
+	var subject, controlPlaneAddress string
+	switch {
+	case dataplane.Spec.Cluster == nil:
+		if setDataPlaneClusterDefaults(dataplane, "", "") {
+			trace(log, "removing cluster configuration", dataplane)
+			return true, r.updateClusterConfiguration(ctx, dataplane)
+		}
+		deleted, err := r.ensureClusterCertificatesDeleted(ctx, dataplane)
+		if deleted {
+			debug(log, "DataPlane cluster certificate deleted", dataplane)
+		}
+		return deleted, err
+	case isClusterDataPlane(dataplane):
+		controlPlaneService, err := r.getClusterControlPlaneService(ctx, dataplane)
+		if err != nil {
+			return false, err
+		}
+		if controlPlaneService == nil {
+			debug(log, "waiting for the DataPlane cluster control plane", dataplane)
+			return true, r.ensureDataPlaneIsMarkedNotProvisioned(ctx, log, dataplane,
+				DataPlaneConditionReasonClusterControlPlaneNotReady,
+				fmt.Sprintf("waiting for DataPlane %s with the %s cluster role to be exposed",
+					dataplane.Spec.Cluster.ControlPlaneName, operatorv1beta1.DataPlaneClusterRoleControlPlane))
+		}
+		controlPlaneAddress = clusterServiceAddress(controlPlaneService)
+		subject = fmt.Sprintf("%s.%s.svc", dataplane.Name, dataplane.Namespace)
+	default:
+		subject = clusterServiceAddress(clusterService)
+	}
+
+	created, certSecret, err := r.ensureClusterCertificate(ctx, dataplane, subject)
+	if err != nil {
+		return false, err
+	}
+	if created {
+		debug(log, "DataPlane cluster certificate created", dataplane)
+		return true, nil // the cluster certificate creation will trigger reconciliation
+	}
+
+	if setDataPlaneClusterDefaults(dataplane, certSecret.Name, controlPlaneAddress) {
+		trace(log, "setting cluster configuration", dataplane)
+		return true, r.updateClusterConfiguration(ctx, dataplane)
+	}
+	return false, nil
+}
+
+func (r *DataPlaneReconciler) updateClusterConfiguration(ctx context.Context, dataplane *operatorv1beta1.DataPlane) error {
+	if err := r.Client.Update(ctx, dataplane); err != nil {
+		return fmt.Errorf("failed updating DataPlane's cluster configuration: %w", err)
+	}
+	return nil
+}
+
+// ensureClusterServiceForDataPlane ensures that a DataPlane with the control_plane
+// role exposes its cluster endpoints through a Service, and that DataPlanes with
+// any other role do not own such Service.
+func (r *DataPlaneReconciler) ensureClusterServiceForDataPlane(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (createdOrUpdated bool, svc *corev1.Service, err error) {
+	services, err := k8sutils.ListServicesForOwner(
+		ctx,
+		r.Client,
+		dataplane.Namespace,
+		dataplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+			consts.DataPlaneServiceTypeLabel:      string(consts.DataPlaneClusterServiceLabelValue),
+		},
+	)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if !isClusterControlPlane(dataplane) {
+		for _, service := range services {
+			service := service
+			if err := r.Client.Delete(ctx, &service); client.IgnoreNotFound(err) != nil {
+				return false, nil, fmt.Errorf("failed deleting DataPlane cluster Service %s: %w", service.Name, err)
+			}
+		}
+		return len(services) > 0, nil, nil
+	}
+
+	count := len(services)
+	if count > 1 {
+		if err := k8sreduce.ReduceServices(ctx, r.Client, services); err != nil {
+			return false, nil, err
+		}
+		return false, nil, errors.New("number of services reduced")
+	}
+
+	generatedService, err := k8sresources.GenerateNewClusterServiceForDataPlane(dataplane)
+	if err != nil {
+		return false, nil, err
+	}
+	addLabelForDataplane(generatedService)
+	k8sutils.SetOwnerForObject(generatedService, dataplane)
+
+	if count == 1 {
+		var updated bool
+		existingService := &services[0]
+		updated, existingService.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingService.ObjectMeta, generatedService.ObjectMeta)
+
+		if !cmp.Equal(existingService.Spec.Selector, generatedService.Spec.Selector) {
+			existingService.Spec.Selector = generatedService.Spec.Selector
+			updated = true
+		}
+		if !cmp.Equal(existingService.Spec.Ports, generatedService.Spec.Ports) {
+			existingService.Spec.Ports = generatedService.Spec.Ports
+			updated = true
+		}
+
+		if updated {
+			if err := r.Client.Update(ctx, existingService); err != nil {
+				return false, existingService, fmt.Errorf("failed updating DataPlane Service %s: %w", existingService.Name, err)
+			}
+			return true, existingService, nil
+		}
+		return false, existingService, nil
+	}
+
+	return true, generatedService, r.Client.Create(ctx, generatedService)
+}
+
+// getClusterControlPlaneService returns the cluster Service of the control_plane
+// DataPlane the provided data_plane DataPlane connects to.
+// It returns a nil Service when the control plane has not been exposed yet.
+func (r *DataPlaneReconciler) getClusterControlPlaneService(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (*corev1.Service, error) {
+	controlPlane := &operatorv1beta1.DataPlane{}
+	nn := types.NamespacedName{Namespace: dataplane.Namespace, Name: dataplane.Spec.Cluster.ControlPlaneName}
+	if err := r.Client.Get(ctx, nn, controlPlane); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !isClusterControlPlane(controlPlane) {
+		return nil, nil
+	}
+
+	services, err := k8sutils.ListServicesForOwner(
+		ctx,
+		r.Client,
+		controlPlane.Namespace,
+		controlPlane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+			consts.DataPlaneServiceTypeLabel:      string(consts.DataPlaneClusterServiceLabelValue),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(services) != 1 {
+		// the control plane reconciliation reduces the number of Services
+		return nil, nil
+	}
+	return &services[0], nil
+}
+
+// ensureClusterCertificate ensures that the DataPlane holds a certificate, issued
+// from the operator CA, which it uses to authenticate the other nodes of its cluster.
+// The control plane certificate is issued for the address of its cluster Service,
+// which the data plane nodes use as server name.
+func (r *DataPlaneReconciler) ensureClusterCertificate(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+	subject string,
+) (bool, *corev1.Secret, error) {
+	usages := []certificatesv1.KeyUsage{
+		certificatesv1.UsageKeyEncipherment,
+		certificatesv1.UsageDigitalSignature,
+		certificatesv1.UsageServerAuth,
+		certificatesv1.UsageClientAuth,
+	}
+	return r.certificateIssuer().ensureCertificateSecret(ctx, certificateRequest{
+		owner:   dataplane,
+		subject: subject,
+		purpose: consts.DataPlaneClusterCertificatePurposeLabelValue,
+		usages:  usages,
+	})
+}
+
+// ensureClusterCertificatesDeleted deletes the cluster certificate Secrets of the
+// DataPlane. It returns true when any Secret has been deleted.
+func (r *DataPlaneReconciler) ensureClusterCertificatesDeleted(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (bool, error) {
+	secrets, err := k8sutils.ListSecretsForOwner(
+		ctx,
+		r.Client,
+		dataplane.UID,
+		client.InNamespace(dataplane.Namespace),
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+			consts.CertificatePurposeLabel:        consts.DataPlaneClusterCertificatePurposeLabelValue,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	for _, secret := range secrets {
+		secret := secret
+		if err := r.Client.Delete(ctx, &secret); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("failed deleting DataPlane cluster certificate Secret %s: %w", secret.Name, err)
+		}
+	}
+	return len(secrets) > 0, nil
+}
+
+// -----------------------------------------------------------------------------
+// DataPlane - Hybrid mode cluster watch
+// -----------------------------------------------------------------------------
+
+// getDataPlanesFromClusterService returns a map function which maps the cluster
+// Service of a control_plane DataPlane to the data_plane DataPlanes connecting to it.
+func getDataPlanesFromClusterService(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) (recs []reconcile.Request) {
+		service, ok := obj.(*corev1.Service)
+		if !ok {
+			log.FromContext(ctx).Error(
+				operatorerrors.ErrUnexpectedObject,
+				"failed to map DataPlane on cluster Service",
+				"expected", "Service", "found", reflect.TypeOf(obj),
+			)
+			return
+		}
+		if service.Labels[consts.DataPlaneServiceTypeLabel] != string(consts.DataPlaneClusterServiceLabelValue) {
+			return
+		}
+
+		var controlPlaneName string
+		for _, ownRef := range service.OwnerReferences {
+			if ownRef.APIVersion == operatorv1beta1.SchemeGroupVersion.String() && ownRef.Kind == "DataPlane" {
+				controlPlaneName = ownRef.Name
+				break
+			}
+		}
+		if controlPlaneName == "" {
+			return
+		}
+
+		dataPlaneList := &operatorv1beta1.DataPlaneList{}
+		if err := c.List(ctx, dataPlaneList,
+			client.InNamespace(service.Namespace),
+			client.MatchingFields{
+				index.ClusterControlPlaneNameIndex: controlPlaneName,
+			}); err != nil {
+			log.FromContext(ctx).Error(err, "failed to map DataPlane on cluster Service")
+			return
+		}
+
+		recs = make([]reconcile.Request, 0, len(dataPlaneList.Items))
+		for _, dp := range dataPlaneList.Items {
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: dp.Namespace,
+					Name:      dp.Name,
+				},
+			})
+		}
+		return recs
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Hybrid mode cluster - Private Functions
+// -----------------------------------------------------------------------------
+
+func isClusterControlPlane(dataplane *operatorv1beta1.DataPlane) bool {
+	return dataplane.Spec.Cluster != nil &&
+		dataplane.Spec.Cluster.Role == operatorv1beta1.DataPlaneClusterRoleControlPlane
+}
+
+func isClusterDataPlane(dataplane *operatorv1beta1.DataPlane) bool {
+	return dataplane.Spec.Cluster != nil &&
+		dataplane.Spec.Cluster.Role == operatorv1beta1.DataPlaneClusterRoleDataPlane
+}
+
+// clusterServiceAddress returns the in-cluster address of the provided cluster Service.
+func clusterServiceAddress(service *corev1.Service) string {
+	return fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace)
+}
+
+// clusterEnvVarNames are the names of the environment variables configuring
+// the proxy container of the DataPlanes for the hybrid mode.
+var clusterEnvVarNames = []string{
+	consts.EnvVarKongRole,
+	consts.EnvVarKongClusterMTLS,
+	consts.EnvVarKongClusterCert,
+	consts.EnvVarKongClusterCertKey,
+	consts.EnvVarKongClusterCACert,
+	consts.EnvVarKongClusterListen,
+	consts.EnvVarKongClusterTelemetryListen,
+	consts.EnvVarKongClusterControlPlane,
+	consts.EnvVarKongClusterServerName,
+	consts.EnvVarKongClusterTelemetryEndpoint,
+	consts.EnvVarKongClusterTelemetryServerName,
+}
+
+// setDataPlaneClusterDefaults sets the hybrid mode configuration in the proxy
+// container of the DataPlane: the cluster certificate volume and the environment
+// variables for the DataPlane's role. The controlPlaneAddress is the address of
+// the control plane cluster Service, and is only used for the data_plane role.
+// When the DataPlane has no cluster options anymore, the configuration which was
+// set by the operator is removed.
+// It returns a boolean indicating whether the DataPlane's spec has been changed.
+func setDataPlaneClusterDefaults(
+	dataplane *operatorv1beta1.DataPlane,
+	certSecretName string,
+	controlPlaneAddress string,
+) bool {
+	if dataplane.Spec.Cluster == nil && dataplane.Spec.Deployment.PodTemplateSpec == nil {
+		return false
+	}
+	if dataplane.Spec.Deployment.PodTemplateSpec == nil {
+		dataplane.Spec.Deployment.PodTemplateSpec = &corev1.PodTemplateSpec{}
+	}
+	podSpec := &dataplane.Spec.Deployment.PodTemplateSpec.Spec
+	container := k8sutils.GetPodContainerByName(podSpec, consts.DataPlaneProxyContainerName)
+
+	if dataplane.Spec.Cluster == nil {
+		// Only the configuration set by the operator, which is recognized by
+		// the cluster certificate volume, is removed. Clusters configured by
+		// hand through the environment variables are left untouched.
+		if !lo.ContainsBy(podSpec.Volumes, func(v corev1.Volume) bool {
+			return v.Name == consts.DataPlaneClusterCertificateVolumeName
+		}) {
+			return false
+		}
+		podSpec.Volumes = lo.Reject(podSpec.Volumes, func(v corev1.Volume, _ int) bool {
+			return v.Name == consts.DataPlaneClusterCertificateVolumeName
+		})
+		if container != nil {
+			container.VolumeMounts = lo.Reject(container.VolumeMounts, func(m corev1.VolumeMount, _ int) bool {
+				return m.Name == consts.DataPlaneClusterCertificateVolumeName
+			})
+			for _, name := range clusterEnvVarNames {
+				container.Env = rejectEnvByName(container.Env, name)
+			}
+		}
+		return true
+	}
+
+	changed := false
+	if container == nil {
+		podSpec.Containers = append(podSpec.Containers, corev1.Container{
+			Name: consts.DataPlaneProxyContainerName,
+		})
+		container = &podSpec.Containers[len(podSpec.Containers)-1]
+		changed = true
+	}
+
+	volume := corev1.Volume{
+		Name: consts.DataPlaneClusterCertificateVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  certSecretName,
+				DefaultMode: pointer.Int32(corev1.SecretVolumeSourceDefaultMode),
+				Items: []corev1.KeyToPath{
+					{Key: "tls.crt", Path: "tls.crt"},
+					{Key: "tls.key", Path: "tls.key"},
+					{Key: "ca.crt", Path: "ca.crt"},
+				},
+			},
+		},
+	}
+	if updatedVolumes, updated := setByName(podSpec.Volumes, volume, func(v corev1.Volume) string { return v.Name }); updated {
+		podSpec.Volumes = updatedVolumes
+		changed = true
+	}
+	volumeMount := corev1.VolumeMount{
+		Name:      consts.DataPlaneClusterCertificateVolumeName,
+		ReadOnly:  true,
+		MountPath: consts.DataPlaneClusterCertificateMountPath,
+	}
+	if updatedMounts, updated := setByName(container.VolumeMounts, volumeMount, func(m corev1.VolumeMount) string { return m.Name }); updated {
+		container.VolumeMounts = updatedMounts
+		changed = true
+	}
+
+	env := map[string]string{
+		consts.EnvVarKongRole:           string(dataplane.Spec.Cluster.Role),
+		consts.EnvVarKongClusterMTLS:    "pki",
+		consts.EnvVarKongClusterCert:    consts.DataPlaneClusterCertificateMountPath + "/tls.crt",
+		consts.EnvVarKongClusterCertKey: consts.DataPlaneClusterCertificateMountPath + "/tls.key",
+		consts.EnvVarKongClusterCACert:  consts.DataPlaneClusterCertificateMountPath + "/ca.crt",
+	}
+	switch dataplane.Spec.Cluster.Role {
+	case operatorv1beta1.DataPlaneClusterRoleControlPlane:
+		env[consts.EnvVarKongClusterListen] = fmt.Sprintf("0.0.0.0:%d", consts.DataPlaneClusterPort)
+		env[consts.EnvVarKongClusterTelemetryListen] = fmt.Sprintf("0.0.0.0:%d", consts.DataPlaneClusterTelemetryPort)
+	case operatorv1beta1.DataPlaneClusterRoleDataPlane:
+		env[consts.EnvVarKongClusterListen] = "off"
+		env[consts.EnvVarKongClusterControlPlane] = fmt.Sprintf("%s:%d", controlPlaneAddress, consts.DataPlaneClusterPort)
+		env[consts.EnvVarKongClusterServerName] = controlPlaneAddress
+		env[consts.EnvVarKongClusterTelemetryEndpoint] = fmt.Sprintf("%s:%d", controlPlaneAddress, consts.DataPlaneClusterTelemetryPort)
+		env[consts.EnvVarKongClusterTelemetryServerName] = controlPlaneAddress
+	}
+
+	for _, name := range clusterEnvVarNames {
+		value, ok := env[name]
+		if !ok {
+			if lo.ContainsBy(container.Env, func(e corev1.EnvVar) bool { return e.Name == name }) {
+				container.Env = rejectEnvByName(container.Env, name)
+				changed = true
+			}
+			continue
+		}
+		if envValueByName(container.Env, name) != value || envVarSourceByName(container.Env, name) != nil {
+			container.Env = updateEnv(container.Env, name, value)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// setByName sets the provided item in the items, replacing the one with the
+// same name. It returns the resulting items and a boolean indicating whether
+// they have been changed.
+func setByName[T any](items []T, item T, name func(T) string) ([]T, bool) {
+	for i := range items {
+		if name(items[i]) == name(item) {
+			if reflect.DeepEqual(items[i], item) {
+				return items, false
+			}
+			items[i] = item
+			return items, true
+		}
+	}
+	return append(items, item), true
+}
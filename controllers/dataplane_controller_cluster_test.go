@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	"github.com/kong/gateway-operator/internal/utils/index"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	"github.com/kong/gateway-operator/test/helpers"
+)
+
+func TestDataPlaneClusterConfiguration(t *testing.T) {
+	const namespace = "default"
+
+	ctx := context.Background()
+	ca := helpers.CreateCA(t)
+	mtlsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mtls-secret",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"tls.crt": ca.CertPEM.Bytes(),
+			"tls.key": ca.KeyPEM.Bytes(),
+		},
+	}
+	newDataPlane := func(name string, database *operatorv1beta1.DataPlaneDatabaseOptions, cluster *operatorv1beta1.DataPlaneClusterOptions) *operatorv1beta1.DataPlane {
+		return &operatorv1beta1.DataPlane{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "gateway-operator.konghq.com/v1beta1",
+				Kind:       "DataPlane",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				UID:       types.UID(uuid.NewString()),
+			},
+			Spec: operatorv1beta1.DataPlaneSpec{
+				DataPlaneOptions: operatorv1beta1.DataPlaneOptions{
+					Database: database,
+					Cluster:  cluster,
+					Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+						DeploymentOptions: operatorv1beta1.DeploymentOptions{
+							PodTemplateSpec: &corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name:  consts.DataPlaneProxyContainerName,
+											Image: consts.DefaultDataPlaneImage,
+											Env: []corev1.EnvVar{
+												{Name: consts.EnvVarKongClusterListen, Value: "off"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	controlPlane := newDataPlane("kong-cp",
+		&operatorv1beta1.DataPlaneDatabaseOptions{
+			Postgres: &operatorv1beta1.PostgresDatabaseOptions{SecretName: "kong-postgres"},
+		},
+		&operatorv1beta1.DataPlaneClusterOptions{
+			Role: operatorv1beta1.DataPlaneClusterRoleControlPlane,
+		},
+	)
+	dataPlane := newDataPlane("kong-dp", nil,
+		&operatorv1beta1.DataPlaneClusterOptions{
+			Role:             operatorv1beta1.DataPlaneClusterRoleDataPlane,
+			ControlPlaneName: controlPlane.Name,
+		},
+	)
+
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(controlPlane, dataPlane, mtlsSecret).
+		WithStatusSubresource(controlPlane, dataPlane).
+		WithIndex(&operatorv1beta1.DataPlane{}, index.ClusterControlPlaneNameIndex, index.ClusterControlPlaneNameOnDataPlane).
+		Build()
+	reconciler := DataPlaneReconciler{
+		Client:                   fakeClient,
+		eventRecorder:            record.NewFakeRecorder(10),
+		ClusterCASecretName:      mtlsSecret.Name,
+		ClusterCASecretNamespace: mtlsSecret.Namespace,
+		DevelopmentMode:          true,
+	}
+	log := logr.Discard()
+
+	getClusterCertificate := func(t *testing.T, dataplane *operatorv1beta1.DataPlane) *corev1.Secret {
+		secrets, err := k8sutils.ListSecretsForOwner(ctx, fakeClient, dataplane.UID,
+			client.MatchingLabels{consts.CertificatePurposeLabel: consts.DataPlaneClusterCertificatePurposeLabelValue})
+		require.NoError(t, err)
+		if len(secrets) == 0 {
+			return nil
+		}
+		require.Len(t, secrets, 1)
+		// The fake client does not convert the StringData of Secrets.
+		secret := &secrets[0]
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+			for k, v := range secret.StringData {
+				secret.Data[k] = []byte(v)
+			}
+			secret.StringData = nil
+			require.NoError(t, fakeClient.Update(ctx, secret))
+		}
+		return secret
+	}
+	requireCertificateSubject := func(t *testing.T, secret *corev1.Secret, subject string) {
+		block, _ := pem.Decode(secret.Data["tls.crt"])
+		require.NotNil(t, block)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		require.NoError(t, err)
+		require.Equal(t, subject, cert.Subject.CommonName)
+		require.Contains(t, cert.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+		require.Contains(t, cert.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+	}
+	proxyEnv := func(dataplane *operatorv1beta1.DataPlane) map[string]string {
+		container := k8sutils.GetPodContainerByName(&dataplane.Spec.Deployment.PodTemplateSpec.Spec, consts.DataPlaneProxyContainerName)
+		require.NotNil(t, container)
+		env := make(map[string]string, len(container.Env))
+		for _, e := range container.Env {
+			env[e.Name] = e.Value
+		}
+		return env
+	}
+	requireClusterVolume := func(t *testing.T, dataplane *operatorv1beta1.DataPlane, secretName string) {
+		podSpec := dataplane.Spec.Deployment.PodTemplateSpec.Spec
+		var found bool
+		for _, v := range podSpec.Volumes {
+			if v.Name == consts.DataPlaneClusterCertificateVolumeName {
+				require.Equal(t, secretName, v.Secret.SecretName)
+				found = true
+			}
+		}
+		require.True(t, found, "the cluster certificate volume should be set")
+		require.Contains(t, podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      consts.DataPlaneClusterCertificateVolumeName,
+			ReadOnly:  true,
+			MountPath: consts.DataPlaneClusterCertificateMountPath,
+		})
+	}
+	reconcileUntilConfigured := func(t *testing.T, dataplane *operatorv1beta1.DataPlane) {
+		for i := 0; i < 5; i++ {
+			wait, err := reconciler.ensureClusterConfiguration(ctx, log, dataplane)
+			require.NoError(t, err)
+			if !wait {
+				return
+			}
+			getClusterCertificate(t, dataplane)
+		}
+		require.Fail(t, "the cluster configuration has not been completed")
+	}
+
+	t.Log("the data plane waits for its control plane to be exposed")
+	wait, err := reconciler.ensureClusterConfiguration(ctx, log, dataPlane)
+	require.NoError(t, err)
+	require.True(t, wait)
+	condition, ok := k8sutils.GetCondition(DataPlaneConditionTypeProvisioned, dataPlane)
+	require.True(t, ok)
+	require.Equal(t, string(DataPlaneConditionReasonClusterControlPlaneNotReady), condition.Reason)
+	require.Nil(t, getClusterCertificate(t, dataPlane))
+
+	t.Log("the control plane is exposed through the cluster Service")
+	reconcileUntilConfigured(t, controlPlane)
+	_, clusterService, err := reconciler.ensureClusterServiceForDataPlane(ctx, controlPlane)
+	require.NoError(t, err)
+	require.NotNil(t, clusterService)
+	require.Equal(t, []int32{consts.DataPlaneClusterPort, consts.DataPlaneClusterTelemetryPort},
+		[]int32{clusterService.Spec.Ports[0].Port, clusterService.Spec.Ports[1].Port})
+	clusterAddress := clusterServiceAddress(clusterService)
+
+	controlPlaneCert := getClusterCertificate(t, controlPlane)
+	require.NotNil(t, controlPlaneCert)
+	requireCertificateSubject(t, controlPlaneCert, clusterAddress)
+	requireClusterVolume(t, controlPlane, controlPlaneCert.Name)
+	env := proxyEnv(controlPlane)
+	require.Equal(t, "control_plane", env[consts.EnvVarKongRole])
+	require.Equal(t, "0.0.0.0:8005", env[consts.EnvVarKongClusterListen])
+	require.Equal(t, "0.0.0.0:8006", env[consts.EnvVarKongClusterTelemetryListen])
+	require.Equal(t, "pki", env[consts.EnvVarKongClusterMTLS])
+	require.NotContains(t, env, consts.EnvVarKongClusterControlPlane)
+
+	t.Log("the admin API certificate is not mistaken for the cluster certificate")
+	created, adminCert, err := reconciler.ensureCertificate(ctx, controlPlane, "kong-cp-admin")
+	require.NoError(t, err)
+	require.True(t, created)
+	require.NotEqual(t, controlPlaneCert.Name, adminCert.Name)
+	block, _ := pem.Decode([]byte(adminCert.StringData["tls.crt"]))
+	require.NotNil(t, block)
+	adminCertificate, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	require.Equal(t, []string{"*.kong-cp-admin.default.svc", consts.DataPlaneAdminAPITLSServerName}, adminCertificate.DNSNames,
+		"the admin API certificate should be valid for the name shared by all the DataPlanes")
+
+	t.Log("the cluster Service changes are mapped to the data planes connecting to it")
+	require.Equal(t,
+		[]reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: dataPlane.Name}}},
+		getDataPlanesFromClusterService(fakeClient)(ctx, clusterService),
+	)
+
+	t.Log("the data plane is configured to connect to the control plane")
+	reconcileUntilConfigured(t, dataPlane)
+	dataPlaneCert := getClusterCertificate(t, dataPlane)
+	require.NotNil(t, dataPlaneCert)
+	requireCertificateSubject(t, dataPlaneCert, "kong-dp.default.svc")
+	requireClusterVolume(t, dataPlane, dataPlaneCert.Name)
+	env = proxyEnv(dataPlane)
+	require.Equal(t, "data_plane", env[consts.EnvVarKongRole])
+	require.Equal(t, "off", env[consts.EnvVarKongClusterListen])
+	require.Equal(t, clusterAddress+":8005", env[consts.EnvVarKongClusterControlPlane])
+	require.Equal(t, clusterAddress, env[consts.EnvVarKongClusterServerName])
+	require.Equal(t, clusterAddress+":8006", env[consts.EnvVarKongClusterTelemetryEndpoint])
+	require.Equal(t, clusterAddress, env[consts.EnvVarKongClusterTelemetryServerName])
+
+	t.Log("removing the cluster options removes the cluster configuration")
+	dataPlane.Spec.Cluster = nil
+	require.NoError(t, fakeClient.Update(ctx, dataPlane))
+	reconcileUntilConfigured(t, dataPlane)
+	env = proxyEnv(dataPlane)
+	for _, name := range clusterEnvVarNames {
+		require.NotContains(t, env, name)
+	}
+	require.Empty(t, dataPlane.Spec.Deployment.PodTemplateSpec.Spec.Volumes)
+	require.Nil(t, getClusterCertificate(t, dataPlane))
+
+	t.Log("the control plane cluster Service is deleted with the control_plane role")
+	controlPlane.Spec.Cluster = nil
+	require.NoError(t, fakeClient.Update(ctx, controlPlane))
+	reconcileUntilConfigured(t, controlPlane)
+	_, clusterService, err = reconciler.ensureClusterServiceForDataPlane(ctx, controlPlane)
+	require.NoError(t, err)
+	require.Nil(t, clusterService)
+}
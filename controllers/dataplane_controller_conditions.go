@@ -14,6 +14,11 @@ const (
 	// not all Deployments (or Daemonsets) for the DataPlane have been provisioned
 	// successfully.
 	DataPlaneConditionTypeProvisioned k8sutils.ConditionType = "Provisioned"
+
+	// DataPlaneConditionTypeDatabaseMigrated is a condition type indicating whether
+	// or not the database migrations of a DataPlane backed by a database have been
+	// run for its current configuration. It is not set for DB-less DataPlanes.
+	DataPlaneConditionTypeDatabaseMigrated k8sutils.ConditionType = "DatabaseMigrated"
 )
 
 // -----------------------------------------------------------------------------
@@ -35,4 +40,76 @@ const (
 	// DataPlaneConditionValidationFailed is a reason which indicates validation of
 	// a dataplane is failed.
 	DataPlaneConditionValidationFailed k8sutils.ConditionReason = "ValidationFailed"
+
+	// DataPlaneConditionReasonClusterControlPlaneNotReady is a reason which indicates
+	// that a DataPlane with the data_plane cluster role waits for its control plane
+	// to be exposed.
+	DataPlaneConditionReasonClusterControlPlaneNotReady k8sutils.ConditionReason = "ClusterControlPlaneNotReady"
+)
+
+const (
+	// DataPlaneConditionReasonMigrationsInProgress is a reason which indicates
+	// that the Job running the database migrations has not completed yet.
+	DataPlaneConditionReasonMigrationsInProgress k8sutils.ConditionReason = "MigrationsInProgress"
+
+	// DataPlaneConditionReasonMigrationsFailed is a reason which indicates that
+	// a Job running the database migrations has failed.
+	DataPlaneConditionReasonMigrationsFailed k8sutils.ConditionReason = "MigrationsFailed"
+
+	// DataPlaneConditionReasonMigrationsUp is a reason which indicates that the
+	// database has been bootstrapped and migrated, and that the pending migrations
+	// will be finished once the DataPlane has been rolled out.
+	DataPlaneConditionReasonMigrationsUp k8sutils.ConditionReason = "MigrationsUp"
+
+	// DataPlaneConditionReasonMigrationsFinished is a reason which indicates that
+	// all the database migrations have been finished.
+	DataPlaneConditionReasonMigrationsFinished k8sutils.ConditionReason = "MigrationsFinished"
+)
+
+// -----------------------------------------------------------------------------
+// DataPlane - Rollout Status Condition Types and Reasons
+// -----------------------------------------------------------------------------
+
+const (
+	// DataPlaneConditionReasonRolloutProgressing is a reason which indicates that
+	// the preview resources are being created and are not ready yet.
+	DataPlaneConditionReasonRolloutProgressing k8sutils.ConditionReason = "PreviewProgressing"
+
+	// DataPlaneConditionReasonRolloutAwaitingPromotion is a reason which indicates
+	// that the preview resources are ready and the rollout is waiting to be promoted.
+	DataPlaneConditionReasonRolloutAwaitingPromotion k8sutils.ConditionReason = "AwaitingPromotion"
+
+	// DataPlaneConditionReasonRolloutPromotionInProgress is a reason which indicates
+	// that the preview resources are being promoted to live.
+	DataPlaneConditionReasonRolloutPromotionInProgress k8sutils.ConditionReason = "PromotionInProgress"
+
+	// DataPlaneConditionReasonRolloutPromotionDone is a reason which indicates
+	// that the promotion of the preview resources has been completed.
+	DataPlaneConditionReasonRolloutPromotionDone k8sutils.ConditionReason = "PromotionDone"
+
+	// DataPlaneConditionReasonRolloutRolledBack is a reason which indicates that
+	// the preview resources have been rolled back because a promotion check failed.
+	DataPlaneConditionReasonRolloutRolledBack k8sutils.ConditionReason = "RolledBack"
+
+	// DataPlaneConditionReasonRolloutCanaryProgressing is a reason which indicates
+	// that the replicas of a Canary rollout step are being scaled.
+	DataPlaneConditionReasonRolloutCanaryProgressing k8sutils.ConditionReason = "CanaryProgressing"
+
+	// DataPlaneConditionReasonRolloutCanaryPaused is a reason which indicates
+	// that a Canary rollout is paused after a step has been completed.
+	DataPlaneConditionReasonRolloutCanaryPaused k8sutils.ConditionReason = "CanaryPaused"
+)
+
+const (
+	// DataPlaneConditionReasonPromotionCheckPending is a reason which indicates
+	// that a promotion check has not passed yet.
+	DataPlaneConditionReasonPromotionCheckPending k8sutils.ConditionReason = "CheckPending"
+
+	// DataPlaneConditionReasonPromotionCheckPassed is a reason which indicates
+	// that a promotion check has passed.
+	DataPlaneConditionReasonPromotionCheckPassed k8sutils.ConditionReason = "CheckPassed"
+
+	// DataPlaneConditionReasonPromotionCheckFailed is a reason which indicates
+	// that a promotion check has failed.
+	DataPlaneConditionReasonPromotionCheckFailed k8sutils.ConditionReason = "CheckFailed"
 )
@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sreduce "github.com/kong/gateway-operator/internal/utils/kubernetes/reduce"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
+)
+
+// -----------------------------------------------------------------------------
+// DataPlaneReconciler - Database migrations
+// -----------------------------------------------------------------------------
+
+// ensureDatabaseMigrationsUp ensures that the database of the DataPlane has been
+// bootstrapped and migrated for the DataPlane's current configuration, before
+// the DataPlane's Deployment gets created or updated.
+// It returns true when the DataPlane's Deployment can be reconciled, i.e. when
+// the DataPlane is DB-less or the migrations have been completed.
+// The migrations state is reported in the DatabaseMigrated condition.
+func (r *DataPlaneReconciler) ensureDatabaseMigrationsUp(
+	ctx context.Context,
+	log logr.Logger,
+	dataplane *operatorv1beta1.DataPlane,
+	certSecretName string,
+) (bool, error) {
+	if !isDatabaseEnabled(dataplane) {
+		deleted, err := r.ensureMigrationsJobsDeleted(ctx, dataplane)
+		if err != nil {
+			return false, err
+		}
+		if deleted {
+			debug(log, "database migrations Jobs deleted", dataplane)
+			return false, nil // the deletion of the owned objects will trigger reconciliation
+		}
+		if _, ok := k8sutils.GetCondition(DataPlaneConditionTypeDatabaseMigrated, dataplane); ok {
+			k8sutils.RemoveCondition(DataPlaneConditionTypeDatabaseMigrated, dataplane)
+			return false, r.patchStatus(ctx, log, dataplane)
+		}
+		return true, nil
+	}
+
+	res, job, err := r.ensureMigrationsJobForDataPlane(ctx, dataplane, certSecretName, consts.DataPlaneMigrationsLabelValueUp)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case res == Updated:
+		// An outdated Job has been deleted, the migrations have to be run again.
+		setDatabaseMigratedCondition(dataplane, metav1.ConditionFalse, DataPlaneConditionReasonMigrationsInProgress,
+			"database migrations are being run")
+		return false, r.patchStatus(ctx, log, dataplane)
+	case isJobFailed(job):
+		r.eventRecorder.Event(dataplane, corev1.EventTypeWarning, string(DataPlaneConditionReasonMigrationsFailed), jobFailureMessage(job))
+		setDatabaseMigratedCondition(dataplane, metav1.ConditionFalse, DataPlaneConditionReasonMigrationsFailed, jobFailureMessage(job))
+		return false, r.patchStatus(ctx, log, dataplane)
+	case !isJobComplete(job):
+		setDatabaseMigratedCondition(dataplane, metav1.ConditionFalse, DataPlaneConditionReasonMigrationsInProgress,
+			"database migrations are being run")
+		return false, r.patchStatus(ctx, log, dataplane)
+	}
+
+	// The migrations might have been finished already for the current configuration.
+	if !k8sutils.IsValidCondition(DataPlaneConditionTypeDatabaseMigrated, dataplane) {
+		setDatabaseMigratedCondition(dataplane, metav1.ConditionTrue, DataPlaneConditionReasonMigrationsUp,
+			"database migrations are up, waiting for the DataPlane to be rolled out to finish them")
+	}
+	return true, nil
+}
+
+// ensureDatabaseMigrationsFinished finishes the pending database migrations once
+// the provided live Deployment has rolled out the DataPlane's current configuration.
+// The migrations state is reported in the DatabaseMigrated condition.
+func (r *DataPlaneReconciler) ensureDatabaseMigrationsFinished(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+	deployment *appsv1.Deployment,
+	certSecretName string,
+) error {
+	if !isDatabaseEnabled(dataplane) {
+		return nil
+	}
+
+	// Pending migrations can only be finished when no pods are running the
+	// previous configuration anymore, which is not the case while a rollout
+	// holds the live Deployment.
+	dataplaneImage, err := r.dataplaneImage(dataplane)
+	if err != nil {
+		return err
+	}
+	desiredDeployment, err := k8sresources.GenerateNewDeploymentForDataPlane(dataplane, dataplaneImage, certSecretName)
+	if err != nil {
+		return err
+	}
+	if !podTemplateSpecsEqual(deployment.Spec.Template, desiredDeployment.Spec.Template) || !k8sutils.IsDeploymentRolledOut(deployment) {
+		return nil
+	}
+
+	res, job, err := r.ensureMigrationsJobForDataPlane(ctx, dataplane, certSecretName, consts.DataPlaneMigrationsLabelValueFinish)
+	if err != nil {
+		return err
+	}
+	switch {
+	case res == Updated:
+		// the deletion of the outdated Job will trigger reconciliation
+	case isJobFailed(job):
+		r.eventRecorder.Event(dataplane, corev1.EventTypeWarning, string(DataPlaneConditionReasonMigrationsFailed), jobFailureMessage(job))
+		setDatabaseMigratedCondition(dataplane, metav1.ConditionFalse, DataPlaneConditionReasonMigrationsFailed, jobFailureMessage(job))
+	case isJobComplete(job):
+		setDatabaseMigratedCondition(dataplane, metav1.ConditionTrue, DataPlaneConditionReasonMigrationsFinished,
+			"database migrations are finished")
+	}
+	return nil
+}
+
+// ensureMigrationsJobForDataPlane ensures that a Job runs the provided migrations
+// step for the DataPlane's current image and database. Since the pod template of
+// Jobs is immutable, outdated Jobs are deleted instead of being updated and
+// their deletion is reported as an update.
+func (r *DataPlaneReconciler) ensureMigrationsJobForDataPlane(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+	certSecretName string,
+	step string,
+) (res CreatedUpdatedOrNoop, job *batchv1.Job, err error) {
+	jobs, err := k8sutils.ListJobsForOwner(
+		ctx,
+		r.Client,
+		dataplane.Namespace,
+		dataplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+			consts.DataPlaneMigrationsLabel:       step,
+		},
+	)
+	if err != nil {
+		return Noop, nil, err
+	}
+
+	count := len(jobs)
+	if count > 1 {
+		if err := k8sreduce.ReduceJobs(ctx, r.Client, jobs); err != nil {
+			return Noop, nil, err
+		}
+		return Updated, nil, errors.New("number of migrations Jobs reduced")
+	}
+
+	dataplaneImage, err := r.dataplaneImage(dataplane)
+	if err != nil {
+		return Noop, nil, err
+	}
+	generatedJob, err := k8sresources.GenerateNewMigrationsJobForDataPlane(dataplane, dataplaneImage, certSecretName, step)
+	if err != nil {
+		return Noop, nil, err
+	}
+	revision, err := migrationsRevision(dataplaneImage, dataplane.Spec.Database)
+	if err != nil {
+		return Noop, nil, err
+	}
+	generatedJob.Labels[consts.DataPlaneMigrationsRevisionLabel] = revision
+	k8sutils.SetOwnerForObject(generatedJob, dataplane)
+	addLabelForDataplane(generatedJob)
+
+	if count == 1 {
+		existingJob := &jobs[0]
+		if existingJob.Labels[consts.DataPlaneMigrationsRevisionLabel] != revision {
+			if err := r.Client.Delete(ctx, existingJob, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+				return Noop, existingJob, fmt.Errorf("failed deleting outdated DataPlane migrations Job %s: %w", existingJob.Name, err)
+			}
+			return Updated, existingJob, nil
+		}
+		return Noop, existingJob, nil
+	}
+
+	return Created, generatedJob, r.Client.Create(ctx, generatedJob)
+}
+
+// ensureMigrationsJobsDeleted deletes all the migrations Jobs of the DataPlane.
+// It returns true when any Job has been deleted.
+func (r *DataPlaneReconciler) ensureMigrationsJobsDeleted(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (bool, error) {
+	jobs, err := k8sutils.ListJobsForOwner(
+		ctx,
+		r.Client,
+		dataplane.Namespace,
+		dataplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+		},
+		client.HasLabels{consts.DataPlaneMigrationsLabel},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	for _, job := range jobs {
+		job := job
+		if err := r.Client.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("failed deleting DataPlane migrations Job %s: %w", job.Name, err)
+		}
+	}
+	return len(jobs) > 0, nil
+}
+
+// -----------------------------------------------------------------------------
+// Database migrations - Private Functions
+// -----------------------------------------------------------------------------
+
+// migrationsRevision returns the revision of the database migrations run for
+// the provided image and database options. The migrations only depend on them:
+// the other changes to the DataPlane's pod template, e.g. to its resources or
+// to its certificate, do not require them to be run again.
+func migrationsRevision(dataplaneImage string, database *operatorv1beta1.DataPlaneDatabaseOptions) (string, error) {
+	b, err := json.Marshal(struct {
+		Image    string                                    `json:"image"`
+		Database *operatorv1beta1.DataPlaneDatabaseOptions `json:"database"`
+	}{
+		Image:    dataplaneImage,
+		Database: database,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed marshaling database migrations options: %w", err)
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write(b)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())), nil
+}
+
+func isDatabaseEnabled(dataplane *operatorv1beta1.DataPlane) bool {
+	return dataplane.Spec.Database != nil && dataplane.Spec.Database.Postgres != nil
+}
+
+// isDatabaseMigrated returns true when the DataPlane is DB-less or its database
+// migrations are up for its current configuration.
+func isDatabaseMigrated(dataplane *operatorv1beta1.DataPlane) bool {
+	condition, ok := k8sutils.GetCondition(DataPlaneConditionTypeDatabaseMigrated, dataplane)
+	return !ok || condition.Status == metav1.ConditionTrue
+}
+
+func setDatabaseMigratedCondition(
+	dataplane *operatorv1beta1.DataPlane,
+	status metav1.ConditionStatus,
+	reason k8sutils.ConditionReason,
+	message string,
+) {
+	k8sutils.SetCondition(
+		k8sutils.NewConditionWithGeneration(DataPlaneConditionTypeDatabaseMigrated, status, reason, message, dataplane.Generation),
+		dataplane,
+	)
+}
+
+func isJobComplete(job *batchv1.Job) bool {
+	return jobConditionIsTrue(job, batchv1.JobComplete)
+}
+
+func isJobFailed(job *batchv1.Job) bool {
+	return jobConditionIsTrue(job, batchv1.JobFailed)
+}
+
+func jobConditionIsTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == conditionType {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func jobFailureMessage(job *batchv1.Job) string {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Message != "" {
+			return fmt.Sprintf("database migrations Job %s failed: %s", job.Name, c.Message)
+		}
+	}
+	return fmt.Sprintf("database migrations Job %s failed", job.Name)
+}
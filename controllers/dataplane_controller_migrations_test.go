@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
+)
+
+func TestDataPlaneDatabaseMigrations(t *testing.T) {
+	const certSecretName = "dataplane-tls-secret"
+
+	ctx := context.Background()
+	dataplane := &operatorv1beta1.DataPlane{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "gateway-operator.konghq.com/v1beta1",
+			Kind:       "DataPlane",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dataplane-postgres",
+			Namespace: "default",
+			UID:       types.UID(uuid.NewString()),
+		},
+		Spec: operatorv1beta1.DataPlaneSpec{
+			DataPlaneOptions: operatorv1beta1.DataPlaneOptions{
+				Database: &operatorv1beta1.DataPlaneDatabaseOptions{
+					Postgres: &operatorv1beta1.PostgresDatabaseOptions{SecretName: "kong-postgres"},
+				},
+				Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+					DeploymentOptions: operatorv1beta1.DeploymentOptions{
+						PodTemplateSpec: &corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{
+									{
+										Name:  consts.DataPlaneProxyContainerName,
+										Image: "kong:3.2",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(dataplane).
+		WithStatusSubresource(dataplane).
+		Build()
+	reconciler := DataPlaneReconciler{
+		Client:          fakeClient,
+		eventRecorder:   record.NewFakeRecorder(10),
+		DevelopmentMode: true,
+	}
+	log := logr.Discard()
+
+	getJob := func(t *testing.T, step string) *batchv1.Job {
+		var jobs batchv1.JobList
+		require.NoError(t, fakeClient.List(ctx, &jobs))
+		for i := range jobs.Items {
+			if jobs.Items[i].Labels[consts.DataPlaneMigrationsLabel] == step {
+				return &jobs.Items[i]
+			}
+		}
+		return nil
+	}
+	completeJob := func(t *testing.T, job *batchv1.Job) {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		require.NoError(t, fakeClient.Status().Update(ctx, job))
+	}
+	requireCondition := func(t *testing.T, status metav1.ConditionStatus, reason k8sutils.ConditionReason) {
+		condition, ok := k8sutils.GetCondition(DataPlaneConditionTypeDatabaseMigrated, dataplane)
+		require.True(t, ok)
+		require.Equal(t, status, condition.Status)
+		require.Equal(t, string(reason), condition.Reason)
+	}
+
+	t.Log("the up Job is created and blocks the Deployment until it completes")
+	migrated, err := reconciler.ensureDatabaseMigrationsUp(ctx, log, dataplane, certSecretName)
+	require.NoError(t, err)
+	require.False(t, migrated)
+	requireCondition(t, metav1.ConditionFalse, DataPlaneConditionReasonMigrationsInProgress)
+	upJob := getJob(t, consts.DataPlaneMigrationsLabelValueUp)
+	require.NotNil(t, upJob)
+	require.False(t, isDatabaseMigrated(dataplane))
+
+	completeJob(t, upJob)
+	migrated, err = reconciler.ensureDatabaseMigrationsUp(ctx, log, dataplane, certSecretName)
+	require.NoError(t, err)
+	require.True(t, migrated)
+	requireCondition(t, metav1.ConditionTrue, DataPlaneConditionReasonMigrationsUp)
+
+	t.Log("the finish Job is not created until the Deployment has been rolled out")
+	deployment, err := k8sresources.GenerateNewDeploymentForDataPlane(dataplane, "kong:3.2", certSecretName)
+	require.NoError(t, err)
+	deployment.Spec.Replicas = nil
+	require.NoError(t, reconciler.ensureDatabaseMigrationsFinished(ctx, dataplane, deployment, certSecretName))
+	require.Nil(t, getJob(t, consts.DataPlaneMigrationsLabelValueFinish))
+
+	deployment.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	require.NoError(t, reconciler.ensureDatabaseMigrationsFinished(ctx, dataplane, deployment, certSecretName))
+	finishJob := getJob(t, consts.DataPlaneMigrationsLabelValueFinish)
+	require.NotNil(t, finishJob)
+	requireCondition(t, metav1.ConditionTrue, DataPlaneConditionReasonMigrationsUp)
+
+	completeJob(t, finishJob)
+	require.NoError(t, reconciler.ensureDatabaseMigrationsFinished(ctx, dataplane, deployment, certSecretName))
+	requireCondition(t, metav1.ConditionTrue, DataPlaneConditionReasonMigrationsFinished)
+
+	migrated, err = reconciler.ensureDatabaseMigrationsUp(ctx, log, dataplane, certSecretName)
+	require.NoError(t, err)
+	require.True(t, migrated)
+	requireCondition(t, metav1.ConditionTrue, DataPlaneConditionReasonMigrationsFinished)
+
+	t.Log("changing the rest of the pod template does not require the migrations to be run again")
+	dataplane.Spec.Deployment.PodTemplateSpec.Spec.Containers[0].Resources = corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+	}
+	require.NoError(t, fakeClient.Update(ctx, dataplane))
+	migrated, err = reconciler.ensureDatabaseMigrationsUp(ctx, log, dataplane, "rotated-tls-secret")
+	require.NoError(t, err)
+	require.True(t, migrated)
+	require.Equal(t, upJob.Name, getJob(t, consts.DataPlaneMigrationsLabelValueUp).Name)
+
+	t.Log("changing the image requires the migrations to be run again")
+	dataplane.Spec.Deployment.PodTemplateSpec.Spec.Containers[0].Image = "kong:3.3"
+	require.NoError(t, fakeClient.Update(ctx, dataplane))
+	migrated, err = reconciler.ensureDatabaseMigrationsUp(ctx, log, dataplane, certSecretName)
+	require.NoError(t, err)
+	require.False(t, migrated)
+	requireCondition(t, metav1.ConditionFalse, DataPlaneConditionReasonMigrationsInProgress)
+	require.Nil(t, getJob(t, consts.DataPlaneMigrationsLabelValueUp), "the outdated up Job should be deleted")
+
+	migrated, err = reconciler.ensureDatabaseMigrationsUp(ctx, log, dataplane, certSecretName)
+	require.NoError(t, err)
+	require.False(t, migrated)
+	upJob = getJob(t, consts.DataPlaneMigrationsLabelValueUp)
+	require.NotNil(t, upJob)
+	require.Equal(t, "kong:3.3", upJob.Spec.Template.Spec.Containers[0].Image)
+
+	t.Log("a failed Job is reported in the condition")
+	upJob.Status.Conditions = []batchv1.JobCondition{{
+		Type:    batchv1.JobFailed,
+		Status:  corev1.ConditionTrue,
+		Message: "Job has reached the specified backoff limit",
+	}}
+	require.NoError(t, fakeClient.Status().Update(ctx, upJob))
+	migrated, err = reconciler.ensureDatabaseMigrationsUp(ctx, log, dataplane, certSecretName)
+	require.NoError(t, err)
+	require.False(t, migrated)
+	requireCondition(t, metav1.ConditionFalse, DataPlaneConditionReasonMigrationsFailed)
+
+	t.Log("removing the database deletes the Jobs and the condition")
+	dataplane.Spec.Database = nil
+	require.NoError(t, fakeClient.Update(ctx, dataplane))
+	migrated, err = reconciler.ensureDatabaseMigrationsUp(ctx, log, dataplane, certSecretName)
+	require.NoError(t, err)
+	require.False(t, migrated)
+	require.Nil(t, getJob(t, consts.DataPlaneMigrationsLabelValueUp))
+	require.Nil(t, getJob(t, consts.DataPlaneMigrationsLabelValueFinish))
+
+	migrated, err = reconciler.ensureDatabaseMigrationsUp(ctx, log, dataplane, certSecretName)
+	require.NoError(t, err)
+	require.False(t, migrated)
+	_, ok := k8sutils.GetCondition(DataPlaneConditionTypeDatabaseMigrated, dataplane)
+	require.False(t, ok)
+
+	migrated, err = reconciler.ensureDatabaseMigrationsUp(ctx, log, dataplane, certSecretName)
+	require.NoError(t, err)
+	require.True(t, migrated)
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	certificatesv1 "k8s.io/api/certificates/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -19,7 +21,6 @@ import (
 	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
 	k8sreduce "github.com/kong/gateway-operator/internal/utils/kubernetes/reduce"
 	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
-	"github.com/kong/gateway-operator/internal/versions"
 )
 
 // -----------------------------------------------------------------------------
@@ -66,6 +67,9 @@ func (r *DataPlaneReconciler) ensureReadinessStatus(
 
 	dataplane.Status.Replicas = dataplaneDeployment.Status.Replicas
 	dataplane.Status.ReadyReplicas = dataplaneDeployment.Status.ReadyReplicas
+	if selector, err := metav1.LabelSelectorAsSelector(dataplaneDeployment.Spec.Selector); err == nil {
+		dataplane.Status.Selector = selector.String()
+	}
 }
 
 func addressOf[T any](v T) *T {
@@ -165,6 +169,14 @@ func (r *DataPlaneReconciler) ensureDataPlaneIsMarkedNotProvisioned(
 // DataPlaneReconciler - Owned Resource Management
 // -----------------------------------------------------------------------------
 
+// certificateIssuer returns the issuer of the certificates of the DataPlanes.
+func (r *DataPlaneReconciler) certificateIssuer() certificateIssuer {
+	return newCertificateIssuer(r.Client, types.NamespacedName{
+		Namespace: r.ClusterCASecretNamespace,
+		Name:      r.ClusterCASecretName,
+	}, r.CertificateOptions)
+}
+
 func (r *DataPlaneReconciler) ensureCertificate(
 	ctx context.Context,
 	dataplane *operatorv1beta1.DataPlane,
@@ -174,15 +186,14 @@ func (r *DataPlaneReconciler) ensureCertificate(
 		certificatesv1.UsageKeyEncipherment,
 		certificatesv1.UsageDigitalSignature, certificatesv1.UsageServerAuth,
 	}
-	return maybeCreateCertificateSecret(ctx,
-		dataplane,
-		fmt.Sprintf("*.%s.%s.svc", adminServiceName, dataplane.Namespace),
-		types.NamespacedName{
-			Namespace: r.ClusterCASecretNamespace,
-			Name:      r.ClusterCASecretName,
-		},
-		usages,
-		r.Client)
+	return r.certificateIssuer().ensureCertificateSecret(ctx, certificateRequest{
+		owner:   dataplane,
+		subject: fmt.Sprintf("*.%s.%s.svc", adminServiceName, dataplane.Namespace),
+		// The ControlPlanes linked to several DataPlanes reach the admin API
+		// through their own admin Service, hence through another name.
+		dnsNames: []string{consts.DataPlaneAdminAPITLSServerName},
+		usages:   usages,
+	})
 }
 
 type CreatedUpdatedOrNoop byte
@@ -198,14 +209,18 @@ func (r *DataPlaneReconciler) ensureDeploymentForDataPlane(
 	dataplane *operatorv1beta1.DataPlane,
 	certSecretName string,
 ) (res CreatedUpdatedOrNoop, deploy *appsv1.Deployment, err error) {
+	selector, err := dataplaneLiveResourcesSelector(consts.DataPlaneDeploymentStateLabel, map[string]string{
+		consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+	})
+	if err != nil {
+		return Noop, nil, err
+	}
 	deployments, err := k8sutils.ListDeploymentsForOwner(
 		ctx,
 		r.Client,
 		dataplane.Namespace,
 		dataplane.UID,
-		client.MatchingLabels{
-			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
-		},
+		selector,
 	)
 	if err != nil {
 		return Noop, nil, err
@@ -219,11 +234,7 @@ func (r *DataPlaneReconciler) ensureDeploymentForDataPlane(
 		return Updated, nil, errors.New("number of deployments reduced")
 	}
 
-	versionValidationOptions := make([]versions.VersionValidationOption, 0)
-	if !r.DevelopmentMode {
-		versionValidationOptions = append(versionValidationOptions, versions.IsDataPlaneImageVersionSupported)
-	}
-	dataplaneImage, err := generateDataPlaneImage(dataplane, versionValidationOptions...)
+	dataplaneImage, err := r.dataplaneImage(dataplane)
 	if err != nil {
 		return Noop, nil, err
 	}
@@ -233,28 +244,31 @@ func (r *DataPlaneReconciler) ensureDeploymentForDataPlane(
 	}
 	k8sutils.SetOwnerForObject(generatedDeployment, dataplane)
 	addLabelForDataplane(generatedDeployment)
+	checksum, err := certificatesChecksum(ctx, r.Client, dataplane)
+	if err != nil {
+		return Noop, nil, err
+	}
+	setCertificatesChecksum(generatedDeployment, checksum)
 
 	if count == 1 {
 		var updated bool
 		existingDeployment := &deployments[0]
 
+		// a Canary rollout scales the live Deployment down as the canary one is scaled up.
+		if replicas, ok := liveDeploymentReplicasForRollout(dataplane); ok {
+			generatedDeployment.Spec.Replicas = &replicas
+		} else if isHorizontalScalingEnabled(dataplane) {
+			// otherwise the HorizontalPodAutoscaler owns the replicas.
+			generatedDeployment.Spec.Replicas = existingDeployment.Spec.Replicas
+		}
+
 		// ensure that object metadata is up to date
 		updated, existingDeployment.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingDeployment.ObjectMeta, generatedDeployment.ObjectMeta)
 
-		// some custom comparison rules are needed for some PodTemplateSpec sub-attributes, in particular
-		// resources and affinity.
-		opts := []cmp.Option{
-			cmp.Comparer(func(a, b corev1.ResourceRequirements) bool { return k8sresources.ResourceRequirementsEqual(a, b) }),
-		}
-
-		// ensure that PodTemplateSpec is up to date
-		// TODO: this is currently relying on us pre-empting API server defaults (by setting them ourselves).
-		// This could in theory lead to situations of incompatibility with newer Kubernetes versions down the
-		// road, the tradeoff was made due to a time crunch of a matter of hours. We should consider other options
-		// to verify whether there are changes staged.
-		//
-		// See: https://github.com/Kong/gateway-operator/issues/904
-		if !cmp.Equal(existingDeployment.Spec.Template, generatedDeployment.Spec.Template, opts...) {
+		// ensure that PodTemplateSpec is up to date, unless a Blue Green rollout
+		// holds the live Deployment until the preview gets promoted.
+		if !podTemplateSpecsEqual(existingDeployment.Spec.Template, generatedDeployment.Spec.Template) &&
+			!isLiveDeploymentHeldByRollout(dataplane) {
 			existingDeployment.Spec.Template = generatedDeployment.Spec.Template
 			updated = true
 		}
@@ -283,24 +297,168 @@ func (r *DataPlaneReconciler) ensureDeploymentForDataPlane(
 	return Created, generatedDeployment, r.Client.Create(ctx, generatedDeployment)
 }
 
-func (r *DataPlaneReconciler) ensureProxyServiceForDataPlane(
+// ensureHPAForDataPlane ensures that a HorizontalPodAutoscaler scales the
+// provided live Deployment when horizontal scaling is enabled for the DataPlane
+// and that it is deleted otherwise. The HorizontalPodAutoscaler is also deleted
+// while a Canary rollout drives the live Deployment's replicas.
+// The deletion of HorizontalPodAutoscalers is reported as an update.
+func (r *DataPlaneReconciler) ensureHPAForDataPlane(
 	ctx context.Context,
 	dataplane *operatorv1beta1.DataPlane,
-) (createdOrUpdated bool, svc *corev1.Service, err error) {
-	services, err := k8sutils.ListServicesForOwner(
+	deploymentName string,
+) (res CreatedUpdatedOrNoop, hpa *autoscalingv2.HorizontalPodAutoscaler, err error) {
+	hpas, err := k8sutils.ListHPAsForOwner(
+		ctx,
+		r.Client,
+		dataplane.Namespace,
+		dataplane.UID,
+		client.MatchingLabels{
+			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+		},
+	)
+	if err != nil {
+		return Noop, nil, err
+	}
+
+	if _, rolloutInProgress := liveDeploymentReplicasForRollout(dataplane); !isHorizontalScalingEnabled(dataplane) || rolloutInProgress {
+		for _, hpa := range hpas {
+			hpa := hpa
+			if err := r.Client.Delete(ctx, &hpa); client.IgnoreNotFound(err) != nil {
+				return Noop, nil, fmt.Errorf("failed deleting DataPlane HorizontalPodAutoscaler %s: %w", hpa.Name, err)
+			}
+		}
+		if len(hpas) > 0 {
+			return Updated, nil, nil
+		}
+		return Noop, nil, nil
+	}
+
+	count := len(hpas)
+	if count > 1 {
+		if err := k8sreduce.ReduceHPAs(ctx, r.Client, hpas); err != nil {
+			return Noop, nil, err
+		}
+		return Updated, nil, errors.New("number of HorizontalPodAutoscalers reduced")
+	}
+
+	generatedHPA, err := k8sresources.GenerateNewHorizontalPodAutoscalerForDataPlane(dataplane, deploymentName)
+	if err != nil {
+		return Noop, nil, err
+	}
+	k8sutils.SetOwnerForObject(generatedHPA, dataplane)
+	addLabelForDataplane(generatedHPA)
+
+	if count == 1 {
+		var updated bool
+		existingHPA := &hpas[0]
+		updated, existingHPA.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingHPA.ObjectMeta, generatedHPA.ObjectMeta)
+
+		if !cmp.Equal(existingHPA.Spec, generatedHPA.Spec) {
+			existingHPA.Spec = generatedHPA.Spec
+			updated = true
+		}
+
+		if updated {
+			if err := r.Client.Update(ctx, existingHPA); err != nil {
+				return Noop, existingHPA, fmt.Errorf("failed updating DataPlane HorizontalPodAutoscaler %s: %w", existingHPA.Name, err)
+			}
+			return Updated, existingHPA, nil
+		}
+		return Noop, existingHPA, nil
+	}
+
+	return Created, generatedHPA, r.Client.Create(ctx, generatedHPA)
+}
+
+// ensurePodDisruptionBudgetForDataPlane ensures that a PodDisruptionBudget covers
+// the DataPlane's pods when PodDisruptionBudget options are set, and that it
+// is deleted otherwise. The deletion is reported as an update.
+func (r *DataPlaneReconciler) ensurePodDisruptionBudgetForDataPlane(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (createdOrUpdated bool, pdb *policyv1.PodDisruptionBudget, err error) {
+	pdbs, err := k8sutils.ListPodDisruptionBudgetsForOwner(
 		ctx,
 		r.Client,
 		dataplane.Namespace,
 		dataplane.UID,
 		client.MatchingLabels{
 			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
-			consts.DataPlaneServiceTypeLabel:      string(consts.DataPlaneProxyServiceLabelValue),
 		},
 	)
 	if err != nil {
 		return false, nil, err
 	}
 
+	if dataplane.Spec.Deployment.PodDisruptionBudget == nil {
+		for _, pdb := range pdbs {
+			pdb := pdb
+			if err := r.Client.Delete(ctx, &pdb); client.IgnoreNotFound(err) != nil {
+				return false, nil, fmt.Errorf("failed deleting DataPlane PodDisruptionBudget %s: %w", pdb.Name, err)
+			}
+		}
+		return len(pdbs) > 0, nil, nil
+	}
+
+	count := len(pdbs)
+	if count > 1 {
+		if err := k8sreduce.ReducePodDisruptionBudgets(ctx, r.Client, pdbs); err != nil {
+			return false, nil, err
+		}
+		return false, nil, errors.New("number of PodDisruptionBudgets reduced")
+	}
+
+	generatedPDB, err := k8sresources.GenerateNewPodDisruptionBudgetForDataPlane(dataplane)
+	if err != nil {
+		return false, nil, err
+	}
+	k8sutils.SetOwnerForObject(generatedPDB, dataplane)
+	addLabelForDataplane(generatedPDB)
+
+	if count == 1 {
+		var updated bool
+		existingPDB := &pdbs[0]
+		updated, existingPDB.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingPDB.ObjectMeta, generatedPDB.ObjectMeta)
+
+		if !cmp.Equal(existingPDB.Spec, generatedPDB.Spec) {
+			existingPDB.Spec = generatedPDB.Spec
+			updated = true
+		}
+
+		if updated {
+			if err := r.Client.Update(ctx, existingPDB); err != nil {
+				return false, existingPDB, fmt.Errorf("failed updating DataPlane PodDisruptionBudget %s: %w", existingPDB.Name, err)
+			}
+			return true, existingPDB, nil
+		}
+		return false, existingPDB, nil
+	}
+
+	return true, generatedPDB, r.Client.Create(ctx, generatedPDB)
+}
+
+func (r *DataPlaneReconciler) ensureProxyServiceForDataPlane(
+	ctx context.Context,
+	dataplane *operatorv1beta1.DataPlane,
+) (createdOrUpdated bool, svc *corev1.Service, err error) {
+	selector, err := dataplaneLiveResourcesSelector(consts.DataPlaneServiceStateLabel, map[string]string{
+		consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+		consts.DataPlaneServiceTypeLabel:      string(consts.DataPlaneProxyServiceLabelValue),
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	services, err := k8sutils.ListServicesForOwner(
+		ctx,
+		r.Client,
+		dataplane.Namespace,
+		dataplane.UID,
+		selector,
+	)
+	if err != nil {
+		return false, nil, err
+	}
+
 	count := len(services)
 	if count > 1 {
 		if err := k8sreduce.ReduceServices(ctx, r.Client, services); err != nil {
@@ -313,6 +471,10 @@ func (r *DataPlaneReconciler) ensureProxyServiceForDataPlane(
 	if err != nil {
 		return false, nil, err
 	}
+	// a Blue Green promotion switches the traffic to the preview pods.
+	if selector, ok := liveServicesSelectorForRollout(dataplane); ok {
+		generatedService.Spec.Selector = selector
+	}
 	addLabelForDataplane(generatedService)
 	addAnnotationsForDataplaneProxyService(generatedService, *dataplane)
 	k8sutils.SetOwnerForObject(generatedService, dataplane)
@@ -344,6 +506,9 @@ func (r *DataPlaneReconciler) ensureProxyServiceForDataPlane(
 			existingService.Spec.Selector = generatedService.Spec.Selector
 			updated = true
 		}
+		if ensureServicePortsAreUpdated(existingService, generatedService) {
+			updated = true
+		}
 
 		if updated {
 			if err := r.Client.Update(ctx, existingService); err != nil {
@@ -361,15 +526,19 @@ func (r *DataPlaneReconciler) ensureAdminServiceForDataPlane(
 	ctx context.Context,
 	dataplane *operatorv1beta1.DataPlane,
 ) (createdOrUpdated bool, svc *corev1.Service, err error) {
+	selector, err := dataplaneLiveResourcesSelector(consts.DataPlaneServiceStateLabel, map[string]string{
+		consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+		consts.DataPlaneServiceTypeLabel:      string(consts.DataPlaneAdminServiceLabelValue),
+	})
+	if err != nil {
+		return false, nil, err
+	}
 	services, err := k8sutils.ListServicesForOwner(
 		ctx,
 		r.Client,
 		dataplane.Namespace,
 		dataplane.UID,
-		client.MatchingLabels{
-			consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
-			consts.DataPlaneServiceTypeLabel:      string(consts.DataPlaneAdminServiceLabelValue),
-		},
+		selector,
 	)
 	if err != nil {
 		return false, nil, err
@@ -387,6 +556,10 @@ func (r *DataPlaneReconciler) ensureAdminServiceForDataPlane(
 	if err != nil {
 		return false, nil, err
 	}
+	// a Blue Green promotion switches the traffic to the preview pods.
+	if selector, ok := liveServicesSelectorForRollout(dataplane); ok {
+		generatedService.Spec.Selector = selector
+	}
 	addLabelForDataplane(generatedService)
 	k8sutils.SetOwnerForObject(generatedService, dataplane)
 
@@ -6,11 +6,15 @@ import (
 	"os"
 	"testing"
 
+	"github.com/google/uuid"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -428,3 +432,89 @@ func TestEnsureDeploymentForDataPlane(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsurePodDisruptionBudgetForDataPlane(t *testing.T) {
+	ctx := context.Background()
+	dataplane := &operatorv1beta1.DataPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			UID:       types.UID(uuid.NewString()),
+		},
+		Spec: operatorv1beta1.DataPlaneSpec{
+			DataPlaneOptions: operatorv1beta1.DataPlaneOptions{
+				Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+					PodDisruptionBudget: &operatorv1beta1.PodDisruptionBudget{
+						MinAvailable: lo.ToPtr(intstr.FromInt(1)),
+					},
+				},
+			},
+		},
+	}
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(dataplane).
+		Build()
+	reconciler := DataPlaneReconciler{Client: fakeClient}
+
+	createdOrUpdated, pdb, err := reconciler.ensurePodDisruptionBudgetForDataPlane(ctx, dataplane)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	require.Equal(t, lo.ToPtr(intstr.FromInt(1)), pdb.Spec.MinAvailable)
+	require.Equal(t, map[string]string{"app": "test"}, pdb.Spec.Selector.MatchLabels)
+
+	createdOrUpdated, _, err = reconciler.ensurePodDisruptionBudgetForDataPlane(ctx, dataplane)
+	require.NoError(t, err)
+	require.False(t, createdOrUpdated, "an up to date PodDisruptionBudget should not be updated")
+
+	dataplane.Spec.Deployment.PodDisruptionBudget = &operatorv1beta1.PodDisruptionBudget{
+		MaxUnavailable: lo.ToPtr(intstr.FromString("25%")),
+	}
+	createdOrUpdated, pdb, err = reconciler.ensurePodDisruptionBudgetForDataPlane(ctx, dataplane)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated)
+	require.Nil(t, pdb.Spec.MinAvailable)
+	require.Equal(t, lo.ToPtr(intstr.FromString("25%")), pdb.Spec.MaxUnavailable)
+
+	dataplane.Spec.Deployment.PodDisruptionBudget = nil
+	createdOrUpdated, _, err = reconciler.ensurePodDisruptionBudgetForDataPlane(ctx, dataplane)
+	require.NoError(t, err)
+	require.True(t, createdOrUpdated, "the deletion of the PodDisruptionBudget should be reported")
+
+	var pdbs policyv1.PodDisruptionBudgetList
+	require.NoError(t, fakeClient.List(ctx, &pdbs))
+	require.Empty(t, pdbs.Items)
+}
+
+func TestEnsureServicePortsAreUpdated(t *testing.T) {
+	existing := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8000), NodePort: 30080},
+				{Name: "http-8080", Protocol: corev1.ProtocolTCP, Port: 8080, TargetPort: intstr.FromInt(8080), NodePort: 30880},
+			},
+		},
+	}
+
+	t.Log("the node ports allocated by Kubernetes are ignored")
+	generated := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8000)},
+				{Name: "http-8080", Protocol: corev1.ProtocolTCP, Port: 8080, TargetPort: intstr.FromInt(8080)},
+			},
+		},
+	}
+	require.False(t, ensureServicePortsAreUpdated(existing, generated))
+
+	t.Log("removed ports are pruned and the node ports of the remaining ones are kept")
+	generated.Spec.Ports = []corev1.ServicePort{
+		{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8000)},
+		{Name: "https-9443", Protocol: corev1.ProtocolTCP, Port: 9443, TargetPort: intstr.FromInt(9443)},
+	}
+	require.True(t, ensureServicePortsAreUpdated(existing, generated))
+	require.Equal(t, []corev1.ServicePort{
+		{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8000), NodePort: 30080},
+		{Name: "https-9443", Protocol: corev1.ProtocolTCP, Port: 9443, TargetPort: intstr.FromInt(9443)},
+	}, existing.Spec.Ports)
+}
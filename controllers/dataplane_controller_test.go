@@ -7,9 +7,11 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -155,11 +157,7 @@ func TestDataPlaneReconciler_Reconcile(t *testing.T) {
 			testBody: func(t *testing.T, reconciler DataPlaneReconciler, dataplaneReq reconcile.Request) {
 				ctx := context.Background()
 
-				// first reconcile loop to allow the reconciler to set the dataplane defaults
 				_, err := reconciler.Reconcile(ctx, dataplaneReq)
-				require.NoError(t, err)
-
-				_, err = reconciler.Reconcile(ctx, dataplaneReq)
 				require.EqualError(t, err, "number of dataplane proxy services reduced")
 
 				svcToBeDeleted, svcToBeKept := &corev1.Service{}, &corev1.Service{}
@@ -251,12 +249,8 @@ func TestDataPlaneReconciler_Reconcile(t *testing.T) {
 			testBody: func(t *testing.T, reconciler DataPlaneReconciler, dataplaneReq reconcile.Request) {
 				ctx := context.Background()
 
-				// first reconcile loop to allow the reconciler to set the dataplane defaults
 				_, err := reconciler.Reconcile(ctx, dataplaneReq)
 				require.NoError(t, err)
-
-				_, err = reconciler.Reconcile(ctx, dataplaneReq)
-				require.NoError(t, err)
 			},
 		},
 		{
@@ -343,21 +337,17 @@ func TestDataPlaneReconciler_Reconcile(t *testing.T) {
 						},
 					},
 					Data: helpers.TLSSecretData(t, ca,
-						helpers.CreateCert(t, "*.test-admin-service.default.svc", ca.Cert, ca.Key),
+						helpers.CreateCert(t, "*.test-admin-service.default.svc", ca.Cert, ca.Key, consts.DataPlaneAdminAPITLSServerName),
 					),
 				},
 			},
 			testBody: func(t *testing.T, reconciler DataPlaneReconciler, dataplaneReq reconcile.Request) {
 				ctx := context.Background()
 
-				// first reconcile loop to allow the reconciler to set the dataplane defaults
+				// first reconcile loop to allow the reconciler to set the service name in the dataplane status
 				_, err := reconciler.Reconcile(ctx, dataplaneReq)
 				require.NoError(t, err)
 
-				// second reconcile loop to allow the reconciler to set the service name in the dataplane status
-				_, err = reconciler.Reconcile(ctx, dataplaneReq)
-				require.NoError(t, err)
-
 				_, err = reconciler.Reconcile(ctx, dataplaneReq)
 				require.NoError(t, err)
 
@@ -468,7 +458,7 @@ func TestDataPlaneReconciler_Reconcile(t *testing.T) {
 						Namespace: "default",
 					},
 					Data: helpers.TLSSecretData(t, ca,
-						helpers.CreateCert(t, "*.test-admin-service.default.svc", ca.Cert, ca.Key),
+						helpers.CreateCert(t, "*.test-admin-service.default.svc", ca.Cert, ca.Key, consts.DataPlaneAdminAPITLSServerName),
 					),
 				},
 			},
@@ -621,7 +611,7 @@ func TestDataPlaneReconciler_Reconcile(t *testing.T) {
 						Namespace: "default",
 					},
 					Data: helpers.TLSSecretData(t, ca,
-						helpers.CreateCert(t, "*.test-admin-service.default.svc", ca.Cert, ca.Key),
+						helpers.CreateCert(t, "*.test-admin-service.default.svc", ca.Cert, ca.Key, consts.DataPlaneAdminAPITLSServerName),
 					),
 				},
 			},
@@ -665,6 +655,167 @@ func TestDataPlaneReconciler_Reconcile(t *testing.T) {
 				assert.EqualValues(t, 1, dp.Status.Replicas)
 			},
 		},
+		{
+			name: "horizontal scaling creates an autoscaler which owns the deployment replicas",
+			dataplaneReq: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "dataplane-hpa",
+					Namespace: "default",
+				},
+			},
+			dataplane: &operatorv1beta1.DataPlane{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "gateway-operator.konghq.com/v1beta1",
+					Kind:       "DataPlane",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dataplane-hpa",
+					Namespace: "default",
+					UID:       types.UID(uuid.NewString()),
+				},
+				Spec: operatorv1beta1.DataPlaneSpec{
+					DataPlaneOptions: operatorv1beta1.DataPlaneOptions{
+						Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+							Scaling: &operatorv1beta1.Scaling{
+								HorizontalScaling: &operatorv1beta1.HorizontalScaling{
+									MinReplicas: lo.ToPtr(int32(2)),
+									MaxReplicas: 5,
+									Metrics: []autoscalingv2.MetricSpec{
+										{
+											Type: autoscalingv2.ResourceMetricSourceType,
+											Resource: &autoscalingv2.ResourceMetricSource{
+												Name: corev1.ResourceCPU,
+												Target: autoscalingv2.MetricTarget{
+													Type:               autoscalingv2.UtilizationMetricType,
+													AverageUtilization: lo.ToPtr(int32(50)),
+												},
+											},
+										},
+									},
+								},
+							},
+							DeploymentOptions: operatorv1beta1.DeploymentOptions{
+								Replicas: lo.ToPtr(int32(1)),
+								PodTemplateSpec: &corev1.PodTemplateSpec{
+									Spec: corev1.PodSpec{
+										Containers: []corev1.Container{
+											{
+												Name:  consts.DataPlaneProxyContainerName,
+												Image: consts.DefaultDataPlaneBaseImage + ":3.2",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Status: operatorv1beta1.DataPlaneStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(DataPlaneConditionTypeProvisioned),
+							Status: metav1.ConditionTrue,
+						},
+					},
+				},
+			},
+			dataplaneSubResources: []controllerruntimeclient.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-dataplane-deployment",
+						Namespace: "default",
+					},
+					Spec: appsv1.DeploymentSpec{
+						// The deployment has been scaled by the autoscaler.
+						Replicas: lo.ToPtr(int32(3)),
+						Selector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "dataplane-hpa"},
+						},
+					},
+					Status: appsv1.DeploymentStatus{
+						AvailableReplicas: 3,
+						ReadyReplicas:     3,
+						Replicas:          3,
+					},
+				},
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-admin-service",
+						Namespace: "default",
+						Labels: map[string]string{
+							"app":                            "dataplane-hpa",
+							consts.DataPlaneServiceTypeLabel: string(consts.DataPlaneAdminServiceLabelValue),
+						},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP: corev1.ClusterIPNone,
+					},
+				},
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-proxy-service",
+						Namespace: "default",
+						Labels: map[string]string{
+							"app":                            "dataplane-hpa",
+							consts.DataPlaneServiceTypeLabel: string(consts.DataPlaneProxyServiceLabelValue),
+						},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP:  "10.0.0.1",
+						ClusterIPs: []string{"10.0.0.1"},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-dataplane-tls-secret-2",
+						Namespace: "default",
+					},
+					Data: helpers.TLSSecretData(t, ca,
+						helpers.CreateCert(t, "*.test-admin-service.default.svc", ca.Cert, ca.Key, consts.DataPlaneAdminAPITLSServerName),
+					),
+				},
+			},
+			testBody: func(t *testing.T, reconciler DataPlaneReconciler, dataplaneReq reconcile.Request) {
+				ctx := context.Background()
+
+				for i := 0; i < 10; i++ {
+					_, err := reconciler.Reconcile(ctx, dataplaneReq)
+					require.NoError(t, err)
+				}
+
+				var hpas autoscalingv2.HorizontalPodAutoscalerList
+				require.NoError(t, reconciler.Client.List(ctx, &hpas))
+				require.Len(t, hpas.Items, 1)
+				hpa := hpas.Items[0]
+				assert.Equal(t, "Deployment", hpa.Spec.ScaleTargetRef.Kind)
+				assert.Equal(t, "test-dataplane-deployment", hpa.Spec.ScaleTargetRef.Name)
+				assert.Equal(t, lo.ToPtr(int32(2)), hpa.Spec.MinReplicas)
+				assert.EqualValues(t, 5, hpa.Spec.MaxReplicas)
+				require.Len(t, hpa.Spec.Metrics, 1)
+
+				deployment := &appsv1.Deployment{}
+				require.NoError(t, reconciler.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "test-dataplane-deployment"}, deployment))
+				assert.Equal(t, lo.ToPtr(int32(3)), deployment.Spec.Replicas, "replicas set by the autoscaler should be kept")
+
+				dp := &operatorv1beta1.DataPlane{}
+				require.NoError(t, reconciler.Client.Get(ctx, dataplaneReq.NamespacedName, dp))
+				assert.EqualValues(t, 3, dp.Status.Replicas)
+				assert.Equal(t, "app=dataplane-hpa", dp.Status.Selector)
+
+				t.Log("disabling horizontal scaling")
+				dp.Spec.Deployment.Scaling = nil
+				require.NoError(t, reconciler.Client.Update(ctx, dp))
+				for i := 0; i < 3; i++ {
+					_, err := reconciler.Reconcile(ctx, dataplaneReq)
+					require.NoError(t, err)
+				}
+
+				require.NoError(t, reconciler.Client.List(ctx, &hpas))
+				assert.Empty(t, hpas.Items)
+				require.NoError(t, reconciler.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "test-dataplane-deployment"}, deployment))
+				assert.Equal(t, lo.ToPtr(int32(1)), deployment.Spec.Replicas, "replicas from the spec should be enforced again")
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1,14 +1,22 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"os"
 
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
 	"github.com/kong/gateway-operator/internal/consts"
 	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
 	"github.com/kong/gateway-operator/internal/versions"
 )
 
@@ -44,6 +52,16 @@ func generateDataPlaneImage(dataplane *operatorv1beta1.DataPlane, validators ...
 	return consts.DefaultDataPlaneImage, nil // TODO: https://github.com/Kong/gateway-operator/issues/20
 }
 
+// dataplaneImage returns the DataPlane's image, validating its version unless
+// running in development mode.
+func (r *DataPlaneReconciler) dataplaneImage(dataplane *operatorv1beta1.DataPlane) (string, error) {
+	versionValidationOptions := make([]versions.VersionValidationOption, 0)
+	if !r.DevelopmentMode {
+		versionValidationOptions = append(versionValidationOptions, versions.IsDataPlaneImageVersionSupported)
+	}
+	return generateDataPlaneImage(dataplane, versionValidationOptions...)
+}
+
 // -----------------------------------------------------------------------------
 // DataPlane - Private Functions - Kubernetes Object Labels and Annotations
 // -----------------------------------------------------------------------------
@@ -71,13 +89,93 @@ func addAnnotationsForDataplaneProxyService(obj client.Object, dataplane operato
 	obj.SetAnnotations(annotations)
 }
 
+// dataplaneLiveResourcesSelector returns a list option which selects objects
+// having all the provided labels, excluding the preview and canary objects created
+// during DataPlane rollouts. stateLabel is the label holding the object's rollout state.
+func dataplaneLiveResourcesSelector(stateLabel string, set map[string]string) (client.MatchingLabelsSelector, error) {
+	notRolledOut, err := labels.NewRequirement(stateLabel, selection.NotIn, []string{
+		consts.DataPlaneStateLabelValuePreview,
+		consts.DataPlaneStateLabelValueCanary,
+	})
+	if err != nil {
+		return client.MatchingLabelsSelector{}, err
+	}
+	return client.MatchingLabelsSelector{
+		Selector: labels.SelectorFromSet(set).Add(*notRolledOut),
+	}, nil
+}
+
+// isHorizontalScalingEnabled returns true if the provided DataPlane's replicas
+// are managed by a HorizontalPodAutoscaler.
+func isHorizontalScalingEnabled(dataplane *operatorv1beta1.DataPlane) bool {
+	return dataplane.Spec.Deployment.Scaling != nil &&
+		dataplane.Spec.Deployment.Scaling.HorizontalScaling != nil
+}
+
+// podTemplateSpecHash returns a hash of the provided PodTemplateSpec which
+// can be used to identify a revision of the DataPlane's pods.
+func podTemplateSpecHash(template corev1.PodTemplateSpec) (string, error) {
+	b, err := json.Marshal(template)
+	if err != nil {
+		return "", fmt.Errorf("failed marshaling pod template: %w", err)
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write(b)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())), nil
+}
+
 // -----------------------------------------------------------------------------
 // DataPlane - Private Functions - Equality Checks
 // -----------------------------------------------------------------------------
 
+// podTemplateSpecsEqual checks whether the provided PodTemplateSpecs are equal.
+// Some custom comparison rules are needed for some PodTemplateSpec sub-attributes,
+// in particular resources and affinity.
+//
+// TODO: this is currently relying on us pre-empting API server defaults (by setting them ourselves).
+// This could in theory lead to situations of incompatibility with newer Kubernetes versions down the
+// road, the tradeoff was made due to a time crunch of a matter of hours. We should consider other options
+// to verify whether there are changes staged.
+//
+// See: https://github.com/Kong/gateway-operator/issues/904
+func podTemplateSpecsEqual(a, b corev1.PodTemplateSpec) bool {
+	return cmp.Equal(a, b,
+		cmp.Comparer(func(a, b corev1.ResourceRequirements) bool { return k8sresources.ResourceRequirementsEqual(a, b) }),
+	)
+}
+
+// ensureServicePortsAreUpdated sets the generated ports on the existing Service
+// when they differ, ignoring the node ports allocated by Kubernetes. The node
+// ports of the ports which are still exposed are kept, so that they don't
+// get reallocated. It returns true when the existing Service has been updated.
+func ensureServicePortsAreUpdated(existing, generated *corev1.Service) bool {
+	ignoreNodePort := cmp.FilterPath(func(p cmp.Path) bool {
+		return p.Last().String() == ".NodePort"
+	}, cmp.Ignore())
+	if cmp.Equal(existing.Spec.Ports, generated.Spec.Ports, ignoreNodePort) {
+		return false
+	}
+
+	ports := make([]corev1.ServicePort, 0, len(generated.Spec.Ports))
+	for _, port := range generated.Spec.Ports {
+		for _, existingPort := range existing.Spec.Ports {
+			if existingPort.Port == port.Port && existingPort.Protocol == port.Protocol {
+				port.NodePort = existingPort.NodePort
+			}
+		}
+		ports = append(ports, port)
+	}
+	existing.Spec.Ports = ports
+	return true
+}
+
 func dataplaneSpecDeepEqual(spec1, spec2 *operatorv1beta1.DataPlaneOptions) bool {
 	// TODO: Doesn't take .Rollout field into account.
 	if !deploymentOptionsDeepEqual(&spec1.Deployment.DeploymentOptions, &spec2.Deployment.DeploymentOptions) ||
+		!cmp.Equal(spec1.Deployment.Scaling, spec2.Deployment.Scaling) ||
+		!cmp.Equal(spec1.Deployment.PodDisruptionBudget, spec2.Deployment.PodDisruptionBudget) ||
+		!cmp.Equal(spec1.Database, spec2.Database) ||
+		!cmp.Equal(spec1.Cluster, spec2.Cluster) ||
 		!servicesOptionsDeepEqual(&spec1.Network, &spec2.Network) {
 		return false
 	}
@@ -1,19 +1,34 @@
 package controllers
 
 import (
+	"context"
+	"reflect"
+
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	operatorerrors "github.com/kong/gateway-operator/internal/errors"
 )
 
 // DataPlaneWatchBuilder creates a controller builder pre-configured with
 // the necessary watches for DataPlane resources that are managed by
-// the operator.
-func DataPlaneWatchBuilder(mgr ctrl.Manager) *builder.Builder {
-	return ctrl.NewControllerManagedBy(mgr).
+// the operator. The certificates of the DataPlanes are issued from the
+// clusterCASecret CA, or requested from cert-manager as set in certOpts.
+func DataPlaneWatchBuilder(mgr ctrl.Manager, clusterCASecret types.NamespacedName, certOpts CertificateOptions) *builder.Builder {
+	b := ctrl.NewControllerManagedBy(mgr).
 		// watch Dataplane objects
 		For(&operatorv1beta1.DataPlane{}).
 		// watch for changes in Secrets created by the dataplane controller
@@ -21,5 +36,97 @@ func DataPlaneWatchBuilder(mgr ctrl.Manager) *builder.Builder {
 		// watch for changes in Services created by the dataplane controller
 		Owns(&corev1.Service{}).
 		// watch for changes in Deployments created by the dataplane controller
-		Owns(&appsv1.Deployment{})
+		Owns(&appsv1.Deployment{}).
+		// watch for changes in HorizontalPodAutoscalers created by the dataplane controller
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		// watch for changes in PodDisruptionBudgets created by the dataplane controller
+		Owns(&policyv1.PodDisruptionBudget{}).
+		// watch for changes in database migrations Jobs created by the dataplane controller
+		Owns(&batchv1.Job{}).
+		// watch for changes in the cluster Services of the hybrid mode control planes,
+		// which the data plane DataPlanes are configured to connect to
+		Watches(
+			&corev1.Service{},
+			handler.EnqueueRequestsFromMapFunc(getDataPlanesFromClusterService(mgr.GetClient()))).
+		// watch for changes in the pods of the dataplane Deployments, whose
+		// failures are reported in the status of the dataplanes
+		Watches(
+			&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(getDataPlaneFromPod(mgr.GetClient())),
+			builder.WithPredicates(hasManagedLabel(consts.DataPlaneManagedLabelValue))).
+		// watch for changes in the cluster CA Secret, from which the certificates
+		// of the dataplanes are reissued when the CA is rotated
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(getDataPlanesForClusterCASecret(mgr.GetClient())),
+			builder.WithPredicates(isClusterCASecret(clusterCASecret)))
+	// watch for changes in the cert-manager Certificates created by the
+	// dataplane controller, when the certificates are requested from cert-manager
+	return ownsCertManagerCertificates(b, certOpts)
+}
+
+// getDataPlaneFromPod maps the pods of the dataplane Deployments to their
+// dataplane, as the failures of the pods are reported in its status while they
+// do not change the status of the Deployment.
+func getDataPlaneFromPod(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) (recs []reconcile.Request) {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok {
+			log.FromContext(ctx).Error(
+				operatorerrors.ErrUnexpectedObject,
+				"failed to map DataPlane on Pod",
+				"expected", "Pod", "found", reflect.TypeOf(obj),
+			)
+			return
+		}
+
+		// The pods of the preview Deployments of the dataplanes are not
+		// labeled with the "app" label but with a dedicated one.
+		name, ok := pod.Labels["app"]
+		if !ok {
+			name, ok = pod.Labels[consts.DataPlanePreviewSelectorLabel]
+		}
+		if !ok {
+			return
+		}
+		dataplane := &operatorv1beta1.DataPlane{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: name}, dataplane); err != nil {
+			if !k8serrors.IsNotFound(err) {
+				log.FromContext(ctx).Error(err, "failed to map DataPlane on Pod")
+			}
+			return
+		}
+
+		return []reconcile.Request{
+			{
+				NamespacedName: types.NamespacedName{
+					Namespace: dataplane.Namespace,
+					Name:      dataplane.Name,
+				},
+			},
+		}
+	}
+}
+
+// getDataPlanesForClusterCASecret maps the cluster CA Secret to all the
+// dataplanes, whose certificates are all issued from it.
+func getDataPlanesForClusterCASecret(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) (recs []reconcile.Request) {
+		dataplanes := &operatorv1beta1.DataPlaneList{}
+		if err := c.List(ctx, dataplanes); err != nil {
+			log.FromContext(ctx).Error(err, "failed to map DataPlanes on the cluster CA Secret")
+			return
+		}
+
+		recs = make([]reconcile.Request, 0, len(dataplanes.Items))
+		for _, dataplane := range dataplanes.Items {
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: dataplane.Namespace,
+					Name:      dataplane.Name,
+				},
+			})
+		}
+		return recs
+	}
 }
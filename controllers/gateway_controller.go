@@ -55,6 +55,12 @@ func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&operatorv1alpha1.ControlPlane{}).
 		// watch for changes in networkpolicies created by the gateway controller
 		Owns(&networkingv1.NetworkPolicy{}).
+		// watch for changes in the controlplanes pushing their configuration to
+		// the dataplanes created by the gateway controller, which are admitted
+		// by the networkpolicies of the dataplanes.
+		Watches(
+			&operatorv1alpha1.ControlPlane{},
+			handler.EnqueueRequestsFromMapFunc(r.listGatewaysForControlPlaneDataPlanes)).
 		// watch for updates to GatewayConfigurations, if any configuration targets a
 		// Gateway that is supported, enqueue that Gateway.
 		Watches(
@@ -230,10 +236,16 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
+	trace(log, "mapping listeners on the dataplane", gateway)
+	listeners, err := newGatewayListeners(&gateway, gatewayConfig.Spec.DataPlaneOptions)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed mapping the listeners of Gateway %s: %w", gateway.Name, err)
+	}
+
 	// Provision dataplane creates a dataplane and adds the DataPlaneReady=True
 	// condition to the Gateway status if the dataplane is ready. If not ready
 	// the status DataPlaneReady=False will be set instead.
-	dataplane := r.provisionDataPlane(ctx, log, &gateway, gatewayConfig)
+	dataplane := r.provisionDataPlane(ctx, log, &gateway, gatewayConfig, listeners)
 
 	// Set the DataPlaneReady Condition to False. This happens only if:
 	// * the new status is false and there was no DataPlaneReady condition in the old gateway, or
@@ -323,8 +335,13 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			gatewayConditionsAware(&gateway))
 	}
 
-	if (!k8sutils.IsProgrammed(gwConditionAware) && !k8sutils.IsProgrammed(oldGwConditionsAware)) || !reflect.DeepEqual(gateway.Status.Addresses, oldGateway.Status.Addresses) {
-		gwConditionAware.SetReadyAndProgrammed()
+	// The listeners are only Ready once the rolled out DataPlane serves them.
+	listeners.setPending(&gateway, dataplane, &services[0])
+	gwConditionAware.SetReadyAndProgrammed(listeners)
+	if !k8sutils.IsProgrammed(oldGwConditionsAware) ||
+		!isProgrammedForGeneration(oldGwConditionsAware, gateway.Generation) ||
+		!reflect.DeepEqual(gateway.Status.Addresses, oldGateway.Status.Addresses) ||
+		!listenerStatusesEqual(gateway.Status.Listeners, oldGateway.Status.Listeners) {
 		debug(log, "gateway is Programmed", gateway)
 		if err = r.patchStatus(ctx, &gateway, oldGateway); err != nil {
 			return ctrl.Result{}, err
@@ -340,6 +357,7 @@ func (r *GatewayReconciler) provisionDataPlane(
 	log logr.Logger,
 	gateway *gwtypes.Gateway,
 	gatewayConfig *operatorv1alpha1.GatewayConfiguration,
+	listeners gatewayListeners,
 ) *operatorv1beta1.DataPlane {
 	log = log.WithName("dataplaneProvisioning")
 
@@ -369,7 +387,7 @@ func (r *GatewayReconciler) provisionDataPlane(
 		return nil
 	}
 	if count == 0 {
-		err = r.createDataPlane(ctx, gateway, gatewayConfig)
+		err = r.createDataPlane(ctx, gateway, gatewayConfig, listeners)
 		if err != nil {
 			debug(log, fmt.Sprintf("dataplane creation failed - error: %v", err), gateway)
 			k8sutils.SetCondition(
@@ -396,6 +414,10 @@ func (r *GatewayReconciler) provisionDataPlane(
 	}
 	// Don't require setting defaults for DataPlane when using Gateway CRD.
 	setDataPlaneOptionsDefaults(expectedDataplaneOptions)
+	// The listeners are set on a copy, as the GatewayConfiguration's options
+	// are the base of the DataPlane's NetworkPolicy.
+	expectedDataplaneOptions = expectedDataplaneOptions.DeepCopy()
+	listeners.setDataPlaneOptions(expectedDataplaneOptions)
 
 	if !dataplaneSpecDeepEqual(&dataplane.Spec.DataPlaneOptions, expectedDataplaneOptions) {
 		trace(log, "dataplane config is out of date, updating", gateway)
@@ -424,8 +446,9 @@ func (r *GatewayReconciler) provisionDataPlane(
 			gatewayConditionsAware(gateway),
 		)
 	} else {
+		reason, message := notReadyReasonAndMessage(dataplane)
 		k8sutils.SetCondition(
-			createDataPlaneCondition(metav1.ConditionFalse, k8sutils.WaitingToBecomeReadyReason, k8sutils.WaitingToBecomeReadyMessage, gateway.Generation),
+			createDataPlaneCondition(metav1.ConditionFalse, reason, message, gateway.Generation),
 			gatewayConditionsAware(gateway),
 		)
 	}
@@ -501,7 +524,8 @@ func (r *GatewayReconciler) provisionControlPlane(
 	// Don't require setting defaults for ControlPlane when using Gateway CRD.
 	setControlPlaneOptionsDefaults(expectedControlplaneOptions)
 
-	if !controlplaneSpecDeepEqual(&controlplane.Spec.ControlPlaneOptions, expectedControlplaneOptions, "CONTROLLER_KONG_ADMIN_URL") {
+	if !controlplaneSpecDeepEqual(&controlplane.Spec.ControlPlaneOptions, expectedControlplaneOptions,
+		"CONTROLLER_KONG_ADMIN_URL", "CONTROLLER_KONG_ADMIN_SVC", "CONTROLLER_KONG_ADMIN_SVC_PORT_NAMES") {
 		trace(log, "controlplane config is out of date, updating", gateway)
 		controlplaneOld := controlplane.DeepCopy()
 		controlplane.Spec.ControlPlaneOptions = *expectedControlplaneOptions
@@ -520,8 +544,9 @@ func (r *GatewayReconciler) provisionControlPlane(
 
 	trace(log, "waiting for controlplane readiness", gateway)
 	if !k8sutils.IsReady(controlplane) {
+		reason, message := notReadyReasonAndMessage(controlplane)
 		k8sutils.SetCondition(
-			createControlPlaneCondition(metav1.ConditionFalse, k8sutils.WaitingToBecomeReadyReason, k8sutils.WaitingToBecomeReadyMessage, gateway.Generation),
+			createControlPlaneCondition(metav1.ConditionFalse, reason, message, gateway.Generation),
 			gatewayConditionsAware(gateway),
 		)
 		return nil
@@ -594,6 +619,17 @@ func setDataPlaneOptionsDefaults(opts *operatorv1beta1.DataPlaneOptions) {
 	}
 }
 
+// notReadyReasonAndMessage returns the reason and the message the provided not
+// ready DataPlane or ControlPlane of a Gateway is reported with: the failure of
+// its pods when they are failing, a generic one otherwise.
+func notReadyReasonAndMessage(resource k8sutils.ConditionsAware) (k8sutils.ConditionReason, string) {
+	ready, ok := k8sutils.GetCondition(k8sutils.ReadyType, resource)
+	if ok && ready.Status == metav1.ConditionFalse && k8sutils.IsPodFailureReason(k8sutils.ConditionReason(ready.Reason)) {
+		return k8sutils.ConditionReason(ready.Reason), ready.Message
+	}
+	return k8sutils.WaitingToBecomeReadyReason, k8sutils.WaitingToBecomeReadyMessage
+}
+
 func createDataPlaneCondition(status metav1.ConditionStatus, reason k8sutils.ConditionReason, message string, observedGeneration int64) metav1.Condition {
 	return k8sutils.NewConditionWithGeneration(DataPlaneReadyType, status, reason, message, observedGeneration)
 }
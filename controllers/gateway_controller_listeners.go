@@ -0,0 +1,371 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	gwtypes "github.com/kong/gateway-operator/internal/types"
+	dataplaneutils "github.com/kong/gateway-operator/internal/utils/dataplane"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
+)
+
+// -----------------------------------------------------------------------------
+// GatewayReconciler - Listeners
+// -----------------------------------------------------------------------------
+
+// privilegedPortsOffset is added to the privileged listener ports to get the
+// port the DataPlane proxy listens on, as Kong doesn't run as root.
+const privilegedPortsOffset = 8000
+
+// kongListenKind tells how the DataPlane proxy handles the traffic on a port.
+type kongListenKind string
+
+const (
+	kongListenKindHTTP   kongListenKind = "http"
+	kongListenKindHTTPS  kongListenKind = "https"
+	kongListenKindStream kongListenKind = "stream"
+)
+
+// kongListenPort identifies a port of the DataPlane proxy.
+type kongListenPort struct {
+	protocol corev1.Protocol
+	port     int32
+}
+
+// gatewayListeners describes how the listeners of a Gateway are served by the
+// proxy of the Gateway's DataPlane.
+type gatewayListeners struct {
+	// proxyListen is the KONG_PROXY_LISTEN of the DataPlane proxy.
+	proxyListen string
+	// streamListen is the KONG_STREAM_LISTEN of the DataPlane proxy.
+	streamListen string
+	// portMaps is the KONG_PORT_MAPS of the DataPlane proxy.
+	portMaps string
+	// ports are the ports exposed by the DataPlane proxy Service.
+	ports []operatorv1beta1.DataPlaneServicePort
+	// invalid holds the listeners which cannot be served by the DataPlane,
+	// along with the reason why.
+	invalid map[gatewayv1beta1.SectionName]string
+	// pending holds the listeners which are not served by the DataPlane yet,
+	// along with the reason why.
+	pending map[gatewayv1beta1.SectionName]string
+}
+
+// newGatewayListeners maps the listeners of the provided Gateway on the proxy
+// configured with the provided DataPlane options.
+//
+// The ports of the KONG_PORT_MAPS of the proxy, 80 and 443 by default, are
+// always exposed. The HTTP and HTTPS listeners on other ports get a dedicated
+// entry in the KONG_PROXY_LISTEN and KONG_PORT_MAPS of the proxy, while the
+// TCP, TLS passthrough and UDP listeners get one in its KONG_STREAM_LISTEN.
+// All of them get a port on the proxy Service.
+func newGatewayListeners(gateway *gwtypes.Gateway, opts *operatorv1beta1.DataPlaneOptions) (gatewayListeners, error) {
+	listeners := gatewayListeners{
+		proxyListen: dataplaneutils.KongDefaults[consts.EnvVarKongProxyListen],
+		portMaps:    dataplaneutils.KongDefaults[consts.EnvVarKongPortMaps],
+		invalid:     make(map[gatewayv1beta1.SectionName]string),
+		pending:     make(map[gatewayv1beta1.SectionName]string),
+	}
+	if opts != nil && opts.Deployment.PodTemplateSpec != nil {
+		container := k8sutils.GetPodContainerByName(&opts.Deployment.PodTemplateSpec.Spec, consts.DataPlaneProxyContainerName)
+		if container != nil {
+			if proxyListen := envValueByName(container.Env, consts.EnvVarKongProxyListen); proxyListen != "" {
+				listeners.proxyListen = proxyListen
+			}
+			if streamListen := envValueByName(container.Env, consts.EnvVarKongStreamListen); streamListen != "off" {
+				listeners.streamListen = streamListen
+			}
+			if portMaps := envValueByName(container.Env, consts.EnvVarKongPortMaps); portMaps != "" {
+				listeners.portMaps = portMaps
+			}
+		}
+	}
+
+	// kinds tells how the proxy handles the traffic on each of its ports.
+	kinds := make(map[kongListenPort]kongListenKind)
+	entries, err := parseKongListenEntries(listeners.proxyListen)
+	if err != nil {
+		return listeners, fmt.Errorf("failed parsing %s env: %w", consts.EnvVarKongProxyListen, err)
+	}
+	for _, e := range entries {
+		kind := kongListenKindHTTP
+		if e.SSL {
+			kind = kongListenKindHTTPS
+		}
+		kinds[kongListenPort{protocol: corev1.ProtocolTCP, port: int32(e.Port)}] = kind
+	}
+	if listeners.streamListen != "" {
+		entries, err := parseKongListenEntries(listeners.streamListen)
+		if err != nil {
+			return listeners, fmt.Errorf("failed parsing %s env: %w", consts.EnvVarKongStreamListen, err)
+		}
+		for _, e := range entries {
+			protocol := corev1.ProtocolTCP
+			if e.UDP {
+				protocol = corev1.ProtocolUDP
+			}
+			kinds[kongListenPort{protocol: protocol, port: int32(e.Port)}] = kongListenKindStream
+		}
+	}
+
+	portMaps, err := parseKongPortMaps(listeners.portMaps)
+	if err != nil {
+		return listeners, fmt.Errorf("failed parsing %s env: %w", consts.EnvVarKongPortMaps, err)
+	}
+	// targetPorts holds the proxy port each of the Service ports targets.
+	targetPorts := make(map[kongListenPort]kongListenPort, len(portMaps))
+	for _, m := range portMaps {
+		port := kongListenPort{protocol: corev1.ProtocolTCP, port: m.port}
+		targetPort := kongListenPort{protocol: corev1.ProtocolTCP, port: m.targetPort}
+		targetPorts[port] = targetPort
+		listeners.ports = append(listeners.ports, proxyServicePort(string(kinds[targetPort]), port, targetPort))
+	}
+
+	var (
+		proxyListen  = []string{listeners.proxyListen}
+		streamListen []string
+		portMapsEnv  = []string{listeners.portMaps}
+	)
+	if listeners.streamListen != "" {
+		streamListen = append(streamListen, listeners.streamListen)
+	}
+	for _, listener := range gateway.Spec.Listeners {
+		protocol, kind, err := listenerKongListenKind(listener)
+		if err != nil {
+			listeners.invalid[listener.Name] = err.Error()
+			continue
+		}
+
+		port := kongListenPort{protocol: protocol, port: int32(listener.Port)}
+		if targetPort, ok := targetPorts[port]; ok {
+			if kinds[targetPort] != kind {
+				listeners.invalid[listener.Name] = fmt.Sprintf("port %d is already served with another protocol", port.port)
+			}
+			continue
+		}
+
+		targetPort := kongListenPort{protocol: protocol, port: proxyListenerTargetPort(port.port)}
+		if protocol == corev1.ProtocolTCP && isReservedDataPlanePort(targetPort.port) {
+			listeners.invalid[listener.Name] = fmt.Sprintf("port %d would be served on the reserved port %d of the DataPlane", port.port, targetPort.port)
+			continue
+		}
+		if targetKind, ok := kinds[targetPort]; ok {
+			if targetKind != kind {
+				listeners.invalid[listener.Name] = fmt.Sprintf("port %d would be served on port %d of the DataPlane, which is already used with another protocol", port.port, targetPort.port)
+				continue
+			}
+		} else {
+			kinds[targetPort] = kind
+			if kind == kongListenKindStream {
+				streamListen = append(streamListen, kongStreamListenEntry(targetPort))
+			} else {
+				proxyListen = append(proxyListen, kongProxyListenEntry(targetPort.port, kind == kongListenKindHTTPS))
+			}
+		}
+
+		targetPorts[port] = targetPort
+		if kind != kongListenKindStream {
+			portMapsEnv = append(portMapsEnv, fmt.Sprintf("%d:%d", port.port, targetPort.port))
+		}
+		listeners.ports = append(listeners.ports, proxyServicePort(strings.ToLower(string(listener.Protocol)), port, targetPort))
+	}
+
+	listeners.proxyListen = strings.Join(proxyListen, ", ")
+	listeners.streamListen = strings.Join(streamListen, ", ")
+	listeners.portMaps = strings.Join(portMapsEnv, ", ")
+	sort.SliceStable(listeners.ports, func(i, j int) bool {
+		if listeners.ports[i].Port == listeners.ports[j].Port {
+			return listeners.ports[i].Protocol < listeners.ports[j].Protocol
+		}
+		return listeners.ports[i].Port < listeners.ports[j].Port
+	})
+	return listeners, nil
+}
+
+// setDataPlaneOptions configures the proxy of the provided DataPlane options to
+// serve the listeners.
+func (l gatewayListeners) setDataPlaneOptions(opts *operatorv1beta1.DataPlaneOptions) {
+	if opts.Deployment.PodTemplateSpec == nil {
+		opts.Deployment.PodTemplateSpec = &corev1.PodTemplateSpec{}
+	}
+	container := k8sutils.GetPodContainerByName(&opts.Deployment.PodTemplateSpec.Spec, consts.DataPlaneProxyContainerName)
+	if container != nil {
+		envVarName := func(e corev1.EnvVar) string { return e.Name }
+		container.Env, _ = setByName(container.Env, corev1.EnvVar{Name: consts.EnvVarKongProxyListen, Value: l.proxyListen}, envVarName)
+		container.Env, _ = setByName(container.Env, corev1.EnvVar{Name: consts.EnvVarKongPortMaps, Value: l.portMaps}, envVarName)
+		if l.streamListen != "" {
+			container.Env, _ = setByName(container.Env, corev1.EnvVar{Name: consts.EnvVarKongStreamListen, Value: l.streamListen}, envVarName)
+		}
+	}
+
+	if opts.Network.Services == nil {
+		opts.Network.Services = &operatorv1beta1.DataPlaneServices{}
+	}
+	if opts.Network.Services.Ingress == nil {
+		opts.Network.Services.Ingress = &operatorv1beta1.ServiceOptions{
+			Type: k8sresources.DefaultDataPlaneProxyServiceType,
+		}
+	}
+	opts.Network.Services.Ingress.Ports = l.ports
+}
+
+// setPending marks the valid listeners as pending until the provided DataPlane
+// has been rolled out and its proxy Service exposes them.
+func (l gatewayListeners) setPending(gateway *gwtypes.Gateway, dataplane *operatorv1beta1.DataPlane, proxyService *corev1.Service) {
+	readyCondition, ok := k8sutils.GetCondition(k8sutils.ReadyType, dataplane)
+	rolledOut := ok && readyCondition.Status == metav1.ConditionTrue && readyCondition.ObservedGeneration == dataplane.Generation
+
+	for _, listener := range gateway.Spec.Listeners {
+		if _, ok := l.invalid[listener.Name]; ok {
+			continue
+		}
+		if !rolledOut {
+			l.pending[listener.Name] = "waiting for the DataPlane to be rolled out"
+			continue
+		}
+		protocol, _, err := listenerKongListenKind(listener)
+		if err != nil {
+			continue
+		}
+		exposed := false
+		for _, p := range proxyService.Spec.Ports {
+			if p.Port == int32(listener.Port) && p.Protocol == protocol {
+				exposed = true
+			}
+		}
+		if !exposed {
+			l.pending[listener.Name] = fmt.Sprintf("waiting for the DataPlane Service to expose port %d", listener.Port)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// GatewayReconciler - Listeners - Private Functions
+// -----------------------------------------------------------------------------
+
+// listenerKongListenKind returns the IP protocol of the provided listener along
+// with the way the DataPlane proxy handles its traffic. It returns an error
+// when the listener cannot be served by the DataPlane.
+func listenerKongListenKind(listener gatewayv1beta1.Listener) (corev1.Protocol, kongListenKind, error) {
+	switch listener.Protocol {
+	case gatewayv1beta1.HTTPProtocolType:
+		return corev1.ProtocolTCP, kongListenKindHTTP, nil
+	case gatewayv1beta1.HTTPSProtocolType:
+		return corev1.ProtocolTCP, kongListenKindHTTPS, nil
+	case gatewayv1beta1.TCPProtocolType:
+		return corev1.ProtocolTCP, kongListenKindStream, nil
+	case gatewayv1beta1.TLSProtocolType:
+		// The TLS connections are passed through to the upstreams by the stream
+		// listeners, routing them by SNI.
+		if listener.TLS == nil || listener.TLS.Mode == nil || *listener.TLS.Mode != gatewayv1beta1.TLSModePassthrough {
+			return "", "", fmt.Errorf("TLS listeners are only supported in the %s mode", gatewayv1beta1.TLSModePassthrough)
+		}
+		return corev1.ProtocolTCP, kongListenKindStream, nil
+	case gatewayv1beta1.UDPProtocolType:
+		return corev1.ProtocolUDP, kongListenKindStream, nil
+	}
+	return "", "", fmt.Errorf("%s listeners are not supported by the DataPlane", listener.Protocol)
+}
+
+type kongPortMap struct {
+	port       int32
+	targetPort int32
+}
+
+// parseKongPortMaps parses the provided KONG_PORT_MAPS value, made of comma
+// separated port:targetPort pairs.
+func parseKongPortMaps(str string) ([]kongPortMap, error) {
+	var portMaps []kongPortMap
+	for _, s := range strings.Split(str, ",") {
+		port, targetPort, ok := strings.Cut(strings.TrimSpace(s), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid port map %q", s)
+		}
+		p, err := strconv.ParseInt(port, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing port %s: %w", port, err)
+		}
+		t, err := strconv.ParseInt(targetPort, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing port %s: %w", targetPort, err)
+		}
+		portMaps = append(portMaps, kongPortMap{port: int32(p), targetPort: int32(t)})
+	}
+	return portMaps, nil
+}
+
+// proxyListenerTargetPort returns the port the DataPlane proxy listens on for
+// a listener port which isn't part of its KONG_PORT_MAPS.
+// Privileged ports are shifted like the default HTTPS port is, e.g. a listener
+// on port 81 is served on port 8081.
+func proxyListenerTargetPort(port int32) int32 {
+	if port < 1024 {
+		return port + privilegedPortsOffset
+	}
+	return port
+}
+
+// isReservedDataPlanePort returns true when the DataPlane uses the provided
+// TCP port for anything else than proxying traffic.
+func isReservedDataPlanePort(port int32) bool {
+	switch port {
+	case consts.DataPlaneAdminAPIPort,
+		consts.DataPlaneStatusPort,
+		consts.DataPlaneClusterPort,
+		consts.DataPlaneClusterTelemetryPort:
+		return true
+	}
+	return false
+}
+
+func kongProxyListenEntry(port int32, ssl bool) string {
+	if ssl {
+		return fmt.Sprintf("0.0.0.0:%d http2 ssl reuseport backlog=16384", port)
+	}
+	return fmt.Sprintf("0.0.0.0:%d reuseport backlog=16384", port)
+}
+
+func kongStreamListenEntry(port kongListenPort) string {
+	if port.protocol == corev1.ProtocolUDP {
+		return fmt.Sprintf("0.0.0.0:%d udp reuseport", port.port)
+	}
+	return fmt.Sprintf("0.0.0.0:%d reuseport backlog=16384", port.port)
+}
+
+// proxyServicePort returns the DataPlane proxy Service port for the provided
+// port, named after the provided protocol name. The default HTTP and HTTPS
+// ports are named after their protocol only.
+func proxyServicePort(protocolName string, port, targetPort kongListenPort) operatorv1beta1.DataPlaneServicePort {
+	if protocolName == "" {
+		protocolName = strings.ToLower(string(port.protocol))
+	}
+	name := protocolName
+	if !(port.port == consts.DefaultHTTPPort && protocolName == string(kongListenKindHTTP)) &&
+		!(port.port == consts.DefaultHTTPSPort && protocolName == string(kongListenKindHTTPS)) {
+		name = fmt.Sprintf("%s-%d", protocolName, port.port)
+	}
+	return operatorv1beta1.DataPlaneServicePort{
+		Name:       name,
+		Port:       port.port,
+		Protocol:   port.protocol,
+		TargetPort: intstr.FromInt(int(targetPort.port)),
+	}
+}
+
+// listenerStatusesEqual checks whether the provided listener statuses are equal,
+// ignoring the transition times of their conditions.
+func listenerStatusesEqual(a, b []gatewayv1beta1.ListenerStatus) bool {
+	return cmp.Equal(a, b, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"))
+}
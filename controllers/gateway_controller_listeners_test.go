@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	gwtypes "github.com/kong/gateway-operator/internal/types"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+)
+
+func TestNewGatewayListeners(t *testing.T) {
+	const (
+		defaultProxyListen = "0.0.0.0:8000 reuseport backlog=16384, 0.0.0.0:8443 http2 ssl reuseport backlog=16384"
+		defaultPortMaps    = "80:8000, 443:8443"
+	)
+	defaultPorts := []operatorv1beta1.DataPlaneServicePort{
+		{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8000)},
+		{Name: "https", Port: 443, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8443)},
+	}
+	listener := func(name string, protocol gatewayv1beta1.ProtocolType, port int) gatewayv1beta1.Listener {
+		return gatewayv1beta1.Listener{
+			Name:     gatewayv1beta1.SectionName(name),
+			Protocol: protocol,
+			Port:     gatewayv1beta1.PortNumber(port),
+		}
+	}
+
+	tlsListener := func(name string, port int, mode gatewayv1beta1.TLSModeType) gatewayv1beta1.Listener {
+		l := listener(name, gatewayv1beta1.TLSProtocolType, port)
+		l.TLS = &gatewayv1beta1.GatewayTLSConfig{Mode: &mode}
+		return l
+	}
+
+	testCases := []struct {
+		name                 string
+		listeners            []gatewayv1beta1.Listener
+		env                  []corev1.EnvVar
+		expectedProxyListen  string
+		expectedStreamListen string
+		expectedPortMaps     string
+		expectedPorts        []operatorv1beta1.DataPlaneServicePort
+		expectedInvalid      []gatewayv1beta1.SectionName
+		expectedErr          bool
+	}{
+		{
+			name: "default listeners are served by the default configuration",
+			listeners: []gatewayv1beta1.Listener{
+				listener("http", gatewayv1beta1.HTTPProtocolType, 80),
+				listener("https", gatewayv1beta1.HTTPSProtocolType, 443),
+			},
+			expectedProxyListen: defaultProxyListen,
+			expectedPortMaps:    defaultPortMaps,
+			expectedPorts:       defaultPorts,
+		},
+		{
+			name: "additional listeners get dedicated ports",
+			listeners: []gatewayv1beta1.Listener{
+				listener("http", gatewayv1beta1.HTTPProtocolType, 80),
+				listener("https-alt", gatewayv1beta1.HTTPSProtocolType, 9443),
+				listener("http-alt", gatewayv1beta1.HTTPProtocolType, 8080),
+				listener("http-alt-hostname", gatewayv1beta1.HTTPProtocolType, 8080),
+				listener("http-privileged", gatewayv1beta1.HTTPProtocolType, 81),
+			},
+			expectedProxyListen: defaultProxyListen +
+				", 0.0.0.0:9443 http2 ssl reuseport backlog=16384" +
+				", 0.0.0.0:8080 reuseport backlog=16384" +
+				", 0.0.0.0:8081 reuseport backlog=16384",
+			expectedPortMaps: defaultPortMaps + ", 9443:9443, 8080:8080, 81:8081",
+			expectedPorts: []operatorv1beta1.DataPlaneServicePort{
+				{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8000)},
+				{Name: "http-81", Port: 81, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8081)},
+				{Name: "https", Port: 443, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8443)},
+				{Name: "http-8080", Port: 8080, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8080)},
+				{Name: "https-9443", Port: 9443, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(9443)},
+			},
+		},
+		{
+			name: "listeners sharing a port of the proxy with the same protocol",
+			listeners: []gatewayv1beta1.Listener{
+				listener("https-alt", gatewayv1beta1.HTTPSProtocolType, 8443),
+			},
+			expectedProxyListen: defaultProxyListen,
+			expectedPortMaps:    defaultPortMaps + ", 8443:8443",
+			expectedPorts: append(defaultPorts,
+				operatorv1beta1.DataPlaneServicePort{Name: "https-8443", Port: 8443, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8443)},
+			),
+		},
+		{
+			name: "stream listeners are served by the stream listen configuration",
+			listeners: []gatewayv1beta1.Listener{
+				listener("http", gatewayv1beta1.HTTPProtocolType, 80),
+				listener("tcp", gatewayv1beta1.TCPProtocolType, 5432),
+				tlsListener("tls", 8883, gatewayv1beta1.TLSModePassthrough),
+				listener("udp", gatewayv1beta1.UDPProtocolType, 53),
+				listener("tcp-privileged", gatewayv1beta1.TCPProtocolType, 53),
+			},
+			expectedProxyListen: defaultProxyListen,
+			expectedStreamListen: "0.0.0.0:5432 reuseport backlog=16384" +
+				", 0.0.0.0:8883 reuseport backlog=16384" +
+				", 0.0.0.0:8053 udp reuseport" +
+				", 0.0.0.0:8053 reuseport backlog=16384",
+			expectedPortMaps: defaultPortMaps,
+			expectedPorts: []operatorv1beta1.DataPlaneServicePort{
+				{Name: "tcp-53", Port: 53, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8053)},
+				{Name: "udp-53", Port: 53, Protocol: corev1.ProtocolUDP, TargetPort: intstr.FromInt(8053)},
+				{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8000)},
+				{Name: "https", Port: 443, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8443)},
+				{Name: "tcp-5432", Port: 5432, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(5432)},
+				{Name: "tls-8883", Port: 8883, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8883)},
+			},
+		},
+		{
+			name: "the stream listen configuration of the GatewayConfiguration is the base",
+			listeners: []gatewayv1beta1.Listener{
+				listener("tcp", gatewayv1beta1.TCPProtocolType, 5432),
+				listener("udp", gatewayv1beta1.UDPProtocolType, 9000),
+			},
+			env: []corev1.EnvVar{
+				{Name: consts.EnvVarKongStreamListen, Value: "0.0.0.0:9000 udp reuseport"},
+			},
+			expectedProxyListen:  defaultProxyListen,
+			expectedStreamListen: "0.0.0.0:9000 udp reuseport, 0.0.0.0:5432 reuseport backlog=16384",
+			expectedPortMaps:     defaultPortMaps,
+			expectedPorts: append(defaultPorts,
+				operatorv1beta1.DataPlaneServicePort{Name: "tcp-5432", Port: 5432, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(5432)},
+				operatorv1beta1.DataPlaneServicePort{Name: "udp-9000", Port: 9000, Protocol: corev1.ProtocolUDP, TargetPort: intstr.FromInt(9000)},
+			),
+		},
+		{
+			name: "listeners which cannot be served are invalid",
+			listeners: []gatewayv1beta1.Listener{
+				tlsListener("tls-terminate", 8883, gatewayv1beta1.TLSModeTerminate),
+				listener("tcp-on-http-port", gatewayv1beta1.TCPProtocolType, 80),
+				listener("tcp-on-cluster-port", gatewayv1beta1.TCPProtocolType, 8005),
+				listener("http-on-https-port", gatewayv1beta1.HTTPProtocolType, 443),
+				listener("http-on-https-proxy-port", gatewayv1beta1.HTTPProtocolType, 8443),
+				listener("http-on-admin-port", gatewayv1beta1.HTTPProtocolType, 444),
+				listener("http-on-status-port", gatewayv1beta1.HTTPProtocolType, 8100),
+			},
+			expectedProxyListen: defaultProxyListen,
+			expectedPortMaps:    defaultPortMaps,
+			expectedPorts:       defaultPorts,
+			expectedInvalid: []gatewayv1beta1.SectionName{
+				"tls-terminate", "tcp-on-http-port", "tcp-on-cluster-port", "http-on-https-port", "http-on-https-proxy-port", "http-on-admin-port", "http-on-status-port",
+			},
+		},
+		{
+			name: "the proxy configuration of the GatewayConfiguration is the base",
+			listeners: []gatewayv1beta1.Listener{
+				listener("http", gatewayv1beta1.HTTPProtocolType, 80),
+				listener("http-alt", gatewayv1beta1.HTTPProtocolType, 8080),
+			},
+			env: []corev1.EnvVar{
+				{Name: consts.EnvVarKongProxyListen, Value: "0.0.0.0:8001 reuseport backlog=16384, 0.0.0.0:8999 http2 ssl reuseport backlog=16384"},
+				{Name: consts.EnvVarKongPortMaps, Value: "80:8001, 443:8999"},
+			},
+			expectedProxyListen: "0.0.0.0:8001 reuseport backlog=16384, 0.0.0.0:8999 http2 ssl reuseport backlog=16384" +
+				", 0.0.0.0:8080 reuseport backlog=16384",
+			expectedPortMaps: "80:8001, 443:8999, 8080:8080",
+			expectedPorts: []operatorv1beta1.DataPlaneServicePort{
+				{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8001)},
+				{Name: "https", Port: 443, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8999)},
+				{Name: "http-8080", Port: 8080, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8080)},
+			},
+		},
+		{
+			name: "invalid port maps",
+			env: []corev1.EnvVar{
+				{Name: consts.EnvVarKongPortMaps, Value: "80"},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &gwtypes.Gateway{
+				Spec: gatewayv1beta1.GatewaySpec{
+					Listeners: tc.listeners,
+				},
+			}
+			opts := &operatorv1beta1.DataPlaneOptions{
+				Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+					DeploymentOptions: operatorv1beta1.DeploymentOptions{
+						PodTemplateSpec: &corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{
+									{
+										Name: consts.DataPlaneProxyContainerName,
+										Env:  tc.env,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			listeners, err := newGatewayListeners(gateway, opts)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedProxyListen, listeners.proxyListen)
+			require.Equal(t, tc.expectedStreamListen, listeners.streamListen)
+			require.Equal(t, tc.expectedPortMaps, listeners.portMaps)
+			require.Equal(t, tc.expectedPorts, listeners.ports)
+			require.Len(t, listeners.invalid, len(tc.expectedInvalid))
+			for _, name := range tc.expectedInvalid {
+				require.Contains(t, listeners.invalid, name)
+			}
+
+			listeners.setDataPlaneOptions(opts)
+			container := k8sutils.GetPodContainerByName(&opts.Deployment.PodTemplateSpec.Spec, consts.DataPlaneProxyContainerName)
+			require.Equal(t, tc.expectedProxyListen, envValueByName(container.Env, consts.EnvVarKongProxyListen))
+			require.Equal(t, tc.expectedStreamListen, envValueByName(container.Env, consts.EnvVarKongStreamListen))
+			require.Equal(t, tc.expectedPortMaps, envValueByName(container.Env, consts.EnvVarKongPortMaps))
+			require.Equal(t, corev1.ServiceTypeLoadBalancer, opts.Network.Services.Ingress.Type)
+			require.Equal(t, tc.expectedPorts, opts.Network.Services.Ingress.Ports)
+		})
+	}
+}
+
+func TestGatewayListenersSetPending(t *testing.T) {
+	gateway := &gwtypes.Gateway{
+		Spec: gatewayv1beta1.GatewaySpec{
+			Listeners: []gatewayv1beta1.Listener{
+				{Name: "http", Protocol: gatewayv1beta1.HTTPProtocolType, Port: 80},
+				{Name: "udp", Protocol: gatewayv1beta1.UDPProtocolType, Port: 53},
+				{Name: "sctp", Protocol: "SCTP", Port: 9000},
+			},
+		},
+	}
+	dataplane := &operatorv1beta1.DataPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 2,
+		},
+	}
+	proxyService := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP},
+				{Name: "tcp-53", Port: 53, Protocol: corev1.ProtocolTCP},
+			},
+		},
+	}
+	newListeners := func() gatewayListeners {
+		return gatewayListeners{
+			invalid: map[gatewayv1beta1.SectionName]string{"sctp": "SCTP listeners are not supported by the DataPlane"},
+			pending: make(map[gatewayv1beta1.SectionName]string),
+		}
+	}
+
+	t.Log("all the valid listeners are pending until the DataPlane is rolled out")
+	k8sutils.SetReady(dataplane, 1)
+	listeners := newListeners()
+	listeners.setPending(gateway, dataplane, proxyService)
+	require.Len(t, listeners.pending, 2)
+	require.Contains(t, listeners.pending, gatewayv1beta1.SectionName("http"))
+	require.Contains(t, listeners.pending, gatewayv1beta1.SectionName("udp"))
+
+	t.Log("the listeners are pending until the proxy Service exposes them")
+	k8sutils.SetReady(dataplane, dataplane.Generation)
+	listeners = newListeners()
+	listeners.setPending(gateway, dataplane, proxyService)
+	require.Len(t, listeners.pending, 1)
+	require.Contains(t, listeners.pending, gatewayv1beta1.SectionName("udp"))
+
+	proxyService.Spec.Ports = append(proxyService.Spec.Ports, corev1.ServicePort{Name: "udp-53", Port: 53, Protocol: corev1.ProtocolUDP})
+	listeners = newListeners()
+	listeners.setPending(gateway, dataplane, proxyService)
+	require.Empty(t, listeners.pending)
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -41,6 +43,7 @@ var (
 func (r *GatewayReconciler) createDataPlane(ctx context.Context,
 	gateway *gwtypes.Gateway,
 	gatewayConfig *operatorv1alpha1.GatewayConfiguration,
+	listeners gatewayListeners,
 ) error {
 	dataplane := &operatorv1beta1.DataPlane{
 		ObjectMeta: metav1.ObjectMeta{
@@ -49,9 +52,10 @@ func (r *GatewayReconciler) createDataPlane(ctx context.Context,
 		},
 	}
 	if gatewayConfig.Spec.DataPlaneOptions != nil {
-		dataplane.Spec.DataPlaneOptions = *gatewayConfig.Spec.DataPlaneOptions
+		dataplane.Spec.DataPlaneOptions = *gatewayConfig.Spec.DataPlaneOptions.DeepCopy()
 	}
 	setDataPlaneOptionsDefaults(&dataplane.Spec.DataPlaneOptions)
+	listeners.setDataPlaneOptions(&dataplane.Spec.DataPlaneOptions)
 	k8sutils.SetOwnerForObject(dataplane, gateway)
 	gatewayutils.LabelObjectAsGatewayManaged(dataplane)
 	return r.Client.Create(ctx, dataplane)
@@ -228,7 +232,61 @@ func (r *GatewayReconciler) ensureDataPlaneHasNetworkPolicy(
 	if err != nil {
 		return false, err
 	}
+	var livePolicies, previewPolicies []networkingv1.NetworkPolicy
+	for _, np := range networkPolicies {
+		if np.Labels[consts.DataPlaneNetworkPolicyStateLabel] == consts.DataPlaneStateLabelValuePreview {
+			previewPolicies = append(previewPolicies, np)
+		} else {
+			livePolicies = append(livePolicies, np)
+		}
+	}
+
+	// The DataPlane admin API is also reachable by the other ControlPlanes
+	// pushing their configuration to the DataPlane.
+	controlplanes, err := listControlPlanesForDataPlane(ctx, r.Client, dataplane)
+	if err != nil {
+		return false, err
+	}
+	controlplanes = lo.Filter(controlplanes, func(cp operatorv1alpha1.ControlPlane, _ int) bool {
+		return cp.UID != controlplane.UID
+	})
+	sort.Slice(controlplanes, func(i, j int) bool {
+		if controlplanes[i].Namespace != controlplanes[j].Namespace {
+			return controlplanes[i].Namespace < controlplanes[j].Namespace
+		}
+		return controlplanes[i].Name < controlplanes[j].Name
+	})
+	controlplanes = append([]operatorv1alpha1.ControlPlane{*controlplane}, controlplanes...)
 
+	generatedPolicy, err := generateDataPlaneNetworkPolicy(gateway.Namespace, gatewayConfig, dataplane, controlplanes)
+	if err != nil {
+		return false, fmt.Errorf("failed generating network policy for DataPlane %s: %w", dataplane.Name, err)
+	}
+	if createdOrUpdate, err := r.ensureNetworkPolicy(ctx, gateway, livePolicies, generatedPolicy); err != nil || createdOrUpdate {
+		return createdOrUpdate, err
+	}
+
+	// The preview pods of Blue Green rollouts are not selected by the live
+	// NetworkPolicy, they get a dedicated one while the strategy is set.
+	if dataplane.Spec.Deployment.Rollout == nil || dataplane.Spec.Deployment.Rollout.Strategy.BlueGreen == nil {
+		for i := range previewPolicies {
+			if err := r.Client.Delete(ctx, &previewPolicies[i]); client.IgnoreNotFound(err) != nil {
+				return false, fmt.Errorf("failed deleting DataPlane's preview NetworkPolicy %s: %w", previewPolicies[i].Name, err)
+			}
+		}
+		return len(previewPolicies) > 0, nil
+	}
+	return r.ensureNetworkPolicy(ctx, gateway, previewPolicies, generateDataPlanePreviewNetworkPolicy(generatedPolicy, dataplane))
+}
+
+// ensureNetworkPolicy ensures that the provided existing NetworkPolicies are
+// reduced to a single one, matching the generated NetworkPolicy.
+func (r *GatewayReconciler) ensureNetworkPolicy(
+	ctx context.Context,
+	gateway *gwtypes.Gateway,
+	networkPolicies []networkingv1.NetworkPolicy,
+	generatedPolicy *networkingv1.NetworkPolicy,
+) (createdOrUpdate bool, err error) {
 	count := len(networkPolicies)
 	if count > 1 {
 		if err := k8sreduce.ReduceNetworkPolicies(ctx, r.Client, networkPolicies); err != nil {
@@ -237,10 +295,6 @@ func (r *GatewayReconciler) ensureDataPlaneHasNetworkPolicy(
 		return false, errors.New("number of networkPolicies reduced")
 	}
 
-	generatedPolicy, err := generateDataPlaneNetworkPolicy(gateway.Namespace, gatewayConfig, dataplane, controlplane)
-	if err != nil {
-		return false, fmt.Errorf("failed generating network policy for DataPlane %s: %w", dataplane.Name, err)
-	}
 	k8sutils.SetOwnerForObject(generatedPolicy, gateway)
 	gatewayutils.LabelObjectAsGatewayManaged(generatedPolicy)
 
@@ -271,7 +325,7 @@ func generateDataPlaneNetworkPolicy(
 	namespace string,
 	gatewayConfig *operatorv1alpha1.GatewayConfiguration,
 	dataplane *operatorv1beta1.DataPlane,
-	controlplane *operatorv1alpha1.ControlPlane,
+	controlplanes []operatorv1alpha1.ControlPlane,
 ) (*networkingv1.NetworkPolicy, error) {
 	var (
 		protocolTCP     = corev1.ProtocolTCP
@@ -316,7 +370,10 @@ func generateDataPlaneNetworkPolicy(
 		Ports: []networkingv1.NetworkPolicyPort{
 			{Protocol: &protocolTCP, Port: &adminAPISSLPort},
 		},
-		From: []networkingv1.NetworkPolicyPeer{{
+		From: make([]networkingv1.NetworkPolicyPeer, 0, len(controlplanes)),
+	}
+	for _, controlplane := range controlplanes {
+		limitAdminAPIIngress.From = append(limitAdminAPIIngress.From, networkingv1.NetworkPolicyPeer{
 			PodSelector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
 					"app": controlplane.Name,
@@ -328,7 +385,7 @@ func generateDataPlaneNetworkPolicy(
 					"kubernetes.io/metadata.name": controlplane.Namespace,
 				},
 			},
-		}},
+		})
 	}
 
 	allowProxyIngress := networkingv1.NetworkPolicyIngressRule{
@@ -337,6 +394,29 @@ func generateDataPlaneNetworkPolicy(
 			{Protocol: &protocolTCP, Port: &proxySSLPort},
 		},
 	}
+	// Allow the traffic on the ports the DataPlane serves the Gateway's
+	// listeners on, as they might not be part of the proxy listen defaults.
+	if services := dataplane.Spec.Network.Services; services != nil && services.Ingress != nil {
+		for _, p := range services.Ingress.Ports {
+			targetPort := p.TargetPort
+			if targetPort == (intstr.IntOrString{}) {
+				targetPort = intstr.FromInt(int(p.Port))
+			}
+			protocol := p.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			if lo.ContainsBy(allowProxyIngress.Ports, func(np networkingv1.NetworkPolicyPort) bool {
+				return *np.Port == targetPort && *np.Protocol == protocol
+			}) {
+				continue
+			}
+			allowProxyIngress.Ports = append(allowProxyIngress.Ports, networkingv1.NetworkPolicyPort{
+				Protocol: &protocol,
+				Port:     &targetPort,
+			})
+		}
+	}
 
 	allowMetricsIngress := networkingv1.NetworkPolicyIngressRule{
 		Ports: []networkingv1.NetworkPolicyPort{
@@ -367,6 +447,25 @@ func generateDataPlaneNetworkPolicy(
 	}, nil
 }
 
+// generateDataPlanePreviewNetworkPolicy generates the NetworkPolicy of the
+// preview pods of the DataPlane, which enforces the same rules as the provided
+// NetworkPolicy of the live pods.
+func generateDataPlanePreviewNetworkPolicy(
+	livePolicy *networkingv1.NetworkPolicy,
+	dataplane *operatorv1beta1.DataPlane,
+) *networkingv1.NetworkPolicy {
+	policy := livePolicy.DeepCopy()
+	policy.GenerateName = fmt.Sprintf("%s-preview-limit-admin-api-", dataplane.Name)
+	if policy.Labels == nil {
+		policy.Labels = make(map[string]string)
+	}
+	policy.Labels[consts.DataPlaneNetworkPolicyStateLabel] = consts.DataPlaneStateLabelValuePreview
+	policy.Spec.PodSelector = metav1.LabelSelector{
+		MatchLabels: k8sresources.PreviewSelectorForDataPlane(dataplane),
+	}
+	return policy
+}
+
 // ensureOwnedControlPlanesDeleted deletes all controlplanes owned by gateway.
 // returns true if at least one controlplane resource is deleted.
 func (r *GatewayReconciler) ensureOwnedControlPlanesDeleted(ctx context.Context, gateway *gwtypes.Gateway) (bool, error) {
@@ -499,6 +598,13 @@ func (g *gatewayConditionsAwareT) InitReadyAndProgrammed() {
 					ObservedGeneration: g.Generation,
 					LastTransitionTime: metav1.Now(),
 				},
+				{
+					Type:               string(gatewayv1beta1.ListenerConditionProgrammed),
+					Status:             metav1.ConditionFalse,
+					Reason:             string(gatewayv1beta1.ListenerReasonPending),
+					ObservedGeneration: g.Generation,
+					LastTransitionTime: metav1.Now(),
+				},
 				resolvedRefsCondition,
 			},
 		}
@@ -508,9 +614,10 @@ func (g *gatewayConditionsAwareT) InitReadyAndProgrammed() {
 
 // SetReadyAndProgrammed sets the gateway Programmed and Ready conditions by
 // setting the underlying Gateway Programmed and Ready status to true.
-// Furthermore, it sets the supportedKinds and initializes the readiness to true with reason
-// Ready or false with reason Invalid for each Gateway listener.
-func (g *gatewayConditionsAwareT) SetReadyAndProgrammed() {
+// Furthermore, it sets the supportedKinds and initializes the readiness and
+// programming of each Gateway listener to true, or to false with reason Invalid
+// or Pending when the DataPlane cannot serve it or doesn't serve it yet.
+func (g *gatewayConditionsAwareT) SetReadyAndProgrammed(listeners gatewayListeners) {
 	k8sutils.SetReady(g, g.Generation)
 	k8sutils.SetProgrammed(g, g.Generation)
 	listenersStatus := []gatewayv1beta1.ListenerStatus{}
@@ -523,15 +630,38 @@ func (g *gatewayConditionsAwareT) SetReadyAndProgrammed() {
 			ObservedGeneration: g.Generation,
 			LastTransitionTime: metav1.Now(),
 		}
+		programmedCondition := metav1.Condition{
+			Type:               string(gatewayv1beta1.ListenerConditionProgrammed),
+			Status:             metav1.ConditionTrue,
+			Reason:             string(gatewayv1beta1.ListenerReasonProgrammed),
+			ObservedGeneration: g.Generation,
+			LastTransitionTime: metav1.Now(),
+		}
 		if resolvedRefsCondition.Status == metav1.ConditionFalse {
 			readyCondition.Status = metav1.ConditionFalse
 			readyCondition.Reason = string(gatewayv1beta1.ListenerReasonInvalid)
 		}
+		if message, ok := listeners.invalid[listener.Name]; ok {
+			readyCondition.Status = metav1.ConditionFalse
+			readyCondition.Reason = string(gatewayv1beta1.ListenerReasonInvalid)
+			readyCondition.Message = message
+			programmedCondition.Status = metav1.ConditionFalse
+			programmedCondition.Reason = string(gatewayv1beta1.ListenerReasonInvalid)
+			programmedCondition.Message = message
+		} else if message, ok := listeners.pending[listener.Name]; ok {
+			readyCondition.Status = metav1.ConditionFalse
+			readyCondition.Reason = string(gatewayv1beta1.ListenerReasonPending)
+			readyCondition.Message = message
+			programmedCondition.Status = metav1.ConditionFalse
+			programmedCondition.Reason = string(gatewayv1beta1.ListenerReasonPending)
+			programmedCondition.Message = message
+		}
 		lStatus := gatewayv1beta1.ListenerStatus{
 			Name:           listener.Name,
 			SupportedKinds: supportedKinds,
 			Conditions: []metav1.Condition{
 				readyCondition,
+				programmedCondition,
 				resolvedRefsCondition,
 			},
 		}
@@ -540,6 +670,13 @@ func (g *gatewayConditionsAwareT) SetReadyAndProgrammed() {
 	g.Status.Listeners = listenersStatus
 }
 
+// isProgrammedForGeneration returns true when the Programmed condition of the
+// provided Gateway has been set for the provided generation.
+func isProgrammedForGeneration(g gatewayConditionsAwareT, generation int64) bool {
+	condition, ok := k8sutils.GetCondition(k8sutils.ProgrammedType, g)
+	return ok && condition.ObservedGeneration == generation
+}
+
 // getSupportedKindsWithCondition returns all the route kinds supported by the listener, along with the resolvedRefs
 // condition, that is based on the presence of errors in such a field.
 func getSupportedKindsWithCondition(generation int64, listener gatewayv1beta1.Listener) (supportedKinds []gatewayv1beta1.RouteGroupKind, resolvedRefsCondition metav1.Condition) {
@@ -601,41 +738,60 @@ type KongListenConfig struct {
 func parseKongListenEnv(str string) (KongListenConfig, error) {
 	kongListenConfig := KongListenConfig{}
 
+	entries, err := parseKongListenEntries(str)
+	if err != nil {
+		return kongListenConfig, err
+	}
+	for _, e := range entries {
+		endpoint := &proxyListenEndpoint{
+			Address: e.Address,
+			Port:    e.Port,
+		}
+		if e.SSL {
+			kongListenConfig.SSLEndpoint = endpoint
+		} else {
+			kongListenConfig.Endpoint = endpoint
+		}
+	}
+
+	return kongListenConfig, nil
+}
+
+type kongListenEntry struct {
+	Address string
+	Port    int
+	SSL     bool
+	UDP     bool
+}
+
+// parseKongListenEntries parses all the comma separated entries of the provided
+// kong listen string.
+func parseKongListenEntries(str string) ([]kongListenEntry, error) {
+	var entries []kongListenEntry
 	for _, s := range strings.Split(str, ",") {
 		s = strings.TrimPrefix(s, " ")
-		i := strings.IndexRune(s, ' ')
-		var hostPort string
-		if i >= 0 {
-			hostPort = s[:i]
+		var hostPort, flags string
+		if i := strings.IndexRune(s, ' '); i >= 0 {
+			hostPort, flags = s[:i], s[i+1:]
 		} else {
 			hostPort = s
 		}
 
 		host, port, err := net.SplitHostPort(hostPort)
 		if err != nil {
-			return kongListenConfig, fmt.Errorf("failed parsing host %s: %w", hostPort, err)
+			return nil, fmt.Errorf("failed parsing host %s: %w", hostPort, err)
 		}
-		flags := s[i+1:]
-		if strings.Contains(flags, "ssl") {
-			p, err := strconv.Atoi(port)
-			if err != nil {
-				return kongListenConfig, fmt.Errorf("failed parsing port %s: %w", port, err)
-			}
-			kongListenConfig.SSLEndpoint = &proxyListenEndpoint{
-				Address: host,
-				Port:    p,
-			}
-		} else {
-			p, err := strconv.Atoi(port)
-			if err != nil {
-				return kongListenConfig, fmt.Errorf("failed parsing port %s: %w", port, err)
-			}
-			kongListenConfig.Endpoint = &proxyListenEndpoint{
-				Address: host,
-				Port:    p,
-			}
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing port %s: %w", port, err)
 		}
+		entries = append(entries, kongListenEntry{
+			Address: host,
+			Port:    p,
+			SSL:     strings.Contains(flags, "ssl"),
+			UDP:     strings.Contains(flags, "udp"),
+		})
 	}
 
-	return kongListenConfig, nil
+	return entries, nil
 }
@@ -3,11 +3,19 @@ package controllers
 import (
 	"testing"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
 	gwtypes "github.com/kong/gateway-operator/internal/types"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
 )
 
 func TestParseKongProxyListenEnv(t *testing.T) {
@@ -239,3 +247,129 @@ func TestGatewayAddressesFromService(t *testing.T) {
 		})
 	}
 }
+
+func TestSetReadyAndProgrammedListeners(t *testing.T) {
+	gateway := &gwtypes.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 2,
+		},
+		Spec: gatewayv1beta1.GatewaySpec{
+			Listeners: []gatewayv1beta1.Listener{
+				{Name: "http", Protocol: gatewayv1beta1.HTTPProtocolType, Port: 8080, AllowedRoutes: &gatewayv1beta1.AllowedRoutes{}},
+				{Name: "tls", Protocol: gatewayv1beta1.TLSProtocolType, Port: 8883, AllowedRoutes: &gatewayv1beta1.AllowedRoutes{}},
+				{Name: "udp", Protocol: gatewayv1beta1.UDPProtocolType, Port: 53, AllowedRoutes: &gatewayv1beta1.AllowedRoutes{}},
+			},
+		},
+	}
+	listenerCondition := func(name gatewayv1beta1.SectionName, conditionType gatewayv1beta1.ListenerConditionType) metav1.Condition {
+		for _, l := range gateway.Status.Listeners {
+			if l.Name == name {
+				condition, ok := lo.Find(l.Conditions, func(c metav1.Condition) bool { return c.Type == string(conditionType) })
+				require.True(t, ok)
+				return condition
+			}
+		}
+		require.Failf(t, "listener status not found", "listener %s", name)
+		return metav1.Condition{}
+	}
+
+	gwConditionsAware := gatewayConditionsAware(gateway)
+	gwConditionsAware.InitReadyAndProgrammed()
+	require.Equal(t, metav1.ConditionFalse, listenerCondition("http", gatewayv1beta1.ListenerConditionProgrammed).Status)
+	require.Equal(t, string(gatewayv1beta1.ListenerReasonPending), listenerCondition("http", gatewayv1beta1.ListenerConditionProgrammed).Reason)
+	require.False(t, isProgrammedForGeneration(gwConditionsAware, gateway.Generation))
+
+	gwConditionsAware.SetReadyAndProgrammed(gatewayListeners{
+		invalid: map[gatewayv1beta1.SectionName]string{
+			"tls": "TLS listeners are only supported in the Passthrough mode",
+		},
+		pending: map[gatewayv1beta1.SectionName]string{
+			"udp": "waiting for the DataPlane Service to expose port 53",
+		},
+	})
+	require.True(t, isProgrammedForGeneration(gwConditionsAware, gateway.Generation))
+	require.Equal(t, metav1.ConditionTrue, listenerCondition("http", gatewayv1beta1.ListenerConditionProgrammed).Status)
+	require.Equal(t, metav1.ConditionTrue, listenerCondition("http", gatewayv1beta1.ListenerConditionReady).Status)
+
+	programmed := listenerCondition("tls", gatewayv1beta1.ListenerConditionProgrammed)
+	require.Equal(t, metav1.ConditionFalse, programmed.Status)
+	require.Equal(t, string(gatewayv1beta1.ListenerReasonInvalid), programmed.Reason)
+	require.Equal(t, "TLS listeners are only supported in the Passthrough mode", programmed.Message)
+	require.Equal(t, metav1.ConditionFalse, listenerCondition("tls", gatewayv1beta1.ListenerConditionReady).Status)
+
+	ready := listenerCondition("udp", gatewayv1beta1.ListenerConditionReady)
+	require.Equal(t, metav1.ConditionFalse, ready.Status)
+	require.Equal(t, string(gatewayv1beta1.ListenerReasonPending), ready.Reason)
+	require.Equal(t, "waiting for the DataPlane Service to expose port 53", ready.Message)
+	require.Equal(t, string(gatewayv1beta1.ListenerReasonPending), listenerCondition("udp", gatewayv1beta1.ListenerConditionProgrammed).Reason)
+}
+
+func TestGenerateDataPlaneNetworkPolicyControlPlanes(t *testing.T) {
+	gatewayConfig := &operatorv1alpha1.GatewayConfiguration{
+		Spec: operatorv1alpha1.GatewayConfigurationSpec{
+			DataPlaneOptions: &operatorv1beta1.DataPlaneOptions{
+				Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+					DeploymentOptions: operatorv1beta1.DeploymentOptions{
+						PodTemplateSpec: &corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: consts.DataPlaneProxyContainerName}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	dataplane := &operatorv1beta1.DataPlane{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gateway-dataplane"},
+	}
+	controlplanes := []operatorv1alpha1.ControlPlane{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gateway-controlplane"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "shared", Name: "regional-controlplane"}},
+	}
+
+	policy, err := generateDataPlaneNetworkPolicy("default", gatewayConfig, dataplane, controlplanes)
+	require.NoError(t, err)
+	adminAPIIngress := policy.Spec.Ingress[0]
+	require.Equal(t, intstr.FromInt(consts.DataPlaneAdminAPIPort), *adminAPIIngress.Ports[0].Port)
+	require.Len(t, adminAPIIngress.From, 2, "every ControlPlane linked to the DataPlane should be admitted")
+	for i, controlplane := range controlplanes {
+		require.Equal(t, map[string]string{"app": controlplane.Name}, adminAPIIngress.From[i].PodSelector.MatchLabels)
+		require.Equal(t, map[string]string{"kubernetes.io/metadata.name": controlplane.Namespace}, adminAPIIngress.From[i].NamespaceSelector.MatchLabels)
+	}
+}
+
+func TestGenerateDataPlanePreviewNetworkPolicy(t *testing.T) {
+	gatewayConfig := &operatorv1alpha1.GatewayConfiguration{
+		Spec: operatorv1alpha1.GatewayConfigurationSpec{
+			DataPlaneOptions: &operatorv1beta1.DataPlaneOptions{
+				Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+					DeploymentOptions: operatorv1beta1.DeploymentOptions{
+						PodTemplateSpec: &corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: consts.DataPlaneProxyContainerName}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	dataplane := &operatorv1beta1.DataPlane{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gateway-dataplane"},
+	}
+	controlplanes := []operatorv1alpha1.ControlPlane{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gateway-controlplane"}},
+	}
+
+	livePolicy, err := generateDataPlaneNetworkPolicy("default", gatewayConfig, dataplane, controlplanes)
+	require.NoError(t, err)
+	previewPolicy := generateDataPlanePreviewNetworkPolicy(livePolicy, dataplane)
+
+	require.Equal(t, k8sresources.PreviewSelectorForDataPlane(dataplane), previewPolicy.Spec.PodSelector.MatchLabels,
+		"the preview pods should be selected")
+	require.Equal(t, map[string]string{"app": dataplane.Name}, livePolicy.Spec.PodSelector.MatchLabels,
+		"the live policy should be left unchanged")
+	require.Equal(t, consts.DataPlaneStateLabelValuePreview, previewPolicy.Labels[consts.DataPlaneNetworkPolicyStateLabel])
+	require.Equal(t, livePolicy.Spec.Ingress, previewPolicy.Spec.Ingress)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/go-logr/logr"
 	"github.com/google/uuid"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
@@ -26,6 +27,7 @@ import (
 	gwtypes "github.com/kong/gateway-operator/internal/types"
 	dataplaneutils "github.com/kong/gateway-operator/internal/utils/dataplane"
 	gatewayutils "github.com/kong/gateway-operator/internal/utils/gateway"
+	"github.com/kong/gateway-operator/internal/utils/index"
 	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
 	"github.com/kong/gateway-operator/pkg/vars"
 )
@@ -342,6 +344,8 @@ func TestGatewayReconciler_Reconcile(t *testing.T) {
 				WithScheme(scheme.Scheme).
 				WithObjects(ObjectsToAdd...).
 				WithStatusSubresource(ObjectsToAdd...).
+				WithIndex(&operatorv1alpha1.ControlPlane{}, index.DataplaneNameIndex, index.DataPlaneNamesOnControlPlane).
+				WithIndex(&operatorv1alpha1.ControlPlane{}, index.DataPlaneSelectorNamespaceIndex, index.DataPlaneSelectorNamespacesOnControlPlane).
 				Build()
 
 			reconciler := GatewayReconciler{
@@ -353,6 +357,110 @@ func TestGatewayReconciler_Reconcile(t *testing.T) {
 	}
 }
 
+func TestGatewayReconciler_provisionControlPlaneUpdatesOptions(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		controlPlaneOptions  operatorv1alpha1.ControlPlaneOptions
+		expectedControlPlane func(t *testing.T, controlplane *operatorv1alpha1.ControlPlane)
+	}{
+		{
+			name: "dataplanes",
+			controlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+				DataPlanes: []string{"other-dataplane"},
+			},
+			expectedControlPlane: func(t *testing.T, controlplane *operatorv1alpha1.ControlPlane) {
+				require.Equal(t, []string{"other-dataplane"}, controlplane.Spec.DataPlanes)
+			},
+		},
+		{
+			name: "dataplane selector",
+			controlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+				DataPlaneSelector: &operatorv1alpha1.DataPlaneSelector{
+					Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "kong"}},
+				},
+			},
+			expectedControlPlane: func(t *testing.T, controlplane *operatorv1alpha1.ControlPlane) {
+				require.NotNil(t, controlplane.Spec.DataPlaneSelector)
+				require.Equal(t, map[string]string{"app": "kong"}, controlplane.Spec.DataPlaneSelector.Selector.MatchLabels)
+			},
+		},
+		{
+			name: "watch namespaces",
+			controlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+				WatchNamespaces: []string{"ns-1", "ns-2"},
+			},
+			expectedControlPlane: func(t *testing.T, controlplane *operatorv1alpha1.ControlPlane) {
+				require.Equal(t, []string{"ns-1", "ns-2"}, controlplane.Spec.WatchNamespaces)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			gatewayClass := &gatewayv1beta1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-gatewayclass",
+				},
+			}
+			gateway := &gwtypes.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-gateway",
+					Namespace: "test-namespace",
+					UID:       types.UID(uuid.NewString()),
+				},
+			}
+			dataplane := &operatorv1beta1.DataPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-dataplane",
+					Namespace: "test-namespace",
+				},
+			}
+			services := []corev1.Service{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-proxy-service",
+						Namespace: "test-namespace",
+					},
+				},
+			}
+
+			reconciler := GatewayReconciler{}
+			currentGatewayConfig := &operatorv1alpha1.GatewayConfiguration{}
+			require.NoError(t, reconciler.setControlplaneGatewayConfigDefaults(gateway, currentGatewayConfig, dataplane.Name, services[0].Name))
+			controlplane := &operatorv1alpha1.ControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-controlplane",
+					Namespace: "test-namespace",
+				},
+				Spec: operatorv1alpha1.ControlPlaneSpec{
+					ControlPlaneOptions: *currentGatewayConfig.Spec.ControlPlaneOptions,
+				},
+			}
+			k8sutils.SetOwnerForObject(controlplane, gateway)
+			gatewayutils.LabelObjectAsGatewayManaged(controlplane)
+
+			reconciler.Client = fakectrlruntimeclient.
+				NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(controlplane).
+				Build()
+
+			gatewayConfig := &operatorv1alpha1.GatewayConfiguration{
+				Spec: operatorv1alpha1.GatewayConfigurationSpec{
+					ControlPlaneOptions: tc.controlPlaneOptions.DeepCopy(),
+				},
+			}
+			reconciler.provisionControlPlane(ctx, logr.Discard(), gatewayClass, gateway, gatewayConfig, dataplane, services)
+
+			require.NoError(t, reconciler.Client.Get(ctx, controllerruntimeclient.ObjectKeyFromObject(controlplane), controlplane))
+			tc.expectedControlPlane(t, controlplane)
+		})
+	}
+}
+
 func Test_setControlPlaneOptionsDefaults(t *testing.T) {
 	testcases := []struct {
 		name     string
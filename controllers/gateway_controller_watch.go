@@ -20,6 +20,7 @@ import (
 	operatorerrors "github.com/kong/gateway-operator/internal/errors"
 	gwtypes "github.com/kong/gateway-operator/internal/types"
 	dataplaneutils "github.com/kong/gateway-operator/internal/utils/dataplane"
+	gatewayutils "github.com/kong/gateway-operator/internal/utils/gateway"
 	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
 	"github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
 	"github.com/kong/gateway-operator/pkg/vars"
@@ -184,6 +185,46 @@ func (r *GatewayReconciler) listGatewaysForGatewayConfig(ctx context.Context, ob
 	return
 }
 
+// listGatewaysForControlPlaneDataPlanes maps a ControlPlane on the Gateways owning
+// the DataPlanes the ControlPlane pushes its configuration to, as their
+// NetworkPolicies admit all these ControlPlanes.
+func (r *GatewayReconciler) listGatewaysForControlPlaneDataPlanes(ctx context.Context, obj client.Object) (recs []reconcile.Request) {
+	controlplane, ok := obj.(*operatorv1alpha1.ControlPlane)
+	if !ok {
+		log.FromContext(ctx).Error(
+			operatorerrors.ErrUnexpectedObject,
+			"failed to run map funcs",
+			"expected", "ControlPlane", "found", reflect.TypeOf(obj),
+		)
+		return
+	}
+
+	dataplanes, err := gatewayutils.ListDataPlanesForControlPlane(ctx, r.Client, controlplane)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "could not list dataplanes in map func")
+		return
+	}
+
+	for _, dataplane := range dataplanes {
+		for _, ownerRef := range dataplane.OwnerReferences {
+			if ownerRef.APIVersion != gatewayv1beta1.GroupVersion.String() || ownerRef.Kind != "Gateway" {
+				continue
+			}
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: dataplane.Namespace,
+					Name:      ownerRef.Name,
+				},
+			}
+			if !lo.Contains(recs, req) {
+				recs = append(recs, req)
+			}
+		}
+	}
+
+	return
+}
+
 func (r *GatewayReconciler) setDataplaneGatewayConfigDefaults(gatewayConfig *operatorv1alpha1.GatewayConfiguration) {
 	if gatewayConfig.Spec.DataPlaneOptions == nil {
 		gatewayConfig.Spec.DataPlaneOptions = new(operatorv1beta1.DataPlaneOptions)
@@ -1,10 +1,13 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
@@ -23,12 +26,18 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
 	certificatesv1 "k8s.io/api/certificates/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlruntimelog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
@@ -41,10 +50,145 @@ import (
 )
 
 // -----------------------------------------------------------------------------
-// Private Consts
+// Certificate Options
 // -----------------------------------------------------------------------------
 
-const requeueWithoutBackoff = time.Millisecond * 200
+// DefaultCertificateValidity is the validity period of the certificates issued
+// for DataPlanes and ControlPlanes when none is configured.
+const DefaultCertificateValidity = time.Hour * 24 * 365
+
+// CertificateKeyAlgorithm is the algorithm of the private keys of the
+// certificates issued for DataPlanes and ControlPlanes.
+type CertificateKeyAlgorithm string
+
+const (
+	// CertificateKeyAlgorithmECDSA generates ECDSA keys, of 256, 384 or 521 bits.
+	CertificateKeyAlgorithmECDSA CertificateKeyAlgorithm = "ECDSA"
+	// CertificateKeyAlgorithmRSA generates RSA keys, of 2048, 3072 or 4096 bits.
+	CertificateKeyAlgorithmRSA CertificateKeyAlgorithm = "RSA"
+)
+
+// CertificateOptions configures the certificates issued for DataPlanes and
+// ControlPlanes.
+type CertificateOptions struct {
+	// Validity is the validity period of the issued certificates. It defaults
+	// to DefaultCertificateValidity.
+	Validity time.Duration
+	// RenewBefore is how long before their expiry the certificates are renewed.
+	RenewBefore time.Duration
+	// KeyAlgorithm is the algorithm of the private keys of the certificates.
+	// It defaults to CertificateKeyAlgorithmECDSA.
+	KeyAlgorithm CertificateKeyAlgorithm
+	// KeySize is the size of the private keys of the certificates, in bits. It
+	// defaults to 256 for ECDSA keys and to 2048 for RSA keys.
+	KeySize int
+	// SignatureAlgorithm is the algorithm the certificates are signed with by
+	// the cluster CA. It has to be compatible with the key of the cluster CA and
+	// defaults to SHA-256 with the algorithm of that key.
+	SignatureAlgorithm x509.SignatureAlgorithm
+	// CertManagerIssuer is the cert-manager issuer the certificates are requested
+	// from. When it is not set, they are issued from the cluster CA.
+	CertManagerIssuer *CertManagerIssuerRef
+}
+
+// Validate returns an error when the options cannot be used to issue certificates.
+// The compatibility of the signature algorithm with the key of the cluster CA
+// is validated along with the cluster CA.
+func (o CertificateOptions) Validate() error {
+	if o.RenewBefore >= o.validity() {
+		return fmt.Errorf("the certificates renewal period (%s) must be shorter than their validity (%s)",
+			o.RenewBefore, o.validity())
+	}
+	var sizes []int
+	switch o.keyAlgorithm() {
+	case CertificateKeyAlgorithmECDSA:
+		sizes = []int{256, 384, 521}
+	case CertificateKeyAlgorithmRSA:
+		sizes = []int{2048, 3072, 4096}
+	default:
+		return fmt.Errorf("unsupported key algorithm %s, supported algorithms are %s and %s",
+			o.KeyAlgorithm, CertificateKeyAlgorithmECDSA, CertificateKeyAlgorithmRSA)
+	}
+	if !lo.Contains(sizes, o.keySize()) {
+		return fmt.Errorf("unsupported %s key size %d, supported sizes are %v", o.keyAlgorithm(), o.KeySize, sizes)
+	}
+	return nil
+}
+
+func (o CertificateOptions) validity() time.Duration {
+	if o.Validity <= 0 {
+		return DefaultCertificateValidity
+	}
+	return o.Validity
+}
+
+func (o CertificateOptions) keyAlgorithm() CertificateKeyAlgorithm {
+	if o.KeyAlgorithm == "" {
+		return CertificateKeyAlgorithmECDSA
+	}
+	return o.KeyAlgorithm
+}
+
+func (o CertificateOptions) keySize() int {
+	switch {
+	case o.KeySize > 0:
+		return o.KeySize
+	case o.keyAlgorithm() == CertificateKeyAlgorithmRSA:
+		return 2048
+	default:
+		return 256
+	}
+}
+
+// matchesKey returns true when the provided public key is of the algorithm and
+// size configured by the options.
+func (o CertificateOptions) matchesKey(pub crypto.PublicKey) bool {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return o.keyAlgorithm() == CertificateKeyAlgorithmRSA && pub.N.BitLen() == o.keySize()
+	case *ecdsa.PublicKey:
+		return o.keyAlgorithm() == CertificateKeyAlgorithmECDSA && pub.Curve.Params().BitSize == o.keySize()
+	default:
+		return false
+	}
+}
+
+// generateKey generates a private key as configured by the options. It returns
+// the key and its PEM encoding.
+func (o CertificateOptions) generateKey() (crypto.Signer, []byte, error) {
+	if o.keyAlgorithm() == CertificateKeyAlgorithmRSA {
+		priv, err := rsa.GenerateKey(rand.Reader, o.keySize())
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(priv),
+		}), nil
+	}
+
+	var curve elliptic.Curve
+	switch o.keySize() {
+	case 384:
+		curve = elliptic.P384()
+	case 521:
+		curve = elliptic.P521()
+	default:
+		curve = elliptic.P256()
+	}
+	priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	privDer, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv, pem.EncodeToMemory(&pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: privDer,
+	}), nil
+}
 
 // -----------------------------------------------------------------------------
 // Private Functions - Certificate management
@@ -90,13 +234,14 @@ limitations under the License.
 */
 
 // signCertificate takes a CertificateSigningRequest and a TLS Secret and returns a PEM x.509 certificate
-// signed by the certificate in the Secret.
-func signCertificate(csr certificatesv1.CertificateSigningRequest, ca *corev1.Secret) ([]byte, error) {
-	caKeyBlock, _ := pem.Decode(ca.Data["tls.key"])
-	if caKeyBlock == nil {
-		return nil, fmt.Errorf("failed decoding 'tls.key' data from secret %s", ca.Name)
+// signed by the certificate in the Secret with the provided signature algorithm, or with the default
+// algorithm of the CA key when it is x509.UnknownSignatureAlgorithm.
+func signCertificate(csr certificatesv1.CertificateSigningRequest, ca *corev1.Secret, signatureAlgorithm x509.SignatureAlgorithm) ([]byte, error) {
+	priv, err := k8sutils.ParsePrivateKey(ca.Data["tls.key"])
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding 'tls.key' data from secret %s: %w", ca.Name, err)
 	}
-	priv, err := x509.ParseECPrivateKey(caKeyBlock.Bytes)
+	signatureAlgorithm, err = k8sutils.SignatureAlgorithmForKey(priv.Public(), signatureAlgorithm)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +276,7 @@ func signCertificate(csr certificatesv1.CertificateSigningRequest, ca *corev1.Se
 			ExpiryString: certExpiryDuration.String(),
 		},
 	}
-	cfs, err := local.NewSigner(priv, caCert, x509.ECDSAWithSHA256, policy)
+	cfs, err := local.NewSigner(priv, caCert, signatureAlgorithm, policy)
 	if err != nil {
 		return nil, err
 	}
@@ -147,28 +292,50 @@ func signCertificate(csr certificatesv1.CertificateSigningRequest, ca *corev1.Se
 // mtlsCASecretNamespace/mtlsCASecretName Secret, or does nothing if a namespace/name Secret is
 // already present. It returns a boolean indicating if it created a Secret and an error indicating
 // any failures it encountered.
+// The certificate is valid for the subject and the provided additional DNS names.
+// An owner can hold several certificate Secrets, which are told apart by their purpose label.
+// The Secret securing the communication between the operator and the owner has an empty purpose.
+// The certificate of an existing Secret is reissued when it has not been signed by the
+// current CA, when it expires within the certOpts renewal window or when its key does not
+// match the certOpts key options, and the Secret is updated to trust the CA certificates
+// currently trusted.
 func maybeCreateCertificateSecret(
 	ctx context.Context,
 	owner client.Object,
 	subject string,
+	dnsNames []string,
+	purpose string,
 	mtlsCASecretNN types.NamespacedName,
 	usages []certificatesv1.KeyUsage,
+	certOpts CertificateOptions,
 	k8sClient client.Client,
 ) (bool, *corev1.Secret, error) {
 	setCALogger(ctrlruntimelog.Log)
 
 	selectorKey, selectorValue := getManagedLabelForOwner(owner)
+	purposeRequirement, err := certificatePurposeRequirement(purpose)
+	if err != nil {
+		return false, nil, err
+	}
 	secrets, err := k8sutils.ListSecretsForOwner(
 		ctx,
 		k8sClient,
 		owner.GetUID(),
-		client.MatchingLabels{
-			selectorKey: selectorValue,
+		client.MatchingLabelsSelector{
+			Selector: labels.SelectorFromSet(labels.Set{
+				selectorKey: selectorValue,
+			}).Add(*purposeRequirement),
 		},
 	)
 	if err != nil {
 		return false, nil, err
 	}
+	// The Secrets issued by cert-manager, when it was configured as the issuer,
+	// are left to their Certificates.
+	secrets = lo.Reject(secrets, func(secret corev1.Secret, _ int) bool {
+		_, ok := secret.Annotations[consts.CertManagerCertificateNameAnnotation]
+		return ok
+	})
 
 	count := len(secrets)
 	if count > 1 {
@@ -182,16 +349,19 @@ func maybeCreateCertificateSecret(
 	generatedSecret := k8sresources.GenerateNewTLSSecret(owner.GetNamespace(), owner.GetName(), ownerPrefix)
 	k8sutils.SetOwnerForObject(generatedSecret, owner)
 	addLabelForOwner(generatedSecret, owner)
+	if purpose != "" {
+		generatedSecret.Labels[consts.CertificatePurposeLabel] = purpose
+	}
 
 	// If there are no secrets yet, then create one.
 	if count == 0 {
-		return generateTLSDataSecret(ctx, generatedSecret, owner, subject, mtlsCASecretNN, usages, k8sClient)
+		return generateTLSDataSecret(ctx, generatedSecret, owner, subject, dnsNames, mtlsCASecretNN, usages, certOpts, k8sClient)
 	}
 
 	// Otherwise there is already 1 certificate matching specified selectors.
 	existingSecret := &secrets[0]
 
-	// Check if existing certificate is for a different subject.
+	// Check if existing certificate is for a different subject or different DNS names.
 	// If that's the case, delete the old certificate and create a new one.
 	block, _ := pem.Decode(existingSecret.Data["tls.crt"])
 	if block == nil {
@@ -200,24 +370,29 @@ func maybeCreateCertificateSecret(
 			return false, nil, err
 		}
 
-		return generateTLSDataSecret(ctx, generatedSecret, owner, subject, mtlsCASecretNN, usages, k8sClient)
+		return generateTLSDataSecret(ctx, generatedSecret, owner, subject, dnsNames, mtlsCASecretNN, usages, certOpts, k8sClient)
 	}
 
 	cert, err := x509.ParseCertificate(block.Bytes)
 	if err != nil {
 		return false, nil, err
 	}
-	if cert.Subject.CommonName != subject {
+	if cert.Subject.CommonName != subject || !cmp.Equal(cert.DNSNames, certificateDNSNames(subject, dnsNames)) {
 		if err := k8sClient.Delete(ctx, existingSecret); err != nil {
 			return false, nil, err
 		}
 
-		return generateTLSDataSecret(ctx, generatedSecret, owner, subject, mtlsCASecretNN, usages, k8sClient)
+		return generateTLSDataSecret(ctx, generatedSecret, owner, subject, dnsNames, mtlsCASecretNN, usages, certOpts, k8sClient)
+	}
+
+	dataUpdated, err := maybeReissueCertificate(ctx, existingSecret, cert, owner, subject, dnsNames, mtlsCASecretNN, usages, certOpts, k8sClient)
+	if err != nil {
+		return false, nil, err
 	}
 
 	var updated bool
 	updated, existingSecret.ObjectMeta = k8sutils.EnsureObjectMetaIsUpdated(existingSecret.ObjectMeta, generatedSecret.ObjectMeta)
-	if updated {
+	if updated || dataUpdated {
 		if err := k8sClient.Update(ctx, existingSecret); err != nil {
 			return false, existingSecret, fmt.Errorf("failed updating secret %s: %w", existingSecret.Name, err)
 		}
@@ -226,6 +401,21 @@ func maybeCreateCertificateSecret(
 	return false, existingSecret, nil
 }
 
+// certificatePurposeRequirement returns the label requirement selecting the
+// certificate Secrets issued for the provided purpose.
+func certificatePurposeRequirement(purpose string) (*labels.Requirement, error) {
+	if purpose == "" {
+		return labels.NewRequirement(consts.CertificatePurposeLabel, selection.DoesNotExist, nil)
+	}
+	return labels.NewRequirement(consts.CertificatePurposeLabel, selection.Equals, []string{purpose})
+}
+
+// certificateDNSNames returns the DNS names a certificate issued for subject
+// and the provided additional DNS names is valid for.
+func certificateDNSNames(subject string, dnsNames []string) []string {
+	return lo.Uniq(append([]string{subject}, dnsNames...))
+}
+
 // generateTLSDataSecret generates a TLS certificate data, fills the provided secret with
 // that data and creates it using the k8s client.
 // It returns a boolean indicating whether the secret has been created, the secret
@@ -235,39 +425,72 @@ func generateTLSDataSecret(
 	generatedSecret *corev1.Secret,
 	owner client.Object,
 	subject string,
+	dnsNames []string,
 	mtlsCASecret types.NamespacedName,
 	usages []certificatesv1.KeyUsage,
+	certOpts CertificateOptions,
 	k8sClient client.Client,
 ) (bool, *corev1.Secret, error) {
+	ca := &corev1.Secret{}
+	err := k8sClient.Get(ctx, mtlsCASecret, ca)
+	if err != nil {
+		return false, nil, err
+	}
+
+	signed, key, err := issueCertificate(owner, subject, dnsNames, ca, usages, certOpts)
+	if err != nil {
+		return false, nil, err
+	}
+
+	generatedSecret.StringData = map[string]string{
+		"ca.crt":  string(k8sutils.CATrustBundle(ca)),
+		"tls.crt": string(signed),
+		"tls.key": string(key),
+	}
+
+	err = k8sClient.Create(ctx, generatedSecret)
+	if err != nil {
+		return false, nil, err
+	}
+
+	return true, generatedSecret, nil
+}
+
+// issueCertificate generates a private key and a certificate for it, signed by
+// the CA in the provided Secret and valid for the certOpts validity period, or
+// until the CA expires if it expires earlier. It returns the PEM encoded certificate,
+// followed by the intermediate CA certificates it is verified through, and key.
+func issueCertificate(
+	owner client.Object,
+	subject string,
+	dnsNames []string,
+	ca *corev1.Secret,
+	usages []certificatesv1.KeyUsage,
+	certOpts CertificateOptions,
+) ([]byte, []byte, error) {
 	template := x509.CertificateRequest{
 		Subject: pkix.Name{
 			CommonName:   subject,
 			Organization: []string{"Kong, Inc."},
 			Country:      []string{"US"},
 		},
-		SignatureAlgorithm: x509.ECDSAWithSHA256,
-		DNSNames:           []string{subject},
+		DNSNames: certificateDNSNames(subject, dnsNames),
 	}
 
-	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	priv, keyPEM, err := certOpts.generateKey()
 	if err != nil {
-		return false, nil, err
+		return nil, nil, err
 	}
 
 	der, err := x509.CreateCertificateRequest(rand.Reader, &template, priv)
 	if err != nil {
-		return false, nil, err
+		return nil, nil, err
 	}
 
 	// This is effectively a placeholder so long as we handle signing internally. When actually creating CSR resources,
 	// this string is used by signers to filter which resources they pay attention to
 	signerName := "gateway-operator.konghq.com/mtls"
-	// TODO This creates certificates that last for 10 years as an arbitrarily long period for the alpha. A production-
-	// ready implementation should use a shorter lifetime and rotate certificates. Rotation requires some mechanism to
-	// recognize that certificates have expired (ideally without permissions to read Secrets across the cluster) and
-	// to get Deployments to acknowledge them. For Kong, this requires a restart, as there's no way to force a reload
-	// of updated files on disk.
-	expiration := int32(315400000)
+	expiration := int32(certOpts.validity().Seconds())
 
 	csr := certificatesv1.CertificateSigningRequest{
 		ObjectMeta: metav1.ObjectMeta{
@@ -285,36 +508,166 @@ func generateTLSDataSecret(
 		},
 	}
 
-	ca := &corev1.Secret{}
-	err = k8sClient.Get(ctx, mtlsCASecret, ca)
+	signed, err := signCertificate(csr, ca, certOpts.SignatureAlgorithm)
 	if err != nil {
-		return false, nil, err
+		return nil, nil, err
+	}
+	caCerts, err := k8sutils.ParseCertificates(ca.Data["tls.crt"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed parsing the CA certificate of secret %s: %w", ca.Name, err)
 	}
 
-	signed, err := signCertificate(csr, ca)
+	return append(signed, k8sutils.IntermediateCACertificates(caCerts)...), keyPEM, nil
+}
+
+// maybeReissueCertificate reissues the certificate held by the provided Secret
+// when it has not been signed by the CA in the mtlsCASecretNN Secret, e.g. after
+// the CA has been rotated, when it expires within the certOpts renewal window or
+// when its key is not of the configured algorithm and size, and sets the CA certificates currently trusted in the Secret. It returns true
+// when the Secret data has been changed.
+func maybeReissueCertificate(
+	ctx context.Context,
+	secret *corev1.Secret,
+	cert *x509.Certificate,
+	owner client.Object,
+	subject string,
+	dnsNames []string,
+	mtlsCASecretNN types.NamespacedName,
+	usages []certificatesv1.KeyUsage,
+	certOpts CertificateOptions,
+	k8sClient client.Client,
+) (bool, error) {
+	ca := &corev1.Secret{}
+	if err := k8sClient.Get(ctx, mtlsCASecretNN, ca); err != nil {
+		return false, err
+	}
+	caCerts, err := k8sutils.ParseCertificates(ca.Data["tls.crt"])
 	if err != nil {
-		return false, nil, err
+		return false, fmt.Errorf("failed parsing the CA certificate of secret %s: %w", ca.Name, err)
 	}
-	privDer, err := x509.MarshalECPrivateKey(priv)
+
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	var updated bool
+	if cert.CheckSignatureFrom(caCerts[0]) != nil ||
+		isCertificateDueForRenewal(cert, caCerts[0], certOpts.RenewBefore) ||
+		!certOpts.matchesKey(cert.PublicKey) {
+		signed, key, err := issueCertificate(owner, subject, dnsNames, ca, usages, certOpts)
+		if err != nil {
+			return false, err
+		}
+		secret.Data["tls.crt"] = signed
+		secret.Data["tls.key"] = key
+		updated = true
+	}
+	if bundle := k8sutils.CATrustBundle(ca); !bytes.Equal(secret.Data["ca.crt"], bundle) {
+		secret.Data["ca.crt"] = bundle
+		updated = true
+	}
+	return updated, nil
+}
+
+// isCertificateDueForRenewal returns true when the provided certificate expires
+// within the renewBefore period. Certificates are not issued past the expiry of
+// their CA, so while the CA expires within that period as well, the certificate
+// is not renewed, which would reissue it over and over, but reissued once the CA
+// is rotated.
+func isCertificateDueForRenewal(cert, caCert *x509.Certificate, renewBefore time.Duration) bool {
+	return time.Until(cert.NotAfter) < renewBefore && time.Until(caCert.NotAfter) > renewBefore
+}
+
+// listCertificateSecrets returns the Secrets owned by the provided owner which
+// hold the certificates issued for it.
+func listCertificateSecrets(ctx context.Context, k8sClient client.Client, owner client.Object) ([]corev1.Secret, error) {
+	selectorKey, selectorValue := getManagedLabelForOwner(owner)
+	secrets, err := k8sutils.ListSecretsForOwner(
+		ctx,
+		k8sClient,
+		owner.GetUID(),
+		client.InNamespace(owner.GetNamespace()),
+		client.MatchingLabels{selectorKey: selectorValue},
+	)
 	if err != nil {
-		return false, nil, err
+		return nil, err
 	}
+	return lo.Filter(secrets, func(secret corev1.Secret, _ int) bool {
+		return k8sutils.IsCertificateSecret(&secret)
+	}), nil
+}
 
-	generatedSecret.StringData = map[string]string{
-		"ca.crt":  string(ca.Data["tls.crt"]),
-		"tls.crt": string(signed),
-		"tls.key": string(pem.EncodeToMemory(&pem.Block{
-			Type:  "EC PRIVATE KEY",
-			Bytes: privDer,
-		})),
+// certificatesChecksum returns the checksum of the certificate Secrets owned
+// by the provided owner, which its Deployments are annotated with in order to
+// restart their pods when the certificates are reissued.
+func certificatesChecksum(ctx context.Context, k8sClient client.Client, owner client.Object) (string, error) {
+	secrets, err := listCertificateSecrets(ctx, k8sClient, owner)
+	if err != nil {
+		return "", err
 	}
+	return k8sutils.CertificatesChecksum(secrets), nil
+}
 
-	err = k8sClient.Create(ctx, generatedSecret)
+// certificatesRenewalDelay returns how long until the earliest of the certificates
+// issued for the provided owner is due for renewal, which its reconciliation is
+// requeued after. It returns 0 when there is no certificate to renew.
+func certificatesRenewalDelay(ctx context.Context, k8sClient client.Client, owner client.Object, renewBefore time.Duration) (time.Duration, error) {
+	secrets, err := listCertificateSecrets(ctx, k8sClient, owner)
 	if err != nil {
-		return false, nil, err
+		return 0, err
+	}
+	var delay time.Duration
+	for _, secret := range secrets {
+		certs, err := k8sutils.ParseCertificates(secret.Data["tls.crt"])
+		if err != nil {
+			return 0, fmt.Errorf("failed parsing the certificate of secret %s: %w", secret.Name, err)
+		}
+		// The certificates due for renewal already have been renewed, unless
+		// they wait for the CA to be rotated.
+		certDelay := time.Until(certs[0].NotAfter.Add(-renewBefore))
+		if certDelay > 0 && (delay == 0 || certDelay < delay) {
+			delay = certDelay
+		}
 	}
+	return delay, nil
+}
 
-	return true, generatedSecret, nil
+// requeueBefore returns the provided result, requeued after the provided delay
+// unless it is requeued earlier already.
+func requeueBefore(res ctrl.Result, delay time.Duration) ctrl.Result {
+	if delay <= 0 || (res.Requeue && res.RequeueAfter == 0) {
+		return res
+	}
+	if res.RequeueAfter == 0 || delay < res.RequeueAfter {
+		res.RequeueAfter = delay
+	}
+	return res
+}
+
+// hasManagedLabel returns a predicate selecting the objects labeled as managed
+// by the provided controller, such as the pods of its Deployments.
+func hasManagedLabel(managedLabelValue string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetLabels()[consts.GatewayOperatorControlledLabel] == managedLabelValue
+	})
+}
+
+// isClusterCASecret returns a predicate selecting the cluster CA Secret.
+func isClusterCASecret(clusterCASecret types.NamespacedName) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == clusterCASecret.Namespace && obj.GetName() == clusterCASecret.Name
+	})
+}
+
+// setCertificatesChecksum annotates the pod template of the provided Deployment
+// with the provided certificates checksum, if any.
+func setCertificatesChecksum(deployment *appsv1.Deployment, checksum string) {
+	if checksum == "" {
+		return
+	}
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = make(map[string]string)
+	}
+	deployment.Spec.Template.Annotations[consts.CertificatesChecksumAnnotation] = checksum
 }
 
 // -----------------------------------------------------------------------------
@@ -395,7 +748,8 @@ func alphaDeploymentOptionsDeepEqual(o1, o2 *operatorv1alpha1.DeploymentOptions,
 		return false
 	}
 
-	if !reflect.DeepEqual(o1.Replicas, o2.Replicas) {
+	if !reflect.DeepEqual(o1.Replicas, o2.Replicas) ||
+		!reflect.DeepEqual(o1.PodDisruptionBudget, o2.PodDisruptionBudget) {
 		return false
 	}
 
@@ -543,3 +897,54 @@ func ensureContainerImageUpdated(container *corev1.Container, imageVersionStr st
 
 	return
 }
+
+// -----------------------------------------------------------------------------
+// Private Functions - Deployment Readiness
+// -----------------------------------------------------------------------------
+
+// setNotReadyForUnavailableDeployment sets the Ready condition of the provided
+// owner of an unavailable Deployment to false. When the pods of the Deployment
+// are failing, the condition reports the failure and the affected pods, and a
+// warning event is recorded unless the failure was already reported.
+func setNotReadyForUnavailableDeployment[T interface {
+	client.Object
+	k8sutils.ConditionsAware
+}](
+	ctx context.Context,
+	cl client.Client,
+	eventRecorder record.EventRecorder,
+	owner T,
+	deployment *appsv1.Deployment,
+) error {
+	failure, failed, err := k8sutils.DiagnoseDeploymentPods(ctx, cl, deployment)
+	if err != nil {
+		return fmt.Errorf("failed diagnosing pods of Deployment %s: %w", deployment.Name, err)
+	}
+	if !failed {
+		k8sutils.SetCondition(
+			k8sutils.NewCondition(k8sutils.ReadyType, metav1.ConditionFalse, k8sutils.WaitingToBecomeReadyReason, k8sutils.WaitingToBecomeReadyMessage),
+			owner,
+		)
+		return nil
+	}
+
+	// The Ready condition of the owner is reinitialized on each reconciliation,
+	// the failure reported last is looked up in the persisted status instead.
+	current, ok := owner.DeepCopyObject().(T)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", owner)
+	}
+	if err := cl.Get(ctx, client.ObjectKeyFromObject(owner), current); err != nil {
+		return err
+	}
+	if reported, ok := k8sutils.GetCondition(k8sutils.ReadyType, current); !ok ||
+		reported.Reason != string(failure.Reason) || reported.Message != failure.Message {
+		eventRecorder.Event(owner, corev1.EventTypeWarning, string(failure.Reason), failure.Message)
+	}
+
+	k8sutils.SetCondition(
+		k8sutils.NewCondition(k8sutils.ReadyType, metav1.ConditionFalse, failure.Reason, failure.Message),
+		owner,
+	)
+	return nil
+}
@@ -2,20 +2,38 @@ package controllers
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
 	"testing"
+	"time"
 
 	"github.com/bombsimon/logrusr/v3"
+	"github.com/google/uuid"
 	"github.com/kong/kubernetes-testing-framework/pkg/utils/kubernetes/generators"
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	certificatesv1 "k8s.io/api/certificates/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
 	gwtypes "github.com/kong/gateway-operator/internal/types"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	"github.com/kong/gateway-operator/test/helpers"
 )
 
 func Test_ensureContainerImageUpdated(t *testing.T) {
@@ -634,3 +652,453 @@ func TestDeploymentOptionsDeepEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestSetNotReadyForUnavailableDeployment(t *testing.T) {
+	ctx := context.Background()
+	dataplane := &operatorv1beta1.DataPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kong",
+			Namespace: "default",
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dataplane-kong",
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "kong"},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kong-a",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "kong"},
+		},
+	}
+
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(dataplane, pod).
+		WithStatusSubresource(dataplane).
+		Build()
+	eventRecorder := record.NewFakeRecorder(10)
+
+	requireReady := func(t *testing.T, reason k8sutils.ConditionReason, message string) {
+		t.Helper()
+		ready, ok := k8sutils.GetCondition(k8sutils.ReadyType, dataplane)
+		require.True(t, ok)
+		require.Equal(t, metav1.ConditionFalse, ready.Status)
+		require.Equal(t, string(reason), ready.Reason)
+		require.Equal(t, message, ready.Message)
+	}
+
+	t.Log("starting pods are reported with the generic reason")
+	require.NoError(t, setNotReadyForUnavailableDeployment(ctx, fakeClient, eventRecorder, dataplane, deployment))
+	requireReady(t, k8sutils.WaitingToBecomeReadyReason, k8sutils.WaitingToBecomeReadyMessage)
+	require.Empty(t, eventRecorder.Events)
+
+	t.Log("failing pods are reported in the Ready condition and in an event")
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name:  "proxy",
+			Image: "kong:3.4",
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"},
+			},
+		},
+	}
+	require.NoError(t, fakeClient.Status().Update(ctx, pod))
+	require.NoError(t, setNotReadyForUnavailableDeployment(ctx, fakeClient, eventRecorder, dataplane, deployment))
+	expectedMessage := "container proxy cannot pull image kong:3.4 (pods: kong-a)"
+	requireReady(t, k8sutils.ImagePullBackOffReason, expectedMessage)
+	require.Len(t, eventRecorder.Events, 1)
+	require.Equal(t, "Warning ImagePullBackOff "+expectedMessage, <-eventRecorder.Events)
+
+	t.Log("the failure is reported in an event only once")
+	require.NoError(t, fakeClient.Status().Update(ctx, dataplane))
+	k8sutils.InitReady(dataplane)
+	require.NoError(t, setNotReadyForUnavailableDeployment(ctx, fakeClient, eventRecorder, dataplane, deployment))
+	requireReady(t, k8sutils.ImagePullBackOffReason, expectedMessage)
+	require.Empty(t, eventRecorder.Events)
+
+	t.Log("the failure is bubbled up to the Gateway")
+	reason, message := notReadyReasonAndMessage(dataplane)
+	require.Equal(t, k8sutils.ImagePullBackOffReason, reason)
+	require.Equal(t, expectedMessage, message)
+	k8sutils.InitReady(dataplane)
+	reason, message = notReadyReasonAndMessage(dataplane)
+	require.Equal(t, k8sutils.WaitingToBecomeReadyReason, reason)
+	require.Equal(t, k8sutils.WaitingToBecomeReadyMessage, message)
+}
+
+// ensureTestCertificateSecret ensures the certificate Secret of the provided owner
+// is issued from the CA in the caNN Secret.
+func ensureTestCertificateSecret(
+	t *testing.T,
+	fakeClient client.Client,
+	owner client.Object,
+	caNN types.NamespacedName,
+	certOpts CertificateOptions,
+) (bool, *corev1.Secret) {
+	t.Helper()
+	ctx := context.Background()
+	createdOrUpdated, secret, err := maybeCreateCertificateSecret(ctx, owner, "kong.default.svc", nil, "",
+		caNN, []certificatesv1.KeyUsage{certificatesv1.UsageServerAuth}, certOpts, fakeClient)
+	require.NoError(t, err)
+	// The fake client does not convert StringData to Data as the API server does.
+	if len(secret.StringData) > 0 {
+		secret.Data = make(map[string][]byte)
+		for k, v := range secret.StringData {
+			secret.Data[k] = []byte(v)
+		}
+		secret.StringData = nil
+		require.NoError(t, fakeClient.Update(ctx, secret))
+	}
+	return createdOrUpdated, secret
+}
+
+func TestMaybeCreateCertificateSecretAfterCARotation(t *testing.T) {
+	ctx := context.Background()
+	caNN := types.NamespacedName{Namespace: "kong-system", Name: "kong-operator-ca"}
+	dataplane := &operatorv1beta1.DataPlane{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "gateway-operator.konghq.com/v1beta1",
+			Kind:       "DataPlane",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kong",
+			Namespace: "default",
+			UID:       types.UID(uuid.NewString()),
+		},
+	}
+	previousCA := helpers.CreateCA(t)
+	ca := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      caNN.Name,
+			Namespace: caNN.Namespace,
+		},
+		Data: map[string][]byte{
+			"tls.crt": previousCA.CertPEM.Bytes(),
+			"tls.key": previousCA.KeyPEM.Bytes(),
+		},
+	}
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(ca).
+		Build()
+
+	ensureCertificate := func(t *testing.T) (bool, *corev1.Secret) {
+		t.Helper()
+		return ensureTestCertificateSecret(t, fakeClient, dataplane, caNN, CertificateOptions{})
+	}
+	requireIssuedBy := func(t *testing.T, secret *corev1.Secret, ca helpers.Cert) {
+		t.Helper()
+		caCerts, err := k8sutils.ParseCertificates(ca.CertPEM.Bytes())
+		require.NoError(t, err)
+		certs, err := k8sutils.ParseCertificates(secret.Data["tls.crt"])
+		require.NoError(t, err)
+		require.NoError(t, certs[0].CheckSignatureFrom(caCerts[0]))
+	}
+
+	t.Log("the certificate is issued from the CA and trusts it")
+	createdOrUpdated, secret := ensureCertificate(t)
+	require.True(t, createdOrUpdated)
+	requireIssuedBy(t, secret, previousCA)
+	require.Equal(t, previousCA.CertPEM.Bytes(), secret.Data["ca.crt"])
+	checksum, err := certificatesChecksum(ctx, fakeClient, dataplane)
+	require.NoError(t, err)
+	require.NotEmpty(t, checksum)
+	createdOrUpdated, _ = ensureCertificate(t)
+	require.False(t, createdOrUpdated)
+
+	t.Log("the certificate is reissued from the new CA and trusts both CAs during the rotation")
+	newCA := helpers.CreateCA(t)
+	bundle := append(append([]byte{}, newCA.CertPEM.Bytes()...), previousCA.CertPEM.Bytes()...)
+	ca.Data = map[string][]byte{
+		"tls.crt": newCA.CertPEM.Bytes(),
+		"tls.key": newCA.KeyPEM.Bytes(),
+		"ca.crt":  bundle,
+	}
+	require.NoError(t, fakeClient.Update(ctx, ca))
+	createdOrUpdated, secret = ensureCertificate(t)
+	require.True(t, createdOrUpdated)
+	requireIssuedBy(t, secret, newCA)
+	require.Equal(t, bundle, secret.Data["ca.crt"])
+	rotatedChecksum, err := certificatesChecksum(ctx, fakeClient, dataplane)
+	require.NoError(t, err)
+	require.NotEqual(t, checksum, rotatedChecksum)
+	createdOrUpdated, _ = ensureCertificate(t)
+	require.False(t, createdOrUpdated)
+
+	t.Log("the previous CA is not trusted anymore once the rotation is completed")
+	issuedCertificate := secret.Data["tls.crt"]
+	ca.Data["ca.crt"] = newCA.CertPEM.Bytes()
+	require.NoError(t, fakeClient.Update(ctx, ca))
+	createdOrUpdated, secret = ensureCertificate(t)
+	require.True(t, createdOrUpdated)
+	require.Equal(t, issuedCertificate, secret.Data["tls.crt"])
+	require.Equal(t, newCA.CertPEM.Bytes(), secret.Data["ca.crt"])
+}
+
+func TestMaybeCreateCertificateSecretRenewal(t *testing.T) {
+	ctx := context.Background()
+	caNN := types.NamespacedName{Namespace: "kong-system", Name: "kong-operator-ca"}
+	controlplane := &operatorv1alpha1.ControlPlane{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "gateway-operator.konghq.com/v1alpha1",
+			Kind:       "ControlPlane",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kong",
+			Namespace: "default",
+			UID:       types.UID(uuid.NewString()),
+		},
+	}
+	ca := helpers.CreateCA(t)
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      caNN.Name,
+				Namespace: caNN.Namespace,
+			},
+			Data: map[string][]byte{
+				"tls.crt": ca.CertPEM.Bytes(),
+				"tls.key": ca.KeyPEM.Bytes(),
+			},
+		}).
+		Build()
+	certOpts := CertificateOptions{
+		Validity:    time.Hour * 2,
+		RenewBefore: time.Hour,
+	}
+	// The certificates are backdated by a few minutes to tolerate clock skews.
+	const backdate = time.Minute * 10
+	notAfter := func(t *testing.T, secret *corev1.Secret) time.Time {
+		certs, err := k8sutils.ParseCertificates(secret.Data["tls.crt"])
+		require.NoError(t, err)
+		return certs[0].NotAfter
+	}
+
+	t.Log("the certificate is issued for the configured validity and its renewal is scheduled")
+	createdOrUpdated, secret := ensureTestCertificateSecret(t, fakeClient, controlplane, caNN, certOpts)
+	require.True(t, createdOrUpdated)
+	require.WithinDuration(t, time.Now().Add(certOpts.Validity), notAfter(t, secret), backdate)
+	delay, err := certificatesRenewalDelay(ctx, fakeClient, controlplane, certOpts.RenewBefore)
+	require.NoError(t, err)
+	require.InDelta(t, time.Hour, delay, float64(backdate))
+	createdOrUpdated, _ = ensureTestCertificateSecret(t, fakeClient, controlplane, caNN, certOpts)
+	require.False(t, createdOrUpdated)
+	checksum, err := certificatesChecksum(ctx, fakeClient, controlplane)
+	require.NoError(t, err)
+
+	t.Log("the certificate is renewed once it expires within the renewal window")
+	issuedCertificate := secret.Data["tls.crt"]
+	certOpts.Validity = time.Hour * 24
+	certOpts.RenewBefore = time.Hour * 3
+	createdOrUpdated, secret = ensureTestCertificateSecret(t, fakeClient, controlplane, caNN, certOpts)
+	require.True(t, createdOrUpdated)
+	require.NotEqual(t, issuedCertificate, secret.Data["tls.crt"])
+	require.WithinDuration(t, time.Now().Add(certOpts.Validity), notAfter(t, secret), backdate)
+	renewedChecksum, err := certificatesChecksum(ctx, fakeClient, controlplane)
+	require.NoError(t, err)
+	require.NotEqual(t, checksum, renewedChecksum, "the pods have to be rolled with the renewed certificate")
+	createdOrUpdated, _ = ensureTestCertificateSecret(t, fakeClient, controlplane, caNN, certOpts)
+	require.False(t, createdOrUpdated)
+
+	t.Log("the certificate is not renewed while it would not outlive its CA, which is rotated first")
+	certOpts.RenewBefore = time.Hour * 24 * 400
+	createdOrUpdated, _ = ensureTestCertificateSecret(t, fakeClient, controlplane, caNN, certOpts)
+	require.False(t, createdOrUpdated)
+	delay, err = certificatesRenewalDelay(ctx, fakeClient, controlplane, certOpts.RenewBefore)
+	require.NoError(t, err)
+	require.Zero(t, delay)
+}
+
+func TestRequeueBefore(t *testing.T) {
+	testCases := []struct {
+		name     string
+		res      ctrl.Result
+		delay    time.Duration
+		expected ctrl.Result
+	}{
+		{
+			name:     "no delay",
+			res:      ctrl.Result{RequeueAfter: time.Minute},
+			expected: ctrl.Result{RequeueAfter: time.Minute},
+		},
+		{
+			name:     "result not requeued",
+			delay:    time.Hour,
+			expected: ctrl.Result{RequeueAfter: time.Hour},
+		},
+		{
+			name:     "result requeued earlier",
+			res:      ctrl.Result{RequeueAfter: time.Minute},
+			delay:    time.Hour,
+			expected: ctrl.Result{RequeueAfter: time.Minute},
+		},
+		{
+			name:     "result requeued later",
+			res:      ctrl.Result{RequeueAfter: time.Hour * 2},
+			delay:    time.Hour,
+			expected: ctrl.Result{RequeueAfter: time.Hour},
+		},
+		{
+			name:     "result requeued immediately",
+			res:      ctrl.Result{Requeue: true},
+			delay:    time.Hour,
+			expected: ctrl.Result{Requeue: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, requeueBefore(tc.res, tc.delay))
+		})
+	}
+}
+
+func TestCertificateOptionsValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		certOpts    CertificateOptions
+		expectedErr string
+	}{
+		{
+			name:     "defaults",
+			certOpts: CertificateOptions{},
+		},
+		{
+			name:     "RSA key with the default size",
+			certOpts: CertificateOptions{KeyAlgorithm: CertificateKeyAlgorithmRSA},
+		},
+		{
+			name:     "ECDSA key of 384 bits",
+			certOpts: CertificateOptions{KeyAlgorithm: CertificateKeyAlgorithmECDSA, KeySize: 384},
+		},
+		{
+			name:        "RSA key of 256 bits",
+			certOpts:    CertificateOptions{KeyAlgorithm: CertificateKeyAlgorithmRSA, KeySize: 256},
+			expectedErr: "unsupported RSA key size 256",
+		},
+		{
+			name:        "Ed25519 key",
+			certOpts:    CertificateOptions{KeyAlgorithm: "Ed25519"},
+			expectedErr: "unsupported key algorithm Ed25519",
+		},
+		{
+			name:        "renewal period longer than the validity",
+			certOpts:    CertificateOptions{Validity: time.Hour, RenewBefore: time.Hour * 2},
+			expectedErr: "must be shorter than their validity",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.certOpts.Validate()
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestIssueCertificateFromIntermediateCA(t *testing.T) {
+	rootKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rootCert, rootPEM := helpers.CreateIntermediateCA(t, "root", rootKey, nil, nil)
+	intermediateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, intermediatePEM := helpers.CreateIntermediateCA(t, "intermediate", intermediateKey, rootCert, rootKey)
+	ca := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kong-operator-ca",
+			Namespace: "kong-system",
+		},
+		Data: map[string][]byte{
+			"tls.crt": append(append([]byte{}, intermediatePEM...), rootPEM...),
+			"tls.key": helpers.EncodePrivateKeyToPEM(t, intermediateKey),
+			"ca.crt":  rootPEM,
+		},
+	}
+	dataplane := &operatorv1beta1.DataPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kong",
+			Namespace: "default",
+		},
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(rootPEM)
+
+	for _, tc := range []struct {
+		name                       string
+		certOpts                   CertificateOptions
+		expectedSignatureAlgorithm x509.SignatureAlgorithm
+		expectedPublicKeyAlgorithm x509.PublicKeyAlgorithm
+	}{
+		{
+			name:                       "defaults",
+			expectedSignatureAlgorithm: x509.SHA256WithRSA,
+			expectedPublicKeyAlgorithm: x509.ECDSA,
+		},
+		{
+			name: "RSA key signed with SHA-512",
+			certOpts: CertificateOptions{
+				KeyAlgorithm:       CertificateKeyAlgorithmRSA,
+				KeySize:            3072,
+				SignatureAlgorithm: x509.SHA512WithRSA,
+			},
+			expectedSignatureAlgorithm: x509.SHA512WithRSA,
+			expectedPublicKeyAlgorithm: x509.RSA,
+		},
+		{
+			name: "ECDSA key of 384 bits",
+			certOpts: CertificateOptions{
+				KeyAlgorithm: CertificateKeyAlgorithmECDSA,
+				KeySize:      384,
+			},
+			expectedSignatureAlgorithm: x509.SHA256WithRSA,
+			expectedPublicKeyAlgorithm: x509.ECDSA,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			certPEM, keyPEM, err := issueCertificate(dataplane, "kong.default.svc", nil, ca,
+				[]certificatesv1.KeyUsage{certificatesv1.UsageServerAuth}, tc.certOpts)
+			require.NoError(t, err)
+
+			certs, err := k8sutils.ParseCertificates(certPEM)
+			require.NoError(t, err)
+			require.Len(t, certs, 2, "the certificate is followed by the intermediate CA certificate only")
+			cert := certs[0]
+			require.Equal(t, tc.expectedSignatureAlgorithm, cert.SignatureAlgorithm)
+			require.Equal(t, tc.expectedPublicKeyAlgorithm, cert.PublicKeyAlgorithm)
+			require.True(t, tc.certOpts.matchesKey(cert.PublicKey))
+
+			intermediates := x509.NewCertPool()
+			intermediates.AddCert(certs[1])
+			_, err = cert.Verify(x509.VerifyOptions{
+				Roots:         roots,
+				Intermediates: intermediates,
+				DNSName:       "kong.default.svc",
+			})
+			require.NoError(t, err)
+
+			_, err = tls.X509KeyPair(certPEM, keyPEM)
+			require.NoError(t, err)
+		})
+	}
+
+	t.Log("the signature algorithm has to be compatible with the CA key")
+	_, _, err = issueCertificate(dataplane, "kong.default.svc", nil, ca,
+		[]certificatesv1.KeyUsage{certificatesv1.UsageServerAuth},
+		CertificateOptions{SignatureAlgorithm: x509.ECDSAWithSHA256})
+	require.ErrorContains(t, err, "cannot be used with an RSA key")
+}
@@ -5,12 +5,7 @@ package controllers
 // -----------------------------------------------------------------------------
 
 //+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
-//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;create;delete
-//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;create;delete
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;create;update;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;create;delete
-//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;create;delete
-//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;create;delete
-//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;create;delete
-//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;create;delete
 //+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;create;update;patch;delete
-//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;create;delete
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;create;update;patch;delete
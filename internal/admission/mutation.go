@@ -0,0 +1,94 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrladmission "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	controlplaneutils "github.com/kong/gateway-operator/internal/utils/controlplane"
+	dataplaneutils "github.com/kong/gateway-operator/internal/utils/dataplane"
+)
+
+// MutatingRequestHandler handles the requests of setting the defaults of
+// objects, so that the controllers do not need to update the objects to set
+// them.
+type MutatingRequestHandler struct {
+	Logger logr.Logger
+}
+
+// NewMutatingRequestHandler create a MutatingRequestHandler to handle mutation
+// requests.
+func NewMutatingRequestHandler(l logr.Logger) *MutatingRequestHandler {
+	return &MutatingRequestHandler{
+		Logger: l.WithValues("component", "mutation-server"),
+	}
+}
+
+// ServeHTTP serves for HTTP requests.
+func (h *MutatingRequestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveAdmissionReview(w, r, h.Logger, h.handleMutation)
+}
+
+func (h *MutatingRequestHandler) handleMutation(_ context.Context, req *admissionv1.AdmissionRequest) (
+	*admissionv1.AdmissionResponse, error,
+) {
+	if req == nil {
+		return &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Code:    http.StatusBadRequest,
+				Reason:  metav1.StatusReasonBadRequest,
+				Message: "empty request",
+				Status:  metav1.StatusFailure,
+			},
+		}, nil
+	}
+
+	var mutated []byte
+	if req.Operation == admissionv1.Create || req.Operation == admissionv1.Update {
+		var err error
+		switch req.Resource {
+		case controlPlaneGVResource:
+			mutated, err = setDefaults(req.Object.Raw, func(controlPlane *operatorv1alpha1.ControlPlane) bool {
+				return controlplaneutils.SetControlPlaneDefaults(&controlPlane.Spec.ControlPlaneOptions)
+			})
+		case dataPlaneGVResource:
+			mutated, err = setDefaults(req.Object.Raw, func(dataPlane *operatorv1beta1.DataPlane) bool {
+				return dataplaneutils.SetDataPlaneDefaults(&dataPlane.Spec.DataPlaneOptions)
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response := ctrladmission.Allowed("")
+	if mutated != nil {
+		response = ctrladmission.PatchResponseFromRaw(req.Object.Raw, mutated)
+	}
+	// the patch operations are marshaled into the patch of the response
+	if err := response.Complete(ctrladmission.Request{AdmissionRequest: *req}); err != nil {
+		return nil, err
+	}
+	return &response.AdmissionResponse, nil
+}
+
+// setDefaults decodes the raw object, sets its defaults with setObjectDefaults
+// and returns it encoded, or nil if no default has been set.
+func setDefaults[T any](raw []byte, setObjectDefaults func(*T) bool) ([]byte, error) {
+	obj := new(T)
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, err
+	}
+	if !setObjectDefaults(obj) {
+		return nil, nil
+	}
+	return json.Marshal(obj)
+}
@@ -0,0 +1,173 @@
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+	controlplaneutils "github.com/kong/gateway-operator/internal/utils/controlplane"
+	dataplaneutils "github.com/kong/gateway-operator/internal/utils/dataplane"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+)
+
+func TestHandleMutation(t *testing.T) {
+	handler := NewMutatingRequestHandler(logr.Discard())
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// mutate sends the object to the mutating webhook and returns the response
+	// and the object with the patch of the response applied.
+	mutate := func(t *testing.T, resource metav1.GroupVersionResource, obj runtime.Object, into any) *admissionv1.AdmissionResponse {
+		raw, err := json.Marshal(obj)
+		require.NoError(t, err)
+		review := &admissionv1.AdmissionReview{
+			Request: &admissionv1.AdmissionRequest{
+				UID:       "uid",
+				Resource:  resource,
+				Operation: admissionv1.Create,
+				Object: runtime.RawExtension{
+					Raw: raw,
+				},
+			},
+		}
+		buf, err := json.Marshal(review)
+		require.NoError(t, err)
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(buf))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		respReview := &admissionv1.AdmissionReview{}
+		require.NoError(t, json.Unmarshal(body, respReview))
+		response := respReview.Response
+		require.True(t, response.Allowed)
+		require.EqualValues(t, "uid", response.UID)
+
+		if response.Patch != nil {
+			require.Equal(t, admissionv1.PatchTypeJSONPatch, *response.PatchType)
+			patch, err := jsonpatch.DecodePatch(response.Patch)
+			require.NoError(t, err)
+			raw, err = patch.Apply(raw)
+			require.NoError(t, err)
+		}
+		require.NoError(t, json.Unmarshal(raw, into))
+		return response
+	}
+
+	t.Run("DataPlane defaults are set", func(t *testing.T) {
+		dataplane := &operatorv1beta1.DataPlane{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "default",
+			},
+			Spec: operatorv1beta1.DataPlaneSpec{
+				DataPlaneOptions: operatorv1beta1.DataPlaneOptions{
+					Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+						DeploymentOptions: operatorv1beta1.DeploymentOptions{
+							PodTemplateSpec: &corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name:  consts.DataPlaneProxyContainerName,
+											Image: consts.DefaultDataPlaneImage,
+											Env: []corev1.EnvVar{
+												{Name: "KONG_PLUGINS", Value: "bundled,custom"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		mutated := &operatorv1beta1.DataPlane{}
+		response := mutate(t, dataPlaneGVResource, dataplane, mutated)
+		require.NotNil(t, response.Patch)
+
+		container := k8sutils.GetPodContainerByName(&mutated.Spec.Deployment.PodTemplateSpec.Spec, consts.DataPlaneProxyContainerName)
+		require.NotNil(t, container)
+		require.Len(t, container.Env, len(dataplaneutils.KongDefaults))
+		for _, envVar := range container.Env {
+			if envVar.Name == "KONG_PLUGINS" {
+				require.Equal(t, "bundled,custom", envVar.Value, "the configuration should not be overridden")
+				continue
+			}
+			require.Equal(t, dataplaneutils.KongDefaults[envVar.Name], envVar.Value)
+		}
+
+		t.Log("the defaults are not set again")
+		response = mutate(t, dataPlaneGVResource, mutated, &operatorv1beta1.DataPlane{})
+		require.Nil(t, response.Patch)
+	})
+
+	t.Run("ControlPlane defaults are set", func(t *testing.T) {
+		controlplane := &operatorv1alpha1.ControlPlane{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "default",
+			},
+			Spec: operatorv1alpha1.ControlPlaneSpec{
+				ControlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+					Deployment: operatorv1alpha1.DeploymentOptions{
+						PodTemplateSpec: &corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{
+									{
+										Name:  consts.ControlPlaneControllerContainerName,
+										Image: consts.DefaultControlPlaneImage,
+										Env: []corev1.EnvVar{
+											{Name: "CONTROLLER_KONG_ADMIN_CA_CERT_FILE", Value: "/custom/ca.crt"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		expected := controlplane.DeepCopy()
+		require.True(t, controlplaneutils.SetControlPlaneDefaults(&expected.Spec.ControlPlaneOptions))
+
+		mutated := &operatorv1alpha1.ControlPlane{}
+		response := mutate(t, controlPlaneGVResource, controlplane, mutated)
+		require.NotNil(t, response.Patch)
+		require.Equal(t, expected.Spec, mutated.Spec)
+
+		container := k8sutils.GetPodContainerByName(&mutated.Spec.Deployment.PodTemplateSpec.Spec, consts.ControlPlaneControllerContainerName)
+		require.Contains(t, container.Env, corev1.EnvVar{Name: "CONTROLLER_KONG_ADMIN_CA_CERT_FILE", Value: "/custom/ca.crt"},
+			"the configuration should not be overridden")
+		require.Contains(t, container.Env, corev1.EnvVar{Name: "CONTROLLER_KONG_ADMIN_TLS_CLIENT_CERT_FILE", Value: "/var/cluster-certificate/tls.crt"})
+
+		t.Log("the defaults are not set again")
+		response = mutate(t, controlPlaneGVResource, mutated, &operatorv1alpha1.ControlPlane{})
+		require.Nil(t, response.Patch)
+	})
+
+	t.Run("other resources are not mutated", func(t *testing.T) {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "default",
+			},
+		}
+		response := mutate(t, metav1.GroupVersionResource{Version: "v1", Resource: "configmaps"}, configMap, &corev1.ConfigMap{})
+		require.Nil(t, response.Patch)
+	})
+}
@@ -2,6 +2,7 @@ package admission
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -18,6 +19,7 @@ import (
 
 	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	controlplane "github.com/kong/gateway-operator/internal/validation/controlplane"
 	"github.com/kong/gateway-operator/internal/validation/dataplane"
 )
 
@@ -26,11 +28,22 @@ var (
 	codecs = serializer.NewCodecFactory(scheme)
 )
 
-// AddNewWebhookServerToManager creates a webhook server in manager.
-func AddNewWebhookServerToManager(mgr ctrl.Manager, logger logr.Logger, webhookPort int, webhookCertDir string) (webhook.Server, error) {
+// AddNewWebhookServerToManager creates a webhook server in manager. The server
+// serves the certificate returned by getCertificate, which is called on every
+// TLS handshake so that the certificate can be renewed without restarting it.
+func AddNewWebhookServerToManager(
+	mgr ctrl.Manager,
+	logger logr.Logger,
+	webhookPort int,
+	getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error),
+) (webhook.Server, error) {
 	hookServer := webhook.NewServer(webhook.Options{
-		CertDir: webhookCertDir,
-		Port:    webhookPort,
+		Port: webhookPort,
+		TLSOpts: []func(*tls.Config){
+			func(cfg *tls.Config) {
+				cfg.GetCertificate = getCertificate
+			},
+		},
 	})
 
 	// add readyz check for checking connection to webhook server
@@ -44,7 +57,9 @@ func AddNewWebhookServerToManager(mgr ctrl.Manager, logger logr.Logger, webhookP
 
 // Validator is the interface of validating
 type Validator interface {
-	ValidateControlPlane(context.Context, operatorv1alpha1.ControlPlane) error
+	// ValidateControlPlane validates a ControlPlane, along with the ControlPlane
+	// it updates, which is nil when it is created.
+	ValidateControlPlane(context.Context, operatorv1alpha1.ControlPlane, *operatorv1alpha1.ControlPlane) error
 	ValidateDataPlane(context.Context, operatorv1beta1.DataPlane) error
 }
 
@@ -57,10 +72,12 @@ type RequestHandler struct {
 }
 
 // NewRequestHandler create a RequestHandler to handle validation requests.
-func NewRequestHandler(c client.Client, l logr.Logger) *RequestHandler {
+// In development mode, the version of the ControlPlane images is not validated.
+func NewRequestHandler(c client.Client, l logr.Logger, devMode bool) *RequestHandler {
 	return &RequestHandler{
 		Validator: &validator{
-			dataplaneValidator: dataplane.NewValidator(c),
+			dataplaneValidator:    dataplane.NewValidator(c),
+			controlplaneValidator: controlplane.NewValidator(c, devMode),
 		},
 		Logger: l.WithValues("component", "validation-server"),
 	}
@@ -68,41 +85,52 @@ func NewRequestHandler(c client.Client, l logr.Logger) *RequestHandler {
 
 // ServeHTTP serves for HTTP requests.
 func (h *RequestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveAdmissionReview(w, r, h.Logger, h.handleValidation)
+}
+
+// serveAdmissionReview reads the AdmissionReview of the HTTP request, and writes
+// it back with the response returned by handle for its request.
+func serveAdmissionReview(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger logr.Logger,
+	handle func(context.Context, *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error),
+) {
 	if r.Body == nil {
-		h.Logger.Error(fmt.Errorf("empty body"), "received request with empty body")
+		logger.Error(fmt.Errorf("empty body"), "received request with empty body")
 		http.Error(w, "admission review object is missing", http.StatusBadRequest)
 		return
 	}
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.Logger.Error(err, "failed to read request from client")
+		logger.Error(err, "failed to read request from client")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	review := &admissionv1.AdmissionReview{}
 	if err := json.Unmarshal(data, review); err != nil {
-		h.Logger.Error(err, "failed to parse AdmissionReview object")
+		logger.Error(err, "failed to parse AdmissionReview object")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	response, err := h.handleValidation(r.Context(), review.Request)
+	response, err := handle(r.Context(), review.Request)
 	if err != nil {
-		h.Logger.Error(err, "failed to run validation")
+		logger.Error(err, "failed to handle the admission request")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	review.Response = response
 	data, err = json.Marshal(review)
 	if err != nil {
-		h.Logger.Error(err, "failed to marshal response")
+		logger.Error(err, "failed to marshal response")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	_, err = w.Write(data)
 	if err != nil {
-		h.Logger.Error(err, "failed to write response")
+		logger.Error(err, "failed to write response")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -151,7 +179,14 @@ func (h *RequestHandler) handleValidation(ctx context.Context, req *admissionv1.
 			if err != nil {
 				return nil, err
 			}
-			err = h.Validator.ValidateControlPlane(ctx, controlPlane)
+			var oldControlPlane *operatorv1alpha1.ControlPlane
+			if req.Operation == admissionv1.Update {
+				oldControlPlane = &operatorv1alpha1.ControlPlane{}
+				if _, _, err := deserializer.Decode(req.OldObject.Raw, nil, oldControlPlane); err != nil {
+					return nil, err
+				}
+			}
+			err = h.Validator.ValidateControlPlane(ctx, controlPlane, oldControlPlane)
 			if err != nil {
 				ok = false
 				msg = err.Error()
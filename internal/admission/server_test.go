@@ -8,17 +8,24 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
 	"github.com/kong/gateway-operator/internal/consts"
+	"github.com/kong/gateway-operator/pkg/vars"
 )
 
 func TestHandleDataplaneValidation(t *testing.T) {
@@ -57,7 +64,7 @@ func TestHandleDataplaneValidation(t *testing.T) {
 	)
 	c := b.Build()
 
-	handler := NewRequestHandler(c, logr.Discard())
+	handler := NewRequestHandler(c, logr.Discard(), false)
 	server := httptest.NewServer(handler)
 
 	testCases := []struct {
@@ -167,7 +174,7 @@ func TestHandleDataplaneValidation(t *testing.T) {
 				},
 			},
 			hasError: true,
-			errMsg:   "database backend postgres of DataPlane not supported currently",
+			errMsg:   "database backend postgres of DataPlane requires the database.postgres options to be set",
 		},
 		{
 			name: "validate_error:database=xxx",
@@ -281,7 +288,7 @@ func TestHandleDataplaneValidation(t *testing.T) {
 				},
 			},
 			hasError: true,
-			errMsg:   "database backend postgres of DataPlane not supported currently",
+			errMsg:   "database backend postgres of DataPlane requires the database.postgres options to be set",
 		},
 		{
 			name: "validate_error:db=xxx_in_cm_envFrom",
@@ -433,3 +440,416 @@ func TestHandleDataplaneValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleControlplaneValidation(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(testScheme))
+	require.NoError(t, operatorv1alpha1.AddToScheme(testScheme))
+	require.NoError(t, operatorv1beta1.AddToScheme(testScheme))
+	require.NoError(t, gatewayv1beta1.AddToScheme(testScheme))
+
+	c := fakeclient.NewClientBuilder().
+		WithScheme(testScheme).
+		WithObjects(
+			&operatorv1beta1.DataPlane{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-dataplane"},
+			},
+			&operatorv1beta1.DataPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "claimed-dataplane",
+					Labels:    map[string]string{"fleet": "eu"},
+				},
+			},
+			&operatorv1beta1.DataPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "selected-dataplane",
+					Labels:    map[string]string{"fleet": "us"},
+				},
+			},
+			&operatorv1alpha1.ControlPlane{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other-controlplane"},
+				Spec: operatorv1alpha1.ControlPlaneSpec{
+					ControlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+						DataPlane: lo.ToPtr("claimed-dataplane"),
+					},
+				},
+			},
+			&operatorv1alpha1.ControlPlane{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "selector-controlplane"},
+				Spec: operatorv1alpha1.ControlPlaneSpec{
+					ControlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+						DataPlane: lo.ToPtr("selector-dataplane"),
+						DataPlaneSelector: &operatorv1alpha1.DataPlaneSelector{
+							Selector: metav1.LabelSelector{MatchLabels: map[string]string{"fleet": "us"}},
+						},
+					},
+				},
+			},
+			&gatewayv1beta1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "kong"},
+				Spec: gatewayv1beta1.GatewayClassSpec{
+					ControllerName: gatewayv1beta1.GatewayController(vars.ControllerName()),
+				},
+			},
+			&gatewayv1beta1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "other"},
+				Spec: gatewayv1beta1.GatewayClassSpec{
+					ControllerName: "example.com/gateway-controller",
+				},
+			},
+			&networkingv1.IngressClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "kong"},
+				Spec: networkingv1.IngressClassSpec{
+					Controller: consts.IngressClassControllerName,
+				},
+			},
+			&networkingv1.IngressClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+				Spec: networkingv1.IngressClassSpec{
+					Controller: "k8s.io/ingress-nginx",
+				},
+			},
+			&networkingv1.IngressClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "claimed",
+					Labels: map[string]string{
+						consts.GatewayOperatorControlledLabel: consts.ControlPlaneManagedLabelValue,
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: operatorv1alpha1.SchemeGroupVersion.String(),
+							Kind:       "ControlPlane",
+							Name:       "other-controlplane",
+							UID:        "other-controlplane-uid",
+						},
+					},
+				},
+				Spec: networkingv1.IngressClassSpec{
+					Controller: consts.IngressClassControllerName,
+				},
+			},
+		).
+		Build()
+
+	handler := NewRequestHandler(c, logr.Discard(), false)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	controlplane := func(image string, modify func(*operatorv1alpha1.ControlPlane)) *operatorv1alpha1.ControlPlane {
+		controlplane := &operatorv1alpha1.ControlPlane{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-controlplane",
+				Namespace: "default",
+			},
+			Spec: operatorv1alpha1.ControlPlaneSpec{
+				ControlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+					Deployment: operatorv1alpha1.DeploymentOptions{
+						PodTemplateSpec: &corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{
+									{
+										Name:  consts.ControlPlaneControllerContainerName,
+										Image: image,
+									},
+								},
+							},
+						},
+					},
+					DataPlane: lo.ToPtr("test-dataplane"),
+				},
+			},
+		}
+		if modify != nil {
+			modify(controlplane)
+		}
+		return controlplane
+	}
+
+	testCases := []struct {
+		name         string
+		controlplane *operatorv1alpha1.ControlPlane
+		// oldControlplane is the ControlPlane updated by the validated one, or
+		// nil when it is created.
+		oldControlplane *operatorv1alpha1.ControlPlane
+		hasError        bool
+		errMsg          string
+	}{
+		{
+			name:         "validate_ok:dataplane",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, nil),
+		},
+		{
+			name: "validate_ok:no_dataplane",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlane = nil
+			}),
+		},
+		{
+			name: "validate_ok:gatewayclass_and_ingressclass",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.GatewayClass = lo.ToPtr(gatewayv1beta1.ObjectName("kong"))
+				cp.Spec.IngressClass = lo.ToPtr("kong")
+			}),
+		},
+		{
+			name: "validate_ok:ingressclass_created",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.IngressClass = lo.ToPtr("new-class")
+				cp.Spec.DefaultIngressClass = true
+			}),
+		},
+		{
+			name: "validate_ok:deleted_with_its_dataplane",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+				cp.Spec.DataPlane = lo.ToPtr("deleted-dataplane")
+			}),
+		},
+		{
+			name:         "validate_error:no_image",
+			controlplane: controlplane("", nil),
+			hasError:     true,
+			errMsg:       "ControlPlane requires an image",
+		},
+		{
+			name:         "validate_error:unsupported_image",
+			controlplane: controlplane("kong/kubernetes-ingress-controller:2.8.0", nil),
+			hasError:     true,
+			errMsg:       "unsupported ControlPlane image kong/kubernetes-ingress-controller:2.8.0",
+		},
+		{
+			name: "validate_error:dataplane_not_found",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlane = lo.ToPtr("missing-dataplane")
+			}),
+			hasError: true,
+			errMsg:   "dataplane missing-dataplane does not exist in namespace default",
+		},
+		{
+			name: "validate_error:dataplane_claimed",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlane = lo.ToPtr("claimed-dataplane")
+			}),
+			hasError: true,
+			errMsg:   "dataplane default/claimed-dataplane is already used by ControlPlane default/other-controlplane",
+		},
+		{
+			name: "validate_error:dataplanes_claimed",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlanes = []string{"claimed-dataplane"}
+			}),
+			hasError: true,
+			errMsg:   "dataplane default/claimed-dataplane is already used by ControlPlane default/other-controlplane",
+		},
+		{
+			name: "validate_error:dataplane_selector_claimed",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlaneSelector = &operatorv1alpha1.DataPlaneSelector{
+					Selector: metav1.LabelSelector{MatchLabels: map[string]string{"fleet": "eu"}},
+				}
+			}),
+			hasError: true,
+			errMsg:   "dataplane default/claimed-dataplane is already used by ControlPlane default/other-controlplane",
+		},
+		{
+			name: "validate_error:dataplane_selected_by_other_controlplane",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlane = lo.ToPtr("selected-dataplane")
+			}),
+			hasError: true,
+			errMsg:   "dataplane default/selected-dataplane is already used by ControlPlane default/selector-controlplane",
+		},
+		{
+			name: "validate_ok:update_with_deleted_dataplane",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlane = lo.ToPtr("missing-dataplane")
+				cp.Spec.Deployment.Replicas = lo.ToPtr(int32(2))
+			}),
+			oldControlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlane = lo.ToPtr("missing-dataplane")
+			}),
+		},
+		{
+			name: "validate_ok:update_with_dataplane_selected_afterwards",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlane = lo.ToPtr("selected-dataplane")
+				cp.Spec.Deployment.Replicas = lo.ToPtr(int32(2))
+			}),
+			oldControlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlane = lo.ToPtr("selected-dataplane")
+			}),
+		},
+		{
+			name: "validate_error:update_to_missing_dataplane",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlane = lo.ToPtr("missing-dataplane")
+			}),
+			oldControlplane: controlplane(consts.DefaultControlPlaneImage, nil),
+			hasError:        true,
+			errMsg:          "dataplane missing-dataplane does not exist in namespace default",
+		},
+		{
+			name: "validate_error:update_to_claimed_dataplanes",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DataPlanes = []string{"claimed-dataplane"}
+			}),
+			oldControlplane: controlplane(consts.DefaultControlPlaneImage, nil),
+			hasError:        true,
+			errMsg:          "dataplane default/claimed-dataplane is already used by ControlPlane default/other-controlplane",
+		},
+		{
+			name: "validate_error:gatewayclass_not_found",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.GatewayClass = lo.ToPtr(gatewayv1beta1.ObjectName("missing"))
+			}),
+			hasError: true,
+			errMsg:   "GatewayClass missing does not exist",
+		},
+		{
+			name: "validate_error:gatewayclass_other_controller",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.GatewayClass = lo.ToPtr(gatewayv1beta1.ObjectName("other"))
+			}),
+			hasError: true,
+			errMsg:   "GatewayClass other is handled by controller example.com/gateway-controller",
+		},
+		{
+			name: "validate_error:ingressclass_other_controller",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.IngressClass = lo.ToPtr("nginx")
+			}),
+			hasError: true,
+			errMsg:   "IngressClass nginx is handled by controller k8s.io/ingress-nginx",
+		},
+		{
+			name: "validate_error:ingressclass_claimed",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.IngressClass = lo.ToPtr("claimed")
+			}),
+			hasError: true,
+			errMsg:   "IngressClass claimed is already claimed by ControlPlane other-controlplane",
+		},
+		{
+			name: "validate_ok:update_with_unsupported_image",
+			controlplane: controlplane("kong/kubernetes-ingress-controller:2.8.0", func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.Deployment.Replicas = lo.ToPtr(int32(2))
+			}),
+			oldControlplane: controlplane("kong/kubernetes-ingress-controller:2.8.0", nil),
+		},
+		{
+			name:            "validate_error:update_to_unsupported_image",
+			controlplane:    controlplane("kong/kubernetes-ingress-controller:2.8.0", nil),
+			oldControlplane: controlplane(consts.DefaultControlPlaneImage, nil),
+			hasError:        true,
+			errMsg:          "unsupported ControlPlane image kong/kubernetes-ingress-controller:2.8.0",
+		},
+		{
+			name: "validate_ok:update_with_deleted_gatewayclass",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.GatewayClass = lo.ToPtr(gatewayv1beta1.ObjectName("missing"))
+				cp.Spec.Deployment.Replicas = lo.ToPtr(int32(2))
+			}),
+			oldControlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.GatewayClass = lo.ToPtr(gatewayv1beta1.ObjectName("missing"))
+			}),
+		},
+		{
+			name: "validate_error:update_to_missing_gatewayclass",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.GatewayClass = lo.ToPtr(gatewayv1beta1.ObjectName("missing"))
+			}),
+			oldControlplane: controlplane(consts.DefaultControlPlaneImage, nil),
+			hasError:        true,
+			errMsg:          "GatewayClass missing does not exist",
+		},
+		{
+			name: "validate_ok:update_with_ingressclass_claimed_afterwards",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.IngressClass = lo.ToPtr("claimed")
+				cp.Spec.Deployment.Replicas = lo.ToPtr(int32(2))
+			}),
+			oldControlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.IngressClass = lo.ToPtr("claimed")
+			}),
+		},
+		{
+			name: "validate_error:update_to_claimed_ingressclass",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.IngressClass = lo.ToPtr("claimed")
+			}),
+			oldControlplane: controlplane(consts.DefaultControlPlaneImage, nil),
+			hasError:        true,
+			errMsg:          "IngressClass claimed is already claimed by ControlPlane other-controlplane",
+		},
+		{
+			name: "validate_error:update_to_default_ingressclass",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.IngressClass = lo.ToPtr("nginx")
+				cp.Spec.DefaultIngressClass = true
+			}),
+			oldControlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.IngressClass = lo.ToPtr("nginx")
+			}),
+			hasError: true,
+			errMsg:   "IngressClass nginx is handled by controller k8s.io/ingress-nginx",
+		},
+		{
+			name: "validate_error:default_ingressclass_without_ingressclass",
+			controlplane: controlplane(consts.DefaultControlPlaneImage, func(cp *operatorv1alpha1.ControlPlane) {
+				cp.Spec.DefaultIngressClass = true
+			}),
+			hasError: true,
+			errMsg:   "ingressClass has to be set along with defaultIngressClass",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			review := &admissionv1.AdmissionReview{
+				Request: &admissionv1.AdmissionRequest{
+					UID: "",
+					Kind: metav1.GroupVersionKind{
+						Group:   operatorv1alpha1.SchemeGroupVersion.Group,
+						Version: operatorv1alpha1.SchemeGroupVersion.Version,
+						Kind:    "controlplanes",
+					},
+					Resource:  controlPlaneGVResource,
+					Name:      tc.controlplane.Name,
+					Namespace: tc.controlplane.Namespace,
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Object: tc.controlplane,
+					},
+				},
+			}
+			if tc.oldControlplane != nil {
+				review.Request.Operation = admissionv1.Update
+				review.Request.OldObject = runtime.RawExtension{Object: tc.oldControlplane}
+			}
+
+			buf, err := json.Marshal(review)
+			require.NoErrorf(t, err, "there should be error in marshaling into JSON")
+			req, err := http.NewRequest("POST", server.URL, bytes.NewReader(buf))
+			require.NoError(t, err, "there should be no error in making HTTP request")
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err, "there should be no error in getting response")
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err, "there should be no error in reading body")
+			resp.Body.Close()
+			respReview := &admissionv1.AdmissionReview{}
+			err = json.Unmarshal(body, respReview)
+			require.NoError(t, err, "there should be no error in unmarshalling body")
+			validationResp := respReview.Response
+
+			if !tc.hasError {
+				require.EqualValues(t, http.StatusOK, validationResp.Result.Code, "response code should be 200 OK: %s", validationResp.Result.Message)
+			} else {
+				require.EqualValues(t, http.StatusBadRequest, validationResp.Result.Code, "response code should be 400 Bad Request")
+				require.Equal(t, tc.errMsg, validationResp.Result.Message, "result message should contain expected content")
+			}
+		})
+	}
+}
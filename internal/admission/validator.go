@@ -14,8 +14,8 @@ type validator struct {
 	controlplaneValidator *controlplanevalidation.Validator
 }
 
-func (v *validator) ValidateControlPlane(ctx context.Context, controlPlane operatorv1alpha1.ControlPlane) error {
-	return v.controlplaneValidator.Validate(&controlPlane)
+func (v *validator) ValidateControlPlane(ctx context.Context, controlPlane operatorv1alpha1.ControlPlane, oldControlPlane *operatorv1alpha1.ControlPlane) error {
+	return v.controlplaneValidator.Validate(ctx, &controlPlane, oldControlPlane)
 }
 
 func (v *validator) ValidateDataPlane(ctx context.Context, dataPlane operatorv1beta1.DataPlane) error {
@@ -21,6 +21,25 @@ const (
 	// GatewayManagedLabelValue indicates that the object's lifecycle is managed by
 	// the gateway controller.
 	GatewayManagedLabelValue = "gateway"
+
+	// CertificatePurposeLabel is the label that is used for certificate Secrets
+	// which are issued for another purpose than securing the communication
+	// between the operator and the owner of the Secret.
+	CertificatePurposeLabel = "gateway-operator.konghq.com/certificate-purpose"
+
+	// ControlPlaneUIDLabel is the label holding the UID of the controlplane
+	// which created the object, set on the objects created in other namespaces
+	// than the one of the controlplane, which cannot refer to it as their owner.
+	ControlPlaneUIDLabel = "gateway-operator.konghq.com/controlplane-uid"
+
+	// ControlPlaneEndpointSliceManagedByLabelValue is the value of the
+	// endpointslice.kubernetes.io/managed-by label of the EndpointSlices which
+	// the controlplane controller mirrors from the DataPlane admin Services.
+	ControlPlaneEndpointSliceManagedByLabelValue = "controlplane.gateway-operator.konghq.com"
+
+	// EndpointSliceSourceUIDLabel is the label holding the UID of the
+	// EndpointSlice an EndpointSlice is mirrored from.
+	EndpointSliceSourceUIDLabel = "gateway-operator.konghq.com/source-endpointslice-uid"
 )
 
 // -----------------------------------------------------------------------------
@@ -49,6 +68,10 @@ const (
 
 	// ControlPlaneControllerContainerName is the name of the ingress controller container in a ControlPlane Deployment
 	ControlPlaneControllerContainerName = "controller"
+
+	// IngressClassControllerName is the name of the controller the ControlPlane
+	// controller handles the IngressClasses of.
+	IngressClassControllerName = "ingress-controllers.konghq.com/kong"
 )
 
 // -----------------------------------------------------------------------------
@@ -57,6 +80,43 @@ const (
 
 const (
 	ClusterCertificateVolume = "cluster-certificate"
+	// ClusterCertificateVolumeMountPath is the path the cluster certificate
+	// volume is mounted at in the ControlPlane and DataPlane containers.
+	ClusterCertificateVolumeMountPath = "/var/cluster-certificate"
+)
+
+// -----------------------------------------------------------------------------
+// Consts - Cluster CA
+// -----------------------------------------------------------------------------
+
+const (
+	// ClusterCATrustBundleKey is the key of the cluster CA Secret holding the CA
+	// certificates which the certificates issued by the operator are verified
+	// with. It holds both the current and the previous CA certificates while the
+	// CA is rotated. The CA certificate alone is trusted when it is absent.
+	ClusterCATrustBundleKey = "ca.crt"
+
+	// ClusterCARotateAnnotation is the annotation which can be set to "true" on
+	// the cluster CA Secret to request the rotation of the CA.
+	ClusterCARotateAnnotation = "gateway-operator.konghq.com/rotate-ca"
+
+	// CertificatesChecksumAnnotation is the pod template annotation holding the
+	// checksum of the certificates the pods are configured with, so that they
+	// are restarted when the certificates are reissued.
+	CertificatesChecksumAnnotation = "gateway-operator.konghq.com/certificates-checksum"
+)
+
+// -----------------------------------------------------------------------------
+// Consts - cert-manager
+// -----------------------------------------------------------------------------
+
+const (
+	// CertManagerGroup is the API group of the cert-manager resources.
+	CertManagerGroup = "cert-manager.io"
+
+	// CertManagerCertificateNameAnnotation is the annotation cert-manager sets on
+	// the Secrets it issues, with the name of their Certificate.
+	CertManagerCertificateNameAnnotation = "cert-manager.io/certificate-name"
 )
 
 // -----------------------------------------------------------------------------
@@ -64,17 +124,12 @@ const (
 // -----------------------------------------------------------------------------
 
 const (
-	// WebhookCertificateConfigBaseImage is the image to use by the certificate config Jobs.
-	WebhookCertificateConfigBaseImage = "registry.k8s.io/ingress-nginx/kube-webhook-certgen:v1.1.1"
 	// WebhookName is the ValidatingWebhookConfiguration name.
 	WebhookName = "gateway-operator-validation.konghq.com"
-	// WebhookCertificateConfigSecretName is the name of the secret containing the webhook certificate.
-	WebhookCertificateConfigSecretName = "gateway-operator-webhook-certs"
-	// WebhookCertificateConfigName is the name given to the resources related by the certificate config Jobs.
-	WebhookCertificateConfigName = "gateway-operator-admission"
-	// WebhookCertificateConfigLabelvalue is the default label for all the resources related
-	// to the certificate config Jobs.
-	WebhookCertificateConfigLabelvalue = "gateway-operator-certificate-config"
+	// MutatingWebhookName is the MutatingWebhookConfiguration name.
+	MutatingWebhookName = "gateway-operator-mutation.konghq.com"
+	// WebhookCertificateSecretName is the name of the secret containing the webhook certificate.
+	WebhookCertificateSecretName = "gateway-operator-webhook-certs"
 	// WebhookServiceName is the name of the service that exposes the validating webhook
 	WebhookServiceName = "gateway-operator-validating-webhook"
 )
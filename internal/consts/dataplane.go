@@ -37,10 +37,25 @@ const (
 	// DataPlane admin API.
 	DataPlaneAdminServiceLabelValue ServiceType = "admin"
 
+	// DataPlaneAdminServicePortName is the name of the admin Service port
+	// exposing the DataPlane admin API, through which the ControlPlane discovers
+	// the DataPlane pods.
+	DataPlaneAdminServicePortName = "admin"
+
+	// DataPlaneAdminAPITLSServerName is a name all the DataPlane admin API
+	// certificates are valid for. A ControlPlane linked to several DataPlanes
+	// reaches them through its own admin Service and verifies their
+	// certificates against this name.
+	DataPlaneAdminAPITLSServerName = "admin.dataplane.gateway-operator.konghq.com"
+
 	// DataPlaneProxyServiceLabelValue indicates that the service is inteded to expose the
 	// DataPlane proxy.
 	DataPlaneProxyServiceLabelValue ServiceType = "proxy"
 
+	// DataPlaneClusterServiceLabelValue indicates that the service is intended to expose
+	// the hybrid mode cluster endpoints of a DataPlane with the control_plane role.
+	DataPlaneClusterServiceLabelValue ServiceType = "cluster"
+
 	// ServiceSelectorOverrideAnnotation is used on the dataplane to override the Selector
 	// of both the admin and proxy services.
 	// The value of such an annotation is to be intended as a comma-separated list of
@@ -55,6 +70,74 @@ const (
 	DataPlaneProxyContainerName = "proxy"
 )
 
+// -----------------------------------------------------------------------------
+// Consts - DataPlane rollouts
+// -----------------------------------------------------------------------------
+
+const (
+	// DataPlaneDeploymentStateLabel is the label set on the Deployments created by the
+	// DataPlane controller during rollouts to tell apart the preview Deployment
+	// from the live one.
+	DataPlaneDeploymentStateLabel = "gateway-operator.konghq.com/dataplane-deployment-state"
+
+	// DataPlaneServiceStateLabel is the label set on the Services created by the
+	// DataPlane controller during rollouts to tell apart the preview Services
+	// from the live ones.
+	DataPlaneServiceStateLabel = "gateway-operator.konghq.com/dataplane-service-state"
+
+	// DataPlaneNetworkPolicyStateLabel is the label set on the NetworkPolicies
+	// created by the Gateway controller to tell apart the NetworkPolicy of the
+	// DataPlane's preview pods from the live one.
+	DataPlaneNetworkPolicyStateLabel = "gateway-operator.konghq.com/dataplane-network-policy-state"
+
+	// DataPlaneStateLabelValueLive indicates that the resource is live, i.e. that
+	// it receives the production traffic.
+	// Resources which do not have the state label set are considered live as well.
+	DataPlaneStateLabelValueLive = "live"
+
+	// DataPlaneStateLabelValuePreview indicates that the resource is a preview
+	// resource created during a rollout.
+	DataPlaneStateLabelValuePreview = "preview"
+
+	// DataPlaneStateLabelValueCanary indicates that the resource is a canary
+	// resource created during a Canary rollout. Unlike the preview resources,
+	// the canary pods are selected by the live Services and receive a share of
+	// the production traffic.
+	DataPlaneStateLabelValueCanary = "canary"
+
+	// DataPlanePreviewSelectorLabel is the label used by the preview Deployment
+	// (and the preview Services) to select the preview pods.
+	// The preview pods do not get the "app" label set, so that they are not
+	// selected by the live Services.
+	DataPlanePreviewSelectorLabel = "gateway-operator.konghq.com/dataplane-preview"
+
+	// DataPlanePromoteWhenReadyAnnotationKey is the annotation which can be set
+	// on a DataPlane using the BreakBeforePromotion promotion strategy to approve
+	// the promotion of its preview resources.
+	//
+	// Example:
+	// gateway-operator.konghq.com/promote-when-ready: "true"
+	DataPlanePromoteWhenReadyAnnotationKey = "gateway-operator.konghq.com/promote-when-ready"
+
+	// DataPlanePromoteWhenReadyAnnotationTrue is the value of the promote-when-ready
+	// annotation which approves the promotion.
+	DataPlanePromoteWhenReadyAnnotationTrue = "true"
+
+	// DataPlaneAllowedControlPlaneNamespacesAnnotation is the annotation which
+	// must be set on a DataPlane for it to be selected by the DataPlane selector
+	// of ControlPlanes in other namespaces. It holds the comma separated list of
+	// the namespaces whose ControlPlanes may select the DataPlane.
+	//
+	// Example:
+	// gateway-operator.konghq.com/allowed-controlplane-namespaces: "team-a,team-b"
+	DataPlaneAllowedControlPlaneNamespacesAnnotation = "gateway-operator.konghq.com/allowed-controlplane-namespaces"
+
+	// DataPlaneConditionTypeRolledOut is a rollout condition type indicating whether
+	// or not the changes to the DataPlane's Deployment have been rolled out.
+	// It is set in the DataPlane's rollout status.
+	DataPlaneConditionTypeRolledOut = "RolledOut"
+)
+
 // -----------------------------------------------------------------------------
 // Consts - DataPlane ports
 // -----------------------------------------------------------------------------
@@ -82,6 +165,14 @@ const (
 
 	// DefaultKongStatusPort is the port that the dataplane users for status.
 	DataPlaneStatusPort = 8100
+
+	// DataPlaneClusterPort is the port that the hybrid mode control plane uses
+	// for the configuration sync with the data plane nodes.
+	DataPlaneClusterPort = 8005
+
+	// DataPlaneClusterTelemetryPort is the port that the hybrid mode control
+	// plane uses for receiving telemetry from the data plane nodes.
+	DataPlaneClusterTelemetryPort = 8006
 )
 
 // -----------------------------------------------------------------------------
@@ -90,7 +181,152 @@ const (
 
 const (
 	// EnvVarKongDatabase is the environment variable name to specify database
-	// backend used for dataplane(Kong gateway). Currently only DBLess mode
-	// (empty, or "off") is supported.
+	// backend used for dataplane(Kong gateway). DBLess mode (empty, or "off")
+	// and "postgres" are supported.
 	EnvVarKongDatabase = "KONG_DATABASE"
+
+	// EnvVarKongPGHost is the environment variable name to specify the host
+	// of the PostgreSQL database used by the dataplane.
+	EnvVarKongPGHost = "KONG_PG_HOST"
+
+	// EnvVarKongPGPort is the environment variable name to specify the port
+	// of the PostgreSQL database used by the dataplane.
+	EnvVarKongPGPort = "KONG_PG_PORT"
+
+	// EnvVarKongPGDatabase is the environment variable name to specify the name
+	// of the PostgreSQL database used by the dataplane.
+	EnvVarKongPGDatabase = "KONG_PG_DATABASE"
+
+	// EnvVarKongPGUser is the environment variable name to specify the user
+	// connecting to the PostgreSQL database used by the dataplane.
+	EnvVarKongPGUser = "KONG_PG_USER"
+
+	// EnvVarKongPGPassword is the environment variable name to specify the
+	// password of the PostgreSQL database user.
+	EnvVarKongPGPassword = "KONG_PG_PASSWORD"
+
+	// EnvVarKongProxyListen is the environment variable name to specify the
+	// addresses and ports the dataplane proxies HTTP and HTTPS traffic on.
+	EnvVarKongProxyListen = "KONG_PROXY_LISTEN"
+
+	// EnvVarKongStreamListen is the environment variable name to specify the
+	// addresses and ports the dataplane proxies TCP, TLS and UDP streams on.
+	EnvVarKongStreamListen = "KONG_STREAM_LISTEN"
+
+	// EnvVarKongPortMaps is the environment variable name to specify how the
+	// ports exposed to clients map to the ports the dataplane listens on.
+	EnvVarKongPortMaps = "KONG_PORT_MAPS"
+
+	// EnvVarKongRole is the environment variable name to specify the role of
+	// the dataplane in a hybrid mode cluster.
+	EnvVarKongRole = "KONG_ROLE"
+
+	// EnvVarKongClusterMTLS is the environment variable name to specify how
+	// the hybrid mode cluster nodes authenticate each other.
+	EnvVarKongClusterMTLS = "KONG_CLUSTER_MTLS"
+
+	// EnvVarKongClusterCert is the environment variable name to specify the
+	// path of the hybrid mode cluster certificate.
+	EnvVarKongClusterCert = "KONG_CLUSTER_CERT"
+
+	// EnvVarKongClusterCertKey is the environment variable name to specify the
+	// path of the hybrid mode cluster certificate key.
+	EnvVarKongClusterCertKey = "KONG_CLUSTER_CERT_KEY"
+
+	// EnvVarKongClusterCACert is the environment variable name to specify the
+	// path of the CA certificate verifying the hybrid mode cluster nodes.
+	EnvVarKongClusterCACert = "KONG_CLUSTER_CA_CERT"
+
+	// EnvVarKongClusterListen is the environment variable name to specify the
+	// address the hybrid mode control plane listens on for data plane nodes.
+	EnvVarKongClusterListen = "KONG_CLUSTER_LISTEN"
+
+	// EnvVarKongClusterTelemetryListen is the environment variable name to
+	// specify the address the hybrid mode control plane receives telemetry on.
+	EnvVarKongClusterTelemetryListen = "KONG_CLUSTER_TELEMETRY_LISTEN"
+
+	// EnvVarKongClusterControlPlane is the environment variable name to specify
+	// the address of the control plane a hybrid mode data plane connects to.
+	EnvVarKongClusterControlPlane = "KONG_CLUSTER_CONTROL_PLANE"
+
+	// EnvVarKongClusterServerName is the environment variable name to specify
+	// the server name the hybrid mode data plane verifies the control plane with.
+	EnvVarKongClusterServerName = "KONG_CLUSTER_SERVER_NAME"
+
+	// EnvVarKongClusterTelemetryEndpoint is the environment variable name to
+	// specify the address a hybrid mode data plane sends telemetry to.
+	EnvVarKongClusterTelemetryEndpoint = "KONG_CLUSTER_TELEMETRY_ENDPOINT"
+
+	// EnvVarKongClusterTelemetryServerName is the environment variable name to
+	// specify the server name of the hybrid mode control plane telemetry endpoint.
+	EnvVarKongClusterTelemetryServerName = "KONG_CLUSTER_TELEMETRY_SERVER_NAME"
+)
+
+// -----------------------------------------------------------------------------
+// Consts - DataPlane database
+// -----------------------------------------------------------------------------
+
+const (
+	// DataPlaneDatabaseModeOff is the KONG_DATABASE value for DB-less DataPlanes.
+	DataPlaneDatabaseModeOff = "off"
+
+	// DataPlaneDatabaseModePostgres is the KONG_DATABASE value for DataPlanes
+	// backed by a PostgreSQL database.
+	DataPlaneDatabaseModePostgres = "postgres"
+
+	// PostgresSecretHostKey is the key of the PostgreSQL connection Secret
+	// holding the database host.
+	PostgresSecretHostKey = "host"
+
+	// PostgresSecretPortKey is the key of the PostgreSQL connection Secret
+	// holding the database port.
+	PostgresSecretPortKey = "port"
+
+	// PostgresSecretDatabaseKey is the key of the PostgreSQL connection Secret
+	// holding the database name.
+	PostgresSecretDatabaseKey = "database"
+
+	// PostgresSecretUserKey is the key of the PostgreSQL connection Secret
+	// holding the database user.
+	PostgresSecretUserKey = "user"
+
+	// PostgresSecretPasswordKey is the key of the PostgreSQL connection Secret
+	// holding the database user's password.
+	PostgresSecretPasswordKey = "password"
+
+	// DataPlaneMigrationsLabel is the label set on the Jobs running the
+	// database migrations of a DataPlane. Its value is the migrations step
+	// run by the Job.
+	DataPlaneMigrationsLabel = "gateway-operator.konghq.com/dataplane-migrations"
+
+	// DataPlaneMigrationsLabelValueUp indicates that the Job bootstraps the
+	// database and runs the pending migrations.
+	DataPlaneMigrationsLabelValueUp = "up"
+
+	// DataPlaneMigrationsLabelValueFinish indicates that the Job finishes the
+	// pending migrations once the DataPlane has been rolled out.
+	DataPlaneMigrationsLabelValueFinish = "finish"
+
+	// DataPlaneMigrationsRevisionLabel is the label set on the migrations Jobs
+	// holding the hash of the Job's pod template. It is used to tell whether
+	// a Job has been run for the DataPlane's current configuration.
+	DataPlaneMigrationsRevisionLabel = "gateway-operator.konghq.com/dataplane-migrations-revision"
+)
+
+// -----------------------------------------------------------------------------
+// Consts - DataPlane hybrid mode
+// -----------------------------------------------------------------------------
+
+const (
+	// DataPlaneClusterCertificateVolumeName is the name of the volume holding
+	// the hybrid mode cluster certificate of a DataPlane.
+	DataPlaneClusterCertificateVolumeName = "kong-cluster-certificate"
+
+	// DataPlaneClusterCertificateMountPath is the path the hybrid mode cluster
+	// certificate of a DataPlane is mounted at in the proxy container.
+	DataPlaneClusterCertificateMountPath = "/var/kong-cluster-certificate"
+
+	// DataPlaneClusterCertificatePurposeLabelValue indicates that the certificate
+	// Secret is used by a DataPlane to authenticate the hybrid mode cluster nodes.
+	DataPlaneClusterCertificatePurposeLabelValue = "hybrid-cluster"
 )
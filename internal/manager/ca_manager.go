@@ -0,0 +1,421 @@
+package manager
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+)
+
+const (
+	// caValidity is the validity period of the cluster CA certificates.
+	caValidity = time.Second * 315400000
+
+	// caRotationCheckInterval is the interval at which the cluster CA is checked
+	// for expiry, rotation requests and the progress of its rotation.
+	caRotationCheckInterval = time.Minute
+
+	// caRotationStartedReason and caRotationCompletedReason are the reasons of
+	// the events recorded for the cluster CA Secret along its rotation.
+	caRotationStartedReason   = "CARotationStarted"
+	caRotationCompletedReason = "CARotationCompleted"
+	// caExpiringReason is the reason of the event recorded for an imported
+	// cluster CA Secret which expires within the rotation period.
+	caExpiringReason = "CAExpiring"
+)
+
+var (
+	clusterCAExpirationTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gateway_operator_cluster_ca_expiration_timestamp_seconds",
+		Help: "Expiration time of the cluster CA certificate, in seconds since the epoch.",
+	})
+	clusterCARotationInProgress = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gateway_operator_cluster_ca_rotation_in_progress",
+		Help: "Whether the cluster CA is being rotated, that is whether the previous CA certificate is still trusted.",
+	})
+	clusterCARotationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_operator_cluster_ca_rotations_total",
+		Help: "Number of cluster CA rotations, by phase (started or completed).",
+	}, []string{"phase"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		clusterCAExpirationTimestamp,
+		clusterCARotationInProgress,
+		clusterCARotationsTotal,
+	)
+}
+
+// caManager creates the cluster CA the operator issues the certificates of the
+// DataPlanes and ControlPlanes from, and rotates it when it is about to expire
+// or when the rotation is requested through the rotate-ca annotation.
+//
+// When the cluster CA is imported, its Secret is provided by the user, who
+// rotates it. The operator neither creates nor rotates it, and only validates
+// it at startup.
+//
+// A rotation is carried out in two steps. The new CA is first generated and
+// trusted along with the previous one through the trust bundle of the CA
+// Secret, while the controllers reissue the certificates and roll the pods
+// configured with them. Once all the certificates have been reissued and the
+// pods have been rolled, the previous CA is retired from the trust bundle.
+type caManager struct {
+	client          client.Client
+	eventRecorder   record.EventRecorder
+	logger          logr.Logger
+	secretName      string
+	secretNamespace string
+	// rotateBefore is how long before its expiry the CA is rotated.
+	rotateBefore time.Duration
+	// imported is true when the CA Secret is provided by the user.
+	imported bool
+	// signatureAlgorithm is the algorithm the certificates are signed with by
+	// the CA, which has to be compatible with its key.
+	signatureAlgorithm x509.SignatureAlgorithm
+}
+
+func (m *caManager) Start(ctx context.Context) error {
+	if m.secretName == "" {
+		return fmt.Errorf("cannot use an empty secret name when creating a CA secret")
+	}
+	if m.secretNamespace == "" {
+		return fmt.Errorf("cannot use an empty secret namespace when creating a CA secret")
+	}
+	if err := m.maybeCreateCACertificate(ctx); err != nil {
+		return err
+	}
+	if err := m.validateCACertificate(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(caRotationCheckInterval)
+	defer ticker.Stop()
+	for {
+		if err := m.maybeRotateCACertificate(ctx); err != nil {
+			m.logger.Error(err, "failed rotating the cluster CA")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *caManager) maybeCreateCACertificate(ctx context.Context) error {
+	ca := &corev1.Secret{}
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+	err := m.client.Get(ctx, client.ObjectKey{Namespace: m.secretNamespace, Name: m.secretName}, ca)
+	if k8serrors.IsNotFound(err) && m.imported {
+		return fmt.Errorf("the imported CA certificate Secret %s/%s does not exist", m.secretNamespace, m.secretName)
+	}
+	if k8serrors.IsNotFound(err) {
+		m.logger.Info(fmt.Sprintf("no CA certificate Secret %s found, generating CA certificate", m.secretName))
+		cert, key, err := generateCACertificate()
+		if err != nil {
+			return err
+		}
+
+		signedSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: m.secretNamespace,
+				Name:      m.secretName,
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: map[string][]byte{
+				"tls.crt": cert,
+				"tls.key": key,
+			},
+		}
+		return m.client.Create(ctx, signedSecret)
+	}
+	return err
+}
+
+// validateCACertificate returns an error when the CA Secret cannot be used to
+// issue certificates.
+func (m *caManager) validateCACertificate(ctx context.Context) error {
+	ca := &corev1.Secret{}
+	if err := m.client.Get(ctx, client.ObjectKey{Namespace: m.secretNamespace, Name: m.secretName}, ca); err != nil {
+		return err
+	}
+	if err := validateCA(ca, m.signatureAlgorithm, time.Now()); err != nil {
+		return fmt.Errorf("the CA certificate Secret %s/%s cannot be used: %w", m.secretNamespace, m.secretName, err)
+	}
+	return nil
+}
+
+// validateCA validates that the provided CA Secret holds, in tls.crt, a CA certificate
+// valid at the provided time and allowed to sign the client and server certificates
+// of DataPlanes and ControlPlanes, optionally followed by the chain of the CA certificates
+// it is issued from, and, in tls.key, the matching RSA or ECDSA private key, which the
+// provided signature algorithm is compatible with. The trust bundle, when present, has
+// to verify that chain.
+func validateCA(ca *corev1.Secret, signatureAlgorithm x509.SignatureAlgorithm, now time.Time) error {
+	caCerts, err := k8sutils.ParseCertificates(ca.Data["tls.crt"])
+	if err != nil {
+		return fmt.Errorf("failed parsing tls.crt: %w", err)
+	}
+	caCert := caCerts[0]
+	if !caCert.BasicConstraintsValid || !caCert.IsCA {
+		return errors.New("the certificate is not a CA certificate")
+	}
+	if caCert.KeyUsage != 0 && caCert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return errors.New("the CA certificate key usages do not include certificate signing")
+	}
+	if len(caCert.ExtKeyUsage) > 0 &&
+		!lo.Contains(caCert.ExtKeyUsage, x509.ExtKeyUsageAny) &&
+		!(lo.Contains(caCert.ExtKeyUsage, x509.ExtKeyUsageServerAuth) && lo.Contains(caCert.ExtKeyUsage, x509.ExtKeyUsageClientAuth)) {
+		return errors.New("the CA certificate extended key usages do not include both server and client authentication")
+	}
+	if now.Before(caCert.NotBefore) {
+		return fmt.Errorf("the CA certificate is not valid before %s", caCert.NotBefore.Format(time.RFC3339))
+	}
+	if !now.Before(caCert.NotAfter) {
+		return fmt.Errorf("the CA certificate has expired at %s", caCert.NotAfter.Format(time.RFC3339))
+	}
+
+	key, err := k8sutils.ParsePrivateKey(ca.Data["tls.key"])
+	if err != nil {
+		return fmt.Errorf("failed parsing tls.key: %w", err)
+	}
+	publicKey, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !publicKey.Equal(caCert.PublicKey) {
+		return errors.New("the private key does not match the CA certificate")
+	}
+	if _, err := k8sutils.SignatureAlgorithmForKey(caCert.PublicKey, signatureAlgorithm); err != nil {
+		return err
+	}
+
+	intermediates := x509.NewCertPool()
+	for i, cert := range caCerts[1:] {
+		if err := caCerts[i].CheckSignatureFrom(cert); err != nil {
+			return fmt.Errorf("the certificate %d of the chain in tls.crt is not issued by the next one: %w", i, err)
+		}
+		intermediates.AddCert(cert)
+	}
+	if bundle := ca.Data[consts.ClusterCATrustBundleKey]; len(bundle) > 0 {
+		trusted, err := k8sutils.ParseCertificates(bundle)
+		if err != nil {
+			return fmt.Errorf("failed parsing %s: %w", consts.ClusterCATrustBundleKey, err)
+		}
+		roots := x509.NewCertPool()
+		for _, cert := range trusted {
+			roots.AddCert(cert)
+		}
+		if _, err := caCert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   now,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err != nil {
+			return fmt.Errorf("the CA certificate is not verified by %s: %w", consts.ClusterCATrustBundleKey, err)
+		}
+	}
+	return nil
+}
+
+// maybeRotateCACertificate starts the rotation of the CA when it expires within
+// the rotateBefore period or when it is requested, and completes the rotation
+// in progress once the certificates issued from the previous CA are not in use
+// anymore.
+func (m *caManager) maybeRotateCACertificate(ctx context.Context) error {
+	ca := &corev1.Secret{}
+	if err := m.client.Get(ctx, client.ObjectKey{Namespace: m.secretNamespace, Name: m.secretName}, ca); err != nil {
+		return err
+	}
+	caCerts, err := k8sutils.ParseCertificates(ca.Data["tls.crt"])
+	if err != nil {
+		return fmt.Errorf("failed parsing the CA certificate of secret %s: %w", m.secretName, err)
+	}
+	caCert := caCerts[0]
+	clusterCAExpirationTimestamp.Set(float64(caCert.NotAfter.Unix()))
+
+	if m.imported {
+		if time.Until(caCert.NotAfter) < m.rotateBefore {
+			message := fmt.Sprintf("the imported CA certificate expires at %s, it has to be rotated", caCert.NotAfter.Format(time.RFC3339))
+			m.logger.Info(message)
+			m.eventRecorder.Event(ca, corev1.EventTypeWarning, caExpiringReason, message)
+		}
+		return nil
+	}
+
+	if bundle := ca.Data[consts.ClusterCATrustBundleKey]; len(bundle) > 0 {
+		trusted, err := k8sutils.ParseCertificates(bundle)
+		if err != nil {
+			return fmt.Errorf("failed parsing the trust bundle of secret %s: %w", m.secretName, err)
+		}
+		if len(trusted) > 1 {
+			clusterCARotationInProgress.Set(1)
+			return m.maybeCompleteCARotation(ctx, ca, caCert)
+		}
+	}
+	clusterCARotationInProgress.Set(0)
+
+	var reason string
+	switch {
+	case ca.Annotations[consts.ClusterCARotateAnnotation] == "true":
+		reason = fmt.Sprintf("requested through the %s annotation", consts.ClusterCARotateAnnotation)
+	case time.Until(caCert.NotAfter) < m.rotateBefore:
+		reason = fmt.Sprintf("the CA certificate expires at %s", caCert.NotAfter.Format(time.RFC3339))
+	default:
+		return nil
+	}
+
+	cert, key, err := generateCACertificate()
+	if err != nil {
+		return err
+	}
+	ca.Data[consts.ClusterCATrustBundleKey] = append(append([]byte{}, cert...), ca.Data["tls.crt"]...)
+	ca.Data["tls.crt"] = cert
+	ca.Data["tls.key"] = key
+	delete(ca.Annotations, consts.ClusterCARotateAnnotation)
+	if err := m.client.Update(ctx, ca); err != nil {
+		return fmt.Errorf("failed updating CA secret %s: %w", m.secretName, err)
+	}
+
+	message := fmt.Sprintf("rotating the cluster CA, %s: both the previous and the new CA are trusted until all the certificates are reissued", reason)
+	m.logger.Info(message)
+	m.eventRecorder.Event(ca, corev1.EventTypeNormal, caRotationStartedReason, message)
+	clusterCARotationsTotal.WithLabelValues("started").Inc()
+	clusterCARotationInProgress.Set(1)
+	return nil
+}
+
+// maybeCompleteCARotation retires the previous CA from the trust bundle once
+// all the certificate Secrets of the DataPlanes and ControlPlanes have been
+// reissued from the current CA and all their Deployments have rolled out
+// their pods with the reissued certificates.
+func (m *caManager) maybeCompleteCARotation(ctx context.Context, ca *corev1.Secret, caCert *x509.Certificate) error {
+	managedBy, err := labels.NewRequirement(consts.GatewayOperatorControlledLabel, selection.In, []string{
+		consts.DataPlaneManagedLabelValue,
+		consts.ControlPlaneManagedLabelValue,
+	})
+	if err != nil {
+		return err
+	}
+	selector := client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*managedBy)}
+
+	secrets := &corev1.SecretList{}
+	if err := m.client.List(ctx, secrets, selector); err != nil {
+		return err
+	}
+	secretsByOwner := make(map[types.UID][]corev1.Secret)
+	for _, secret := range secrets.Items {
+		if !k8sutils.IsCertificateSecret(&secret) {
+			continue
+		}
+		// The certificates issued by cert-manager are not issued from the cluster CA.
+		if _, ok := secret.Annotations[consts.CertManagerCertificateNameAnnotation]; !ok {
+			certs, err := k8sutils.ParseCertificates(secret.Data["tls.crt"])
+			if err != nil || certs[0].CheckSignatureFrom(caCert) != nil {
+				m.logger.V(1).Info("waiting for the certificate to be reissued", "secret", client.ObjectKeyFromObject(&secret))
+				return nil
+			}
+		}
+		for _, ownerRef := range secret.OwnerReferences {
+			secretsByOwner[ownerRef.UID] = append(secretsByOwner[ownerRef.UID], secret)
+		}
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := m.client.List(ctx, deployments, selector); err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		var ownerSecrets []corev1.Secret
+		for _, ownerRef := range deployment.OwnerReferences {
+			ownerSecrets = append(ownerSecrets, secretsByOwner[ownerRef.UID]...)
+		}
+		if deployment.Spec.Template.Annotations[consts.CertificatesChecksumAnnotation] != k8sutils.CertificatesChecksum(ownerSecrets) ||
+			!k8sutils.IsDeploymentRolledOut(&deployment) {
+			m.logger.V(1).Info("waiting for the deployment to roll out the reissued certificates", "deployment", client.ObjectKeyFromObject(&deployment))
+			return nil
+		}
+	}
+
+	ca.Data[consts.ClusterCATrustBundleKey] = ca.Data["tls.crt"]
+	if err := m.client.Update(ctx, ca); err != nil {
+		return fmt.Errorf("failed updating CA secret %s: %w", m.secretName, err)
+	}
+
+	message := "cluster CA rotated: all the certificates have been reissued, the previous CA is not trusted anymore"
+	m.logger.Info(message)
+	m.eventRecorder.Event(ca, corev1.EventTypeNormal, caRotationCompletedReason, message)
+	clusterCARotationsTotal.WithLabelValues("completed").Inc()
+	clusterCARotationInProgress.Set(0)
+	return nil
+}
+
+// generateCACertificate generates a self-signed CA certificate and its private
+// key. It returns the PEM encoded certificate and key.
+func generateCACertificate() ([]byte, []byte, error) {
+	serial, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+	if err != nil {
+		return nil, nil, err
+	}
+	template := x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   "Kong Gateway Operator CA",
+			Organization: []string{"Kong, Inc."},
+			Country:      []string{"US"},
+		},
+		SerialNumber:          serial,
+		SignatureAlgorithm:    x509.ECDSAWithSHA256,
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign + x509.KeyUsageKeyEncipherment + x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	privDer, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, priv.Public(), priv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: der,
+		}), pem.EncodeToMemory(&pem.Block{
+			Type:  "EC PRIVATE KEY",
+			Bytes: privDer,
+		}), nil
+}
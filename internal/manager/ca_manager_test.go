@@ -0,0 +1,329 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	"github.com/kong/gateway-operator/test/helpers"
+)
+
+func TestCAManagerRotation(t *testing.T) {
+	ctx := context.Background()
+	caNN := types.NamespacedName{Namespace: "kong-system", Name: "kong-operator-ca"}
+	ownerRef := metav1.OwnerReference{
+		APIVersion: "gateway-operator.konghq.com/v1beta1",
+		Kind:       "DataPlane",
+		Name:       "dataplane",
+		UID:        types.UID("dataplane-uid"),
+	}
+	managedLabels := map[string]string{consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue}
+
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	eventRecorder := record.NewFakeRecorder(10)
+	m := &caManager{
+		client:          fakeClient,
+		eventRecorder:   eventRecorder,
+		logger:          logr.Discard(),
+		secretName:      caNN.Name,
+		secretNamespace: caNN.Namespace,
+		rotateBefore:    time.Hour * 24 * 30,
+	}
+
+	getCA := func(t *testing.T) *corev1.Secret {
+		ca := &corev1.Secret{}
+		require.NoError(t, fakeClient.Get(ctx, caNN, ca))
+		return ca
+	}
+	trustedCAs := func(t *testing.T) int {
+		bundle := getCA(t).Data[consts.ClusterCATrustBundleKey]
+		if len(bundle) == 0 {
+			return 0
+		}
+		certs, err := k8sutils.ParseCertificates(bundle)
+		require.NoError(t, err)
+		return len(certs)
+	}
+	// issueFromCA issues the certificate of the certificate Secret as the
+	// controllers do, from the current cluster CA.
+	issueFromCA := func(t *testing.T, secret *corev1.Secret) {
+		ca := getCA(t)
+		caCerts, err := k8sutils.ParseCertificates(ca.Data["tls.crt"])
+		require.NoError(t, err)
+		block, _ := pem.Decode(ca.Data["tls.key"])
+		caKey, err := x509.ParseECPrivateKey(block.Bytes)
+		require.NoError(t, err)
+		cert := helpers.CreateCert(t, "dataplane", caCerts[0], caKey)
+		bundle := ca.Data[consts.ClusterCATrustBundleKey]
+		if len(bundle) == 0 {
+			bundle = ca.Data["tls.crt"]
+		}
+		secret.Data = map[string][]byte{
+			"tls.crt": cert.CertPEM.Bytes(),
+			"tls.key": cert.KeyPEM.Bytes(),
+			"ca.crt":  bundle,
+		}
+	}
+
+	t.Log("the CA is created and not rotated while it is far from its expiry")
+	require.NoError(t, m.maybeCreateCACertificate(ctx))
+	require.NoError(t, m.maybeRotateCACertificate(ctx))
+	require.Zero(t, trustedCAs(t))
+	require.Empty(t, eventRecorder.Events)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "dataplane-cert",
+			Namespace:       "default",
+			Labels:          managedLabels,
+			OwnerReferences: []metav1.OwnerReference{ownerRef},
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+	issueFromCA(t, secret)
+	require.NoError(t, fakeClient.Create(ctx, secret))
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "dataplane",
+			Namespace:       "default",
+			Labels:          managedLabels,
+			OwnerReferences: []metav1.OwnerReference{ownerRef},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						consts.CertificatesChecksumAnnotation: k8sutils.CertificatesChecksum([]corev1.Secret{*secret}),
+					},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}
+	require.NoError(t, fakeClient.Create(ctx, deployment))
+
+	t.Log("the rotation requested through the annotation trusts both CAs")
+	ca := getCA(t)
+	previousCACert := ca.Data["tls.crt"]
+	ca.Annotations = map[string]string{consts.ClusterCARotateAnnotation: "true"}
+	require.NoError(t, fakeClient.Update(ctx, ca))
+	require.NoError(t, m.maybeRotateCACertificate(ctx))
+	ca = getCA(t)
+	require.NotEqual(t, previousCACert, ca.Data["tls.crt"])
+	require.NotContains(t, ca.Annotations, consts.ClusterCARotateAnnotation)
+	require.Equal(t, 2, trustedCAs(t))
+	require.Contains(t, <-eventRecorder.Events, caRotationStartedReason)
+
+	t.Log("the rotation is not completed while certificates are issued from the previous CA")
+	require.NoError(t, m.maybeRotateCACertificate(ctx))
+	require.Equal(t, 2, trustedCAs(t))
+
+	t.Log("the rotation is not completed until the pods are rolled with the reissued certificates")
+	issueFromCA(t, secret)
+	require.NoError(t, fakeClient.Update(ctx, secret))
+	require.NoError(t, m.maybeRotateCACertificate(ctx))
+	require.Equal(t, 2, trustedCAs(t))
+
+	deployment.Spec.Template.Annotations[consts.CertificatesChecksumAnnotation] = k8sutils.CertificatesChecksum([]corev1.Secret{*secret})
+	require.NoError(t, fakeClient.Update(ctx, deployment))
+	deployment.Status.UpdatedReplicas = 0
+	require.NoError(t, fakeClient.Status().Update(ctx, deployment))
+	require.NoError(t, m.maybeRotateCACertificate(ctx))
+	require.Equal(t, 2, trustedCAs(t))
+
+	deployment.Status.UpdatedReplicas = 1
+	require.NoError(t, fakeClient.Status().Update(ctx, deployment))
+	require.NoError(t, m.maybeRotateCACertificate(ctx))
+	require.Equal(t, 1, trustedCAs(t))
+	require.Equal(t, getCA(t).Data["tls.crt"], getCA(t).Data[consts.ClusterCATrustBundleKey])
+	require.Contains(t, <-eventRecorder.Events, caRotationCompletedReason)
+
+	t.Log("the CA is rotated when it expires within the rotateBefore period")
+	m.rotateBefore = caValidity + time.Hour
+	require.NoError(t, m.maybeRotateCACertificate(ctx))
+	require.Equal(t, 2, trustedCAs(t))
+	require.Contains(t, <-eventRecorder.Events, "the CA certificate expires at")
+}
+
+func TestValidateCA(t *testing.T) {
+	rsaRootKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rootCert, rootPEM := helpers.CreateIntermediateCA(t, "root", rsaRootKey, nil, nil)
+	intermediateKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, intermediatePEM := helpers.CreateIntermediateCA(t, "intermediate", intermediateKey, rootCert, rsaRootKey)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, otherRootPEM := helpers.CreateIntermediateCA(t, "other-root", otherKey, nil, nil)
+
+	generatedCert, generatedKey, err := generateCACertificate()
+	require.NoError(t, err)
+	leafCA := helpers.CreateCA(t)
+	leaf := helpers.CreateCert(t, "leaf", leafCA.Cert, leafCA.Key)
+
+	join := func(pems ...[]byte) []byte {
+		return bytes.Join(pems, nil)
+	}
+
+	for _, tc := range []struct {
+		name               string
+		data               map[string][]byte
+		signatureAlgorithm x509.SignatureAlgorithm
+		now                time.Time
+		expectedErr        string
+	}{
+		{
+			name: "generated CA",
+			data: map[string][]byte{
+				"tls.crt": generatedCert,
+				"tls.key": generatedKey,
+			},
+		},
+		{
+			name: "generated CA with an RSA signature algorithm",
+			data: map[string][]byte{
+				"tls.crt": generatedCert,
+				"tls.key": generatedKey,
+			},
+			signatureAlgorithm: x509.SHA256WithRSA,
+			expectedErr:        "cannot be used with an ECDSA key",
+		},
+		{
+			name: "RSA root CA",
+			data: map[string][]byte{
+				"tls.crt": rootPEM,
+				"tls.key": helpers.EncodePrivateKeyToPEM(t, rsaRootKey),
+			},
+			signatureAlgorithm: x509.SHA384WithRSA,
+		},
+		{
+			name: "intermediate CA with its chain and the root CA trusted",
+			data: map[string][]byte{
+				"tls.crt": join(intermediatePEM, rootPEM),
+				"tls.key": helpers.EncodePrivateKeyToPEM(t, intermediateKey),
+				"ca.crt":  rootPEM,
+			},
+			signatureAlgorithm: x509.ECDSAWithSHA384,
+		},
+		{
+			name: "intermediate CA not verified by the trust bundle",
+			data: map[string][]byte{
+				"tls.crt": intermediatePEM,
+				"tls.key": helpers.EncodePrivateKeyToPEM(t, intermediateKey),
+				"ca.crt":  otherRootPEM,
+			},
+			expectedErr: "is not verified by ca.crt",
+		},
+		{
+			name: "intermediate CA followed by a certificate not issuing it",
+			data: map[string][]byte{
+				"tls.crt": join(intermediatePEM, otherRootPEM),
+				"tls.key": helpers.EncodePrivateKeyToPEM(t, intermediateKey),
+			},
+			expectedErr: "is not issued by the next one",
+		},
+		{
+			name: "key not matching the CA certificate",
+			data: map[string][]byte{
+				"tls.crt": intermediatePEM,
+				"tls.key": helpers.EncodePrivateKeyToPEM(t, otherKey),
+			},
+			expectedErr: "the private key does not match the CA certificate",
+		},
+		{
+			name: "not a CA certificate",
+			data: map[string][]byte{
+				"tls.crt": leaf.CertPEM.Bytes(),
+				"tls.key": leaf.KeyPEM.Bytes(),
+			},
+			expectedErr: "the certificate is not a CA certificate",
+		},
+		{
+			name: "expired CA",
+			data: map[string][]byte{
+				"tls.crt": rootPEM,
+				"tls.key": helpers.EncodePrivateKeyToPEM(t, rsaRootKey),
+			},
+			now:         time.Now().AddDate(2, 0, 0),
+			expectedErr: "the CA certificate has expired",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			now := tc.now
+			if now.IsZero() {
+				now = time.Now()
+			}
+			err := validateCA(&corev1.Secret{Data: tc.data}, tc.signatureAlgorithm, now)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestCAManagerImportedCA(t *testing.T) {
+	ctx := context.Background()
+	caNN := types.NamespacedName{Namespace: "kong-system", Name: "kong-operator-ca"}
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	eventRecorder := record.NewFakeRecorder(10)
+	m := &caManager{
+		client:          fakeClient,
+		eventRecorder:   eventRecorder,
+		logger:          logr.Discard(),
+		secretName:      caNN.Name,
+		secretNamespace: caNN.Namespace,
+		rotateBefore:    time.Hour * 24 * 30,
+		imported:        true,
+	}
+
+	t.Log("the imported CA is not generated")
+	require.ErrorContains(t, m.maybeCreateCACertificate(ctx), "does not exist")
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, certPEM := helpers.CreateIntermediateCA(t, "root", key, nil, nil)
+	ca := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        caNN.Name,
+			Namespace:   caNN.Namespace,
+			Annotations: map[string]string{consts.ClusterCARotateAnnotation: "true"},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"tls.crt": certPEM,
+			"tls.key": helpers.EncodePrivateKeyToPEM(t, key),
+		},
+	}
+	require.NoError(t, fakeClient.Create(ctx, ca))
+	require.NoError(t, m.maybeCreateCACertificate(ctx))
+	require.NoError(t, m.validateCACertificate(ctx))
+
+	t.Log("the imported CA is not rotated, but its upcoming expiry is reported")
+	m.rotateBefore = time.Hour * 24 * 365 * 2
+	require.NoError(t, m.maybeRotateCACertificate(ctx))
+	require.NoError(t, fakeClient.Get(ctx, caNN, ca))
+	require.Equal(t, certPEM, ca.Data["tls.crt"])
+	require.Contains(t, <-eventRecorder.Events, caExpiringReason)
+}
@@ -54,10 +54,38 @@ func (c *ControllerDef) MaybeSetupWithManager(mgr ctrl.Manager) error {
 }
 
 func setupIndexes(mgr manager.Manager) error {
-	return index.IndexDataPlaneNameOnControlPlane(mgr.GetCache())
+	if err := index.IndexDataPlaneNameOnControlPlane(mgr.GetCache()); err != nil {
+		return err
+	}
+	if err := index.IndexDataPlaneSelectorNamespaceOnControlPlane(mgr.GetCache()); err != nil {
+		return err
+	}
+	return index.IndexClusterControlPlaneNameOnDataPlane(mgr.GetCache())
+}
+
+// certificateOptionsForConfig returns the options of the certificates issued for
+// DataPlanes and ControlPlanes set in the provided config.
+func certificateOptionsForConfig(c *Config) controllers.CertificateOptions {
+	certificateOptions := controllers.CertificateOptions{
+		Validity:           c.CertificateValidity,
+		RenewBefore:        c.CertificateRenewBefore,
+		KeyAlgorithm:       c.CertificateKeyAlgorithm,
+		KeySize:            c.CertificateKeySize,
+		SignatureAlgorithm: c.CertificateSignatureAlgorithm,
+	}
+	if c.CertManagerIssuerName != "" {
+		certificateOptions.CertManagerIssuer = &controllers.CertManagerIssuerRef{
+			Name:  c.CertManagerIssuerName,
+			Kind:  c.CertManagerIssuerKind,
+			Group: c.CertManagerIssuerGroup,
+		}
+	}
+	return certificateOptions
 }
 
 func setupControllers(mgr manager.Manager, c *Config) []ControllerDef {
+	certificateOptions := certificateOptionsForConfig(c)
+
 	controllers := []ControllerDef{
 		// GatewayClass controller
 		{
@@ -99,6 +127,7 @@ func setupControllers(mgr manager.Manager, c *Config) []ControllerDef {
 				Scheme:                   mgr.GetScheme(),
 				ClusterCASecretName:      c.ClusterCASecretName,
 				ClusterCASecretNamespace: c.ClusterCASecretNamespace,
+				CertificateOptions:       certificateOptions,
 				DevelopmentMode:          c.DevelopmentMode,
 			},
 		},
@@ -110,6 +139,7 @@ func setupControllers(mgr manager.Manager, c *Config) []ControllerDef {
 				Scheme:                   mgr.GetScheme(),
 				ClusterCASecretName:      c.ClusterCASecretName,
 				ClusterCASecretNamespace: c.ClusterCASecretNamespace,
+				CertificateOptions:       certificateOptions,
 				DevelopmentMode:          c.DevelopmentMode,
 			},
 		},
@@ -117,13 +147,17 @@ func setupControllers(mgr manager.Manager, c *Config) []ControllerDef {
 		{
 			Enabled: c.DataPlaneBlueGreenControllerEnabled,
 			Controller: &controllers.DataPlaneBlueGreenReconciler{
-				Client:          mgr.GetClient(),
-				DevelopmentMode: c.DevelopmentMode,
+				Client:                   mgr.GetClient(),
+				DevelopmentMode:          c.DevelopmentMode,
+				ClusterCASecretName:      c.ClusterCASecretName,
+				ClusterCASecretNamespace: c.ClusterCASecretNamespace,
+				CertificateOptions:       certificateOptions,
 				DataPlaneReconciler: &controllers.DataPlaneReconciler{
 					Client:                   mgr.GetClient(),
 					Scheme:                   mgr.GetScheme(),
 					ClusterCASecretName:      c.ClusterCASecretName,
 					ClusterCASecretNamespace: c.ClusterCASecretNamespace,
+					CertificateOptions:       certificateOptions,
 					DevelopmentMode:          c.DevelopmentMode,
 				},
 			},
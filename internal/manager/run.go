@@ -18,27 +18,22 @@ package manager
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
 	"crypto/x509"
-	"crypto/x509/pkix"
-	"encoding/pem"
 	"fmt"
-	"math"
-	"math/big"
 	"os"
 	"time"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
-	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -46,6 +41,8 @@ import (
 
 	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/controllers"
+	"github.com/kong/gateway-operator/internal/consts"
 	"github.com/kong/gateway-operator/internal/manager/logging"
 	"github.com/kong/gateway-operator/internal/manager/metadata"
 	"github.com/kong/gateway-operator/internal/telemetry"
@@ -57,12 +54,6 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
-const (
-	caCertFilename  = "ca.crt"
-	tlsCertFilename = "tls.crt"
-	tlsKeyFilename  = "tls.key"
-)
-
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(operatorv1alpha1.AddToScheme(scheme))
@@ -74,7 +65,6 @@ func init() {
 type Config struct {
 	MetricsAddr              string
 	ProbeAddr                string
-	WebhookCertDir           string
 	WebhookPort              int
 	LeaderElection           bool
 	LeaderElectionNamespace  string
@@ -90,6 +80,34 @@ type Config struct {
 	ClusterCASecretNamespace string
 	LoggerOpts               zap.Options
 
+	// ClusterCARotateBefore is how long before its expiry the cluster CA is rotated.
+	ClusterCARotateBefore time.Duration
+	// CertificateValidity is the validity period of the certificates issued
+	// from the cluster CA for DataPlanes and ControlPlanes.
+	CertificateValidity time.Duration
+	// CertificateRenewBefore is how long before their expiry the certificates
+	// issued for DataPlanes and ControlPlanes are renewed.
+	CertificateRenewBefore time.Duration
+	// ClusterCAImported is true when the cluster CA Secret is provided by the
+	// user, in which case the operator neither creates nor rotates it.
+	ClusterCAImported bool
+	// CertificateKeyAlgorithm and CertificateKeySize are the algorithm and size
+	// of the private keys of the certificates issued for DataPlanes and ControlPlanes.
+	// The size defaults to the smallest supported size of the algorithm.
+	CertificateKeyAlgorithm controllers.CertificateKeyAlgorithm
+	CertificateKeySize      int
+	// CertificateSignatureAlgorithm is the algorithm the certificates issued for
+	// DataPlanes and ControlPlanes are signed with by the cluster CA. The default
+	// algorithm of the cluster CA key is used when it is unknown.
+	CertificateSignatureAlgorithm x509.SignatureAlgorithm
+	// CertManagerIssuerName, CertManagerIssuerKind and CertManagerIssuerGroup
+	// refer to the cert-manager issuer the certificates of DataPlanes and
+	// ControlPlanes are requested from. They are issued from the cluster CA
+	// when no issuer name is set.
+	CertManagerIssuerName  string
+	CertManagerIssuerKind  string
+	CertManagerIssuerGroup string
+
 	GatewayControllerEnabled            bool
 	ControlPlaneControllerEnabled       bool
 	DataPlaneControllerEnabled          bool
@@ -110,13 +128,18 @@ func DefaultConfig() Config {
 	return Config{
 		MetricsAddr:                   ":8080",
 		ProbeAddr:                     ":8081",
-		WebhookCertDir:                defaultWebhookCertDir,
 		WebhookPort:                   9443,
 		DevelopmentMode:               false,
 		LeaderElection:                true,
 		LeaderElectionNamespace:       defaultLeaderElectionNamespace,
 		ClusterCASecretName:           "kong-operator-ca",
 		ClusterCASecretNamespace:      defaultNamespace,
+		ClusterCARotateBefore:         time.Hour * 24 * 30,
+		CertificateValidity:           controllers.DefaultCertificateValidity,
+		CertificateRenewBefore:        time.Hour * 24 * 30,
+		CertificateKeyAlgorithm:       controllers.CertificateKeyAlgorithmECDSA,
+		CertManagerIssuerKind:         "ClusterIssuer",
+		CertManagerIssuerGroup:        consts.CertManagerGroup,
 		ControllerNamespace:           defaultNamespace,
 		LoggerOpts:                    zap.Options{},
 		GatewayControllerEnabled:      true,
@@ -135,11 +158,20 @@ func Run(cfg Config) error {
 		"commit", metadata.Commit,
 	)
 
+	if err := certificateOptionsForConfig(&cfg).Validate(); err != nil {
+		return fmt.Errorf("invalid certificate options: %w", err)
+	}
+
 	if cfg.ControllerName != "" {
 		setupLog.Info(fmt.Sprintf("custom controller name provided: %s", cfg.ControllerName))
 		vars.SetControllerName(cfg.ControllerName)
 	}
 
+	podsSelector, err := managedPodsSelector()
+	if err != nil {
+		return err
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                  scheme,
 		MetricsBindAddress:      cfg.MetricsAddr,
@@ -149,15 +181,35 @@ func Run(cfg Config) error {
 		LeaderElectionNamespace: cfg.LeaderElectionNamespace,
 		LeaderElectionID:        "a7feedc84.konghq.com",
 		NewClient:               cfg.NewClientFunc,
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				// Only the pods of the DataPlane and ControlPlane Deployments,
+				// whose failures are reported in their status, are cached.
+				&corev1.Pod{}: {Label: podsSelector},
+			},
+		},
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				// The leader election Leases of the ControlPlanes are read
+				// directly, as caching them would cache all the Leases of
+				// the cluster.
+				DisableFor: []client.Object{&coordinationv1.Lease{}},
+			},
+		},
 	})
 	if err != nil {
 		return err
 	}
 
 	caMgr := &caManager{
-		client:          mgr.GetClient(),
-		secretName:      cfg.ClusterCASecretName,
-		secretNamespace: cfg.ClusterCASecretNamespace,
+		client:             mgr.GetClient(),
+		eventRecorder:      mgr.GetEventRecorderFor("ca-manager"),
+		logger:             ctrl.Log.WithName("ca_manager"),
+		secretName:         cfg.ClusterCASecretName,
+		secretNamespace:    cfg.ClusterCASecretNamespace,
+		rotateBefore:       cfg.ClusterCARotateBefore,
+		imported:           cfg.ClusterCAImported,
+		signatureAlgorithm: cfg.CertificateSignatureAlgorithm,
 	}
 	err = mgr.Add(caMgr)
 	if err != nil {
@@ -187,7 +239,7 @@ func Run(cfg Config) error {
 		}
 
 		defer func() {
-			setupLog.Info("cleaning up webhook and certificateConfig resources")
+			setupLog.Info("cleaning up webhook resources")
 			if err := webhookMgr.cleanup(context.Background()); err != nil {
 				setupLog.Error(err, "error while performing cleanup")
 			}
@@ -237,93 +289,6 @@ func Run(cfg Config) error {
 	return nil
 }
 
-type caManager struct {
-	client          client.Client
-	secretName      string
-	secretNamespace string
-}
-
-func (m *caManager) Start(ctx context.Context) error {
-	if m.secretName == "" {
-		return fmt.Errorf("cannot use an empty secret name when creating a CA secret")
-	}
-	if m.secretNamespace == "" {
-		return fmt.Errorf("cannot use an empty secret namespace when creating a CA secret")
-	}
-	return m.maybeCreateCACertificate(ctx)
-}
-
-func (m *caManager) maybeCreateCACertificate(ctx context.Context) error {
-	// TODO https://github.com/Kong/gateway-operator/issues/108 this also needs to check if the CA is expired and
-	// managed, and needs to reissue it (and all issued certificates) if so
-	ca := &corev1.Secret{}
-	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
-	defer cancel()
-	err := m.client.Get(ctx, client.ObjectKey{Namespace: m.secretNamespace, Name: m.secretName}, ca)
-	if k8serrors.IsNotFound(err) {
-		serial, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
-		if err != nil {
-			return err
-		}
-		setupLog.Info(fmt.Sprintf("no CA certificate Secret %s found, generating CA certificate", m.secretName))
-		template := x509.Certificate{
-			Subject: pkix.Name{
-				CommonName:   "Kong Gateway Operator CA",
-				Organization: []string{"Kong, Inc."},
-				Country:      []string{"US"},
-			},
-			SerialNumber:          serial,
-			SignatureAlgorithm:    x509.ECDSAWithSHA256,
-			NotBefore:             time.Now(),
-			NotAfter:              time.Now().Add(time.Second * 315400000),
-			KeyUsage:              x509.KeyUsageCertSign + x509.KeyUsageKeyEncipherment + x509.KeyUsageDigitalSignature,
-			BasicConstraintsValid: true,
-			IsCA:                  true,
-		}
-
-		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-		if err != nil {
-			return err
-		}
-		privDer, err := x509.MarshalECPrivateKey(priv)
-		if err != nil {
-			return err
-		}
-
-		der, err := x509.CreateCertificate(rand.Reader, &template, &template, priv.Public(), priv)
-		if err != nil {
-			return err
-		}
-
-		signedSecret := &corev1.Secret{
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace: m.secretNamespace,
-				Name:      m.secretName,
-			},
-			Type: corev1.SecretTypeTLS,
-			StringData: map[string]string{
-				"tls.crt": string(pem.EncodeToMemory(&pem.Block{
-					Type:  "CERTIFICATE",
-					Bytes: der,
-				})),
-
-				"tls.key": string(pem.EncodeToMemory(&pem.Block{
-					Type:  "EC PRIVATE KEY",
-					Bytes: privDer,
-				})),
-			},
-		}
-		err = m.client.Create(ctx, signedSecret)
-		if err != nil {
-			return err
-		}
-
-	} else if err != nil {
-		return err
-	}
-	return nil
-}
-
 func getKubeconfig(apiServerPath string, kubeconfig string) (*rest.Config, error) {
 	config, err := clientcmd.BuildConfigFromFlags(apiServerPath, kubeconfig)
 	if err != nil {
@@ -369,3 +334,16 @@ func setupAnonymousReports(cfg Config) (func(), error) {
 
 	return tMgr.Stop, nil
 }
+
+// managedPodsSelector returns the label selector of the pods of the DataPlane
+// and ControlPlane Deployments.
+func managedPodsSelector() (labels.Selector, error) {
+	requirement, err := labels.NewRequirement(consts.GatewayOperatorControlledLabel, selection.In, []string{
+		consts.DataPlaneManagedLabelValue,
+		consts.ControlPlaneManagedLabelValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(*requirement), nil
+}
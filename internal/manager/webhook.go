@@ -20,9 +20,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
-	"path"
-	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -30,6 +27,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -41,18 +39,20 @@ import (
 )
 
 const (
-	defaultWebhookCertDir = "/tmp/k8s-webhook-server/serving-certs"
-
-	defaultsecretPollInterval = 2 * time.Second
-	defaultsecretPollTimeout  = 60 * time.Second
+	// defaultCertificatePollInterval and defaultCertificatePollTimeout are the
+	// interval at which the webhook certificate issuance is attempted until the
+	// cluster CA has been created, and how long it is attempted for.
+	defaultCertificatePollInterval = 2 * time.Second
+	defaultCertificatePollTimeout  = 60 * time.Second
 )
 
 type webhookManager struct {
-	client client.Client
-	mgr    ctrl.Manager
-	logger logr.Logger
-	cfg    *Config
-	server webhook.Server
+	client             client.Client
+	mgr                ctrl.Manager
+	logger             logr.Logger
+	cfg                *Config
+	server             webhook.Server
+	certificateManager *webhookCertificateManager
 }
 
 // PrepareWebhookServer creates a webhook server and add it to the controller manager.
@@ -63,15 +63,25 @@ func (m *webhookManager) PrepareWebhookServer(ctx context.Context) error {
 	if m.cfg.ControllerNamespace == "" {
 		return errors.New("controllerNamespace must be set")
 	}
-	if m.cfg.WebhookCertDir == "" {
-		return errors.New("webhookCertDir must be set")
-	}
 	if m.cfg.WebhookPort == 0 {
 		return errors.New("webhookPort must be set")
 	}
 
+	m.certificateManager = &webhookCertificateManager{
+		client:              m.client,
+		logger:              m.logger.WithName("certificate"),
+		namespace:           m.cfg.ControllerNamespace,
+		serviceName:         consts.WebhookServiceName,
+		webhookName:         consts.WebhookName,
+		mutatingWebhookName: consts.MutatingWebhookName,
+		clusterCASecretNN:   types.NamespacedName{Namespace: m.cfg.ClusterCASecretNamespace, Name: m.cfg.ClusterCASecretName},
+		validity:            m.cfg.CertificateValidity,
+		renewBefore:         m.cfg.CertificateRenewBefore,
+		signatureAlgorithm:  m.cfg.CertificateSignatureAlgorithm,
+	}
+
 	// create and start a new webhook server
-	hookServer, err := admission.AddNewWebhookServerToManager(m.mgr, ctrl.Log, m.cfg.WebhookPort, m.cfg.WebhookCertDir)
+	hookServer, err := admission.AddNewWebhookServerToManager(m.mgr, ctrl.Log, m.cfg.WebhookPort, m.certificateManager.GetCertificate)
 	if err != nil {
 		return err
 	}
@@ -87,46 +97,18 @@ func (m *webhookManager) Start(ctx context.Context) error {
 		return err
 	}
 
-	certSecret := &corev1.Secret{}
-	// check if the certificate secret already exists
-	if err := m.client.Get(ctx, types.NamespacedName{Namespace: m.cfg.ControllerNamespace, Name: consts.WebhookCertificateConfigSecretName}, certSecret); err != nil {
-		if !k8serrors.IsNotFound(err) {
-			return err
-		}
-		// no certificate secret found, create all the resources needed to produce it (if they already exist, it is no-op)
-		if err := m.createCertificateConfigResources(ctx); err != nil {
-			return err
-		}
-
-		// wait for the certificate to be created
-		certSecret, err = m.waitForWebhookCertificate(ctx, defaultsecretPollTimeout, defaultsecretPollInterval)
-		if err != nil {
-			return err
-		}
-	}
-
-	// write the webhook certificate files on the filesystem
-	{
-		p := path.Join(m.cfg.WebhookCertDir, caCertFilename)
-		if err := os.WriteFile(p, certSecret.Data["ca"], os.ModePerm); err != nil {
-			return fmt.Errorf("failed writing CA to %s: %w", p, err)
-		}
-	}
-	{
-		p := path.Join(m.cfg.WebhookCertDir, tlsCertFilename)
-		if err := os.WriteFile(p, certSecret.Data["cert"], os.ModePerm); err != nil {
-			return fmt.Errorf("failed writing certificate to %s: %w", p, err)
-		}
+	// issue the webhook certificate, or load it if it has been issued already,
+	// before serving the webhook, and keep it up to date afterwards
+	if err := m.waitForWebhookCertificate(ctx, defaultCertificatePollTimeout, defaultCertificatePollInterval); err != nil {
+		return err
 	}
-	{
-		p := path.Join(m.cfg.WebhookCertDir, tlsKeyFilename)
-		if err := os.WriteFile(p, certSecret.Data["key"], os.ModePerm); err != nil {
-			return fmt.Errorf("failed writing key to %s: %w", p, err)
-		}
+	if err := m.mgr.Add(m.certificateManager); err != nil {
+		return err
 	}
 
-	handler := admission.NewRequestHandler(m.mgr.GetClient(), m.logger)
+	handler := admission.NewRequestHandler(m.mgr.GetClient(), m.logger, m.cfg.DevelopmentMode)
 	m.server.Register("/validate", handler)
+	m.server.Register("/mutate", admission.NewMutatingRequestHandler(m.logger))
 	if err := m.mgr.Add(m.server); err != nil {
 		return err
 	}
@@ -142,76 +124,31 @@ func (m *webhookManager) Start(ctx context.Context) error {
 	return nil
 }
 
-// createCertificateConfigResources create all the resources needed by the CertificateConfig jobs
-func (m *webhookManager) createCertificateConfigResources(ctx context.Context) error {
-	// create the certificateConfig ServiceAccount
-	serviceAccount := k8sresources.GenerateNewServiceAccountForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookCertificateConfigName, consts.WebhookCertificateConfigLabelvalue)
-	if err := m.client.Create(ctx, serviceAccount); err != nil {
-		if !k8serrors.IsAlreadyExists(err) {
-			return err
-		}
-	}
-
-	// create the certificateConfig ClusterRole
-	clusterRole := k8sresources.GenerateNewClusterRoleForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookCertificateConfigName, consts.WebhookCertificateConfigLabelvalue)
-	if err := m.setNamespaceAsOwner(ctx, clusterRole); err != nil {
+func (m *webhookManager) createWebhookResources(ctx context.Context) error {
+	// create the operator ValidatinWebhookConfiguration
+	validatingWebhookConfiguration := k8sresources.GenerateNewValidatingWebhookConfiguration(m.cfg.ControllerNamespace, consts.WebhookServiceName, consts.WebhookName)
+	if err := m.setNamespaceAsOwner(ctx, validatingWebhookConfiguration); err != nil {
 		return err
 	}
-	if err := m.client.Create(ctx, clusterRole); err != nil {
+	if err := m.client.Create(ctx, validatingWebhookConfiguration); err != nil {
 		if !k8serrors.IsAlreadyExists(err) {
 			return err
 		}
 	}
 
-	// create the certificateConfig ClusterRoleBinding
-	clusterRoleBinding := k8sresources.GenerateNewClusterRoleBindingForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookCertificateConfigName, consts.WebhookCertificateConfigLabelvalue)
-	if err := m.setNamespaceAsOwner(ctx, clusterRoleBinding); err != nil {
+	// create the operator MutatingWebhookConfiguration
+	mutatingWebhookConfiguration := k8sresources.GenerateNewMutatingWebhookConfiguration(m.cfg.ControllerNamespace, consts.WebhookServiceName, consts.MutatingWebhookName)
+	if err := m.setNamespaceAsOwner(ctx, mutatingWebhookConfiguration); err != nil {
 		return err
 	}
-	if err := m.client.Create(ctx, clusterRoleBinding); err != nil {
-		if !k8serrors.IsAlreadyExists(err) {
-			return err
-		}
-	}
-
-	// create the certificateConfig Role
-	role := k8sresources.GenerateNewRoleForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookCertificateConfigName, consts.WebhookCertificateConfigLabelvalue)
-	if err := m.client.Create(ctx, role); err != nil {
-		if !k8serrors.IsAlreadyExists(err) {
-			return err
-		}
-	}
-
-	// create the certificateConfig RoleBinding
-	roleBinding := k8sresources.GenerateNewRoleBindingForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookCertificateConfigName, consts.WebhookCertificateConfigLabelvalue)
-	if err := m.client.Create(ctx, roleBinding); err != nil {
-		if !k8serrors.IsAlreadyExists(err) {
-			return err
-		}
-	}
-
-	// create the certificateConfig jobs
-	if err := m.createCertificateConfigJobs(ctx); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (m *webhookManager) createWebhookResources(ctx context.Context) error {
-	// create the operator ValidatinWebhookConfiguration
-	validatingWebhookConfiguration := k8sresources.GenerateNewValidatingWebhookConfiguration(m.cfg.ControllerNamespace, consts.WebhookServiceName, consts.WebhookName)
-	if err := m.setNamespaceAsOwner(ctx, validatingWebhookConfiguration); err != nil {
-		return err
-	}
-	if err := m.client.Create(ctx, validatingWebhookConfiguration); err != nil {
+	if err := m.client.Create(ctx, mutatingWebhookConfiguration); err != nil {
 		if !k8serrors.IsAlreadyExists(err) {
 			return err
 		}
 	}
 
 	// create the Service needed to expose the operator Webhook
-	webhookService := k8sresources.GenerateNewServiceForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookServiceName)
+	webhookService := k8sresources.GenerateNewServiceForWebhook(m.cfg.ControllerNamespace, consts.WebhookServiceName)
 	if err := m.client.Create(ctx, webhookService); err != nil {
 		if !k8serrors.IsAlreadyExists(err) {
 			return err
@@ -221,35 +158,9 @@ func (m *webhookManager) createWebhookResources(ctx context.Context) error {
 	return nil
 }
 
-func (m *webhookManager) createCertificateConfigJobs(ctx context.Context) error {
-	jobCertificateConfigImage := consts.WebhookCertificateConfigBaseImage
-	if relatedJobImage := os.Getenv("RELATED_IMAGE_CERTIFICATE_CONFIG"); relatedJobImage != "" {
-		// RELATED_IMAGE_CERTIFICATE_CONFIG is set by the operator-sdk when building the operator bundle.
-		// https://github.com/Kong/gateway-operator/issues/261
-		jobCertificateConfigImage = relatedJobImage
-	}
-	job := k8sresources.GenerateNewWebhookCertificateConfigJob(
-		m.cfg.ControllerNamespace,
-		consts.WebhookCertificateConfigName,
-		jobCertificateConfigImage,
-		consts.WebhookCertificateConfigSecretName,
-		consts.WebhookName,
-	)
-
-	if err := m.client.Create(ctx, job); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func (m *webhookManager) cleanup(ctx context.Context) error {
 	m.logger.Info("cleaning up webhook resources")
 
-	if err := m.cleanupCertificateConfigResources(ctx); err != nil {
-		return err
-	}
-
 	return m.cleanupWebhookResources(ctx)
 }
 
@@ -262,8 +173,16 @@ func (m *webhookManager) cleanupWebhookResources(ctx context.Context) error {
 		}
 	}
 
+	// delete the operator MutatingWebhookConfiguration
+	mutatingWebhookConfiguration := k8sresources.GenerateNewMutatingWebhookConfiguration(m.cfg.ControllerNamespace, consts.WebhookServiceName, consts.MutatingWebhookName)
+	if err := m.client.Delete(ctx, mutatingWebhookConfiguration); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+
 	// delete the Service needed to expose the operator Webhook
-	webhookService := k8sresources.GenerateNewServiceForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookServiceName)
+	webhookService := k8sresources.GenerateNewServiceForWebhook(m.cfg.ControllerNamespace, consts.WebhookServiceName)
 	if err := m.client.Delete(ctx, webhookService); err != nil {
 		if !k8serrors.IsNotFound(err) {
 			return err
@@ -272,7 +191,7 @@ func (m *webhookManager) cleanupWebhookResources(ctx context.Context) error {
 
 	certSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      consts.WebhookCertificateConfigSecretName,
+			Name:      consts.WebhookCertificateSecretName,
 			Namespace: m.cfg.ControllerNamespace,
 		},
 	}
@@ -285,91 +204,22 @@ func (m *webhookManager) cleanupWebhookResources(ctx context.Context) error {
 	return nil
 }
 
-func (m *webhookManager) cleanupCertificateConfigResources(ctx context.Context) error {
-	// delete the certificateConfig ServiceAccount
-	serviceAccount := k8sresources.GenerateNewServiceAccountForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookCertificateConfigName, consts.WebhookCertificateConfigLabelvalue)
-	if err := m.client.Delete(ctx, serviceAccount); err != nil {
-		if !k8serrors.IsNotFound(err) {
-			return err
-		}
-	}
-
-	// delete the certificateConfig ClusterRole
-	clusterRole := k8sresources.GenerateNewClusterRoleForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookCertificateConfigName, consts.WebhookCertificateConfigLabelvalue)
-	if err := m.client.Delete(ctx, clusterRole); err != nil {
-		if !k8serrors.IsNotFound(err) {
-			return err
-		}
-	}
-
-	// delete the certificateConfig ClusterRoleBinding
-	clusterRoleBinding := k8sresources.GenerateNewClusterRoleBindingForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookCertificateConfigName, consts.WebhookCertificateConfigLabelvalue)
-	if err := m.client.Delete(ctx, clusterRoleBinding); err != nil {
-		if !k8serrors.IsNotFound(err) {
-			return err
-		}
-	}
-
-	// delete the certificateConfig Role
-	role := k8sresources.GenerateNewRoleForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookCertificateConfigName, consts.WebhookCertificateConfigLabelvalue)
-	if err := m.client.Delete(ctx, role); err != nil {
-		if !k8serrors.IsNotFound(err) {
-			return err
-		}
-	}
-
-	// delete the certificateConfig RoleBinding
-	roleBinding := k8sresources.GenerateNewRoleBindingForCertificateConfig(m.cfg.ControllerNamespace, consts.WebhookCertificateConfigName, consts.WebhookCertificateConfigLabelvalue)
-	if err := m.client.Delete(ctx, roleBinding); err != nil {
-		if !k8serrors.IsNotFound(err) {
-			return err
-		}
-	}
-
-	return nil
-}
-
-// waitForWebhookCertificate polls the API server at a specific interval until the webhook certificate
-// secret is created. If the timer expires, it returns an error. Otherwise, the Secret is returned.
-func (m *webhookManager) waitForWebhookCertificate(ctx context.Context, pollTimeout time.Duration, pollInterval time.Duration) (*corev1.Secret, error) {
-	ticker := time.NewTicker(pollInterval)
-	quit := make(chan struct{})
-	errChan := make(chan error, 1)
-	certificateSecret := &corev1.Secret{}
-	wg := sync.WaitGroup{}
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case <-ticker.C:
-				err := m.client.Get(ctx, types.NamespacedName{Namespace: m.cfg.ControllerNamespace, Name: consts.WebhookCertificateConfigSecretName}, certificateSecret)
-				if err != nil {
-					if !k8serrors.IsNotFound(err) {
-						errChan <- err
-						return
-					}
-					continue
-				}
-				return
-			case <-quit:
-				ticker.Stop()
-				errChan <- fmt.Errorf("timeout for creating webhook certificate expired")
-				return
-			}
-		}
-	}()
-	time.AfterFunc(pollTimeout, func() {
-		close(quit)
+// waitForWebhookCertificate attempts at a specific interval to issue or load the
+// webhook certificate until it succeeds, as long as the cluster CA it is issued
+// from has not been created yet. If the timer expires, it returns an error.
+func (m *webhookManager) waitForWebhookCertificate(ctx context.Context, pollTimeout time.Duration, pollInterval time.Duration) error {
+	err := wait.PollUntilContextTimeout(ctx, pollInterval, pollTimeout, true, func(ctx context.Context) (bool, error) {
+		err := m.certificateManager.ensureCertificate(ctx)
+		if k8serrors.IsNotFound(err) {
+			m.logger.V(1).Info("waiting for the webhook certificate to be issued", "reason", err.Error())
+			return false, nil
+		}
+		return err == nil, err
 	})
-	wg.Wait()
-	ticker.Stop()
-	select {
-	case err := <-errChan:
-		return nil, err
-	default:
-		return certificateSecret, nil
+	if wait.Interrupted(err) {
+		return fmt.Errorf("timeout for creating webhook certificate expired")
 	}
+	return err
 }
 
 // setNamespaceAsOwner sets the namespace as ownerReference for the given objects.
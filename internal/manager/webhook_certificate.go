@@ -0,0 +1,331 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+)
+
+// webhookCertificateCheckInterval is the interval at which the webhook serving
+// certificate is checked for expiry and for the rotation of the cluster CA.
+const webhookCertificateCheckInterval = time.Minute
+
+// webhookCertificateManager issues the serving certificate of the webhook server
+// from the cluster CA, stores it in the webhook certificate Secret and serves it
+// to the webhook server, which loads it on every TLS handshake. It reissues the
+// certificate before it expires or once the cluster CA has been rotated, and
+// sets the CA certificates it is verified with as the CA bundle of the
+// ValidatingWebhookConfiguration and of the MutatingWebhookConfiguration.
+type webhookCertificateManager struct {
+	client      client.Client
+	logger      logr.Logger
+	namespace   string
+	serviceName string
+	// webhookName and mutatingWebhookName are the names of the
+	// ValidatingWebhookConfiguration and of the MutatingWebhookConfiguration
+	// the CA bundle is set in.
+	webhookName         string
+	mutatingWebhookName string
+	clusterCASecretNN   types.NamespacedName
+	// validity is the validity period of the issued certificates and
+	// renewBefore is how long before their expiry they are reissued.
+	validity    time.Duration
+	renewBefore time.Duration
+	// signatureAlgorithm is the algorithm the certificates are signed with by
+	// the cluster CA, or x509.UnknownSignatureAlgorithm for the default
+	// algorithm of its key.
+	signatureAlgorithm x509.SignatureAlgorithm
+
+	certificate atomic.Pointer[tls.Certificate]
+}
+
+// GetCertificate returns the current webhook serving certificate. It is meant
+// to be used as the GetCertificate function of the webhook server TLS config.
+func (m *webhookCertificateManager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert := m.certificate.Load()
+	if cert == nil {
+		return nil, errors.New("the webhook certificate has not been issued yet")
+	}
+	return cert, nil
+}
+
+// Start keeps the webhook serving certificate up to date until the context is
+// done. The certificate is expected to have been issued already.
+func (m *webhookCertificateManager) Start(ctx context.Context) error {
+	ticker := time.NewTicker(webhookCertificateCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := m.ensureCertificate(ctx); err != nil {
+				m.logger.Error(err, "failed ensuring the webhook certificate")
+			}
+		}
+	}
+}
+
+// ensureCertificate issues the webhook serving certificate when the webhook
+// certificate Secret does not hold a valid one, serves it to the webhook server
+// and sets the CA bundle of the ValidatingWebhookConfiguration.
+func (m *webhookCertificateManager) ensureCertificate(ctx context.Context) error {
+	ca := &corev1.Secret{}
+	if err := m.client.Get(ctx, m.clusterCASecretNN, ca); err != nil {
+		return fmt.Errorf("failed getting the cluster CA secret: %w", err)
+	}
+	caBundle := k8sutils.CATrustBundle(ca)
+
+	secret, err := m.ensureCertificateSecret(ctx, ca)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return fmt.Errorf("failed loading the webhook certificate of secret %s: %w", secret.Name, err)
+	}
+	m.certificate.Store(&cert)
+
+	return m.ensureCABundle(ctx, caBundle)
+}
+
+// ensureCertificateSecret returns the webhook certificate Secret, created or
+// updated with a certificate issued from the provided cluster CA when it does
+// not hold a certificate valid for the webhook Service, signed by the current
+// CA and not due for renewal.
+func (m *webhookCertificateManager) ensureCertificateSecret(ctx context.Context, ca *corev1.Secret) (*corev1.Secret, error) {
+	nn := types.NamespacedName{Namespace: m.namespace, Name: consts.WebhookCertificateSecretName}
+	secret := &corev1.Secret{}
+	err := m.client.Get(ctx, nn, secret)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+	// The Secret created by the kube-webhook-certgen Job used by the previous
+	// versions of the operator is replaced, as the type of a Secret is immutable.
+	if err == nil && secret.Type != corev1.SecretTypeTLS {
+		m.logger.Info("replacing the webhook certificate secret, which is not a TLS secret", "secret", nn)
+		if err := m.client.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		err = k8serrors.NewNotFound(corev1.Resource("secrets"), nn.Name)
+	}
+
+	if k8serrors.IsNotFound(err) {
+		certPEM, keyPEM, err := m.issueCertificate(ca)
+		if err != nil {
+			return nil, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: nn.Namespace,
+				Name:      nn.Name,
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: map[string][]byte{
+				corev1.TLSCertKey:              certPEM,
+				corev1.TLSPrivateKeyKey:        keyPEM,
+				corev1.ServiceAccountRootCAKey: k8sutils.CATrustBundle(ca),
+			},
+		}
+		if err := m.client.Create(ctx, secret); err != nil {
+			return nil, fmt.Errorf("failed creating the webhook certificate secret: %w", err)
+		}
+		m.logger.Info("webhook certificate issued", "secret", nn)
+		return secret, nil
+	}
+
+	caCerts, err := k8sutils.ParseCertificates(ca.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing the CA certificate of secret %s: %w", ca.Name, err)
+	}
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	reissue := m.isCertificateReissueNeeded(secret, caCerts[0])
+	updated := reissue
+	if reissue {
+		certPEM, keyPEM, err := m.issueCertificate(ca)
+		if err != nil {
+			return nil, err
+		}
+		secret.Data[corev1.TLSCertKey] = certPEM
+		secret.Data[corev1.TLSPrivateKeyKey] = keyPEM
+	}
+	if caBundle := k8sutils.CATrustBundle(ca); !bytes.Equal(secret.Data[corev1.ServiceAccountRootCAKey], caBundle) {
+		secret.Data[corev1.ServiceAccountRootCAKey] = caBundle
+		updated = true
+	}
+	if updated {
+		if err := m.client.Update(ctx, secret); err != nil {
+			return nil, fmt.Errorf("failed updating the webhook certificate secret: %w", err)
+		}
+		if reissue {
+			m.logger.Info("webhook certificate reissued", "secret", nn)
+		}
+	}
+	return secret, nil
+}
+
+// isCertificateReissueNeeded returns true when the provided webhook certificate
+// Secret does not hold a certificate and key valid for the webhook Service and
+// signed by the provided cluster CA certificate, or when the certificate expires
+// within the renewal period. The certificate is not reissued while the CA itself
+// expires within that period, as it is not issued past the expiry of the CA.
+func (m *webhookCertificateManager) isCertificateReissueNeeded(secret *corev1.Secret, caCert *x509.Certificate) bool {
+	if _, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		return true
+	}
+	certs, err := k8sutils.ParseCertificates(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return true
+	}
+	cert := certs[0]
+	return cert.CheckSignatureFrom(caCert) != nil ||
+		!cmp.Equal(cert.DNSNames, m.dnsNames()) ||
+		(time.Until(cert.NotAfter) < m.renewBefore && time.Until(caCert.NotAfter) > m.renewBefore)
+}
+
+// ensureCABundle sets the provided CA bundle in all the webhooks of the
+// ValidatingWebhookConfiguration and of the MutatingWebhookConfiguration.
+func (m *webhookCertificateManager) ensureCABundle(ctx context.Context, caBundle []byte) error {
+	validatingWebhookConfiguration := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	if err := m.client.Get(ctx, types.NamespacedName{Name: m.webhookName}, validatingWebhookConfiguration); err != nil {
+		return fmt.Errorf("failed getting ValidatingWebhookConfiguration %s: %w", m.webhookName, err)
+	}
+	old := validatingWebhookConfiguration.DeepCopy()
+	var updated bool
+	for i := range validatingWebhookConfiguration.Webhooks {
+		updated = setCABundle(&validatingWebhookConfiguration.Webhooks[i].ClientConfig, caBundle) || updated
+	}
+	if updated {
+		if err := m.client.Patch(ctx, validatingWebhookConfiguration, client.MergeFrom(old)); err != nil {
+			return fmt.Errorf("failed patching the CA bundle of ValidatingWebhookConfiguration %s: %w", m.webhookName, err)
+		}
+	}
+
+	mutatingWebhookConfiguration := &admissionregistrationv1.MutatingWebhookConfiguration{}
+	if err := m.client.Get(ctx, types.NamespacedName{Name: m.mutatingWebhookName}, mutatingWebhookConfiguration); err != nil {
+		return fmt.Errorf("failed getting MutatingWebhookConfiguration %s: %w", m.mutatingWebhookName, err)
+	}
+	oldMutating := mutatingWebhookConfiguration.DeepCopy()
+	updated = false
+	for i := range mutatingWebhookConfiguration.Webhooks {
+		updated = setCABundle(&mutatingWebhookConfiguration.Webhooks[i].ClientConfig, caBundle) || updated
+	}
+	if updated {
+		if err := m.client.Patch(ctx, mutatingWebhookConfiguration, client.MergeFrom(oldMutating)); err != nil {
+			return fmt.Errorf("failed patching the CA bundle of MutatingWebhookConfiguration %s: %w", m.mutatingWebhookName, err)
+		}
+	}
+	return nil
+}
+
+// setCABundle sets the provided CA bundle in the webhook client config and
+// returns true if it has changed.
+func setCABundle(clientConfig *admissionregistrationv1.WebhookClientConfig, caBundle []byte) bool {
+	if bytes.Equal(clientConfig.CABundle, caBundle) {
+		return false
+	}
+	clientConfig.CABundle = caBundle
+	return true
+}
+
+// dnsNames returns the DNS names of the webhook Service the certificate is
+// valid for.
+func (m *webhookCertificateManager) dnsNames() []string {
+	return []string{
+		m.serviceName,
+		fmt.Sprintf("%s.%s.svc", m.serviceName, m.namespace),
+	}
+}
+
+// issueCertificate generates a private key and a serving certificate for the
+// webhook Service, signed by the provided cluster CA and valid for the validity
+// period, or until the CA expires if it expires earlier. It returns the PEM
+// encoded certificate, followed by the intermediate CA certificates it is
+// verified through, and key.
+func (m *webhookCertificateManager) issueCertificate(ca *corev1.Secret) ([]byte, []byte, error) {
+	caCerts, err := k8sutils.ParseCertificates(ca.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed parsing the CA certificate of secret %s: %w", ca.Name, err)
+	}
+	caCert := caCerts[0]
+	caKey, err := k8sutils.ParsePrivateKey(ca.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed parsing the CA key of secret %s: %w", ca.Name, err)
+	}
+	signatureAlgorithm, err := k8sutils.SignatureAlgorithmForKey(caKey.Public(), m.signatureAlgorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+	if err != nil {
+		return nil, nil, err
+	}
+	notAfter := time.Now().Add(m.validity)
+	if caCert.NotAfter.Before(notAfter) {
+		notAfter = caCert.NotAfter
+	}
+	dnsNames := m.dnsNames()
+	template := x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   dnsNames[len(dnsNames)-1],
+			Organization: []string{"Kong, Inc."},
+			Country:      []string{"US"},
+		},
+		SerialNumber:       serial,
+		SignatureAlgorithm: signatureAlgorithm,
+		DNSNames:           dnsNames,
+		NotBefore:          time.Now().Add(-time.Minute),
+		NotAfter:           notAfter,
+		KeyUsage:           x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:        []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	privDer, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, caCert, priv.Public(), caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: der,
+	})
+	return append(certPEM, k8sutils.IntermediateCACertificates(caCerts)...), pem.EncodeToMemory(&pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: privDer,
+	}), nil
+}
@@ -18,18 +18,24 @@ package manager
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/kong/gateway-operator/internal/consts"
+	k8sresources "github.com/kong/gateway-operator/internal/utils/kubernetes/resources"
 )
 
 func TestWaitForWebhookCertificate(t *testing.T) {
@@ -38,31 +44,39 @@ func TestWaitForWebhookCertificate(t *testing.T) {
 	testScheme := runtime.NewScheme()
 	require.NoError(t, clientgoscheme.AddToScheme(testScheme))
 
+	caCert, caKey, err := generateCACertificate()
+	require.NoError(t, err)
+
 	testCases := []struct {
 		name         string
 		pollTimeout  time.Duration
 		pollInterval time.Duration
 		createAfter  time.Duration
 		namespace    string
-		secret       *corev1.Secret
+		ca           *corev1.Secret
 		err          error
 	}{
 		{
-			name:         "secret created before the timer expires",
+			name:         "cluster CA created before the timer expires",
 			pollTimeout:  4 * time.Second,
 			pollInterval: 10 * time.Millisecond,
 			createAfter:  500 * time.Millisecond,
 			namespace:    "test",
-			secret: &corev1.Secret{
+			ca: &corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      consts.WebhookCertificateConfigSecretName,
+					Name:      "kong-operator-ca",
 					Namespace: "test",
 				},
+				Type: corev1.SecretTypeTLS,
+				Data: map[string][]byte{
+					"tls.crt": caCert,
+					"tls.key": caKey,
+				},
 			},
 			err: nil,
 		},
 		{
-			name:         "secret not created before the timer expires",
+			name:         "cluster CA not created before the timer expires",
 			pollTimeout:  500 * time.Millisecond,
 			pollInterval: 10 * time.Millisecond,
 			namespace:    "test",
@@ -79,21 +93,27 @@ func TestWaitForWebhookCertificate(t *testing.T) {
 			fakeClient := fakectrlruntimeclient.
 				NewClientBuilder().
 				WithScheme(testScheme).
+				WithObjects(
+					k8sresources.GenerateNewValidatingWebhookConfiguration("test", consts.WebhookServiceName, consts.WebhookName),
+					k8sresources.GenerateNewMutatingWebhookConfiguration("test", consts.WebhookServiceName, consts.MutatingWebhookName),
+				).
 				Build()
 
 			webhookMgr := webhookManager{
 				client: fakeClient,
+				logger: logr.Discard(),
 				cfg: &Config{
 					ControllerNamespace: "test",
 				},
+				certificateManager: newTestWebhookCertificateManager(fakeClient),
 			}
 
 			if tc.createAfter != 0 {
 				time.AfterFunc(tc.createAfter, func() {
-					require.NoError(t, fakeClient.Create(ctx, tc.secret))
+					require.NoError(t, fakeClient.Create(ctx, tc.ca))
 				})
 			}
-			_, err := webhookMgr.waitForWebhookCertificate(ctx, tc.pollTimeout, tc.pollInterval)
+			err := webhookMgr.waitForWebhookCertificate(ctx, tc.pollTimeout, tc.pollInterval)
 			if tc.err != nil {
 				require.EqualError(t, err, tc.err.Error(), tc.name)
 			} else {
@@ -102,3 +122,116 @@ func TestWaitForWebhookCertificate(t *testing.T) {
 		})
 	}
 }
+
+func newTestWebhookCertificateManager(fakeClient client.Client) *webhookCertificateManager {
+	return &webhookCertificateManager{
+		client:              fakeClient,
+		logger:              logr.Discard(),
+		namespace:           "test",
+		serviceName:         consts.WebhookServiceName,
+		webhookName:         consts.WebhookName,
+		mutatingWebhookName: consts.MutatingWebhookName,
+		clusterCASecretNN:   types.NamespacedName{Namespace: "test", Name: "kong-operator-ca"},
+		validity:            time.Hour * 24 * 365,
+		renewBefore:         time.Hour * 24 * 30,
+	}
+}
+
+func TestWebhookCertificateManager(t *testing.T) {
+	ctx := context.Background()
+	caNN := types.NamespacedName{Namespace: "test", Name: "kong-operator-ca"}
+	caCert, caKey, err := generateCACertificate()
+	require.NoError(t, err)
+	ca := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      caNN.Name,
+			Namespace: caNN.Namespace,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"tls.crt": caCert,
+			"tls.key": caKey,
+		},
+	}
+	// certgenSecret is the Secret created by the kube-webhook-certgen Job used
+	// by the previous versions of the operator.
+	certgenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      consts.WebhookCertificateSecretName,
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			"ca":   []byte("ca"),
+			"cert": []byte("cert"),
+			"key":  []byte("key"),
+		},
+	}
+	fakeClient := fakectrlruntimeclient.NewClientBuilder().
+		WithScheme(clientgoscheme.Scheme).
+		WithObjects(
+			ca,
+			certgenSecret,
+			k8sresources.GenerateNewValidatingWebhookConfiguration("test", consts.WebhookServiceName, consts.WebhookName),
+			k8sresources.GenerateNewMutatingWebhookConfiguration("test", consts.WebhookServiceName, consts.MutatingWebhookName),
+		).
+		Build()
+	m := newTestWebhookCertificateManager(fakeClient)
+
+	// servedCertificate returns the certificate served by the webhook server,
+	// after verifying it for the webhook Service with the CA bundle of the
+	// ValidatingWebhookConfiguration.
+	servedCertificate := func(t *testing.T) *x509.Certificate {
+		tlsCert, err := m.GetCertificate(nil)
+		require.NoError(t, err)
+		cert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+		require.NoError(t, err)
+
+		webhookConfiguration := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: consts.WebhookName}, webhookConfiguration))
+		roots := x509.NewCertPool()
+		require.True(t, roots.AppendCertsFromPEM(webhookConfiguration.Webhooks[0].ClientConfig.CABundle))
+		_, err = cert.Verify(x509.VerifyOptions{
+			Roots:   roots,
+			DNSName: fmt.Sprintf("%s.test.svc", consts.WebhookServiceName),
+		})
+		require.NoError(t, err)
+
+		mutatingWebhookConfiguration := &admissionregistrationv1.MutatingWebhookConfiguration{}
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: consts.MutatingWebhookName}, mutatingWebhookConfiguration))
+		require.Equal(t, webhookConfiguration.Webhooks[0].ClientConfig.CABundle, mutatingWebhookConfiguration.Webhooks[0].ClientConfig.CABundle)
+		return cert
+	}
+
+	t.Log("no certificate is served until it has been issued")
+	_, err = m.GetCertificate(nil)
+	require.Error(t, err)
+
+	t.Log("the Secret created by kube-webhook-certgen is replaced with an issued certificate")
+	require.NoError(t, m.ensureCertificate(ctx))
+	secret := &corev1.Secret{}
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(certgenSecret), secret))
+	require.Equal(t, corev1.SecretTypeTLS, secret.Type)
+	cert := servedCertificate(t)
+
+	t.Log("the certificate is kept while it is valid")
+	require.NoError(t, m.ensureCertificate(ctx))
+	require.Equal(t, cert.SerialNumber, servedCertificate(t).SerialNumber)
+
+	t.Log("the certificate is reissued once the cluster CA has been rotated")
+	newCACert, newCAKey, err := generateCACertificate()
+	require.NoError(t, err)
+	ca.Data = map[string][]byte{
+		"tls.crt":                      newCACert,
+		"tls.key":                      newCAKey,
+		consts.ClusterCATrustBundleKey: append(append([]byte{}, newCACert...), caCert...),
+	}
+	require.NoError(t, fakeClient.Update(ctx, ca))
+	require.NoError(t, m.ensureCertificate(ctx))
+	rotatedCert := servedCertificate(t)
+	require.NotEqual(t, cert.SerialNumber, rotatedCert.SerialNumber)
+
+	t.Log("the certificate is reissued when it expires within the renewal period")
+	m.renewBefore = m.validity + time.Hour
+	require.NoError(t, m.ensureCertificate(ctx))
+	require.NotEqual(t, rotatedCert.SerialNumber, servedCertificate(t).SerialNumber)
+}
@@ -0,0 +1,71 @@
+package controlplane
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	"github.com/kong/gateway-operator/pkg/vars"
+)
+
+// -----------------------------------------------------------------------------
+// ControlPlane Utils - Config
+// -----------------------------------------------------------------------------
+
+// controllerDefaults returns the baseline configuration options of the
+// controller container that do not depend on the DataPlane of the ControlPlane.
+func controllerDefaults() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name: "POD_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "metadata.namespace",
+				},
+			},
+		},
+		{
+			Name: "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "metadata.name",
+				},
+			},
+		},
+		{Name: "CONTROLLER_GATEWAY_API_CONTROLLER_NAME", Value: vars.ControllerName()},
+		{Name: "CONTROLLER_KONG_ADMIN_TLS_CLIENT_CERT_FILE", Value: "/var/cluster-certificate/tls.crt"},
+		{Name: "CONTROLLER_KONG_ADMIN_TLS_CLIENT_KEY_FILE", Value: "/var/cluster-certificate/tls.key"},
+		{Name: "CONTROLLER_KONG_ADMIN_CA_CERT_FILE", Value: "/var/cluster-certificate/ca.crt"},
+	}
+}
+
+// SetControlPlaneDefaults sets any unset default configuration options of the
+// controller container of the ControlPlane that do not depend on its DataPlane.
+// No configuration is overridden.
+// returns true if new envs are actually appended.
+func SetControlPlaneDefaults(spec *operatorv1alpha1.ControlPlaneOptions) bool {
+	if spec.Deployment.PodTemplateSpec == nil {
+		spec.Deployment.PodTemplateSpec = &corev1.PodTemplateSpec{}
+	}
+
+	podSpec := &spec.Deployment.PodTemplateSpec.Spec
+	container := k8sutils.GetPodContainerByName(podSpec, consts.ControlPlaneControllerContainerName)
+	if container == nil {
+		podSpec.Containers = append(podSpec.Containers, corev1.Container{
+			Name: consts.ControlPlaneControllerContainerName,
+		})
+		container = &podSpec.Containers[len(podSpec.Containers)-1]
+	}
+
+	updated := false
+	for _, envVar := range controllerDefaults() {
+		if !k8sutils.IsEnvVarPresent(envVar, container.Env) {
+			container.Env = append(container.Env, envVar)
+			updated = true
+		}
+	}
+	return updated
+}
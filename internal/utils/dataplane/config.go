@@ -50,6 +50,8 @@ var KongDefaults = map[string]string{
 // SetDataPlaneDefaults sets any unset default configuration options on the
 // DataPlane. No configuration is overridden. EnvVars are sorted
 // lexographically as a side effect.
+// DataPlanes with a PostgreSQL database configured default to the "postgres"
+// database mode instead of the DB-less one.
 // returns true if new envs are actually appended.
 func SetDataPlaneDefaults(spec *operatorv1beta1.DataPlaneOptions) bool {
 	if spec.Deployment.PodTemplateSpec == nil {
@@ -63,8 +65,15 @@ func SetDataPlaneDefaults(spec *operatorv1beta1.DataPlaneOptions) bool {
 		generated = true
 	}
 
+	defaults := KongDefaults
+	if spec.Database != nil && spec.Database.Postgres != nil {
+		defaults = lo.Assign(KongDefaults, map[string]string{
+			consts.EnvVarKongDatabase: consts.DataPlaneDatabaseModePostgres,
+		})
+	}
+
 	updated := false
-	for k, v := range KongDefaults {
+	for k, v := range defaults {
 		envVar := corev1.EnvVar{Name: k, Value: v}
 		if !k8sutils.IsEnvVarPresent(envVar, dataplaneContainer.Env) {
 			dataplaneContainer.Env = append(dataplaneContainer.Env, envVar)
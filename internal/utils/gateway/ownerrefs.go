@@ -3,8 +3,14 @@ package gateway
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/samber/lo"
 	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -103,6 +109,141 @@ func GetDataPlaneForControlPlane(
 	return &dataplane, nil
 }
 
+// ListDataPlanesForControlPlane retrieves the DataPlane objects a ControlPlane
+// pushes its configuration to, that are the ones referenced by name and the
+// ones matching its DataPlane selector which allow the namespace of the
+// ControlPlane to select them. The DataPlane referenced by the
+// DataPlane field comes first, and the DataPlanes which are referenced by name
+// but do not exist are omitted.
+func ListDataPlanesForControlPlane(
+	ctx context.Context,
+	c client.Client,
+	controlplane *operatorv1alpha1.ControlPlane,
+) ([]operatorv1beta1.DataPlane, error) {
+	dataplanes := make([]operatorv1beta1.DataPlane, 0)
+	seen := make(map[types.NamespacedName]struct{})
+	add := func(dataplane operatorv1beta1.DataPlane) {
+		nn := client.ObjectKeyFromObject(&dataplane)
+		if _, ok := seen[nn]; ok {
+			return
+		}
+		seen[nn] = struct{}{}
+		dataplanes = append(dataplanes, dataplane)
+	}
+
+	for _, name := range ControlPlaneDataPlaneNames(controlplane) {
+		dataplane := operatorv1beta1.DataPlane{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: controlplane.Namespace, Name: name}, &dataplane); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		add(dataplane)
+	}
+
+	if controlplane.Spec.DataPlaneSelector == nil {
+		return dataplanes, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&controlplane.Spec.DataPlaneSelector.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dataplane selector: %w", err)
+	}
+	selected := make([]operatorv1beta1.DataPlane, 0)
+	for _, namespace := range DataPlaneSelectorNamespaces(controlplane) {
+		dataplaneList := &operatorv1beta1.DataPlaneList{}
+		if err := c.List(ctx, dataplaneList,
+			client.InNamespace(namespace),
+			client.MatchingLabelsSelector{Selector: selector},
+		); err != nil {
+			return nil, err
+		}
+		for _, dataplane := range dataplaneList.Items {
+			if DataPlaneAllowsControlPlaneNamespace(&dataplane, controlplane.Namespace) {
+				selected = append(selected, dataplane)
+			}
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].Namespace != selected[j].Namespace {
+			return selected[i].Namespace < selected[j].Namespace
+		}
+		return selected[i].Name < selected[j].Name
+	})
+	for _, dataplane := range selected {
+		add(dataplane)
+	}
+
+	return dataplanes, nil
+}
+
+// ControlPlaneReferencesDataPlane returns true when the provided DataPlane is
+// one of the DataPlanes the provided ControlPlane pushes its configuration to.
+func ControlPlaneReferencesDataPlane(
+	controlplane *operatorv1alpha1.ControlPlane,
+	dataplane *operatorv1beta1.DataPlane,
+) bool {
+	if dataplane.Namespace == controlplane.Namespace &&
+		lo.Contains(ControlPlaneDataPlaneNames(controlplane), dataplane.Name) {
+		return true
+	}
+
+	if controlplane.Spec.DataPlaneSelector == nil ||
+		!lo.Contains(DataPlaneSelectorNamespaces(controlplane), dataplane.Namespace) ||
+		!DataPlaneAllowsControlPlaneNamespace(dataplane, controlplane.Namespace) {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&controlplane.Spec.DataPlaneSelector.Selector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(dataplane.Labels))
+}
+
+// DataPlaneAllowsControlPlaneNamespace returns true when the DataPlane selectors
+// of the ControlPlanes in the provided namespace may select the provided
+// DataPlane. The DataPlanes can be selected from their own namespace, and from
+// the namespaces listed in their allowed ControlPlane namespaces annotation.
+func DataPlaneAllowsControlPlaneNamespace(dataplane *operatorv1beta1.DataPlane, namespace string) bool {
+	if dataplane.Namespace == namespace {
+		return true
+	}
+	allowed, ok := dataplane.Annotations[consts.DataPlaneAllowedControlPlaneNamespacesAnnotation]
+	if !ok {
+		return false
+	}
+	return lo.ContainsBy(strings.Split(allowed, ","), func(allowedNamespace string) bool {
+		return strings.TrimSpace(allowedNamespace) == namespace
+	})
+}
+
+// ControlPlaneDataPlaneNames returns the names of the DataPlanes a ControlPlane
+// references by name, in its own namespace.
+func ControlPlaneDataPlaneNames(controlplane *operatorv1alpha1.ControlPlane) []string {
+	names := make([]string, 0, len(controlplane.Spec.DataPlanes)+1)
+	if controlplane.Spec.DataPlane != nil && *controlplane.Spec.DataPlane != "" {
+		names = append(names, *controlplane.Spec.DataPlane)
+	}
+	for _, name := range controlplane.Spec.DataPlanes {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return lo.Uniq(names)
+}
+
+// DataPlaneSelectorNamespaces returns the namespaces in which a ControlPlane
+// selects DataPlanes through its DataPlane selector.
+func DataPlaneSelectorNamespaces(controlplane *operatorv1alpha1.ControlPlane) []string {
+	if controlplane.Spec.DataPlaneSelector == nil {
+		return nil
+	}
+	if len(controlplane.Spec.DataPlaneSelector.Namespaces) == 0 {
+		return []string{controlplane.Namespace}
+	}
+	return controlplane.Spec.DataPlaneSelector.Namespaces
+}
+
 // GetDataplaneServiceName is a helper function that retrieves the name of the service owned by provided dataplane.
 // It accepts a string as the last argument to specify which service to retrieve (proxy/admin)
 func GetDataplaneServiceName(
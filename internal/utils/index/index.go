@@ -7,24 +7,76 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	gatewayutils "github.com/kong/gateway-operator/internal/utils/gateway"
 )
 
 const (
 	// DataplaneNameIndex is the key to be used to access the .spec.dataplaneName indexed values
 	DataplaneNameIndex = "dataplane"
+
+	// DataPlaneSelectorNamespaceIndex is the key to be used to access the
+	// namespaces in which ControlPlanes select DataPlanes through their
+	// .spec.dataplaneSelector
+	DataPlaneSelectorNamespaceIndex = "dataplaneSelectorNamespace"
+
+	// ClusterControlPlaneNameIndex is the key to be used to access the
+	// .spec.cluster.controlPlaneName indexed values
+	ClusterControlPlaneNameIndex = "clusterControlPlane"
 )
 
-// IndexDataPlaneNameOnControlPlane indexes the ControlPlane .spec.dataplaneName field
-// on the "dataplane" key.
+// IndexDataPlaneNameOnControlPlane indexes the ControlPlane .spec.dataplane and
+// .spec.dataplanes fields on the "dataplane" key.
 func IndexDataPlaneNameOnControlPlane(c cache.Cache) error {
-	return c.IndexField(context.Background(), &operatorv1alpha1.ControlPlane{}, DataplaneNameIndex, func(o client.Object) []string {
-		controlPlane, ok := o.(*operatorv1alpha1.ControlPlane)
-		if !ok {
-			return []string{}
-		}
-		if controlPlane.Spec.DataPlane != nil {
-			return []string{*controlPlane.Spec.DataPlane}
-		}
+	return c.IndexField(context.Background(), &operatorv1alpha1.ControlPlane{}, DataplaneNameIndex, DataPlaneNamesOnControlPlane)
+}
+
+// DataPlaneNamesOnControlPlane returns the names of the DataPlanes the provided
+// ControlPlane references by name.
+func DataPlaneNamesOnControlPlane(o client.Object) []string {
+	controlPlane, ok := o.(*operatorv1alpha1.ControlPlane)
+	if !ok {
+		return []string{}
+	}
+	return gatewayutils.ControlPlaneDataPlaneNames(controlPlane)
+}
+
+// IndexDataPlaneSelectorNamespaceOnControlPlane indexes the namespaces in which
+// the ControlPlanes select DataPlanes through their .spec.dataplaneSelector on
+// the "dataplaneSelectorNamespace" key.
+func IndexDataPlaneSelectorNamespaceOnControlPlane(c cache.Cache) error {
+	return c.IndexField(context.Background(), &operatorv1alpha1.ControlPlane{}, DataPlaneSelectorNamespaceIndex, DataPlaneSelectorNamespacesOnControlPlane)
+}
+
+// DataPlaneSelectorNamespacesOnControlPlane returns the namespaces in which the
+// provided ControlPlane selects DataPlanes.
+func DataPlaneSelectorNamespacesOnControlPlane(o client.Object) []string {
+	controlPlane, ok := o.(*operatorv1alpha1.ControlPlane)
+	if !ok {
+		return []string{}
+	}
+	namespaces := gatewayutils.DataPlaneSelectorNamespaces(controlPlane)
+	if namespaces == nil {
+		return []string{}
+	}
+	return namespaces
+}
+
+// IndexClusterControlPlaneNameOnDataPlane indexes the DataPlane .spec.cluster.controlPlaneName
+// field on the "clusterControlPlane" key.
+func IndexClusterControlPlaneNameOnDataPlane(c cache.Cache) error {
+	return c.IndexField(context.Background(), &operatorv1beta1.DataPlane{}, ClusterControlPlaneNameIndex, ClusterControlPlaneNameOnDataPlane)
+}
+
+// ClusterControlPlaneNameOnDataPlane returns the name of the hybrid mode control
+// plane DataPlane the provided DataPlane connects to.
+func ClusterControlPlaneNameOnDataPlane(o client.Object) []string {
+	dataPlane, ok := o.(*operatorv1beta1.DataPlane)
+	if !ok {
 		return []string{}
-	})
+	}
+	if dataPlane.Spec.Cluster != nil && dataPlane.Spec.Cluster.ControlPlaneName != "" {
+		return []string{dataPlane.Spec.Cluster.ControlPlaneName}
+	}
+	return []string{}
 }
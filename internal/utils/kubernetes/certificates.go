@@ -0,0 +1,200 @@
+package kubernetes
+
+// This file includes utility functions for operating the certificate `Secret`
+// resources issued by the operator.
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kong/gateway-operator/internal/consts"
+)
+
+// IsCertificateSecret returns true if the provided Secret holds a certificate
+// along with the CA certificates it is verified with, as the certificate
+// Secrets issued by the operator do.
+func IsCertificateSecret(secret *corev1.Secret) bool {
+	return secret.Type == corev1.SecretTypeTLS &&
+		len(secret.Data[corev1.TLSCertKey]) > 0 &&
+		len(secret.Data[corev1.ServiceAccountRootCAKey]) > 0
+}
+
+// CertificatesChecksum returns a checksum of the certificates and CA certificates
+// held by the certificate Secrets among the provided ones, which changes whenever
+// any of them is reissued. It returns an empty string when there are none.
+func CertificatesChecksum(secrets []corev1.Secret) string {
+	certificateSecrets := make([]*corev1.Secret, 0, len(secrets))
+	for i := range secrets {
+		if IsCertificateSecret(&secrets[i]) {
+			certificateSecrets = append(certificateSecrets, &secrets[i])
+		}
+	}
+	if len(certificateSecrets) == 0 {
+		return ""
+	}
+	sort.Slice(certificateSecrets, func(i, j int) bool {
+		return certificateSecrets[i].Name < certificateSecrets[j].Name
+	})
+
+	hash := sha256.New()
+	for _, secret := range certificateSecrets {
+		hash.Write([]byte(secret.Name))
+		hash.Write(secret.Data[corev1.TLSCertKey])
+		hash.Write(secret.Data[corev1.ServiceAccountRootCAKey])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ParseCertificates parses the PEM encoded certificates of the provided bundle.
+func ParseCertificates(bundle []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	return certs, nil
+}
+
+// CATrustBundle returns the CA certificates the certificates issued from the
+// provided CA Secret are verified with: its trust bundle, or the CA certificate
+// itself when it has none.
+func CATrustBundle(ca *corev1.Secret) []byte {
+	if bundle := ca.Data[consts.ClusterCATrustBundleKey]; len(bundle) > 0 {
+		return bundle
+	}
+	return ca.Data[corev1.TLSCertKey]
+}
+
+// IntermediateCACertificates returns the PEM encoded certificates among the
+// provided CA certificates which are not self-signed: the CA certificate itself
+// when it is an intermediate CA, followed by the intermediate CA certificates it
+// is issued from. They are sent along with the certificates issued from the CA,
+// while the root CA certificate is trusted by the peers through the trust bundle.
+func IntermediateCACertificates(caCerts []*x509.Certificate) []byte {
+	var chain []byte
+	for _, caCert := range caCerts {
+		if bytes.Equal(caCert.RawIssuer, caCert.RawSubject) && caCert.CheckSignatureFrom(caCert) == nil {
+			continue
+		}
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: caCert.Raw,
+		})...)
+	}
+	return chain
+}
+
+// ParsePrivateKey parses the PEM encoded RSA or ECDSA private key, in the PKCS #1,
+// SEC 1 or PKCS #8 format.
+func ParsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.New("failed parsing the private key: unsupported format")
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T, only RSA and ECDSA keys are supported", key)
+	}
+}
+
+// supportedSignatureAlgorithms are the algorithms the certificates issued by
+// the operator can be signed with.
+var supportedSignatureAlgorithms = []x509.SignatureAlgorithm{
+	x509.SHA256WithRSA,
+	x509.SHA384WithRSA,
+	x509.SHA512WithRSA,
+	x509.ECDSAWithSHA256,
+	x509.ECDSAWithSHA384,
+	x509.ECDSAWithSHA512,
+}
+
+// ParseSignatureAlgorithm returns the signature algorithm named after the
+// x509 package names, e.g. SHA256-RSA or ECDSA-SHA384. An empty name stands for
+// x509.UnknownSignatureAlgorithm, that is for the default algorithm of the key
+// the certificates are signed with.
+func ParseSignatureAlgorithm(name string) (x509.SignatureAlgorithm, error) {
+	if name == "" {
+		return x509.UnknownSignatureAlgorithm, nil
+	}
+	names := make([]string, 0, len(supportedSignatureAlgorithms))
+	for _, algorithm := range supportedSignatureAlgorithms {
+		if strings.EqualFold(algorithm.String(), name) {
+			return algorithm, nil
+		}
+		names = append(names, algorithm.String())
+	}
+	return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported signature algorithm %s, supported algorithms are %s",
+		name, strings.Join(names, ", "))
+}
+
+// SignatureAlgorithmForKey returns the algorithm the certificates signed with the
+// private key of the provided public key are signed with: the requested one,
+// which has to be compatible with the key, or the default one of the key when
+// the requested one is x509.UnknownSignatureAlgorithm.
+func SignatureAlgorithmForKey(pub crypto.PublicKey, requested x509.SignatureAlgorithm) (x509.SignatureAlgorithm, error) {
+	var (
+		keyType          string
+		defaultAlgorithm x509.SignatureAlgorithm
+		compatible       []x509.SignatureAlgorithm
+	)
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		keyType = "RSA"
+		defaultAlgorithm = x509.SHA256WithRSA
+		compatible = []x509.SignatureAlgorithm{x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA}
+	case *ecdsa.PublicKey:
+		keyType = "ECDSA"
+		defaultAlgorithm = x509.ECDSAWithSHA256
+		compatible = []x509.SignatureAlgorithm{x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512}
+	default:
+		return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported public key type %T, only RSA and ECDSA keys are supported", pub)
+	}
+	if requested == x509.UnknownSignatureAlgorithm {
+		return defaultAlgorithm, nil
+	}
+	for _, algorithm := range compatible {
+		if algorithm == requested {
+			return requested, nil
+		}
+	}
+	return x509.UnknownSignatureAlgorithm, fmt.Errorf("signature algorithm %s cannot be used with an %s key", requested, keyType)
+}
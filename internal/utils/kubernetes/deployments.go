@@ -0,0 +1,25 @@
+package kubernetes
+
+// This file includes utility functions for operating `Deployment`
+// resources in kubernetes.
+import (
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// DeploymentReplicas returns the number of replicas of the provided Deployment.
+func DeploymentReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas == nil {
+		return 1
+	}
+	return *deployment.Spec.Replicas
+}
+
+// IsDeploymentRolledOut returns true when all the replicas of the provided
+// Deployment run its current pod template and are available.
+func IsDeploymentRolledOut(deployment *appsv1.Deployment) bool {
+	replicas := DeploymentReplicas(deployment)
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.Replicas == replicas &&
+		deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.AvailableReplicas == replicas
+}
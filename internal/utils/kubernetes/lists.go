@@ -4,7 +4,12 @@ import (
 	"context"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -78,6 +83,142 @@ func ListServicesForOwner(
 	return services, nil
 }
 
+// ListEndpointSlicesForOwner is a helper function to map a list of EndpointSlices
+// by list options and reduce by OwnerReference UID and namespace to efficiently
+// list only the objects owned by the provided UID.
+func ListEndpointSlicesForOwner(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	uid types.UID,
+	listOpts ...client.ListOption,
+) ([]discoveryv1.EndpointSlice, error) {
+	endpointSliceList := &discoveryv1.EndpointSliceList{}
+
+	err := c.List(
+		ctx,
+		endpointSliceList,
+		append(
+			[]client.ListOption{client.InNamespace(namespace)},
+			listOpts...,
+		)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	endpointSlices := make([]discoveryv1.EndpointSlice, 0)
+	for _, endpointSlice := range endpointSliceList.Items {
+		if IsOwnedByRefUID(&endpointSlice.ObjectMeta, uid) {
+			endpointSlices = append(endpointSlices, endpointSlice)
+		}
+	}
+
+	return endpointSlices, nil
+}
+
+// ListHPAsForOwner is a helper function to map a list of HorizontalPodAutoscalers
+// by list options and reduce by OwnerReference UID and namespace to efficiently
+// list only the objects owned by the provided UID.
+func ListHPAsForOwner(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	uid types.UID,
+	listOpts ...client.ListOption,
+) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpaList := &autoscalingv2.HorizontalPodAutoscalerList{}
+
+	err := c.List(
+		ctx,
+		hpaList,
+		append(
+			[]client.ListOption{client.InNamespace(namespace)},
+			listOpts...,
+		)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hpas := make([]autoscalingv2.HorizontalPodAutoscaler, 0)
+	for _, hpa := range hpaList.Items {
+		if IsOwnedByRefUID(&hpa.ObjectMeta, uid) {
+			hpas = append(hpas, hpa)
+		}
+	}
+
+	return hpas, nil
+}
+
+// ListJobsForOwner is a helper function to map a list of Jobs
+// by list options and reduce by OwnerReference UID and namespace to efficiently
+// list only the objects owned by the provided UID.
+func ListJobsForOwner(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	uid types.UID,
+	listOpts ...client.ListOption,
+) ([]batchv1.Job, error) {
+	jobList := &batchv1.JobList{}
+
+	err := c.List(
+		ctx,
+		jobList,
+		append(
+			[]client.ListOption{client.InNamespace(namespace)},
+			listOpts...,
+		)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]batchv1.Job, 0)
+	for _, job := range jobList.Items {
+		if IsOwnedByRefUID(&job.ObjectMeta, uid) {
+			jobs = append(jobs, job)
+		}
+	}
+
+	return jobs, nil
+}
+
+// ListPodDisruptionBudgetsForOwner is a helper function to map a list of PodDisruptionBudgets
+// by list options and reduce by OwnerReference UID and namespace to efficiently
+// list only the objects owned by the provided UID.
+func ListPodDisruptionBudgetsForOwner(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	uid types.UID,
+	listOpts ...client.ListOption,
+) ([]policyv1.PodDisruptionBudget, error) {
+	pdbList := &policyv1.PodDisruptionBudgetList{}
+
+	err := c.List(
+		ctx,
+		pdbList,
+		append(
+			[]client.ListOption{client.InNamespace(namespace)},
+			listOpts...,
+		)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pdbs := make([]policyv1.PodDisruptionBudget, 0)
+	for _, pdb := range pdbList.Items {
+		if IsOwnedByRefUID(&pdb.ObjectMeta, uid) {
+			pdbs = append(pdbs, pdb)
+		}
+	}
+
+	return pdbs, nil
+}
+
 // ListServiceAccountsForOwner is a helper function to map a list of ServiceAccounts
 // by list options and reduce by OwnerReference UID and namespace to efficiently
 // list only the objects owned by the provided UID.
@@ -115,6 +256,80 @@ func ListServiceAccountsForOwner(
 	return serviceAccounts, nil
 }
 
+// ListRolesForOwner is a helper function to map a list of Roles
+// by list options and reduce by OwnerReference UID and namespace to efficiently
+// list only the objects owned by the provided UID.
+func ListRolesForOwner(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	uid types.UID,
+	listOpts ...client.ListOption,
+) ([]rbacv1.Role, error) {
+	roleList := &rbacv1.RoleList{}
+
+	err := c.List(
+		ctx,
+		roleList,
+		append(
+			[]client.ListOption{client.InNamespace(namespace)},
+			listOpts...,
+		)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]rbacv1.Role, 0)
+	for _, role := range roleList.Items {
+		for _, ownerRef := range role.ObjectMeta.OwnerReferences {
+			if ownerRef.UID == uid {
+				roles = append(roles, role)
+				break
+			}
+		}
+	}
+
+	return roles, nil
+}
+
+// ListRoleBindingsForOwner is a helper function to map a list of RoleBindings
+// by list options and reduce by OwnerReference UID and namespace to efficiently
+// list only the objects owned by the provided UID.
+func ListRoleBindingsForOwner(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	uid types.UID,
+	listOpts ...client.ListOption,
+) ([]rbacv1.RoleBinding, error) {
+	roleBindingList := &rbacv1.RoleBindingList{}
+
+	err := c.List(
+		ctx,
+		roleBindingList,
+		append(
+			[]client.ListOption{client.InNamespace(namespace)},
+			listOpts...,
+		)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	roleBindings := make([]rbacv1.RoleBinding, 0)
+	for _, roleBinding := range roleBindingList.Items {
+		for _, ownerRef := range roleBinding.ObjectMeta.OwnerReferences {
+			if ownerRef.UID == uid {
+				roleBindings = append(roleBindings, roleBinding)
+				break
+			}
+		}
+	}
+
+	return roleBindings, nil
+}
+
 // ListClusterRolesForOwner is a helper function to map a list of ClusterRoles
 // by list options and reduce by OwnerReference UID to efficiently
 // list only the objects owned by the provided UID.
@@ -181,6 +396,39 @@ func ListClusterRoleBindingsForOwner(
 	return clusterRoleBindings, nil
 }
 
+// ListIngressClassesForOwner is a helper function to map a list of IngressClasses
+// by list options and reduce by OwnerReference UID to efficiently
+// list only the objects owned by the provided UID.
+func ListIngressClassesForOwner(
+	ctx context.Context,
+	c client.Client,
+	uid types.UID,
+	listOpts ...client.ListOption,
+) ([]networkingv1.IngressClass, error) {
+	ingressClassList := &networkingv1.IngressClassList{}
+
+	err := c.List(
+		ctx,
+		ingressClassList,
+		listOpts...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ingressClasses := make([]networkingv1.IngressClass, 0)
+	for _, ingressClass := range ingressClassList.Items {
+		for _, ownerRef := range ingressClass.ObjectMeta.OwnerReferences {
+			if ownerRef.UID == uid {
+				ingressClasses = append(ingressClasses, ingressClass)
+				break
+			}
+		}
+	}
+
+	return ingressClasses, nil
+}
+
 // ListSecretsForOwner is a helper function to map a list of Secrets
 // by list options and reduce by OwnerReference UID to efficiently
 // list only the objects owned by the provided UID.
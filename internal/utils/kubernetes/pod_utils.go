@@ -3,7 +3,15 @@ package kubernetes
 // This file includes utility functions for operating `Pod`
 // resources in kubernetes.
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // GetPodContainerByName takes a PodSpec reference and a string and returns a reference to the container in the PodSpec
@@ -39,3 +47,135 @@ func GetContainerVolumeMountByMountPath(container *corev1.Container, mountPath s
 
 	return nil
 }
+
+// PodFailure describes why the pods of a Deployment are not available.
+type PodFailure struct {
+	// Reason is the reason of the failure, one of the pod failure reasons.
+	Reason ConditionReason
+	// Message details the failure and names the affected pods.
+	Message string
+	// Pods are the names of the affected pods.
+	Pods []string
+}
+
+// podFailureReasons are the reasons pods can fail with, from the most specific
+// to the least specific one.
+var podFailureReasons = []ConditionReason{
+	ImagePullBackOffReason,
+	OOMKilledReason,
+	CrashLoopBackOffReason,
+	UnschedulableReason,
+}
+
+// DiagnoseDeploymentPods inspects the pods of the provided Deployment and its
+// Progressing condition to find out why the Deployment is not available.
+// The most specific failure found is returned, along with the pods affected by
+// it. It returns false when no failure is found, e.g. when the pods are still
+// starting.
+func DiagnoseDeploymentPods(ctx context.Context, cl client.Client, deployment *appsv1.Deployment) (PodFailure, bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return PodFailure{}, false, err
+	}
+
+	var pods corev1.PodList
+	if err := cl.List(ctx, &pods,
+		client.InNamespace(deployment.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return PodFailure{}, false, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+
+	failures := make(map[ConditionReason]*PodFailure)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		reason, message, failed := diagnosePod(pod)
+		if !failed {
+			continue
+		}
+		failure, ok := failures[reason]
+		if !ok {
+			failure = &PodFailure{Reason: reason, Message: message}
+			failures[reason] = failure
+		}
+		failure.Pods = append(failure.Pods, pod.Name)
+	}
+	for _, reason := range podFailureReasons {
+		if failure, ok := failures[reason]; ok {
+			failure.Message = fmt.Sprintf("%s (pods: %s)", failure.Message, strings.Join(failure.Pods, ", "))
+			return *failure, true, nil
+		}
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing &&
+			condition.Status == corev1.ConditionFalse &&
+			condition.Reason == string(ProgressDeadlineExceededReason) {
+			return PodFailure{
+				Reason:  ProgressDeadlineExceededReason,
+				Message: fmt.Sprintf("Deployment %s has not progressed: %s", deployment.Name, condition.Message),
+			}, true, nil
+		}
+	}
+
+	return PodFailure{}, false, nil
+}
+
+// diagnosePod returns the most specific failure of the provided pod, if any.
+// The messages only carry the details which do not change from one attempt
+// to run the pod to another, so that they can be reported as they are.
+func diagnosePod(pod *corev1.Pod) (ConditionReason, string, bool) {
+	var (
+		reason  ConditionReason
+		message string
+		rank    = len(podFailureReasons)
+	)
+	report := func(r ConditionReason, m string) {
+		for i, knownReason := range podFailureReasons {
+			if knownReason == r && i < rank {
+				reason, message, rank = r, m, i
+			}
+		}
+	}
+
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			switch waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+				report(ImagePullBackOffReason, fmt.Sprintf("container %s cannot pull image %s", status.Name, status.Image))
+			case "CrashLoopBackOff":
+				if terminated := status.LastTerminationState.Terminated; terminated != nil {
+					if terminated.Reason == string(OOMKilledReason) {
+						report(OOMKilledReason, fmt.Sprintf("container %s has been killed for running out of memory", status.Name))
+					} else {
+						report(CrashLoopBackOffReason, fmt.Sprintf("container %s keeps crashing, last exit code %d", status.Name, terminated.ExitCode))
+					}
+				} else {
+					report(CrashLoopBackOffReason, fmt.Sprintf("container %s keeps crashing", status.Name))
+				}
+			}
+		}
+		if terminated := status.State.Terminated; terminated != nil && terminated.Reason == string(OOMKilledReason) {
+			report(OOMKilledReason, fmt.Sprintf("container %s has been killed for running out of memory", status.Name))
+		}
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled &&
+			condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			report(UnschedulableReason, fmt.Sprintf("pod cannot be scheduled: %s", condition.Message))
+		}
+	}
+
+	return reason, message, rank < len(podFailureReasons)
+}
@@ -0,0 +1,188 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDiagnoseDeploymentPods(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dataplane-kong",
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "kong"},
+			},
+		},
+	}
+	newPod := func(name string, status corev1.PodStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"app": "kong"},
+			},
+			Status: status,
+		}
+	}
+	waitingContainer := func(reason string) corev1.PodStatus {
+		return corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "proxy",
+					Image: "kong:3.4",
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: reason},
+					},
+				},
+			},
+		}
+	}
+	crashingContainer := func(lastReason string, exitCode int32) corev1.PodStatus {
+		status := waitingContainer("CrashLoopBackOff")
+		status.ContainerStatuses[0].LastTerminationState = corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{Reason: lastReason, ExitCode: exitCode},
+		}
+		return status
+	}
+	unschedulable := corev1.PodStatus{
+		Conditions: []corev1.PodCondition{
+			{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 3 Insufficient cpu.",
+			},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		pods            []*corev1.Pod
+		conditions      []appsv1.DeploymentCondition
+		expectedFailure PodFailure
+		expectedFailed  bool
+	}{
+		{
+			name: "starting pods are not reported",
+			pods: []*corev1.Pod{
+				newPod("kong-a", waitingContainer("ContainerCreating")),
+			},
+		},
+		{
+			name: "image pull failures",
+			pods: []*corev1.Pod{
+				newPod("kong-b", waitingContainer("ErrImagePull")),
+				newPod("kong-a", waitingContainer("ImagePullBackOff")),
+			},
+			expectedFailure: PodFailure{
+				Reason:  ImagePullBackOffReason,
+				Message: "container proxy cannot pull image kong:3.4 (pods: kong-a, kong-b)",
+				Pods:    []string{"kong-a", "kong-b"},
+			},
+			expectedFailed: true,
+		},
+		{
+			name: "containers running out of memory",
+			pods: []*corev1.Pod{
+				newPod("kong-a", crashingContainer("OOMKilled", 137)),
+			},
+			expectedFailure: PodFailure{
+				Reason:  OOMKilledReason,
+				Message: "container proxy has been killed for running out of memory (pods: kong-a)",
+				Pods:    []string{"kong-a"},
+			},
+			expectedFailed: true,
+		},
+		{
+			name: "crash looping containers",
+			pods: []*corev1.Pod{
+				newPod("kong-a", crashingContainer("Error", 1)),
+			},
+			expectedFailure: PodFailure{
+				Reason:  CrashLoopBackOffReason,
+				Message: "container proxy keeps crashing, last exit code 1 (pods: kong-a)",
+				Pods:    []string{"kong-a"},
+			},
+			expectedFailed: true,
+		},
+		{
+			name: "the most specific failure is reported",
+			pods: []*corev1.Pod{
+				newPod("kong-a", unschedulable),
+				newPod("kong-b", crashingContainer("Error", 1)),
+				newPod("kong-c", waitingContainer("ImagePullBackOff")),
+			},
+			expectedFailure: PodFailure{
+				Reason:  ImagePullBackOffReason,
+				Message: "container proxy cannot pull image kong:3.4 (pods: kong-c)",
+				Pods:    []string{"kong-c"},
+			},
+			expectedFailed: true,
+		},
+		{
+			name: "unschedulable pods",
+			pods: []*corev1.Pod{
+				newPod("kong-a", unschedulable),
+			},
+			expectedFailure: PodFailure{
+				Reason:  UnschedulableReason,
+				Message: "pod cannot be scheduled: 0/3 nodes are available: 3 Insufficient cpu. (pods: kong-a)",
+				Pods:    []string{"kong-a"},
+			},
+			expectedFailed: true,
+		},
+		{
+			name: "deployment exceeding its progress deadline",
+			conditions: []appsv1.DeploymentCondition{
+				{
+					Type:    appsv1.DeploymentProgressing,
+					Status:  corev1.ConditionFalse,
+					Reason:  "ProgressDeadlineExceeded",
+					Message: `ReplicaSet "dataplane-kong-1" has timed out progressing.`,
+				},
+			},
+			expectedFailure: PodFailure{
+				Reason:  ProgressDeadlineExceededReason,
+				Message: `Deployment dataplane-kong has not progressed: ReplicaSet "dataplane-kong-1" has timed out progressing.`,
+			},
+			expectedFailed: true,
+		},
+		{
+			name: "pods of other deployments are ignored",
+			pods: []*corev1.Pod{
+				func() *corev1.Pod {
+					pod := newPod("other", waitingContainer("ImagePullBackOff"))
+					pod.Labels["app"] = "other"
+					return pod
+				}(),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			deployment := deployment.DeepCopy()
+			deployment.Status.Conditions = tc.conditions
+			builder := fakectrlruntimeclient.NewClientBuilder().WithScheme(scheme.Scheme)
+			for _, pod := range tc.pods {
+				builder.WithObjects(pod)
+			}
+
+			failure, failed, err := DiagnoseDeploymentPods(context.Background(), builder.Build(), deployment)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedFailed, failed)
+			require.Equal(t, tc.expectedFailure, failure)
+		})
+	}
+}
@@ -2,9 +2,12 @@ package reduce
 
 import (
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 )
 
@@ -100,6 +103,52 @@ func filterClusterRoleBindings(clusterRoleBindings []rbacv1.ClusterRoleBinding)
 	return append(clusterRoleBindings[:toFilter], clusterRoleBindings[toFilter+1:]...)
 }
 
+// -----------------------------------------------------------------------------
+// Filter functions - Roles
+// -----------------------------------------------------------------------------
+
+// filterRoles filters out the Role to be kept and returns all the Roles to be
+// deleted.
+// The filtered-out Role is decided as follows:
+// 1. creationTimestamp (older is better)
+func filterRoles(roles []rbacv1.Role) []rbacv1.Role {
+	if len(roles) < 2 {
+		return []rbacv1.Role{}
+	}
+
+	toFilter := 0
+	for i, role := range roles {
+		if role.CreationTimestamp.Before(&roles[toFilter].CreationTimestamp) {
+			toFilter = i
+		}
+	}
+
+	return append(roles[:toFilter], roles[toFilter+1:]...)
+}
+
+// -----------------------------------------------------------------------------
+// Filter functions - RoleBindings
+// -----------------------------------------------------------------------------
+
+// filterRoleBindings filters out the RoleBinding to be kept and returns all
+// the RoleBindings to be deleted.
+// The filtered-out RoleBinding is decided as follows:
+// 1. creationTimestamp (older is better)
+func filterRoleBindings(roleBindings []rbacv1.RoleBinding) []rbacv1.RoleBinding {
+	if len(roleBindings) < 2 {
+		return []rbacv1.RoleBinding{}
+	}
+
+	toFilter := 0
+	for i, roleBinding := range roleBindings {
+		if roleBinding.CreationTimestamp.Before(&roleBindings[toFilter].CreationTimestamp) {
+			toFilter = i
+		}
+	}
+
+	return append(roleBindings[:toFilter], roleBindings[toFilter+1:]...)
+}
+
 // -----------------------------------------------------------------------------
 // Filter functions - Deployments
 // -----------------------------------------------------------------------------
@@ -227,3 +276,68 @@ func filterNetworkPolicies(networkPolicies []networkingv1.NetworkPolicy) []netwo
 
 	return append(networkPolicies[:best], networkPolicies[best+1:]...)
 }
+
+// -----------------------------------------------------------------------------
+// Filter functions - HorizontalPodAutoscalers
+// -----------------------------------------------------------------------------
+
+// filterHPAs filters out the HorizontalPodAutoscaler to be kept and returns
+// all the HorizontalPodAutoscalers to be deleted.
+// The filtered-out HorizontalPodAutoscaler is decided as follows:
+// 1. creationTimestamp (older is better)
+func filterHPAs(hpas []autoscalingv2.HorizontalPodAutoscaler) []autoscalingv2.HorizontalPodAutoscaler {
+	if len(hpas) < 2 {
+		return []autoscalingv2.HorizontalPodAutoscaler{}
+	}
+
+	best := 0
+	for i, hpa := range hpas {
+		if hpa.CreationTimestamp.Before(&hpas[best].CreationTimestamp) {
+			best = i
+		}
+	}
+
+	return append(hpas[:best], hpas[best+1:]...)
+}
+
+// -----------------------------------------------------------------------------
+// Filter functions - PodDisruptionBudgets
+// -----------------------------------------------------------------------------
+
+// filterPodDisruptionBudgets filters out the PodDisruptionBudget to be kept and returns
+// all the PodDisruptionBudgets to be deleted.
+// The filtered-out PodDisruptionBudget is decided as follows:
+// 1. creationTimestamp (older is better)
+func filterPodDisruptionBudgets(pdbs []policyv1.PodDisruptionBudget) []policyv1.PodDisruptionBudget {
+	if len(pdbs) < 2 {
+		return []policyv1.PodDisruptionBudget{}
+	}
+
+	best := 0
+	for i, pdb := range pdbs {
+		if pdb.CreationTimestamp.Before(&pdbs[best].CreationTimestamp) {
+			best = i
+		}
+	}
+
+	return append(pdbs[:best], pdbs[best+1:]...)
+}
+
+// filterJobs filters out the Job to be kept and returns all the Jobs
+// to be deleted.
+// The filtered-out Job is decided as follows:
+// 1. creationTimestamp (newer is better, as it runs the latest configuration)
+func filterJobs(jobs []batchv1.Job) []batchv1.Job {
+	if len(jobs) < 2 {
+		return []batchv1.Job{}
+	}
+
+	best := 0
+	for i, job := range jobs {
+		if jobs[best].CreationTimestamp.Before(&job.CreationTimestamp) {
+			best = i
+		}
+	}
+
+	return append(jobs[:best], jobs[best+1:]...)
+}
@@ -6,8 +6,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 )
@@ -367,3 +370,85 @@ func TestFilterServices(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterHPAs(t *testing.T) {
+	testCases := []struct {
+		name         string
+		hpas         []autoscalingv2.HorizontalPodAutoscaler
+		filteredHPAs []autoscalingv2.HorizontalPodAutoscaler
+	}{
+		{
+			name: "the older hpa must be filtered out",
+			hpas: []autoscalingv2.HorizontalPodAutoscaler{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "1/1/2000",
+						CreationTimestamp: metav1.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "6/30/1990",
+						CreationTimestamp: metav1.Date(1990, time.June, 30, 0, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+			filteredHPAs: []autoscalingv2.HorizontalPodAutoscaler{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "1/1/2000",
+						CreationTimestamp: metav1.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			filteredHPAs := filterHPAs(tc.hpas)
+			require.Equal(t, filteredHPAs, tc.filteredHPAs)
+		})
+	}
+}
+
+func TestFilterPodDisruptionBudgets(t *testing.T) {
+	pdbs := []policyv1.PodDisruptionBudget{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "1/1/2000",
+				CreationTimestamp: metav1.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "6/30/1990",
+				CreationTimestamp: metav1.Date(1990, time.June, 30, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	filtered := filterPodDisruptionBudgets(pdbs)
+	require.Len(t, filtered, 1)
+	require.Equal(t, "1/1/2000", filtered[0].Name, "the older PodDisruptionBudget must be kept")
+}
+
+func TestFilterJobs(t *testing.T) {
+	jobs := []batchv1.Job{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "1/1/2000",
+				CreationTimestamp: metav1.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "6/30/1990",
+				CreationTimestamp: metav1.Date(1990, time.June, 30, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	filtered := filterJobs(jobs)
+	require.Len(t, filtered, 1)
+	require.Equal(t, "6/30/1990", filtered[0].Name, "the newer Job must be kept")
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -59,6 +63,30 @@ func ReduceClusterRoleBindings(ctx context.Context, k8sClient client.Client, clu
 	return nil
 }
 
+// ReduceRoles detects the best Role in the set and deletes all the others.
+func ReduceRoles(ctx context.Context, k8sClient client.Client, roles []rbacv1.Role) error {
+	filteredRoles := filterRoles(roles)
+	for _, role := range filteredRoles {
+		role := role
+		if err := k8sClient.Delete(ctx, &role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReduceRoleBindings detects the best RoleBinding in the set and deletes all the others.
+func ReduceRoleBindings(ctx context.Context, k8sClient client.Client, roleBindings []rbacv1.RoleBinding) error {
+	filteredRoleBindings := filterRoleBindings(roleBindings)
+	for _, roleBinding := range filteredRoleBindings {
+		roleBinding := roleBinding
+		if err := k8sClient.Delete(ctx, &roleBinding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ReduceDeployments detects the best Deployment in the set and deletes all the others.
 func ReduceDeployments(ctx context.Context, k8sClient client.Client, deployments []appsv1.Deployment) error {
 	filteredDeployments := filterDeployments(deployments)
@@ -107,3 +135,40 @@ func ReduceNetworkPolicies(ctx context.Context, k8sClient client.Client, network
 	}
 	return nil
 }
+
+// ReduceHPAs detects the best HorizontalPodAutoscaler in the set and deletes all the others.
+func ReduceHPAs(ctx context.Context, k8sClient client.Client, hpas []autoscalingv2.HorizontalPodAutoscaler) error {
+	filteredHPAs := filterHPAs(hpas)
+	for _, hpa := range filteredHPAs {
+		hpa := hpa
+		if err := k8sClient.Delete(ctx, &hpa); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReducePodDisruptionBudgets detects the best PodDisruptionBudget in the set and deletes all the others.
+func ReducePodDisruptionBudgets(ctx context.Context, k8sClient client.Client, pdbs []policyv1.PodDisruptionBudget) error {
+	filteredPDBs := filterPodDisruptionBudgets(pdbs)
+	for _, pdb := range filteredPDBs {
+		pdb := pdb
+		if err := k8sClient.Delete(ctx, &pdb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReduceJobs detects the best Job in the set and deletes all the others,
+// along with their pods.
+func ReduceJobs(ctx context.Context, k8sClient client.Client, jobs []batchv1.Job) error {
+	filteredJobs := filterJobs(jobs)
+	for _, job := range filteredJobs {
+		job := job
+		if err := k8sClient.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
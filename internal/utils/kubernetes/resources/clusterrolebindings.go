@@ -37,28 +37,3 @@ func GenerateNewClusterRoleBindingForControlPlane(namespace, controlplaneName, s
 		},
 	}
 }
-
-// GenerateNewClusterRoleBindingForCertificateConfig is a helper to generate a ClusterRoleBinding
-// to be used by the certificateConfig jobs
-func GenerateNewClusterRoleBindingForCertificateConfig(namespace, name, labelValue string) *rbacv1.ClusterRoleBinding {
-	return &rbacv1.ClusterRoleBinding{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
-			Labels: map[string]string{
-				"app": labelValue,
-			},
-		},
-		RoleRef: rbacv1.RoleRef{
-			APIGroup: "rbac.authorization.k8s.io",
-			Kind:     "ClusterRole",
-			Name:     name,
-		},
-		Subjects: []rbacv1.Subject{
-			{
-				Kind:      "ServiceAccount",
-				Name:      name,
-				Namespace: namespace,
-			},
-		},
-	}
-}
@@ -2,35 +2,58 @@ package resources
 
 import (
 	rbacv1 "k8s.io/api/rbac/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // -----------------------------------------------------------------------------
 // ClusterRole generator helpers
 // -----------------------------------------------------------------------------
 
-// GenerateNewClusterRoleForCertificateConfig is a helper to generate a ClusterRole
-// to be used by the certificateConfig jobs
-func GenerateNewClusterRoleForCertificateConfig(namespace, name, labelValue string) *rbacv1.ClusterRole {
-	return &rbacv1.ClusterRole{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
-			Labels: map[string]string{
-				"app": labelValue,
-			},
-		},
-		Rules: []rbacv1.PolicyRule{
-			{
-				APIGroups: []string{
-					"admissionregistration.k8s.io",
-				},
-				Resources: []string{
-					"validatingwebhookconfigurations",
-				},
-				Verbs: []string{
-					"get", "update",
-				},
-			},
-		},
+// controlPlaneClusterScopedResources are the cluster scoped resources found in
+// the ClusterRoles generated for the controlplanes.
+var controlPlaneClusterScopedResources = map[schema.GroupResource]struct{}{
+	{Group: "", Resource: "nodes"}:                                             {},
+	{Group: "networking.k8s.io", Resource: "ingressclasses"}:                   {},
+	{Group: "gateway.networking.k8s.io", Resource: "gatewayclasses"}:           {},
+	{Group: "gateway.networking.k8s.io", Resource: "gatewayclasses/status"}:    {},
+	{Group: "configuration.konghq.com", Resource: "kongclusterplugins"}:        {},
+	{Group: "configuration.konghq.com", Resource: "kongclusterplugins/status"}: {},
+}
+
+// SplitControlPlaneClusterRoleRules splits the rules of a ClusterRole generated
+// for a controlplane between the rules granting access to cluster scoped
+// resources and the rules granting access to namespaced resources, which can
+// be granted per namespace by Roles.
+func SplitControlPlaneClusterRoleRules(rules []rbacv1.PolicyRule) (clusterScoped, namespaced []rbacv1.PolicyRule) {
+	for _, rule := range rules {
+		var clusterScopedResources, namespacedResources []string
+		for _, resource := range rule.Resources {
+			if isControlPlaneClusterScopedResource(rule.APIGroups, resource) {
+				clusterScopedResources = append(clusterScopedResources, resource)
+			} else {
+				namespacedResources = append(namespacedResources, resource)
+			}
+		}
+
+		if len(clusterScopedResources) > 0 {
+			clusterScopedRule := *rule.DeepCopy()
+			clusterScopedRule.Resources = clusterScopedResources
+			clusterScoped = append(clusterScoped, clusterScopedRule)
+		}
+		if len(namespacedResources) > 0 {
+			namespacedRule := *rule.DeepCopy()
+			namespacedRule.Resources = namespacedResources
+			namespaced = append(namespaced, namespacedRule)
+		}
+	}
+	return clusterScoped, namespaced
+}
+
+func isControlPlaneClusterScopedResource(apiGroups []string, resource string) bool {
+	for _, group := range apiGroups {
+		if _, ok := controlPlaneClusterScopedResources[schema.GroupResource{Group: group, Resource: resource}]; ok {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,92 @@
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	"github.com/kong/gateway-operator/internal/versions"
+)
+
+// DefaultControlPlaneAdmissionWebhookPort is the port the admission webhook of
+// the controller listens on when it is not set in the ControlPlane options.
+const DefaultControlPlaneAdmissionWebhookPort = 8080
+
+// GenerateControllerOptionsEnv renders the typed controller options of the
+// ControlPlane into the environment of its controller container.
+// The feature gates and options which are not supported by the version of the
+// given controller image are left out.
+func GenerateControllerOptionsEnv(controlplane *operatorv1alpha1.ControlPlane, controlplaneImage string) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	if controlplane.Spec.IngressClass != nil {
+		env = append(env, corev1.EnvVar{Name: "CONTROLLER_INGRESS_CLASS", Value: *controlplane.Spec.IngressClass})
+	}
+
+	opts := controlplane.Spec.Controller
+	if opts == nil {
+		return env
+	}
+
+	featureGates := make([]string, 0, len(opts.FeatureGates))
+	for gate, enabled := range opts.FeatureGates {
+		if versions.IsControllerFeatureGateSupported(controlplaneImage, gate) {
+			featureGates = append(featureGates, fmt.Sprintf("%s=%t", gate, enabled))
+		}
+	}
+	if len(featureGates) > 0 {
+		sort.Strings(featureGates)
+		env = append(env, corev1.EnvVar{Name: "CONTROLLER_FEATURE_GATES", Value: strings.Join(featureGates, ",")})
+	}
+
+	if opts.SyncPeriod != nil {
+		env = append(env, corev1.EnvVar{Name: "CONTROLLER_SYNC_PERIOD", Value: opts.SyncPeriod.Duration.String()})
+	}
+	if opts.ProxySyncPeriod != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  "CONTROLLER_PROXY_SYNC_SECONDS",
+			Value: strconv.FormatFloat(opts.ProxySyncPeriod.Seconds(), 'f', -1, 64),
+		})
+	}
+
+	if opts.Konnect != nil && versions.IsControllerOptionSupported(controlplaneImage, "konnect") {
+		env = append(env,
+			corev1.EnvVar{Name: "CONTROLLER_KONNECT_SYNC_ENABLED", Value: "true"},
+			corev1.EnvVar{Name: "CONTROLLER_KONNECT_RUNTIME_GROUP_ID", Value: opts.Konnect.RuntimeGroupID},
+		)
+		if opts.Konnect.Address != "" {
+			env = append(env, corev1.EnvVar{Name: "CONTROLLER_KONNECT_ADDRESS", Value: opts.Konnect.Address})
+		}
+		env = append(env,
+			secretKeyEnvVar("CONTROLLER_KONNECT_TLS_CLIENT_CERT", opts.Konnect.TLSClientCertificateSecretName, corev1.TLSCertKey, false),
+			secretKeyEnvVar("CONTROLLER_KONNECT_TLS_CLIENT_KEY", opts.Konnect.TLSClientCertificateSecretName, corev1.TLSPrivateKeyKey, false),
+		)
+	}
+
+	if opts.Metrics != nil {
+		switch {
+		case opts.Metrics.Enabled != nil && !*opts.Metrics.Enabled:
+			// the metrics endpoint is not served on the "0" address.
+			env = append(env, corev1.EnvVar{Name: "CONTROLLER_METRICS_BIND_ADDRESS", Value: "0"})
+		case opts.Metrics.Port != 0:
+			env = append(env, corev1.EnvVar{Name: "CONTROLLER_METRICS_BIND_ADDRESS", Value: fmt.Sprintf(":%d", opts.Metrics.Port)})
+		}
+	}
+
+	if opts.AdmissionWebhook != nil {
+		port := opts.AdmissionWebhook.Port
+		if port == 0 {
+			port = DefaultControlPlaneAdmissionWebhookPort
+		}
+		env = append(env,
+			corev1.EnvVar{Name: "CONTROLLER_ADMISSION_WEBHOOK_LISTEN", Value: fmt.Sprintf(":%d", port)},
+			secretKeyEnvVar("CONTROLLER_ADMISSION_WEBHOOK_CERT", opts.AdmissionWebhook.CertificateSecretName, corev1.TLSCertKey, false),
+			secretKeyEnvVar("CONTROLLER_ADMISSION_WEBHOOK_KEY", opts.AdmissionWebhook.CertificateSecretName, corev1.TLSPrivateKeyKey, false),
+		)
+	}
+
+	return env
+}
@@ -0,0 +1,66 @@
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+)
+
+// -----------------------------------------------------------------------------
+// Database env vars generators
+// -----------------------------------------------------------------------------
+
+// GenerateDataPlaneDatabaseEnvVars generates the environment variables which
+// configure the Kong proxy to use the DataPlane's database. The connection
+// details are read from the keys of the Secret referenced in the DataPlane's
+// database options. No environment variables are generated for DB-less DataPlanes.
+func GenerateDataPlaneDatabaseEnvVars(dataplane *operatorv1beta1.DataPlane) []corev1.EnvVar {
+	if dataplane.Spec.Database == nil || dataplane.Spec.Database.Postgres == nil {
+		return nil
+	}
+	secretName := dataplane.Spec.Database.Postgres.SecretName
+
+	return []corev1.EnvVar{
+		{
+			Name:  consts.EnvVarKongDatabase,
+			Value: consts.DataPlaneDatabaseModePostgres,
+		},
+		secretKeyEnvVar(consts.EnvVarKongPGHost, secretName, consts.PostgresSecretHostKey, false),
+		secretKeyEnvVar(consts.EnvVarKongPGPort, secretName, consts.PostgresSecretPortKey, true),
+		secretKeyEnvVar(consts.EnvVarKongPGDatabase, secretName, consts.PostgresSecretDatabaseKey, true),
+		secretKeyEnvVar(consts.EnvVarKongPGUser, secretName, consts.PostgresSecretUserKey, false),
+		secretKeyEnvVar(consts.EnvVarKongPGPassword, secretName, consts.PostgresSecretPasswordKey, false),
+	}
+}
+
+func secretKeyEnvVar(name, secretName, key string, optional bool) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+				Optional:             pointer.Bool(optional),
+			},
+		},
+	}
+}
+
+// setContainerEnvVars sets the provided environment variables on the container,
+// overriding the ones with the same names.
+func setContainerEnvVars(container *corev1.Container, envVars []corev1.EnvVar) {
+	for _, envVar := range envVars {
+		found := false
+		for i := range container.Env {
+			if container.Env[i].Name == envVar.Name {
+				container.Env[i] = envVar
+				found = true
+			}
+		}
+		if !found {
+			container.Env = append(container.Env, envVar)
+		}
+	}
+}
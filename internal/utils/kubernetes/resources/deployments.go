@@ -2,6 +2,7 @@ package resources
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -13,6 +14,7 @@ import (
 	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
 	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
 )
 
 const (
@@ -55,6 +57,8 @@ func GenerateNewDeploymentForControlPlane(controlplane *operatorv1alpha1.Control
 					CreationTimestamp: metav1.Time{},
 					Labels: map[string]string{
 						"app": controlplane.Name,
+						// the operator only caches the pods carrying this label.
+						consts.GatewayOperatorControlledLabel: consts.ControlPlaneManagedLabelValue,
 					},
 				},
 				Spec: corev1.PodSpec{
@@ -98,6 +102,29 @@ func GenerateNewDeploymentForControlPlane(controlplane *operatorv1alpha1.Control
 		},
 	}
 
+	// The replicas of the controlplane elect a leader through a lease which is
+	// specific to the controlplane, so that several of them can share a namespace.
+	deployment.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+		{
+			Name:  "CONTROLLER_ELECTION_ID",
+			Value: ControlPlaneElectionID(controlplane.Name),
+		},
+		{
+			Name:  "CONTROLLER_ELECTION_NAMESPACE",
+			Value: controlplane.Namespace,
+		},
+	}
+	// The controlplane is only granted access to the namespaces it watches
+	// when they are restricted.
+	if len(controlplane.Spec.WatchNamespaces) > 0 {
+		deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+			Name:  "CONTROLLER_WATCH_NAMESPACE",
+			Value: strings.Join(controlplane.Spec.WatchNamespaces, ","),
+		})
+	}
+	deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env,
+		GenerateControllerOptionsEnv(controlplane, controlplaneImage)...)
+
 	if controlplane.Spec.Deployment.PodTemplateSpec != nil {
 		patchedPodTemplateSpec, err := StrategicMergePatchPodTemplateSpec(&deployment.Spec.Template, controlplane.Spec.Deployment.PodTemplateSpec)
 		if err != nil {
@@ -109,6 +136,13 @@ func GenerateNewDeploymentForControlPlane(controlplane *operatorv1alpha1.Control
 	return deployment, nil
 }
 
+// ControlPlaneElectionID returns the ID of the leader election run by the
+// replicas of the controlplane with the provided name, which is the name of the
+// Lease they compete for.
+func ControlPlaneElectionID(controlplaneName string) string {
+	return fmt.Sprintf("%s-%s.konghq.com", consts.ControlPlanePrefix, controlplaneName)
+}
+
 func GenerateControlPlaneContainer(image string) corev1.Container {
 	return corev1.Container{
 		Name:                     consts.ControlPlaneControllerContainerName,
@@ -120,7 +154,7 @@ func GenerateControlPlaneContainer(image string) corev1.Container {
 			{
 				Name:      ClusterCertificateVolumeName,
 				ReadOnly:  true,
-				MountPath: "/var/cluster-certificate",
+				MountPath: consts.ClusterCertificateVolumeMountPath,
 			},
 		},
 		Ports: []corev1.ContainerPort{
@@ -187,6 +221,15 @@ func GenerateNewDeploymentForDataPlane(dataplane *operatorv1beta1.DataPlane, dat
 				MatchLabels: map[string]string{
 					"app": dataplane.Name,
 				},
+				// the canary pods of a Canary rollout share the "app" label
+				// with the live pods, but they are not managed by the live Deployment.
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      consts.DataPlaneDeploymentStateLabel,
+						Operator: metav1.LabelSelectorOpNotIn,
+						Values:   []string{consts.DataPlaneStateLabelValueCanary},
+					},
+				},
 			},
 			Strategy: appsv1.DeploymentStrategy{
 				Type: appsv1.RollingUpdateDeploymentStrategyType,
@@ -206,6 +249,8 @@ func GenerateNewDeploymentForDataPlane(dataplane *operatorv1beta1.DataPlane, dat
 					CreationTimestamp: metav1.Time{},
 					Labels: map[string]string{
 						"app": dataplane.Name,
+						// the operator only caches the pods carrying this label.
+						consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
 					},
 				},
 				Spec: corev1.PodSpec{
@@ -284,9 +329,91 @@ func GenerateNewDeploymentForDataPlane(dataplane *operatorv1beta1.DataPlane, dat
 		deployment.Spec.Template = *patchedPodTemplateSpec
 	}
 
+	if envVars := GenerateDataPlaneDatabaseEnvVars(dataplane); len(envVars) > 0 {
+		container := k8sutils.GetPodContainerByName(&deployment.Spec.Template.Spec, consts.DataPlaneProxyContainerName)
+		if container != nil {
+			setContainerEnvVars(container, envVars)
+		}
+	}
+
+	return deployment, nil
+}
+
+// GenerateNewPreviewDeploymentForDataPlane generates a new preview Deployment
+// for the DataPlane, which is used during Blue Green rollouts.
+// The preview pods are selected through a dedicated label instead of the "app"
+// label, which ensures they do not receive traffic from the live Services.
+func GenerateNewPreviewDeploymentForDataPlane(dataplane *operatorv1beta1.DataPlane, dataplaneImage, certSecretName string) (*appsv1.Deployment, error) {
+	deployment, err := GenerateNewDeploymentForDataPlane(dataplane, dataplaneImage, certSecretName)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment.GenerateName = fmt.Sprintf("%s-preview-%s-", consts.DataPlanePrefix, dataplane.Name)
+	deployment.Labels[consts.DataPlaneDeploymentStateLabel] = consts.DataPlaneStateLabelValuePreview
+
+	selector := PreviewSelectorForDataPlane(dataplane)
+	deployment.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: selector,
+	}
+	if deployment.Spec.Template.Labels == nil {
+		deployment.Spec.Template.Labels = make(map[string]string)
+	}
+	delete(deployment.Spec.Template.Labels, "app")
+	for k, v := range selector {
+		deployment.Spec.Template.Labels[k] = v
+	}
+
+	return deployment, nil
+}
+
+// GenerateNewCanaryDeploymentForDataPlane generates a new canary Deployment
+// for the DataPlane, which is used during Canary rollouts.
+// The canary pods keep the "app" label of the live pods, so that both receive
+// traffic from the live Services, proportionally to their number of replicas,
+// and are selected through the additional state label, which the selector of
+// the live Deployment excludes.
+func GenerateNewCanaryDeploymentForDataPlane(dataplane *operatorv1beta1.DataPlane, dataplaneImage, certSecretName string, replicas int32) (*appsv1.Deployment, error) {
+	deployment, err := GenerateNewDeploymentForDataPlane(dataplane, dataplaneImage, certSecretName)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment.GenerateName = fmt.Sprintf("%s-canary-%s-", consts.DataPlanePrefix, dataplane.Name)
+	deployment.Labels[consts.DataPlaneDeploymentStateLabel] = consts.DataPlaneStateLabelValueCanary
+	deployment.Spec.Replicas = &replicas
+
+	selector := CanarySelectorForDataPlane(dataplane)
+	deployment.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: selector,
+	}
+	if deployment.Spec.Template.Labels == nil {
+		deployment.Spec.Template.Labels = make(map[string]string)
+	}
+	for k, v := range selector {
+		deployment.Spec.Template.Labels[k] = v
+	}
+
 	return deployment, nil
 }
 
+// CanarySelectorForDataPlane returns the labels used to select the pods of
+// the canary Deployment of the provided DataPlane.
+func CanarySelectorForDataPlane(dataplane *operatorv1beta1.DataPlane) map[string]string {
+	return map[string]string{
+		"app":                                dataplane.Name,
+		consts.DataPlaneDeploymentStateLabel: consts.DataPlaneStateLabelValueCanary,
+	}
+}
+
+// PreviewSelectorForDataPlane returns the labels used to select the pods of
+// the preview Deployment of the provided DataPlane.
+func PreviewSelectorForDataPlane(dataplane *operatorv1beta1.DataPlane) map[string]string {
+	return map[string]string{
+		consts.DataPlanePreviewSelectorLabel: dataplane.Name,
+	}
+}
+
 func GenerateDataPlaneContainer(image string) corev1.Container {
 	return corev1.Container{
 		Name:            consts.DataPlaneProxyContainerName,
@@ -382,7 +509,7 @@ func generateDataplaneDeploymentVolumeMounts() []corev1.VolumeMount {
 		{
 			Name:      ClusterCertificateVolumeName,
 			ReadOnly:  true,
-			MountPath: "/var/cluster-certificate",
+			MountPath: consts.ClusterCertificateVolumeMountPath,
 		},
 	}
 
@@ -2,13 +2,17 @@ package resources
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
 
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
 	"github.com/kong/gateway-operator/internal/consts"
 )
@@ -188,8 +192,9 @@ func TestGenerateNewDeploymentForDataPlane(t *testing.T) {
 			testFunc: func(t *testing.T, deploymentSpec *appsv1.DeploymentSpec) {
 				require.Equal(t,
 					map[string]string{
-						"app":     "dataplane-name",
-						"label-a": "value-a",
+						"app":                                 "dataplane-name",
+						"label-a":                             "value-a",
+						consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
 					},
 					deploymentSpec.Template.Labels,
 				)
@@ -262,6 +267,53 @@ func TestGenerateNewDeploymentForDataPlane(t *testing.T) {
 				require.Equal(t, expected, actual)
 			},
 		},
+		{
+			name: "with postgres database specified the database env vars override the proxy env",
+			dataplane: &operatorv1beta1.DataPlane{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "gateway-operator.konghq.com/v1beta1",
+					Kind:       "DataPlane",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dataplane-name",
+					Namespace: "test-namespace",
+				},
+				Spec: operatorv1beta1.DataPlaneSpec{
+					DataPlaneOptions: operatorv1beta1.DataPlaneOptions{
+						Database: &operatorv1beta1.DataPlaneDatabaseOptions{
+							Postgres: &operatorv1beta1.PostgresDatabaseOptions{SecretName: "kong-postgres"},
+						},
+						Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+							DeploymentOptions: operatorv1beta1.DeploymentOptions{
+								PodTemplateSpec: &corev1.PodTemplateSpec{
+									Spec: corev1.PodSpec{
+										Containers: []corev1.Container{{
+											Name: consts.DataPlaneProxyContainerName,
+											Env: []corev1.EnvVar{
+												{Name: consts.EnvVarKongPGHost, Value: "localhost"},
+											},
+										}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			testFunc: func(t *testing.T, deploymentSpec *appsv1.DeploymentSpec) {
+				require.Len(t, deploymentSpec.Template.Spec.Containers, 1)
+				container := deploymentSpec.Template.Spec.Containers[0]
+				require.Len(t, container.Env, 6)
+				require.Equal(t, consts.EnvVarKongPGHost, container.Env[0].Name)
+				require.Empty(t, container.Env[0].Value)
+				require.Equal(t, &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "kong-postgres"},
+					Key:                  consts.PostgresSecretHostKey,
+					Optional:             pointer.Bool(false),
+				}, container.Env[0].ValueFrom.SecretKeyRef)
+				require.Contains(t, container.Env, corev1.EnvVar{Name: consts.EnvVarKongDatabase, Value: "postgres"})
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -272,3 +324,213 @@ func TestGenerateNewDeploymentForDataPlane(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateNewPreviewDeploymentForDataPlane(t *testing.T) {
+	dataplane := &operatorv1beta1.DataPlane{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "gateway-operator.konghq.com/v1beta1",
+			Kind:       "DataPlane",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dp",
+			Namespace: "test-namespace",
+		},
+	}
+
+	deployment, err := GenerateNewPreviewDeploymentForDataPlane(dataplane, "kong:3.3", "cert-secret-name")
+	require.NoError(t, err)
+
+	require.Equal(t, "dataplane-preview-dp-", deployment.GenerateName)
+	require.Equal(t, consts.DataPlaneStateLabelValuePreview, deployment.Labels[consts.DataPlaneDeploymentStateLabel])
+	require.Equal(t, map[string]string{consts.DataPlanePreviewSelectorLabel: "dp"}, deployment.Spec.Selector.MatchLabels)
+	require.Equal(t, map[string]string{
+		consts.DataPlanePreviewSelectorLabel:  "dp",
+		consts.GatewayOperatorControlledLabel: consts.DataPlaneManagedLabelValue,
+	}, deployment.Spec.Template.Labels,
+		"preview pods must not have the app label which is used by the live services")
+}
+
+func TestGenerateNewCanaryDeploymentForDataPlane(t *testing.T) {
+	dataplane := &operatorv1beta1.DataPlane{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "gateway-operator.konghq.com/v1beta1",
+			Kind:       "DataPlane",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dp",
+			Namespace: "test-namespace",
+		},
+	}
+
+	deployment, err := GenerateNewCanaryDeploymentForDataPlane(dataplane, "kong:3.3", "cert-secret-name", 2)
+	require.NoError(t, err)
+
+	require.Equal(t, "dataplane-canary-dp-", deployment.GenerateName)
+	require.Equal(t, consts.DataPlaneStateLabelValueCanary, deployment.Labels[consts.DataPlaneDeploymentStateLabel])
+	require.NotNil(t, deployment.Spec.Replicas)
+	require.Equal(t, int32(2), *deployment.Spec.Replicas)
+	require.Equal(t, "dp", deployment.Spec.Template.Labels["app"],
+		"canary pods must have the app label to be selected by the live services")
+
+	liveDeployment, err := GenerateNewDeploymentForDataPlane(dataplane, "kong:3.2", "cert-secret-name")
+	require.NoError(t, err)
+	canarySelector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	require.NoError(t, err)
+	liveSelector, err := metav1.LabelSelectorAsSelector(liveDeployment.Spec.Selector)
+	require.NoError(t, err)
+	require.True(t, canarySelector.Matches(labels.Set(deployment.Spec.Template.Labels)))
+	require.False(t, canarySelector.Matches(labels.Set(liveDeployment.Spec.Template.Labels)),
+		"the canary deployment must not select the live pods")
+	require.True(t, liveSelector.Matches(labels.Set(liveDeployment.Spec.Template.Labels)))
+	require.False(t, liveSelector.Matches(labels.Set(deployment.Spec.Template.Labels)),
+		"the live deployment must not select the canary pods")
+}
+
+func TestGenerateNewDeploymentForControlPlaneWithVolumes(t *testing.T) {
+	controlplane := &operatorv1alpha1.ControlPlane{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "gateway-operator.konghq.com/v1alpha1",
+			Kind:       "ControlPlane",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cp",
+			Namespace: "test-namespace",
+		},
+		Spec: operatorv1alpha1.ControlPlaneSpec{
+			ControlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+				Deployment: operatorv1alpha1.DeploymentOptions{
+					PodTemplateSpec: &corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Volumes: []corev1.Volume{{
+								Name: "ca-bundle",
+								VolumeSource: corev1.VolumeSource{
+									ConfigMap: &corev1.ConfigMapVolumeSource{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "ca-bundle"},
+									},
+								},
+							}},
+							Containers: []corev1.Container{{
+								Name: consts.ControlPlaneControllerContainerName,
+								VolumeMounts: []corev1.VolumeMount{{
+									Name:      "ca-bundle",
+									ReadOnly:  true,
+									MountPath: "/etc/ssl/ca-bundle",
+								}},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	deployment, err := GenerateNewDeploymentForControlPlane(controlplane, consts.DefaultControlPlaneImage, "sa", "cert-secret-name")
+	require.NoError(t, err)
+
+	podSpec := deployment.Spec.Template.Spec
+	require.Len(t, podSpec.Volumes, 2)
+	require.Contains(t, podSpec.Volumes, controlplane.Spec.Deployment.PodTemplateSpec.Spec.Volumes[0])
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == ClusterCertificateVolumeName {
+			require.Equal(t, "cert-secret-name", volume.Secret.SecretName)
+		}
+	}
+
+	require.Len(t, podSpec.Containers, 1)
+	require.ElementsMatch(t, []corev1.VolumeMount{
+		{
+			Name:      ClusterCertificateVolumeName,
+			ReadOnly:  true,
+			MountPath: consts.ClusterCertificateVolumeMountPath,
+		},
+		{
+			Name:      "ca-bundle",
+			ReadOnly:  true,
+			MountPath: "/etc/ssl/ca-bundle",
+		},
+	}, podSpec.Containers[0].VolumeMounts)
+}
+
+func TestGenerateNewDeploymentForControlPlaneWithControllerOptions(t *testing.T) {
+	controlplane := &operatorv1alpha1.ControlPlane{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "gateway-operator.konghq.com/v1alpha1",
+			Kind:       "ControlPlane",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cp",
+			Namespace: "test-namespace",
+		},
+		Spec: operatorv1alpha1.ControlPlaneSpec{
+			ControlPlaneOptions: operatorv1alpha1.ControlPlaneOptions{
+				Controller: &operatorv1alpha1.ControllerOptions{
+					FeatureGates: map[string]bool{
+						"GatewayAlpha":     true,
+						"CombinedServices": true,
+						"Knative":          false,
+						"Unknown":          true,
+					},
+					SyncPeriod:      &metav1.Duration{Duration: 10 * time.Minute},
+					ProxySyncPeriod: &metav1.Duration{Duration: 1500 * time.Millisecond},
+					Konnect: &operatorv1alpha1.ControllerKonnectOptions{
+						RuntimeGroupID:                 "rg-id",
+						TLSClientCertificateSecretName: "konnect-client",
+					},
+					Metrics: &operatorv1alpha1.ControllerMetricsOptions{
+						Port: 9090,
+					},
+					AdmissionWebhook: &operatorv1alpha1.ControllerAdmissionWebhookOptions{
+						CertificateSecretName: "webhook-cert",
+					},
+				},
+				Deployment: operatorv1alpha1.DeploymentOptions{
+					PodTemplateSpec: &corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{
+								Name: consts.ControlPlaneControllerContainerName,
+								Env: []corev1.EnvVar{
+									{Name: "CONTROLLER_SYNC_PERIOD", Value: "1h"},
+								},
+							}},
+						},
+					},
+				},
+			},
+			IngressClass: pointer.String("kong-internal"),
+		},
+	}
+
+	deployment, err := GenerateNewDeploymentForControlPlane(controlplane, "kong/kubernetes-ingress-controller:2.10.4", "sa", "cert-secret-name")
+	require.NoError(t, err)
+
+	env := deployment.Spec.Template.Spec.Containers[0].Env
+	envValues := make(map[string]string, len(env))
+	for _, envVar := range env {
+		envValues[envVar.Name] = envVar.Value
+	}
+	require.Equal(t, "kong-internal", envValues["CONTROLLER_INGRESS_CLASS"])
+	require.Equal(t, "GatewayAlpha=true,Knative=false", envValues["CONTROLLER_FEATURE_GATES"],
+		"the unknown and unsupported feature gates should be left out")
+	require.Equal(t, "1h", envValues["CONTROLLER_SYNC_PERIOD"], "the pod template should override the controller options")
+	require.Equal(t, "1.5", envValues["CONTROLLER_PROXY_SYNC_SECONDS"])
+	require.Equal(t, "true", envValues["CONTROLLER_KONNECT_SYNC_ENABLED"])
+	require.Equal(t, "rg-id", envValues["CONTROLLER_KONNECT_RUNTIME_GROUP_ID"])
+	require.NotContains(t, envValues, "CONTROLLER_KONNECT_ADDRESS")
+	require.Equal(t, ":9090", envValues["CONTROLLER_METRICS_BIND_ADDRESS"])
+	require.Equal(t, ":8080", envValues["CONTROLLER_ADMISSION_WEBHOOK_LISTEN"])
+	require.Contains(t, env, corev1.EnvVar{
+		Name: "CONTROLLER_ADMISSION_WEBHOOK_CERT",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "webhook-cert"},
+				Key:                  corev1.TLSCertKey,
+				Optional:             pointer.Bool(false),
+			},
+		},
+	})
+
+	t.Log("the metrics endpoint can be disabled")
+	controlplane.Spec.Controller.Metrics.Enabled = pointer.Bool(false)
+	env = GenerateControllerOptionsEnv(controlplane, consts.DefaultControlPlaneImage)
+	require.Contains(t, env, corev1.EnvVar{Name: "CONTROLLER_METRICS_BIND_ADDRESS", Value: "0"})
+}
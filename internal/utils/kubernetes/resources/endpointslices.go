@@ -0,0 +1,54 @@
+package resources
+
+import (
+	"fmt"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	"github.com/kong/gateway-operator/internal/consts"
+)
+
+// -----------------------------------------------------------------------------
+// EndpointSlice generators
+// -----------------------------------------------------------------------------
+
+// GenerateNewAdminEndpointSliceForControlPlane generates an EndpointSlice of the
+// admin Service of a ControlPlane, mirroring the ready endpoints of the provided
+// EndpointSlice of a DataPlane admin Service.
+func GenerateNewAdminEndpointSliceForControlPlane(
+	controlplane *operatorv1alpha1.ControlPlane,
+	serviceName string,
+	source *discoveryv1.EndpointSlice,
+) *discoveryv1.EndpointSlice {
+	endpoints := make([]discoveryv1.Endpoint, 0, len(source.Endpoints))
+	for _, endpoint := range source.Endpoints {
+		// A nil ready condition is to be interpreted as ready.
+		if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+			continue
+		}
+		endpoints = append(endpoints, *endpoint.DeepCopy())
+	}
+
+	ports := make([]discoveryv1.EndpointPort, 0, len(source.Ports))
+	for _, port := range source.Ports {
+		ports = append(ports, *port.DeepCopy())
+	}
+
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    controlplane.Namespace,
+			GenerateName: fmt.Sprintf("%s-admin-%s-", consts.ControlPlanePrefix, controlplane.Name),
+			Labels: map[string]string{
+				"app":                              controlplane.Name,
+				discoveryv1.LabelServiceName:       serviceName,
+				discoveryv1.LabelManagedBy:         consts.ControlPlaneEndpointSliceManagedByLabelValue,
+				consts.EndpointSliceSourceUIDLabel: string(source.UID),
+			},
+		},
+		AddressType: source.AddressType,
+		Endpoints:   endpoints,
+		Ports:       ports,
+	}
+}
@@ -0,0 +1,48 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+)
+
+// -----------------------------------------------------------------------------
+// HorizontalPodAutoscaler generators
+// -----------------------------------------------------------------------------
+
+// GenerateNewHorizontalPodAutoscalerForDataPlane generates a HorizontalPodAutoscaler
+// for the DataPlane, which scales the provided Deployment according to the
+// DataPlane's horizontal scaling options.
+func GenerateNewHorizontalPodAutoscalerForDataPlane(dataplane *operatorv1beta1.DataPlane, deploymentName string) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	if dataplane.Spec.Deployment.Scaling == nil || dataplane.Spec.Deployment.Scaling.HorizontalScaling == nil {
+		return nil, errors.New("cannot generate HorizontalPodAutoscaler for DataPlane without horizontal scaling options")
+	}
+	horizontal := dataplane.Spec.Deployment.Scaling.HorizontalScaling
+
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    dataplane.Namespace,
+			GenerateName: fmt.Sprintf("%s-%s-", consts.DataPlanePrefix, dataplane.Name),
+			Labels: map[string]string{
+				"app": dataplane.Name,
+			},
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "Deployment",
+				Name:       deploymentName,
+			},
+			MinReplicas: horizontal.MinReplicas,
+			MaxReplicas: horizontal.MaxReplicas,
+			Metrics:     horizontal.Metrics,
+			Behavior:    horizontal.Behavior,
+		},
+	}, nil
+}
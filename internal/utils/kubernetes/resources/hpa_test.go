@@ -0,0 +1,56 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+)
+
+func TestGenerateNewHorizontalPodAutoscalerForDataPlane(t *testing.T) {
+	dataplane := &operatorv1beta1.DataPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dp",
+			Namespace: "default",
+		},
+	}
+
+	_, err := GenerateNewHorizontalPodAutoscalerForDataPlane(dataplane, "dataplane-dp-abcde")
+	require.Error(t, err, "generating a HorizontalPodAutoscaler requires horizontal scaling options")
+
+	dataplane.Spec.Deployment.Scaling = &operatorv1beta1.Scaling{
+		HorizontalScaling: &operatorv1beta1.HorizontalScaling{
+			MinReplicas: lo.ToPtr(int32(2)),
+			MaxReplicas: 10,
+			Metrics: []autoscalingv2.MetricSpec{
+				{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name: "memory",
+						Target: autoscalingv2.MetricTarget{
+							Type:               autoscalingv2.UtilizationMetricType,
+							AverageUtilization: lo.ToPtr(int32(70)),
+						},
+					},
+				},
+			},
+		},
+	}
+	hpa, err := GenerateNewHorizontalPodAutoscalerForDataPlane(dataplane, "dataplane-dp-abcde")
+	require.NoError(t, err)
+	assert.Equal(t, "default", hpa.Namespace)
+	assert.Equal(t, "dataplane-dp-", hpa.GenerateName)
+	assert.Equal(t, autoscalingv2.CrossVersionObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       "dataplane-dp-abcde",
+	}, hpa.Spec.ScaleTargetRef)
+	assert.Equal(t, lo.ToPtr(int32(2)), hpa.Spec.MinReplicas)
+	assert.EqualValues(t, 10, hpa.Spec.MaxReplicas)
+	assert.Equal(t, dataplane.Spec.Deployment.Scaling.HorizontalScaling.Metrics, hpa.Spec.Metrics)
+}
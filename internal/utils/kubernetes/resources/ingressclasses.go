@@ -0,0 +1,35 @@
+package resources
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	"github.com/kong/gateway-operator/internal/consts"
+)
+
+// -----------------------------------------------------------------------------
+// IngressClass generators
+// -----------------------------------------------------------------------------
+
+// GenerateNewIngressClassForControlPlane is a helper to generate the IngressClass
+// claimed by the ControlPlane through its IngressClass field.
+func GenerateNewIngressClassForControlPlane(controlplane *operatorv1alpha1.ControlPlane) *networkingv1.IngressClass {
+	ingressClass := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: *controlplane.Spec.IngressClass,
+			Labels: map[string]string{
+				"app": controlplane.Name,
+			},
+		},
+		Spec: networkingv1.IngressClassSpec{
+			Controller: consts.IngressClassControllerName,
+		},
+	}
+	if controlplane.Spec.DefaultIngressClass {
+		ingressClass.Annotations = map[string]string{
+			networkingv1.AnnotationIsDefaultIngressClass: "true",
+		}
+	}
+	return ingressClass
+}
@@ -6,90 +6,81 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/utils/pointer"
 
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
 	"github.com/kong/gateway-operator/internal/consts"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
 )
 
 // -----------------------------------------------------------------------------
 // Jobs generators
 // -----------------------------------------------------------------------------
 
-// GenerateNewWebhookCertificateConfigJob generates the create and patch jobs for the certificateConfig
-func GenerateNewWebhookCertificateConfigJob(namespace,
-	serviceAccountName,
-	imageName,
-	secretName,
-	webhookName string,
-) *batchv1.Job {
-	return newWebhookCertificateConfigJobCommon(namespace, serviceAccountName, func(j *batchv1.Job) {
-		j.GenerateName = fmt.Sprintf("%s-", consts.WebhookCertificateConfigName)
+// GenerateNewMigrationsJobForDataPlane generates a Job running the provided
+// database migrations step for the DataPlane.
+//
+// The Job's pod template is derived from the DataPlane's Deployment, so that the
+// migrations run with the same image and configuration as the proxy. The pods
+// do not get the "app" label set, so that they are not selected by the
+// DataPlane's Services.
+//
+// The "up" step bootstraps the database, if needed, and runs the pending
+// migrations. The "finish" step finishes the pending migrations and is meant
+// to be run once all the DataPlane's pods are running the new version.
+func GenerateNewMigrationsJobForDataPlane(dataplane *operatorv1beta1.DataPlane, dataplaneImage, certSecretName, step string) (*batchv1.Job, error) {
+	deployment, err := GenerateNewDeploymentForDataPlane(dataplane, dataplaneImage, certSecretName)
+	if err != nil {
+		return nil, err
+	}
 
-		// We rely on the order of execution for creating and patching the certificates
-		// hence we use the init containers for that purpose.
-		// The "done" container is only present for the purposes of not breaking
-		// the spec: Jobs require at least one container to be present in the spec.
+	template := deployment.Spec.Template
+	if template.Labels == nil {
+		template.Labels = make(map[string]string)
+	}
+	delete(template.Labels, "app")
+	template.Labels[consts.DataPlaneMigrationsLabel] = step
+	template.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
 
-		j.Spec.Template.Spec.InitContainers = []corev1.Container{
-			{
-				Name: "create",
-				Args: []string{
-					"create",
-					fmt.Sprintf("--host=gateway-operator-validating-webhook,gateway-operator-validating-webhook.%s.svc", namespace),
-					fmt.Sprintf("--namespace=%s", namespace),
-					fmt.Sprintf("--secret-name=%s", secretName),
-				},
-				Image:           imageName,
-				ImagePullPolicy: corev1.PullIfNotPresent,
-			},
-			{
-				Name: "patch",
-				Args: []string{
-					"patch",
-					fmt.Sprintf("--webhook-name=%s", webhookName),
-					fmt.Sprintf("--namespace=%s", namespace),
-					"--patch-mutating=false",
-					"--patch-validating=true",
-					fmt.Sprintf("--secret-name=%s", secretName),
-					"--patch-failure-policy=Fail",
-				},
-				Image:           imageName,
-				ImagePullPolicy: corev1.PullIfNotPresent,
-			},
-		}
+	proxy := k8sutils.GetPodContainerByName(&template.Spec, consts.DataPlaneProxyContainerName)
+	if proxy == nil {
+		return nil, fmt.Errorf("cannot generate migrations Job for DataPlane %s without the proxy container", dataplane.Name)
+	}
+	migrationsContainer := func(command string) corev1.Container {
+		c := proxy.DeepCopy()
+		c.Name = fmt.Sprintf("kong-migrations-%s", command)
+		c.Args = []string{"kong", "migrations", command}
+		c.Ports = nil
+		c.ReadinessProbe = nil
+		c.LivenessProbe = nil
+		c.StartupProbe = nil
+		c.Lifecycle = nil
+		return *c
+	}
 
-		j.Spec.Template.Spec.Containers = []corev1.Container{
-			{
-				Name:            "done",
-				Image:           "busybox",
-				Args:            []string{"echo", "done"},
-				ImagePullPolicy: corev1.PullIfNotPresent,
-			},
-		}
-	})
-}
+	switch step {
+	case consts.DataPlaneMigrationsLabelValueUp:
+		// Bootstrapping is a no-op for an already bootstrapped database,
+		// hence it is always run before the migrations.
+		template.Spec.InitContainers = []corev1.Container{migrationsContainer("bootstrap")}
+		template.Spec.Containers = []corev1.Container{migrationsContainer("up")}
+	case consts.DataPlaneMigrationsLabelValueFinish:
+		template.Spec.InitContainers = nil
+		template.Spec.Containers = []corev1.Container{migrationsContainer("finish")}
+	default:
+		return nil, fmt.Errorf("unsupported migrations step %s", step)
+	}
 
-func newWebhookCertificateConfigJobCommon(namespace, serviceAccountName string, options ...func(*batchv1.Job)) *batchv1.Job {
-	job := &batchv1.Job{
+	return &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: namespace,
+			Namespace:    dataplane.Namespace,
+			GenerateName: fmt.Sprintf("%s-%s-migrations-%s-", consts.DataPlanePrefix, dataplane.Name, step),
+			Labels: map[string]string{
+				"app":                           dataplane.Name,
+				consts.DataPlaneMigrationsLabel: step,
+			},
 		},
 		Spec: batchv1.JobSpec{
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					RestartPolicy:      corev1.RestartPolicyOnFailure,
-					ServiceAccountName: serviceAccountName,
-					SecurityContext: &corev1.PodSecurityContext{
-						RunAsNonRoot: pointer.Bool(true),
-						RunAsUser:    pointer.Int64(2000),
-					},
-				},
-			},
+			Template: template,
 		},
-	}
-
-	for _, o := range options {
-		o(job)
-	}
-	return job
+	}, nil
 }
@@ -0,0 +1,94 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+)
+
+func TestGenerateNewMigrationsJobForDataPlane(t *testing.T) {
+	dataplane := &operatorv1beta1.DataPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dp",
+			Namespace: "default",
+		},
+		Spec: operatorv1beta1.DataPlaneSpec{
+			DataPlaneOptions: operatorv1beta1.DataPlaneOptions{
+				Database: &operatorv1beta1.DataPlaneDatabaseOptions{
+					Postgres: &operatorv1beta1.PostgresDatabaseOptions{SecretName: "kong-postgres"},
+				},
+				Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+					DeploymentOptions: operatorv1beta1.DeploymentOptions{
+						PodTemplateSpec: &corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{
+									{
+										Name: consts.DataPlaneProxyContainerName,
+										Env: []corev1.EnvVar{
+											{Name: consts.EnvVarKongDatabase, Value: "off"},
+											{Name: "KONG_LOG_LEVEL", Value: "debug"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("up", func(t *testing.T) {
+		job, err := GenerateNewMigrationsJobForDataPlane(dataplane, consts.DefaultDataPlaneImage, "cert-secret", consts.DataPlaneMigrationsLabelValueUp)
+		require.NoError(t, err)
+
+		assert.Equal(t, "dataplane-dp-migrations-up-", job.GenerateName)
+		assert.Equal(t, consts.DataPlaneMigrationsLabelValueUp, job.Labels[consts.DataPlaneMigrationsLabel])
+		assert.NotContains(t, job.Spec.Template.Labels, "app", "the migrations pods must not be selected by the DataPlane's Services")
+		assert.Equal(t, corev1.RestartPolicyOnFailure, job.Spec.Template.Spec.RestartPolicy)
+
+		require.Len(t, job.Spec.Template.Spec.InitContainers, 1)
+		assert.Equal(t, []string{"kong", "migrations", "bootstrap"}, job.Spec.Template.Spec.InitContainers[0].Args)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		container := job.Spec.Template.Spec.Containers[0]
+		assert.Equal(t, []string{"kong", "migrations", "up"}, container.Args)
+		assert.Equal(t, consts.DefaultDataPlaneImage, container.Image)
+		assert.Empty(t, container.Ports)
+		assert.Nil(t, container.ReadinessProbe)
+		assert.Nil(t, container.Lifecycle)
+
+		envs := make(map[string]corev1.EnvVar, len(container.Env))
+		for _, env := range container.Env {
+			envs[env.Name] = env
+		}
+		assert.Equal(t, "postgres", envs[consts.EnvVarKongDatabase].Value, "the database mode must be set by the database options")
+		assert.Equal(t, "debug", envs["KONG_LOG_LEVEL"].Value)
+		require.NotNil(t, envs[consts.EnvVarKongPGPassword].ValueFrom)
+		assert.Equal(t, &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "kong-postgres"},
+			Key:                  consts.PostgresSecretPasswordKey,
+			Optional:             pointer.Bool(false),
+		}, envs[consts.EnvVarKongPGPassword].ValueFrom.SecretKeyRef)
+	})
+
+	t.Run("finish", func(t *testing.T) {
+		job, err := GenerateNewMigrationsJobForDataPlane(dataplane, consts.DefaultDataPlaneImage, "cert-secret", consts.DataPlaneMigrationsLabelValueFinish)
+		require.NoError(t, err)
+
+		assert.Empty(t, job.Spec.Template.Spec.InitContainers)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, []string{"kong", "migrations", "finish"}, job.Spec.Template.Spec.Containers[0].Args)
+	})
+
+	t.Run("unsupported step", func(t *testing.T) {
+		_, err := GenerateNewMigrationsJobForDataPlane(dataplane, consts.DefaultDataPlaneImage, "cert-secret", "down")
+		require.Error(t, err)
+	})
+}
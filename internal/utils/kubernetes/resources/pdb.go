@@ -0,0 +1,73 @@
+package resources
+
+import (
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
+	"github.com/kong/gateway-operator/internal/consts"
+)
+
+// -----------------------------------------------------------------------------
+// PodDisruptionBudget generators
+// -----------------------------------------------------------------------------
+
+// GenerateNewPodDisruptionBudgetForDataPlane generates a new PodDisruptionBudget
+// for the DataPlane's pods. The canary pods of a Canary rollout share the "app"
+// label of the live pods and are covered by it as well.
+func GenerateNewPodDisruptionBudgetForDataPlane(dataplane *operatorv1beta1.DataPlane) (*policyv1.PodDisruptionBudget, error) {
+	opts := dataplane.Spec.Deployment.PodDisruptionBudget
+	if opts == nil {
+		return nil, fmt.Errorf("cannot generate PodDisruptionBudget for DataPlane %s without PodDisruptionBudget options", dataplane.Name)
+	}
+
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    dataplane.Namespace,
+			GenerateName: fmt.Sprintf("%s-%s-", consts.DataPlanePrefix, dataplane.Name),
+			Labels: map[string]string{
+				"app": dataplane.Name,
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   opts.MinAvailable,
+			MaxUnavailable: opts.MaxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": dataplane.Name,
+				},
+			},
+		},
+	}, nil
+}
+
+// GenerateNewPodDisruptionBudgetForControlPlane generates a new PodDisruptionBudget
+// for the ControlPlane's pods.
+func GenerateNewPodDisruptionBudgetForControlPlane(controlplane *operatorv1alpha1.ControlPlane) (*policyv1.PodDisruptionBudget, error) {
+	opts := controlplane.Spec.Deployment.PodDisruptionBudget
+	if opts == nil {
+		return nil, fmt.Errorf("cannot generate PodDisruptionBudget for ControlPlane %s without PodDisruptionBudget options", controlplane.Name)
+	}
+
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    controlplane.Namespace,
+			GenerateName: fmt.Sprintf("%s-%s-", consts.ControlPlanePrefix, controlplane.Name),
+			Labels: map[string]string{
+				"app": controlplane.Name,
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   opts.MinAvailable,
+			MaxUnavailable: opts.MaxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": controlplane.Name,
+				},
+			},
+		},
+	}, nil
+}
@@ -1,36 +1,69 @@
 package resources
 
 import (
+	"fmt"
+
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kong/gateway-operator/internal/consts"
 )
 
 // -----------------------------------------------------------------------------
-// ClusterRoleBinding generators
+// RoleBinding generators
 // -----------------------------------------------------------------------------
 
-// GenerateNewRoleBindingForCertificateConfig is a helper to generate a RoleBinding
-// to be used by the certificateConfig jobs
-func GenerateNewRoleBindingForCertificateConfig(namespace, name, labelValue string) *rbacv1.RoleBinding {
+// GenerateNewRoleBindingForControlPlane is a helper to generate a RoleBinding
+// resource to bind the namespaced role to the service account used by the
+// controlplane deployment.
+func GenerateNewRoleBindingForControlPlane(namespace, controlplaneName, serviceAccountName, roleName string) *rbacv1.RoleBinding {
 	return &rbacv1.RoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
+			Namespace:    namespace,
+			GenerateName: fmt.Sprintf("%s-%s-", consts.ControlPlanePrefix, controlplaneName),
 			Labels: map[string]string{
-				"app": labelValue,
+				"app": controlplaneName,
 			},
 		},
 		RoleRef: rbacv1.RoleRef{
 			APIGroup: "rbac.authorization.k8s.io",
 			Kind:     "Role",
-			Name:     name,
+			Name:     roleName,
 		},
 		Subjects: []rbacv1.Subject{
 			{
 				Kind:      "ServiceAccount",
-				Name:      name,
+				Name:      serviceAccountName,
 				Namespace: namespace,
 			},
 		},
 	}
 }
+
+// GenerateNewRoleBindingForControlPlaneWatchNamespace is a helper to generate
+// a RoleBinding resource to bind the role of a namespace watched by the
+// controlplane to the service account used by the controlplane deployment,
+// which lives in the controlplane namespace.
+func GenerateNewRoleBindingForControlPlaneWatchNamespace(namespace, controlplaneNamespace, controlplaneName, serviceAccountName, roleName string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    namespace,
+			GenerateName: fmt.Sprintf("%s-%s-watch-", consts.ControlPlanePrefix, controlplaneName),
+			Labels: map[string]string{
+				"app": controlplaneName,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     roleName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      serviceAccountName,
+				Namespace: controlplaneNamespace,
+			},
+		},
+	}
+}
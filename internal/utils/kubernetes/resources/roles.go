@@ -1,37 +1,80 @@
 package resources
 
 import (
+	"fmt"
+
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kong/gateway-operator/internal/consts"
 )
 
 // -----------------------------------------------------------------------------
 // Role generator helpers
 // -----------------------------------------------------------------------------
 
-// GenerateNewRoleForCertificateConfig is a helper to generate a Role
-// to be used by the certificateConfig jobs
-func GenerateNewRoleForCertificateConfig(namespace, name, labelValue string) *rbacv1.Role {
+// GenerateNewRoleForControlPlane is a helper to generate a Role granting the
+// controlplane deployment the permissions it needs in its own namespace to
+// run the leader election among its replicas.
+func GenerateNewRoleForControlPlane(namespace, controlplaneName string) *rbacv1.Role {
 	return &rbacv1.Role{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
+			Namespace:    namespace,
+			GenerateName: fmt.Sprintf("%s-%s-", consts.ControlPlanePrefix, controlplaneName),
 			Labels: map[string]string{
-				"app": labelValue,
+				"app": controlplaneName,
 			},
 		},
 		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{
+					"coordination.k8s.io",
+				},
+				Resources: []string{
+					"leases",
+				},
+				Verbs: []string{
+					"get", "list", "watch", "create", "update", "patch", "delete",
+				},
+			},
 			{
 				APIGroups: []string{
 					"",
 				},
 				Resources: []string{
-					"secrets",
+					"configmaps",
 				},
 				Verbs: []string{
-					"get", "create",
+					"get", "list", "watch", "create", "update", "patch", "delete",
 				},
 			},
+			{
+				APIGroups: []string{
+					"",
+				},
+				Resources: []string{
+					"events",
+				},
+				Verbs: []string{
+					"create", "patch",
+				},
+			},
+		},
+	}
+}
+
+// GenerateNewRoleForControlPlaneWatchNamespace is a helper to generate a Role
+// granting the controlplane deployment the provided permissions on the
+// namespaced resources of a namespace it watches.
+func GenerateNewRoleForControlPlaneWatchNamespace(namespace, controlplaneName string, rules []rbacv1.PolicyRule) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    namespace,
+			GenerateName: fmt.Sprintf("%s-%s-watch-", consts.ControlPlanePrefix, controlplaneName),
+			Labels: map[string]string{
+				"app": controlplaneName,
+			},
 		},
+		Rules: rules,
 	}
 }
@@ -9,6 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
 	"github.com/kong/gateway-operator/internal/consts"
 )
@@ -17,9 +18,9 @@ import (
 // Service generators
 // -----------------------------------------------------------------------------
 
-// GenerateNewServiceForCertificateConfig is a helper to generate a service
+// GenerateNewServiceForWebhook is a helper to generate a service
 // to expose the operator webhook
-func GenerateNewServiceForCertificateConfig(namespace, name string) *corev1.Service {
+func GenerateNewServiceForWebhook(namespace, name string) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -55,20 +56,7 @@ func GenerateNewProxyServiceForDataplane(dataplane *operatorv1beta1.DataPlane) (
 		Spec: corev1.ServiceSpec{
 			Type:     getDataPlaneIngressServiceType(dataplane),
 			Selector: map[string]string{"app": dataplane.Name},
-			Ports: []corev1.ServicePort{
-				{
-					Name:       "http",
-					Protocol:   corev1.ProtocolTCP,
-					Port:       consts.DefaultHTTPPort,
-					TargetPort: intstr.FromInt(consts.DataPlaneProxyPort),
-				},
-				{
-					Name:       "https",
-					Protocol:   corev1.ProtocolTCP,
-					Port:       consts.DefaultHTTPSPort,
-					TargetPort: intstr.FromInt(consts.DataPlaneProxySSLPort),
-				},
-			},
+			Ports:    getDataPlaneIngressServicePorts(dataplane),
 		},
 	}
 	if selectorOverride, ok := dataplane.Annotations[consts.ServiceSelectorOverrideAnnotation]; ok {
@@ -85,13 +73,55 @@ func GenerateNewProxyServiceForDataplane(dataplane *operatorv1beta1.DataPlane) (
 const DefaultDataPlaneProxyServiceType = corev1.ServiceTypeLoadBalancer
 
 func getDataPlaneIngressServiceType(dataplane *operatorv1beta1.DataPlane) corev1.ServiceType {
-	if dataplane == nil || dataplane.Spec.Network.Services == nil {
+	if dataplane == nil || dataplane.Spec.Network.Services == nil || dataplane.Spec.Network.Services.Ingress == nil ||
+		dataplane.Spec.Network.Services.Ingress.Type == "" {
 		return DefaultDataPlaneProxyServiceType
 	}
 
 	return dataplane.Spec.Network.Services.Ingress.Type
 }
 
+// getDataPlaneIngressServicePorts returns the ports configured for the DataPlane
+// proxy service, exposing HTTP on port 80 and HTTPS on port 443 when unset.
+func getDataPlaneIngressServicePorts(dataplane *operatorv1beta1.DataPlane) []corev1.ServicePort {
+	if dataplane == nil || dataplane.Spec.Network.Services == nil || dataplane.Spec.Network.Services.Ingress == nil ||
+		len(dataplane.Spec.Network.Services.Ingress.Ports) == 0 {
+		return []corev1.ServicePort{
+			{
+				Name:       "http",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       consts.DefaultHTTPPort,
+				TargetPort: intstr.FromInt(consts.DataPlaneProxyPort),
+			},
+			{
+				Name:       "https",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       consts.DefaultHTTPSPort,
+				TargetPort: intstr.FromInt(consts.DataPlaneProxySSLPort),
+			},
+		}
+	}
+
+	ports := make([]corev1.ServicePort, 0, len(dataplane.Spec.Network.Services.Ingress.Ports))
+	for _, p := range dataplane.Spec.Network.Services.Ingress.Ports {
+		targetPort := p.TargetPort
+		if targetPort == (intstr.IntOrString{}) {
+			targetPort = intstr.FromInt(int(p.Port))
+		}
+		protocol := p.Protocol
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		ports = append(ports, corev1.ServicePort{
+			Name:       p.Name,
+			Protocol:   protocol,
+			Port:       p.Port,
+			TargetPort: targetPort,
+		})
+	}
+	return ports
+}
+
 // GenerateNewAdminServiceForDataPlane is a helper to generate the headless dataplane admin service
 func GenerateNewAdminServiceForDataPlane(dataplane *operatorv1beta1.DataPlane) (*corev1.Service, error) {
 	adminService := &corev1.Service{
@@ -109,7 +139,7 @@ func GenerateNewAdminServiceForDataPlane(dataplane *operatorv1beta1.DataPlane) (
 			Selector:  map[string]string{"app": dataplane.Name},
 			Ports: []corev1.ServicePort{
 				{
-					Name:       "admin",
+					Name:       consts.DataPlaneAdminServicePortName,
 					Protocol:   corev1.ProtocolTCP,
 					Port:       int32(consts.DataPlaneAdminAPIPort),
 					TargetPort: intstr.FromInt(consts.DataPlaneAdminAPIPort),
@@ -128,6 +158,78 @@ func GenerateNewAdminServiceForDataPlane(dataplane *operatorv1beta1.DataPlane) (
 	return adminService, nil
 }
 
+// GenerateNewClusterServiceForDataPlane is a helper to generate the service
+// exposing the hybrid mode cluster endpoints of a control_plane dataplane
+func GenerateNewClusterServiceForDataPlane(dataplane *operatorv1beta1.DataPlane) (*corev1.Service, error) {
+	clusterService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    dataplane.Namespace,
+			GenerateName: fmt.Sprintf("%s-cluster-%s-", consts.DataPlanePrefix, dataplane.Name),
+			Labels: map[string]string{
+				"app":                            dataplane.Name,
+				consts.DataPlaneServiceTypeLabel: string(consts.DataPlaneClusterServiceLabelValue),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{"app": dataplane.Name},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "cluster",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       consts.DataPlaneClusterPort,
+					TargetPort: intstr.FromInt(consts.DataPlaneClusterPort),
+				},
+				{
+					Name:       "telemetry",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       consts.DataPlaneClusterTelemetryPort,
+					TargetPort: intstr.FromInt(consts.DataPlaneClusterTelemetryPort),
+				},
+			},
+		},
+	}
+	if selectorOverride, ok := dataplane.Annotations[consts.ServiceSelectorOverrideAnnotation]; ok {
+		newSelector, err := getSelectorOverrides(selectorOverride)
+		if err != nil {
+			return nil, err
+		}
+		clusterService.Spec.Selector = newSelector
+	}
+
+	return clusterService, nil
+}
+
+// GenerateNewPreviewProxyServiceForDataPlane is a helper to generate the
+// dataplane preview proxy service, used during Blue Green rollouts.
+// Contrary to the live proxy service, it is always of ClusterIP type.
+func GenerateNewPreviewProxyServiceForDataPlane(dataplane *operatorv1beta1.DataPlane) (*corev1.Service, error) {
+	proxyService, err := GenerateNewProxyServiceForDataplane(dataplane)
+	if err != nil {
+		return nil, err
+	}
+	proxyService.GenerateName = fmt.Sprintf("%s-proxy-preview-%s-", consts.DataPlanePrefix, dataplane.Name)
+	proxyService.Labels[consts.DataPlaneServiceStateLabel] = consts.DataPlaneStateLabelValuePreview
+	proxyService.Spec.Type = corev1.ServiceTypeClusterIP
+	proxyService.Spec.Selector = PreviewSelectorForDataPlane(dataplane)
+
+	return proxyService, nil
+}
+
+// GenerateNewPreviewAdminServiceForDataPlane is a helper to generate the headless
+// dataplane preview admin service, used during Blue Green rollouts.
+func GenerateNewPreviewAdminServiceForDataPlane(dataplane *operatorv1beta1.DataPlane) (*corev1.Service, error) {
+	adminService, err := GenerateNewAdminServiceForDataPlane(dataplane)
+	if err != nil {
+		return nil, err
+	}
+	adminService.GenerateName = fmt.Sprintf("%s-admin-preview-%s-", consts.DataPlanePrefix, dataplane.Name)
+	adminService.Labels[consts.DataPlaneServiceStateLabel] = consts.DataPlaneStateLabelValuePreview
+	adminService.Spec.Selector = PreviewSelectorForDataPlane(dataplane)
+
+	return adminService, nil
+}
+
 func getSelectorOverrides(overrideAnnotation string) (map[string]string, error) {
 	if overrideAnnotation == "" {
 		return nil, errors.New("selector override empty - expected format: key1=value,key2=value2")
@@ -144,3 +246,31 @@ func getSelectorOverrides(overrideAnnotation string) (map[string]string, error)
 	}
 	return selector, nil
 }
+
+// GenerateNewAdminServiceForControlPlane is a helper to generate the headless
+// admin service of a controlplane linked to several dataplanes. The service has
+// no selector: its endpoints are the admin API endpoints of all the dataplanes,
+// mirrored by the controlplane controller.
+func GenerateNewAdminServiceForControlPlane(controlplane *operatorv1alpha1.ControlPlane) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    controlplane.Namespace,
+			GenerateName: fmt.Sprintf("%s-admin-%s-", consts.ControlPlanePrefix, controlplane.Name),
+			Labels: map[string]string{
+				"app": controlplane.Name,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       consts.DataPlaneAdminServicePortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(consts.DataPlaneAdminAPIPort),
+					TargetPort: intstr.FromInt(consts.DataPlaneAdminAPIPort),
+				},
+			},
+		},
+	}
+}
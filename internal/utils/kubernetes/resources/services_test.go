@@ -4,6 +4,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
 )
 
 func TestGetSelectorOverrides(t *testing.T) {
@@ -69,3 +73,43 @@ func TestGetSelectorOverrides(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateNewProxyServiceForDataplanePorts(t *testing.T) {
+	t.Run("default ports", func(t *testing.T) {
+		svc, err := GenerateNewProxyServiceForDataplane(&operatorv1beta1.DataPlane{})
+		require.NoError(t, err)
+		require.Equal(t, corev1.ServiceTypeLoadBalancer, svc.Spec.Type)
+		require.Equal(t, []corev1.ServicePort{
+			{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8000)},
+			{Name: "https", Protocol: corev1.ProtocolTCP, Port: 443, TargetPort: intstr.FromInt(8443)},
+		}, svc.Spec.Ports)
+	})
+
+	t.Run("configured ports", func(t *testing.T) {
+		dataplane := &operatorv1beta1.DataPlane{
+			Spec: operatorv1beta1.DataPlaneSpec{
+				DataPlaneOptions: operatorv1beta1.DataPlaneOptions{
+					Network: operatorv1beta1.DataPlaneNetworkOptions{
+						Services: &operatorv1beta1.DataPlaneServices{
+							Ingress: &operatorv1beta1.ServiceOptions{
+								Ports: []operatorv1beta1.DataPlaneServicePort{
+									{Name: "http", Port: 80, TargetPort: intstr.FromInt(8000)},
+									{Name: "http-8080", Port: 8080},
+									{Name: "udp-53", Port: 53, Protocol: corev1.ProtocolUDP, TargetPort: intstr.FromInt(8053)},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		svc, err := GenerateNewProxyServiceForDataplane(dataplane)
+		require.NoError(t, err)
+		require.Equal(t, corev1.ServiceTypeLoadBalancer, svc.Spec.Type)
+		require.Equal(t, []corev1.ServicePort{
+			{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8000)},
+			{Name: "http-8080", Protocol: corev1.ProtocolTCP, Port: 8080, TargetPort: intstr.FromInt(8080)},
+			{Name: "udp-53", Protocol: corev1.ProtocolUDP, Port: 53, TargetPort: intstr.FromInt(8053)},
+		}, svc.Spec.Ports)
+	})
+}
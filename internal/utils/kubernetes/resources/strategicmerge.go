@@ -20,6 +20,12 @@ func StrategicMergePatchPodTemplateSpec(base, patch *corev1.PodTemplateSpec) (*c
 		return nil, fmt.Errorf("failed to marshal JSON for base %s: %w", base.Name, err)
 	}
 
+	// Containers are not omitted when empty, and a null list in a strategic
+	// merge patch deletes the list of base.
+	if patch.Spec.Containers == nil {
+		patch = patch.DeepCopy()
+		patch.Spec.Containers = []corev1.Container{}
+	}
 	patchBytes, err := json.Marshal(patch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal JSON for patch %s: %w", patch.Name, err)
@@ -31,7 +37,10 @@ func StrategicMergePatchPodTemplateSpec(base, patch *corev1.PodTemplateSpec) (*c
 		return nil, fmt.Errorf("failed to generate merge patch for %s: %w", base.Name, err)
 	}
 
-	patchResult := base.DeepCopy()
+	// Unmarshal into an empty object rather than a copy of base so that the
+	// list elements reordered by the patch do not keep the fields of the base
+	// elements previously found at the same index.
+	patchResult := &corev1.PodTemplateSpec{}
 	if err := json.Unmarshal(jsonResultBytes, patchResult); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal merged %s: %w", base.Name, err)
 	}
@@ -87,7 +87,7 @@ func TestStrategicMergePatchPodTemplateSpec(t *testing.T) {
 					Volumes: []corev1.Volume{
 						{
 							// NOTE: we need to provide the existing entry in the slice
-							// to prevent merging the provided new entry with existing entries.
+							// to keep it before the provided new entry in the merged slice.
 							Name: "cluster-certificate",
 						},
 						{
@@ -105,7 +105,7 @@ func TestStrategicMergePatchPodTemplateSpec(t *testing.T) {
 							VolumeMounts: []corev1.VolumeMount{
 								{
 									// NOTE: we need to provide the existing entry in the slice
-									// to prevent merging the provided new entry with existing entries.
+									// to keep it before the provided new entry in the merged slice.
 									Name:      "cluster-certificate",
 									MountPath: "/var/cluster-certificate",
 								},
@@ -137,6 +137,52 @@ func TestStrategicMergePatchPodTemplateSpec(t *testing.T) {
 				return d.Spec.Template
 			},
 		},
+		{
+			Name: "prepend a volume and volume mount",
+			Patch: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{
+						{
+							Name: "volume1",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "configmap1"},
+								},
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name: "controller",
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "volume1",
+									MountPath: "/volume1",
+								},
+							},
+						},
+					},
+				},
+			},
+			Expected: func() corev1.PodTemplateSpec {
+				d, err := makeControlPlaneDeployment()
+				require.NoError(t, err)
+				d.Spec.Template.Spec.Volumes = append([]corev1.Volume{{
+					Name: "volume1",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "configmap1"},
+						},
+					},
+				}}, d.Spec.Template.Spec.Volumes...)
+				d.Spec.Template.Spec.Containers[0].VolumeMounts = append([]corev1.VolumeMount{{
+					Name:      "volume1",
+					MountPath: "/volume1",
+				}}, d.Spec.Template.Spec.Containers[0].VolumeMounts...)
+
+				return d.Spec.Template
+			},
+		},
 		{
 			Name: "append a sidecar",
 			Patch: &corev1.PodTemplateSpec{
@@ -144,7 +190,7 @@ func TestStrategicMergePatchPodTemplateSpec(t *testing.T) {
 					Containers: []corev1.Container{
 						{
 							// NOTE: we need to provide the existing entry in the slice
-							// to prevent merging the provided new entry with existing entries.
+							// to keep it before the provided new entry in the merged slice.
 							Name: "controller",
 						},
 						{
@@ -189,7 +235,7 @@ func TestStrategicMergePatchPodTemplateSpec(t *testing.T) {
 					Containers: []corev1.Container{
 						{
 							// NOTE: we need to provide the existing entry in the slice
-							// to prevent merging the provided new entry with existing entries.
+							// to keep it before the provided new entry in the merged slice.
 							Name: "controller",
 						},
 						{
@@ -215,7 +261,7 @@ func TestStrategicMergePatchPodTemplateSpec(t *testing.T) {
 					Volumes: []corev1.Volume{
 						{
 							// NOTE: we need to provide the existing entry in the slice
-							// to prevent merging the provided new entry with existing entries.
+							// to keep it before the provided new entry in the merged slice.
 							Name: "cluster-certificate",
 						},
 						{
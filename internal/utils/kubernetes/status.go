@@ -39,6 +39,22 @@ const (
 	// UnableToProvisionReason generic message for unexpected errors
 	UnableToProvisionReason ConditionReason = "UnableToProvision"
 
+	// ImagePullBackOffReason indicates the pods of a resource cannot pull a container image
+	ImagePullBackOffReason ConditionReason = "ImagePullBackOff"
+
+	// OOMKilledReason indicates a container of the pods of a resource has run out of memory
+	OOMKilledReason ConditionReason = "OOMKilled"
+
+	// CrashLoopBackOffReason indicates a container of the pods of a resource keeps crashing
+	CrashLoopBackOffReason ConditionReason = "CrashLoopBackOff"
+
+	// UnschedulableReason indicates the pods of a resource cannot be scheduled
+	UnschedulableReason ConditionReason = "Unschedulable"
+
+	// ProgressDeadlineExceededReason indicates the Deployment of a resource has
+	// not progressed within its progress deadline
+	ProgressDeadlineExceededReason ConditionReason = "ProgressDeadlineExceeded"
+
 	// DependenciesNotReadyMessage indicates the other conditions are not yet ready
 	DependenciesNotReadyMessage = "There are other conditions that are not yet ready"
 
@@ -74,6 +90,18 @@ func SetCondition(condition metav1.Condition, resource ConditionsAware) {
 	resource.SetConditions(newConditions)
 }
 
+// RemoveCondition removes the condition with the given type from the provided resource
+func RemoveCondition(cType ConditionType, resource ConditionsAware) {
+	conditions := resource.GetConditions()
+	newConditions := make([]metav1.Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		if condition.Type != string(cType) {
+			newConditions = append(newConditions, condition)
+		}
+	}
+	resource.SetConditions(newConditions)
+}
+
 // GetCondition returns the condition with the given type, if it exists. If the condition does not exists it returns false.
 func GetCondition(cType ConditionType, resource ConditionsAware) (metav1.Condition, bool) {
 	for _, condition := range resource.GetConditions() {
@@ -94,6 +122,17 @@ func IsValidCondition(cType ConditionType, resource ConditionsAware) bool {
 	return false
 }
 
+// IsPodFailureReason returns true if the reason reports the failure of the pods
+// of a resource, as found by DiagnoseDeploymentPods.
+func IsPodFailureReason(reason ConditionReason) bool {
+	switch reason {
+	case ImagePullBackOffReason, OOMKilledReason, CrashLoopBackOffReason,
+		UnschedulableReason, ProgressDeadlineExceededReason:
+		return true
+	}
+	return false
+}
+
 // InitReady initializes the Ready status to False
 func InitReady(resource ConditionsAware) {
 	SetCondition(NewCondition(ReadyType, metav1.ConditionFalse, DependenciesNotReadyReason, DependenciesNotReadyMessage), resource)
@@ -1,30 +1,85 @@
 package dataplane
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
 
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
 	"github.com/kong/gateway-operator/internal/consts"
+	gatewayutils "github.com/kong/gateway-operator/internal/utils/gateway"
 	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
+	"github.com/kong/gateway-operator/internal/versions"
+	"github.com/kong/gateway-operator/pkg/vars"
 )
 
 // Validator validates ControlPlane objects.
-type Validator struct{}
+type Validator struct {
+	c client.Client
+	// devMode disables the validation of the version of the ControlPlane image,
+	// as the controller does in development mode.
+	devMode bool
+}
 
 // NewValidator creates a ControlPlane validator.
-func NewValidator(c client.Client) *Validator {
-	return &Validator{}
+func NewValidator(c client.Client, devMode bool) *Validator {
+	return &Validator{c: c, devMode: devMode}
 }
 
 // Validate validates a ControlPlane object and return the first validation error found.
-func (v *Validator) Validate(controlplane *operatorv1alpha1.ControlPlane) error {
+// The old ControlPlane is the one being updated, or nil when the ControlPlane is created.
+func (v *Validator) Validate(ctx context.Context, controlplane, oldControlplane *operatorv1alpha1.ControlPlane) error {
+	// ControlPlanes being deleted are only updated to remove their finalizers,
+	// which must not be prevented by the objects they refer to being deleted too.
+	if controlplane.DeletionTimestamp != nil {
+		return nil
+	}
+
 	if err := v.ValidateDeploymentOptions(&controlplane.Spec.Deployment); err != nil {
 		return err
 	}
-
-	// prepared for more validations
+	// The image and the classes are only validated when the ControlPlane is
+	// created or when they are changed, so that the ControlPlanes running an
+	// image which is no longer supported, or referring to classes which were
+	// deleted or claimed afterwards, can still be updated.
+	if oldControlplane == nil || controllerImage(&oldControlplane.Spec.Deployment) != controllerImage(&controlplane.Spec.Deployment) {
+		if err := v.ValidateImage(&controlplane.Spec.Deployment); err != nil {
+			return err
+		}
+	}
+	if err := v.ValidateWatchNamespaces(&controlplane.Spec.ControlPlaneOptions); err != nil {
+		return err
+	}
+	if err := v.ValidateDataPlanes(&controlplane.Spec.ControlPlaneOptions); err != nil {
+		return err
+	}
+	if err := v.ValidateDataPlane(ctx, controlplane, oldControlplane); err != nil {
+		return err
+	}
+	if oldControlplane == nil || !reflect.DeepEqual(oldControlplane.Spec.GatewayClass, controlplane.Spec.GatewayClass) {
+		if err := v.ValidateGatewayClass(ctx, controlplane); err != nil {
+			return err
+		}
+	}
+	if oldControlplane == nil || ingressClassChanged(&oldControlplane.Spec, &controlplane.Spec) {
+		if err := v.ValidateIngressClass(ctx, controlplane); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -37,11 +92,6 @@ func (v *Validator) ValidateDeploymentOptions(opts *operatorv1alpha1.DeploymentO
 		return errors.New("ControlPlane requires an image")
 	}
 
-	// Ref: https://github.com/Kong/gateway-operator/issues/736
-	if opts.Replicas != nil && *opts.Replicas != 1 {
-		return errors.New("ControlPlane only supports replicas of 1")
-	}
-
 	container := k8sutils.GetPodContainerByName(&opts.PodTemplateSpec.Spec, consts.ControlPlaneControllerContainerName)
 	if container == nil {
 		// We need the controller container for e.g. specifying an image which
@@ -55,9 +105,274 @@ func (v *Validator) ValidateDeploymentOptions(opts *operatorv1alpha1.DeploymentO
 		return errors.New("ControlPlane requires an image")
 	}
 
-	// Ref: https://github.com/Kong/gateway-operator/issues/740
-	if len(container.VolumeMounts) > 0 || len(opts.PodTemplateSpec.Spec.Volumes) > 0 {
-		return errors.New("ControlPlane does not support custom volumes and volume mounts")
+	if err := v.validateVolumes(&opts.PodTemplateSpec.Spec, container); err != nil {
+		return err
+	}
+
+	if pdb := opts.PodDisruptionBudget; pdb != nil && (pdb.MinAvailable == nil) == (pdb.MaxUnavailable == nil) {
+		return errors.New("PodDisruptionBudget has to set exactly one of minAvailable and maxUnavailable")
+	}
+
+	return nil
+}
+
+// ValidateImage validates that the version of the image of the controller
+// container is supported, unless in development mode. It expects the
+// DeploymentOptions to have been validated already.
+func (v *Validator) ValidateImage(opts *operatorv1alpha1.DeploymentOptions) error {
+	if v.devMode {
+		return nil
+	}
+
+	container := k8sutils.GetPodContainerByName(&opts.PodTemplateSpec.Spec, consts.ControlPlaneControllerContainerName)
+	supported, err := versions.IsControlPlaneImageVersionSupported(container.Image)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return fmt.Errorf("unsupported ControlPlane image %s", container.Image)
+	}
+	return nil
+}
+
+// ValidateWatchNamespaces validates the WatchNamespaces field of ControlPlane object.
+func (v *Validator) ValidateWatchNamespaces(opts *operatorv1alpha1.ControlPlaneOptions) error {
+	if len(opts.WatchNamespaces) == 0 {
+		return nil
+	}
+
+	for _, namespace := range opts.WatchNamespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid watch namespace %q: %s", namespace, strings.Join(errs, ", "))
+		}
+	}
+
+	// The watched namespaces have to match the ones the controller is granted access to.
+	if opts.Deployment.PodTemplateSpec != nil {
+		container := k8sutils.GetPodContainerByName(&opts.Deployment.PodTemplateSpec.Spec, consts.ControlPlaneControllerContainerName)
+		if container != nil && lo.ContainsBy(container.Env, func(env corev1.EnvVar) bool { return env.Name == "CONTROLLER_WATCH_NAMESPACE" }) {
+			return errors.New("CONTROLLER_WATCH_NAMESPACE cannot be set along with watchNamespaces")
+		}
+	}
+
+	return nil
+}
+
+// ValidateDataPlanes validates the DataPlanes and DataPlaneSelector fields of
+// ControlPlane object.
+func (v *Validator) ValidateDataPlanes(opts *operatorv1alpha1.ControlPlaneOptions) error {
+	if len(opts.DataPlanes) == 0 && opts.DataPlaneSelector == nil {
+		return nil
+	}
+
+	// The proxy Service of the DataPlane field is the one published in the
+	// status of the configured resources.
+	if opts.DataPlane == nil || *opts.DataPlane == "" {
+		return errors.New("dataplane has to be set along with dataplanes and dataplaneSelector")
+	}
+
+	for _, name := range opts.DataPlanes {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("invalid dataplane name %q: %s", name, strings.Join(errs, ", "))
+		}
+	}
+
+	if selector := opts.DataPlaneSelector; selector != nil {
+		for _, namespace := range selector.Namespaces {
+			if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+				return fmt.Errorf("invalid dataplane selector namespace %q: %s", namespace, strings.Join(errs, ", "))
+			}
+		}
+		if len(selector.Selector.MatchLabels) == 0 && len(selector.Selector.MatchExpressions) == 0 {
+			return errors.New("dataplane selector cannot be empty")
+		}
+		if _, err := metav1.LabelSelectorAsSelector(&selector.Selector); err != nil {
+			return fmt.Errorf("invalid dataplane selector: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateDataPlane validates that the DataPlane referred to by the DataPlane
+// field of ControlPlane object exists in its namespace, and that none of the
+// DataPlanes the ControlPlane pushes its configuration to is used by another
+// ControlPlane already. As the DataPlanes can be deleted or selected by other
+// ControlPlanes afterwards, this is only validated when the ControlPlane is
+// created or when its DataPlanes are changed, so that its other updates are
+// not rejected. The old ControlPlane is nil when the ControlPlane is created.
+func (v *Validator) ValidateDataPlane(ctx context.Context, controlplane, oldControlplane *operatorv1alpha1.ControlPlane) error {
+	name := lo.FromPtr(controlplane.Spec.DataPlane)
+	if name == "" {
+		return nil
+	}
+	if oldControlplane != nil && !dataPlanesChanged(&oldControlplane.Spec.ControlPlaneOptions, &controlplane.Spec.ControlPlaneOptions) {
+		return nil
+	}
+
+	if oldControlplane == nil || lo.FromPtr(oldControlplane.Spec.DataPlane) != name {
+		dataplane := &operatorv1beta1.DataPlane{}
+		if err := v.c.Get(ctx, types.NamespacedName{Namespace: controlplane.Namespace, Name: name}, dataplane); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return fmt.Errorf("dataplane %s does not exist in namespace %s", name, controlplane.Namespace)
+			}
+			return err
+		}
+	}
+
+	dataplanes, err := gatewayutils.ListDataPlanesForControlPlane(ctx, v.c, controlplane)
+	if err != nil {
+		return err
+	}
+	if len(dataplanes) == 0 {
+		return nil
+	}
+
+	// The DataPlane selectors of the ControlPlanes may select DataPlanes in
+	// other namespaces than theirs.
+	controlplanes := &operatorv1alpha1.ControlPlaneList{}
+	if err := v.c.List(ctx, controlplanes); err != nil {
+		return err
+	}
+	for i := range controlplanes.Items {
+		other := &controlplanes.Items[i]
+		if (other.Namespace == controlplane.Namespace && other.Name == controlplane.Name) || other.DeletionTimestamp != nil {
+			continue
+		}
+		otherDataPlanes, err := gatewayutils.ListDataPlanesForControlPlane(ctx, v.c, other)
+		if err != nil {
+			return err
+		}
+		for _, dataplane := range dataplanes {
+			if lo.ContainsBy(otherDataPlanes, func(otherDataPlane operatorv1beta1.DataPlane) bool {
+				return otherDataPlane.Namespace == dataplane.Namespace && otherDataPlane.Name == dataplane.Name
+			}) {
+				return fmt.Errorf("dataplane %s/%s is already used by ControlPlane %s/%s",
+					dataplane.Namespace, dataplane.Name, other.Namespace, other.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateGatewayClass validates that the GatewayClass field of ControlPlane
+// object refers to an existing GatewayClass, handled by the controller of the
+// ControlPlane.
+func (v *Validator) ValidateGatewayClass(ctx context.Context, controlplane *operatorv1alpha1.ControlPlane) error {
+	if controlplane.Spec.GatewayClass == nil {
+		return nil
+	}
+
+	name := string(*controlplane.Spec.GatewayClass)
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("invalid gatewayClass %q: %s", name, strings.Join(errs, ", "))
+	}
+
+	gatewayClass := &gatewayv1beta1.GatewayClass{}
+	if err := v.c.Get(ctx, client.ObjectKey{Name: name}, gatewayClass); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fmt.Errorf("GatewayClass %s does not exist", name)
+		}
+		return err
+	}
+	if string(gatewayClass.Spec.ControllerName) != vars.ControllerName() {
+		return fmt.Errorf("GatewayClass %s is handled by controller %s", name, gatewayClass.Spec.ControllerName)
+	}
+
+	return nil
+}
+
+// ValidateIngressClass validates the IngressClass and DefaultIngressClass fields
+// of ControlPlane object. The IngressClass is created for the ControlPlane when
+// it does not exist, otherwise it has to be handled by the Kong controller and
+// not to be claimed by another ControlPlane.
+func (v *Validator) ValidateIngressClass(ctx context.Context, controlplane *operatorv1alpha1.ControlPlane) error {
+	if controlplane.Spec.IngressClass == nil {
+		if controlplane.Spec.DefaultIngressClass {
+			return errors.New("ingressClass has to be set along with defaultIngressClass")
+		}
+		return nil
+	}
+
+	name := *controlplane.Spec.IngressClass
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("invalid ingressClass %q: %s", name, strings.Join(errs, ", "))
+	}
+
+	ingressClass := &networkingv1.IngressClass{}
+	if err := v.c.Get(ctx, client.ObjectKey{Name: name}, ingressClass); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if ingressClass.Labels[consts.GatewayOperatorControlledLabel] == consts.ControlPlaneManagedLabelValue &&
+		!k8sutils.IsOwnedByRefUID(ingressClass, controlplane.UID) {
+		owner, _ := lo.Find(ingressClass.OwnerReferences, func(ref metav1.OwnerReference) bool {
+			return ref.Kind == "ControlPlane"
+		})
+		return fmt.Errorf("IngressClass %s is already claimed by ControlPlane %s", name, owner.Name)
+	}
+	if ingressClass.Spec.Controller != consts.IngressClassControllerName {
+		return fmt.Errorf("IngressClass %s is handled by controller %s", name, ingressClass.Spec.Controller)
+	}
+
+	return nil
+}
+
+// dataPlanesChanged returns true when the DataPlanes a ControlPlane pushes its
+// configuration to are changed between the provided ControlPlane options.
+func dataPlanesChanged(oldOpts, opts *operatorv1alpha1.ControlPlaneOptions) bool {
+	return lo.FromPtr(oldOpts.DataPlane) != lo.FromPtr(opts.DataPlane) ||
+		!reflect.DeepEqual(oldOpts.DataPlanes, opts.DataPlanes) ||
+		!reflect.DeepEqual(oldOpts.DataPlaneSelector, opts.DataPlaneSelector)
+}
+
+// ingressClassChanged returns true when the IngressClass of a ControlPlane, or
+// whether it is the default class, is changed between the provided ControlPlane
+// specs.
+func ingressClassChanged(oldSpec, spec *operatorv1alpha1.ControlPlaneSpec) bool {
+	return lo.FromPtr(oldSpec.IngressClass) != lo.FromPtr(spec.IngressClass) ||
+		oldSpec.DefaultIngressClass != spec.DefaultIngressClass
+}
+
+// controllerImage returns the image of the controller container of the
+// provided DeploymentOptions, or an empty string when there is none.
+func controllerImage(opts *operatorv1alpha1.DeploymentOptions) string {
+	if opts.PodTemplateSpec == nil {
+		return ""
+	}
+	container := k8sutils.GetPodContainerByName(&opts.PodTemplateSpec.Spec, consts.ControlPlaneControllerContainerName)
+	if container == nil {
+		return ""
+	}
+	return container.Image
+}
+
+// validateVolumes validates the custom volumes and volume mounts, which are
+// merged with the ones of the generated Deployment. They cannot replace the
+// volume holding the certificate the controller uses to connect to the
+// DataPlane admin API.
+func (v *Validator) validateVolumes(podSpec *corev1.PodSpec, container *corev1.Container) error {
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == consts.ClusterCertificateVolume {
+			return fmt.Errorf("volume name %s is reserved", volume.Name)
+		}
+	}
+
+	for _, mount := range container.VolumeMounts {
+		if mount.Name == consts.ClusterCertificateVolume {
+			return fmt.Errorf("volume %s is already mounted in the controller container", mount.Name)
+		}
+		mountPath := path.Clean(mount.MountPath)
+		if mountPath == consts.ClusterCertificateVolumeMountPath ||
+			strings.HasPrefix(mountPath, consts.ClusterCertificateVolumeMountPath+"/") {
+			return fmt.Errorf("volume mount path %s is reserved for the cluster certificate", mount.MountPath)
+		}
+		if !lo.ContainsBy(podSpec.Volumes, func(volume corev1.Volume) bool { return volume.Name == mount.Name }) {
+			return fmt.Errorf("volume mount %s does not refer to any volume", mount.Name)
+		}
 	}
 
 	return nil
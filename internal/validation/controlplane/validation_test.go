@@ -0,0 +1,295 @@
+package dataplane
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1alpha1 "github.com/kong/gateway-operator/apis/v1alpha1"
+	"github.com/kong/gateway-operator/internal/consts"
+)
+
+func TestValidateDeploymentOptionsVolumes(t *testing.T) {
+	caBundleVolume := corev1.Volume{
+		Name: "ca-bundle",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "ca-bundle"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		msg     string
+		volumes []corev1.Volume
+		mounts  []corev1.VolumeMount
+		errMsg  string
+	}{
+		{
+			msg:     "custom volume mounted in the controller container",
+			volumes: []corev1.Volume{caBundleVolume},
+			mounts:  []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ssl/ca-bundle"}},
+		},
+		{
+			msg:     "custom volume not mounted in the controller container",
+			volumes: []corev1.Volume{caBundleVolume},
+		},
+		{
+			msg: "volume replacing the cluster certificate",
+			volumes: []corev1.Volume{{
+				Name: consts.ClusterCertificateVolume,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{SecretName: "my-certificate"},
+				},
+			}},
+			errMsg: "volume name cluster-certificate is reserved",
+		},
+		{
+			msg:    "cluster certificate mounted again",
+			mounts: []corev1.VolumeMount{{Name: consts.ClusterCertificateVolume, MountPath: "/etc/certificate"}},
+			errMsg: "volume cluster-certificate is already mounted in the controller container",
+		},
+		{
+			msg:     "volume mounted at the cluster certificate path",
+			volumes: []corev1.Volume{caBundleVolume},
+			mounts:  []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/var/cluster-certificate/"}},
+			errMsg:  "volume mount path /var/cluster-certificate/ is reserved for the cluster certificate",
+		},
+		{
+			msg:     "volume mounted below the cluster certificate path",
+			volumes: []corev1.Volume{caBundleVolume},
+			mounts:  []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/var/cluster-certificate/ca"}},
+			errMsg:  "volume mount path /var/cluster-certificate/ca is reserved for the cluster certificate",
+		},
+		{
+			msg:     "volume mounted next to the cluster certificate path",
+			volumes: []corev1.Volume{caBundleVolume},
+			mounts:  []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/var/cluster-certificate-ca"}},
+		},
+		{
+			msg:    "volume mount without volume",
+			mounts: []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ssl/ca-bundle"}},
+			errMsg: "volume mount ca-bundle does not refer to any volume",
+		},
+	}
+
+	v := NewValidator(nil, false)
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.msg, func(t *testing.T) {
+			opts := &operatorv1alpha1.DeploymentOptions{
+				PodTemplateSpec: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Volumes: tc.volumes,
+						Containers: []corev1.Container{
+							{
+								Name:         consts.ControlPlaneControllerContainerName,
+								Image:        consts.DefaultControlPlaneImage,
+								VolumeMounts: tc.mounts,
+							},
+						},
+					},
+				},
+			}
+			err := v.ValidateDeploymentOptions(opts)
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidateWatchNamespaces(t *testing.T) {
+	testCases := []struct {
+		msg             string
+		watchNamespaces []string
+		env             []corev1.EnvVar
+		errMsg          string
+	}{
+		{
+			msg: "all namespaces watched",
+			env: []corev1.EnvVar{{Name: "CONTROLLER_WATCH_NAMESPACE", Value: "tenant-a"}},
+		},
+		{
+			msg:             "restricted watch namespaces",
+			watchNamespaces: []string{"tenant-a", "tenant-b"},
+		},
+		{
+			msg:             "invalid watch namespace",
+			watchNamespaces: []string{"Tenant_A"},
+			errMsg:          `invalid watch namespace "Tenant_A": a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		},
+		{
+			msg:             "watch namespaces also set through the environment",
+			watchNamespaces: []string{"tenant-a"},
+			env:             []corev1.EnvVar{{Name: "CONTROLLER_WATCH_NAMESPACE", Value: "tenant-b"}},
+			errMsg:          "CONTROLLER_WATCH_NAMESPACE cannot be set along with watchNamespaces",
+		},
+	}
+
+	v := NewValidator(nil, false)
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.msg, func(t *testing.T) {
+			opts := &operatorv1alpha1.ControlPlaneOptions{
+				Deployment: operatorv1alpha1.DeploymentOptions{
+					PodTemplateSpec: &corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  consts.ControlPlaneControllerContainerName,
+									Image: consts.DefaultControlPlaneImage,
+									Env:   tc.env,
+								},
+							},
+						},
+					},
+				},
+				WatchNamespaces: tc.watchNamespaces,
+			}
+			err := v.ValidateWatchNamespaces(opts)
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidateDataPlanes(t *testing.T) {
+	testCases := []struct {
+		msg        string
+		dataplane  *string
+		dataplanes []string
+		selector   *operatorv1alpha1.DataPlaneSelector
+		errMsg     string
+	}{
+		{
+			msg:       "single dataplane",
+			dataplane: lo.ToPtr("dp-eu"),
+		},
+		{
+			msg:        "additional dataplanes",
+			dataplane:  lo.ToPtr("dp-eu"),
+			dataplanes: []string{"dp-us", "dp-ap"},
+			selector: &operatorv1alpha1.DataPlaneSelector{
+				Namespaces: []string{"region-us", "region-ap"},
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{"fleet": "regional"},
+				},
+			},
+		},
+		{
+			msg:        "additional dataplanes without dataplane",
+			dataplanes: []string{"dp-us"},
+			errMsg:     "dataplane has to be set along with dataplanes and dataplaneSelector",
+		},
+		{
+			msg:        "invalid dataplane name",
+			dataplane:  lo.ToPtr("dp-eu"),
+			dataplanes: []string{"DP_US"},
+			errMsg:     `invalid dataplane name "DP_US": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+		},
+		{
+			msg:       "empty selector",
+			dataplane: lo.ToPtr("dp-eu"),
+			selector:  &operatorv1alpha1.DataPlaneSelector{},
+			errMsg:    "dataplane selector cannot be empty",
+		},
+		{
+			msg:       "invalid selector",
+			dataplane: lo.ToPtr("dp-eu"),
+			selector: &operatorv1alpha1.DataPlaneSelector{
+				Selector: metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "fleet", Operator: "Contains", Values: []string{"regional"}},
+					},
+				},
+			},
+			errMsg: `invalid dataplane selector: "Contains" is not a valid label selector operator`,
+		},
+		{
+			msg:       "invalid selector namespace",
+			dataplane: lo.ToPtr("dp-eu"),
+			selector: &operatorv1alpha1.DataPlaneSelector{
+				Namespaces: []string{"Region_US"},
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{"fleet": "regional"},
+				},
+			},
+			errMsg: `invalid dataplane selector namespace "Region_US": a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		},
+	}
+
+	v := NewValidator(nil, false)
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.msg, func(t *testing.T) {
+			opts := &operatorv1alpha1.ControlPlaneOptions{
+				DataPlane:         tc.dataplane,
+				DataPlanes:        tc.dataplanes,
+				DataPlaneSelector: tc.selector,
+			}
+			err := v.ValidateDataPlanes(opts)
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidateImage(t *testing.T) {
+	testCases := []struct {
+		msg     string
+		image   string
+		devMode bool
+		errMsg  string
+	}{
+		{
+			msg:   "supported image",
+			image: consts.DefaultControlPlaneImage,
+		},
+		{
+			msg:    "unsupported image",
+			image:  "kong/kubernetes-ingress-controller:2.8.0",
+			errMsg: "unsupported ControlPlane image kong/kubernetes-ingress-controller:2.8.0",
+		},
+		{
+			msg:     "unsupported image in development mode",
+			image:   "kong/kubernetes-ingress-controller:2.8.0",
+			devMode: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.msg, func(t *testing.T) {
+			opts := &operatorv1alpha1.DeploymentOptions{
+				PodTemplateSpec: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  consts.ControlPlaneControllerContainerName,
+								Image: tc.image,
+							},
+						},
+					},
+				},
+			}
+			err := NewValidator(nil, tc.devMode).ValidateImage(opts)
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.errMsg)
+			}
+		})
+	}
+}
@@ -32,7 +32,88 @@ func (v *Validator) Validate(dataplane *operatorv1beta1.DataPlane) error {
 	if err != nil {
 		return err
 	}
-	// prepared for more validations
+	if err := v.ValidateDataPlaneDatabaseOptions(dataplane.Namespace, &dataplane.Spec.DataPlaneOptions); err != nil {
+		return err
+	}
+	if err := v.ValidateDataPlaneClusterOptions(dataplane.Name, &dataplane.Spec.DataPlaneOptions); err != nil {
+		return err
+	}
+	if err := v.ValidateDataPlaneRolloutOptions(dataplane.Spec.Deployment.Rollout); err != nil {
+		return err
+	}
+	if err := v.ValidateDataPlaneScalingOptions(dataplane.Spec.Deployment.Scaling); err != nil {
+		return err
+	}
+	if err := v.ValidateDataPlanePodDisruptionBudgetOptions(dataplane.Spec.Deployment.PodDisruptionBudget); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateDataPlanePodDisruptionBudgetOptions validates the PodDisruptionBudget field of DataPlane object.
+func (v *Validator) ValidateDataPlanePodDisruptionBudgetOptions(pdb *operatorv1beta1.PodDisruptionBudget) error {
+	if pdb == nil {
+		return nil
+	}
+	if (pdb.MinAvailable == nil) == (pdb.MaxUnavailable == nil) {
+		return errors.New("PodDisruptionBudget has to set exactly one of minAvailable and maxUnavailable")
+	}
+	return nil
+}
+
+// ValidateDataPlaneScalingOptions validates the Scaling field of DataPlane object.
+func (v *Validator) ValidateDataPlaneScalingOptions(scaling *operatorv1beta1.Scaling) error {
+	if scaling == nil || scaling.HorizontalScaling == nil {
+		return nil
+	}
+	horizontal := scaling.HorizontalScaling
+	if horizontal.MinReplicas != nil && *horizontal.MinReplicas > horizontal.MaxReplicas {
+		return fmt.Errorf("horizontal scaling minReplicas %d cannot be greater than maxReplicas %d",
+			*horizontal.MinReplicas, horizontal.MaxReplicas)
+	}
+	return nil
+}
+
+// ValidateDataPlaneRolloutOptions validates the Rollout field of DataPlane object.
+func (v *Validator) ValidateDataPlaneRolloutOptions(rollout *operatorv1beta1.Rollout) error {
+	if rollout == nil {
+		return nil
+	}
+	if rollout.Strategy.BlueGreen != nil && rollout.Strategy.Canary != nil {
+		return errors.New("only one of blueGreen and canary rollout strategies can be set")
+	}
+	if rollout.Strategy.Canary != nil {
+		return v.validateCanaryStrategy(rollout.Strategy.Canary)
+	}
+	if rollout.Strategy.BlueGreen == nil {
+		return nil
+	}
+
+	names := make(map[string]struct{}, len(rollout.Strategy.BlueGreen.PromotionChecks))
+	for _, check := range rollout.Strategy.BlueGreen.PromotionChecks {
+		// The checks' results are reported as conditions along with the RolledOut one.
+		if check.Name == consts.DataPlaneConditionTypeRolledOut {
+			return fmt.Errorf("promotion check name %s is reserved", check.Name)
+		}
+		if _, ok := names[check.Name]; ok {
+			return fmt.Errorf("duplicate promotion check name %s", check.Name)
+		}
+		names[check.Name] = struct{}{}
+
+		kinds := 0
+		if check.HTTP != nil {
+			kinds++
+		}
+		if check.Soak != nil {
+			kinds++
+		}
+		if check.PodRestarts != nil {
+			kinds++
+		}
+		if kinds != 1 {
+			return fmt.Errorf("promotion check %s has to set exactly one of http, soak or podRestarts", check.Name)
+		}
+	}
 	return nil
 }
 
@@ -58,27 +139,115 @@ func (v *Validator) ValidateDataPlaneDeploymentOptions(namespace string, opts *o
 	}
 
 	// validate db mode.
-	dbMode, dbModeFound, err := v.getDBModeFromEnv(namespace, container.Env)
+	dbMode, err := v.getDBMode(namespace, container)
 	if err != nil {
 		return err
 	}
 
-	// if dbMode not found in envVar, search for it in EnvVarFrom.
-	if !dbModeFound {
-		dbMode, _, err = v.getDBModeFromEnvFrom(namespace, container.EnvFrom)
-		if err != nil {
-			return err
+	// only support dbless and postgres modes.
+	switch dbMode {
+	case "", consts.DataPlaneDatabaseModeOff, consts.DataPlaneDatabaseModePostgres:
+	default:
+		return fmt.Errorf("database backend %s of DataPlane not supported currently", dbMode)
+	}
+
+	return nil
+}
+
+// ValidateDataPlaneDatabaseOptions validates the Database field of DataPlane object
+// against the database mode set in the proxy container's environment.
+func (v *Validator) ValidateDataPlaneDatabaseOptions(namespace string, opts *operatorv1beta1.DataPlaneOptions) error {
+	if opts.Database != nil && opts.Database.Postgres == nil {
+		return errors.New("DataPlane database options require postgres to be set")
+	}
+	postgres := opts.Database != nil && opts.Database.Postgres != nil
+
+	var dbMode string
+	if opts.Deployment.PodTemplateSpec != nil {
+		container := k8sutils.GetPodContainerByName(&opts.Deployment.PodTemplateSpec.Spec, consts.DataPlaneProxyContainerName)
+		if container != nil {
+			var err error
+			if dbMode, err = v.getDBMode(namespace, container); err != nil {
+				return err
+			}
 		}
 	}
 
-	// only support dbless mode.
-	if dbMode != "" && dbMode != "off" {
-		return fmt.Errorf("database backend %s of DataPlane not supported currently", dbMode)
+	if dbMode == consts.DataPlaneDatabaseModePostgres && !postgres {
+		return errors.New("database backend postgres of DataPlane requires the database.postgres options to be set")
+	}
+	// An unset database mode is defaulted to postgres when the postgres options are set.
+	if postgres && dbMode != "" && dbMode != consts.DataPlaneDatabaseModePostgres {
+		return fmt.Errorf("database backend %s of DataPlane conflicts with the database.postgres options", dbMode)
+	}
+	return nil
+}
+
+// ValidateDataPlaneClusterOptions validates the Cluster field of DataPlane object.
+func (v *Validator) ValidateDataPlaneClusterOptions(name string, opts *operatorv1beta1.DataPlaneOptions) error {
+	cluster := opts.Cluster
+	if cluster == nil {
+		return nil
+	}
+	postgres := opts.Database != nil && opts.Database.Postgres != nil
+
+	switch cluster.Role {
+	case operatorv1beta1.DataPlaneClusterRoleControlPlane:
+		if !postgres {
+			return fmt.Errorf("cluster role %s of DataPlane requires the database.postgres options to be set", cluster.Role)
+		}
+		if cluster.ControlPlaneName != "" {
+			return fmt.Errorf("cluster role %s of DataPlane cannot have a controlPlaneName", cluster.Role)
+		}
+	case operatorv1beta1.DataPlaneClusterRoleDataPlane:
+		if postgres {
+			return fmt.Errorf("cluster role %s of DataPlane cannot have database options", cluster.Role)
+		}
+		if cluster.ControlPlaneName == "" {
+			return fmt.Errorf("cluster role %s of DataPlane requires a controlPlaneName", cluster.Role)
+		}
+		if cluster.ControlPlaneName == name {
+			return errors.New("cluster controlPlaneName of DataPlane cannot reference the DataPlane itself")
+		}
+	default:
+		return fmt.Errorf("cluster role %s of DataPlane is not supported", cluster.Role)
 	}
+	return nil
+}
 
+func (v *Validator) validateCanaryStrategy(canary *operatorv1beta1.CanaryStrategy) error {
+	if len(canary.Steps) == 0 {
+		return errors.New("canary rollout strategy requires at least one step")
+	}
+	for i, step := range canary.Steps {
+		if step.Weight < 1 || step.Weight > 100 {
+			return fmt.Errorf("canary step %d weight %d must be between 1 and 100", i, step.Weight)
+		}
+		if i > 0 && step.Weight <= canary.Steps[i-1].Weight {
+			return fmt.Errorf("canary step %d weight %d must be greater than the previous step's weight", i, step.Weight)
+		}
+	}
 	return nil
 }
 
+// getDBMode gets the dbmode of the container from its Env or, if not found
+// there, from its EnvFrom.
+func (v *Validator) getDBMode(namespace string, container *corev1.Container) (string, error) {
+	dbMode, dbModeFound, err := v.getDBModeFromEnv(namespace, container.Env)
+	if err != nil {
+		return "", err
+	}
+
+	// if dbMode not found in envVar, search for it in EnvVarFrom.
+	if !dbModeFound {
+		dbMode, _, err = v.getDBModeFromEnvFrom(namespace, container.EnvFrom)
+		if err != nil {
+			return "", err
+		}
+	}
+	return dbMode, nil
+}
+
 // getDBModeFromEnv gets the dbmode from Env.
 // If the second return value is false, the dbMode is not found in Env.
 func (v *Validator) getDBModeFromEnv(namespace string, envs []corev1.EnvVar) (string, bool, error) {
@@ -3,10 +3,13 @@ package dataplane
 import (
 	"encoding/base64"
 	"testing"
+	"time"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	operatorv1beta1 "github.com/kong/gateway-operator/apis/v1beta1"
@@ -123,7 +126,7 @@ func TestValidateDeployOptions(t *testing.T) {
 			hasError: false,
 		},
 		{
-			msg: "dataplane with dbmode=postgres should be invalid",
+			msg: "dataplane with dbmode=postgres without database options should be invalid",
 			dataplane: &operatorv1beta1.DataPlane{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "test-db-postgres",
@@ -155,7 +158,7 @@ func TestValidateDeployOptions(t *testing.T) {
 				},
 			},
 			hasError: true,
-			errMsg:   "database backend postgres of DataPlane not supported currently",
+			errMsg:   "database backend postgres of DataPlane requires the database.postgres options to be set",
 		},
 		{
 			msg: "dataplane with arbitrary dbmode should be invalid",
@@ -232,7 +235,7 @@ func TestValidateDeployOptions(t *testing.T) {
 			hasError: false,
 		},
 		{
-			msg: "dataplane with dbmode=postgres (from secret) should be invalid",
+			msg: "dataplane with dbmode=postgres (from secret) without database options should be invalid",
 			dataplane: &operatorv1beta1.DataPlane{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "test-db-postgres-in-secret",
@@ -269,7 +272,7 @@ func TestValidateDeployOptions(t *testing.T) {
 				},
 			},
 			hasError: true,
-			errMsg:   "database backend postgres of DataPlane not supported currently",
+			errMsg:   "database backend postgres of DataPlane requires the database.postgres options to be set",
 		},
 		{
 			msg: "dataplane with dbmode=xxx (from configmap in envFrom) should be invalid",
@@ -362,3 +365,472 @@ func TestValidateDeployOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRolloutOptions(t *testing.T) {
+	testCases := []struct {
+		msg      string
+		rollout  *operatorv1beta1.Rollout
+		hasError bool
+		errMsg   string
+	}{
+		{
+			msg: "no rollout should be valid",
+		},
+		{
+			msg: "promotion checks with unique names and a single kind set should be valid",
+			rollout: &operatorv1beta1.Rollout{
+				Strategy: operatorv1beta1.RolloutStrategy{
+					BlueGreen: &operatorv1beta1.BlueGreenStrategy{
+						PromotionChecks: []operatorv1beta1.PromotionCheck{
+							{
+								Name: "status",
+								HTTP: &operatorv1beta1.HTTPPromotionCheck{ExpectedStatusCodes: []int{200}},
+							},
+							{
+								Name: "soak",
+								Soak: &operatorv1beta1.SoakPromotionCheck{Duration: metav1.Duration{Duration: time.Minute}},
+							},
+							{
+								Name:        "restarts",
+								PodRestarts: &operatorv1beta1.PodRestartsPromotionCheck{},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg: "promotion checks with duplicate names should be invalid",
+			rollout: &operatorv1beta1.Rollout{
+				Strategy: operatorv1beta1.RolloutStrategy{
+					BlueGreen: &operatorv1beta1.BlueGreenStrategy{
+						PromotionChecks: []operatorv1beta1.PromotionCheck{
+							{
+								Name:        "check",
+								PodRestarts: &operatorv1beta1.PodRestartsPromotionCheck{},
+							},
+							{
+								Name: "check",
+								Soak: &operatorv1beta1.SoakPromotionCheck{Duration: metav1.Duration{Duration: time.Minute}},
+							},
+						},
+					},
+				},
+			},
+			hasError: true,
+			errMsg:   "duplicate promotion check name check",
+		},
+		{
+			msg: "promotion check with a reserved name should be invalid",
+			rollout: &operatorv1beta1.Rollout{
+				Strategy: operatorv1beta1.RolloutStrategy{
+					BlueGreen: &operatorv1beta1.BlueGreenStrategy{
+						PromotionChecks: []operatorv1beta1.PromotionCheck{
+							{
+								Name:        "RolledOut",
+								PodRestarts: &operatorv1beta1.PodRestartsPromotionCheck{},
+							},
+						},
+					},
+				},
+			},
+			hasError: true,
+			errMsg:   "promotion check name RolledOut is reserved",
+		},
+		{
+			msg: "promotion check without any kind set should be invalid",
+			rollout: &operatorv1beta1.Rollout{
+				Strategy: operatorv1beta1.RolloutStrategy{
+					BlueGreen: &operatorv1beta1.BlueGreenStrategy{
+						PromotionChecks: []operatorv1beta1.PromotionCheck{
+							{
+								Name: "check",
+							},
+						},
+					},
+				},
+			},
+			hasError: true,
+			errMsg:   "promotion check check has to set exactly one of http, soak or podRestarts",
+		},
+		{
+			msg: "promotion check with multiple kinds set should be invalid",
+			rollout: &operatorv1beta1.Rollout{
+				Strategy: operatorv1beta1.RolloutStrategy{
+					BlueGreen: &operatorv1beta1.BlueGreenStrategy{
+						PromotionChecks: []operatorv1beta1.PromotionCheck{
+							{
+								Name:        "check",
+								Soak:        &operatorv1beta1.SoakPromotionCheck{Duration: metav1.Duration{Duration: time.Minute}},
+								PodRestarts: &operatorv1beta1.PodRestartsPromotionCheck{},
+							},
+						},
+					},
+				},
+			},
+			hasError: true,
+			errMsg:   "promotion check check has to set exactly one of http, soak or podRestarts",
+		},
+		{
+			msg: "canary steps with increasing weights should be valid",
+			rollout: &operatorv1beta1.Rollout{
+				Strategy: operatorv1beta1.RolloutStrategy{
+					Canary: &operatorv1beta1.CanaryStrategy{
+						Steps: []operatorv1beta1.CanaryStep{
+							{Weight: 10, Pause: &metav1.Duration{Duration: time.Minute}},
+							{Weight: 50},
+							{Weight: 100},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg: "canary steps with non increasing weights should be invalid",
+			rollout: &operatorv1beta1.Rollout{
+				Strategy: operatorv1beta1.RolloutStrategy{
+					Canary: &operatorv1beta1.CanaryStrategy{
+						Steps: []operatorv1beta1.CanaryStep{
+							{Weight: 50},
+							{Weight: 50},
+						},
+					},
+				},
+			},
+			hasError: true,
+			errMsg:   "canary step 1 weight 50 must be greater than the previous step's weight",
+		},
+		{
+			msg: "canary without steps should be invalid",
+			rollout: &operatorv1beta1.Rollout{
+				Strategy: operatorv1beta1.RolloutStrategy{
+					Canary: &operatorv1beta1.CanaryStrategy{},
+				},
+			},
+			hasError: true,
+			errMsg:   "canary rollout strategy requires at least one step",
+		},
+		{
+			msg: "both blue green and canary strategies should be invalid",
+			rollout: &operatorv1beta1.Rollout{
+				Strategy: operatorv1beta1.RolloutStrategy{
+					BlueGreen: &operatorv1beta1.BlueGreenStrategy{},
+					Canary: &operatorv1beta1.CanaryStrategy{
+						Steps: []operatorv1beta1.CanaryStep{{Weight: 100}},
+					},
+				},
+			},
+			hasError: true,
+			errMsg:   "only one of blueGreen and canary rollout strategies can be set",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.msg, func(t *testing.T) {
+			v := &Validator{
+				c: fakeclient.NewClientBuilder().Build(),
+			}
+			err := v.ValidateDataPlaneRolloutOptions(tc.rollout)
+			if !tc.hasError {
+				require.NoError(t, err, tc.msg)
+			} else {
+				require.EqualError(t, err, tc.errMsg, tc.msg)
+			}
+		})
+	}
+}
+
+func TestValidateScalingOptions(t *testing.T) {
+	testCases := []struct {
+		msg      string
+		scaling  *operatorv1beta1.Scaling
+		hasError bool
+		errMsg   string
+	}{
+		{
+			msg: "no scaling should be valid",
+		},
+		{
+			msg: "horizontal scaling with minReplicas lower than maxReplicas should be valid",
+			scaling: &operatorv1beta1.Scaling{
+				HorizontalScaling: &operatorv1beta1.HorizontalScaling{
+					MinReplicas: lo.ToPtr(int32(2)),
+					MaxReplicas: 10,
+				},
+			},
+		},
+		{
+			msg: "horizontal scaling without minReplicas should be valid",
+			scaling: &operatorv1beta1.Scaling{
+				HorizontalScaling: &operatorv1beta1.HorizontalScaling{
+					MaxReplicas: 1,
+				},
+			},
+		},
+		{
+			msg: "horizontal scaling with minReplicas greater than maxReplicas should be invalid",
+			scaling: &operatorv1beta1.Scaling{
+				HorizontalScaling: &operatorv1beta1.HorizontalScaling{
+					MinReplicas: lo.ToPtr(int32(5)),
+					MaxReplicas: 3,
+				},
+			},
+			hasError: true,
+			errMsg:   "horizontal scaling minReplicas 5 cannot be greater than maxReplicas 3",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.msg, func(t *testing.T) {
+			v := &Validator{
+				c: fakeclient.NewClientBuilder().Build(),
+			}
+			err := v.ValidateDataPlaneScalingOptions(tc.scaling)
+			if !tc.hasError {
+				require.NoError(t, err, tc.msg)
+			} else {
+				require.EqualError(t, err, tc.errMsg, tc.msg)
+			}
+		})
+	}
+}
+
+func TestValidatePodDisruptionBudgetOptions(t *testing.T) {
+	testCases := []struct {
+		msg      string
+		pdb      *operatorv1beta1.PodDisruptionBudget
+		hasError bool
+	}{
+		{
+			msg: "no PodDisruptionBudget should be valid",
+		},
+		{
+			msg: "minAvailable only should be valid",
+			pdb: &operatorv1beta1.PodDisruptionBudget{MinAvailable: lo.ToPtr(intstr.FromInt(1))},
+		},
+		{
+			msg: "maxUnavailable only should be valid",
+			pdb: &operatorv1beta1.PodDisruptionBudget{MaxUnavailable: lo.ToPtr(intstr.FromString("50%"))},
+		},
+		{
+			msg:      "neither minAvailable nor maxUnavailable should be invalid",
+			pdb:      &operatorv1beta1.PodDisruptionBudget{},
+			hasError: true,
+		},
+		{
+			msg: "both minAvailable and maxUnavailable should be invalid",
+			pdb: &operatorv1beta1.PodDisruptionBudget{
+				MinAvailable:   lo.ToPtr(intstr.FromInt(1)),
+				MaxUnavailable: lo.ToPtr(intstr.FromInt(1)),
+			},
+			hasError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.msg, func(t *testing.T) {
+			v := &Validator{
+				c: fakeclient.NewClientBuilder().Build(),
+			}
+			err := v.ValidateDataPlanePodDisruptionBudgetOptions(tc.pdb)
+			if !tc.hasError {
+				require.NoError(t, err, tc.msg)
+			} else {
+				require.EqualError(t, err, "PodDisruptionBudget has to set exactly one of minAvailable and maxUnavailable", tc.msg)
+			}
+		})
+	}
+}
+
+func TestValidateDatabaseOptions(t *testing.T) {
+	withDBMode := func(dbMode string, database *operatorv1beta1.DataPlaneDatabaseOptions) *operatorv1beta1.DataPlaneOptions {
+		opts := &operatorv1beta1.DataPlaneOptions{
+			Database: database,
+			Deployment: operatorv1beta1.DataPlaneDeploymentOptions{
+				DeploymentOptions: operatorv1beta1.DeploymentOptions{
+					PodTemplateSpec: &corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  consts.DataPlaneProxyContainerName,
+									Image: consts.DefaultDataPlaneImage,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		if dbMode != "" {
+			opts.Deployment.PodTemplateSpec.Spec.Containers[0].Env = []corev1.EnvVar{
+				{Name: consts.EnvVarKongDatabase, Value: dbMode},
+			}
+		}
+		return opts
+	}
+	postgres := &operatorv1beta1.DataPlaneDatabaseOptions{
+		Postgres: &operatorv1beta1.PostgresDatabaseOptions{SecretName: "kong-postgres"},
+	}
+
+	testCases := []struct {
+		msg      string
+		opts     *operatorv1beta1.DataPlaneOptions
+		hasError bool
+		errMsg   string
+	}{
+		{
+			msg:  "no database options with dbmode=off should be valid",
+			opts: withDBMode("off", nil),
+		},
+		{
+			msg:  "postgres options with dbmode=postgres should be valid",
+			opts: withDBMode("postgres", postgres),
+		},
+		{
+			msg:  "postgres options with empty dbmode should be valid",
+			opts: withDBMode("", postgres),
+		},
+		{
+			msg:      "postgres options with dbmode=off should be invalid",
+			opts:     withDBMode("off", postgres),
+			hasError: true,
+			errMsg:   "database backend off of DataPlane conflicts with the database.postgres options",
+		},
+		{
+			msg:      "dbmode=postgres without database options should be invalid",
+			opts:     withDBMode("postgres", nil),
+			hasError: true,
+			errMsg:   "database backend postgres of DataPlane requires the database.postgres options to be set",
+		},
+		{
+			msg:      "database options without postgres should be invalid",
+			opts:     withDBMode("", &operatorv1beta1.DataPlaneDatabaseOptions{}),
+			hasError: true,
+			errMsg:   "DataPlane database options require postgres to be set",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.msg, func(t *testing.T) {
+			v := &Validator{
+				c: fakeclient.NewClientBuilder().Build(),
+			}
+			err := v.ValidateDataPlaneDatabaseOptions("default", tc.opts)
+			if !tc.hasError {
+				require.NoError(t, err, tc.msg)
+			} else {
+				require.EqualError(t, err, tc.errMsg, tc.msg)
+			}
+		})
+	}
+}
+
+func TestValidateClusterOptions(t *testing.T) {
+	postgres := &operatorv1beta1.DataPlaneDatabaseOptions{
+		Postgres: &operatorv1beta1.PostgresDatabaseOptions{SecretName: "kong-postgres"},
+	}
+
+	testCases := []struct {
+		msg      string
+		opts     *operatorv1beta1.DataPlaneOptions
+		hasError bool
+		errMsg   string
+	}{
+		{
+			msg:  "no cluster options should be valid",
+			opts: &operatorv1beta1.DataPlaneOptions{},
+		},
+		{
+			msg: "control_plane role with postgres options should be valid",
+			opts: &operatorv1beta1.DataPlaneOptions{
+				Database: postgres,
+				Cluster: &operatorv1beta1.DataPlaneClusterOptions{
+					Role: operatorv1beta1.DataPlaneClusterRoleControlPlane,
+				},
+			},
+		},
+		{
+			msg: "control_plane role without database options should be invalid",
+			opts: &operatorv1beta1.DataPlaneOptions{
+				Cluster: &operatorv1beta1.DataPlaneClusterOptions{
+					Role: operatorv1beta1.DataPlaneClusterRoleControlPlane,
+				},
+			},
+			hasError: true,
+			errMsg:   "cluster role control_plane of DataPlane requires the database.postgres options to be set",
+		},
+		{
+			msg: "control_plane role with controlPlaneName should be invalid",
+			opts: &operatorv1beta1.DataPlaneOptions{
+				Database: postgres,
+				Cluster: &operatorv1beta1.DataPlaneClusterOptions{
+					Role:             operatorv1beta1.DataPlaneClusterRoleControlPlane,
+					ControlPlaneName: "kong-cp",
+				},
+			},
+			hasError: true,
+			errMsg:   "cluster role control_plane of DataPlane cannot have a controlPlaneName",
+		},
+		{
+			msg: "data_plane role with controlPlaneName should be valid",
+			opts: &operatorv1beta1.DataPlaneOptions{
+				Cluster: &operatorv1beta1.DataPlaneClusterOptions{
+					Role:             operatorv1beta1.DataPlaneClusterRoleDataPlane,
+					ControlPlaneName: "kong-cp",
+				},
+			},
+		},
+		{
+			msg: "data_plane role without controlPlaneName should be invalid",
+			opts: &operatorv1beta1.DataPlaneOptions{
+				Cluster: &operatorv1beta1.DataPlaneClusterOptions{
+					Role: operatorv1beta1.DataPlaneClusterRoleDataPlane,
+				},
+			},
+			hasError: true,
+			errMsg:   "cluster role data_plane of DataPlane requires a controlPlaneName",
+		},
+		{
+			msg: "data_plane role referencing itself should be invalid",
+			opts: &operatorv1beta1.DataPlaneOptions{
+				Cluster: &operatorv1beta1.DataPlaneClusterOptions{
+					Role:             operatorv1beta1.DataPlaneClusterRoleDataPlane,
+					ControlPlaneName: "kong-dp",
+				},
+			},
+			hasError: true,
+			errMsg:   "cluster controlPlaneName of DataPlane cannot reference the DataPlane itself",
+		},
+		{
+			msg: "data_plane role with database options should be invalid",
+			opts: &operatorv1beta1.DataPlaneOptions{
+				Database: postgres,
+				Cluster: &operatorv1beta1.DataPlaneClusterOptions{
+					Role:             operatorv1beta1.DataPlaneClusterRoleDataPlane,
+					ControlPlaneName: "kong-cp",
+				},
+			},
+			hasError: true,
+			errMsg:   "cluster role data_plane of DataPlane cannot have database options",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.msg, func(t *testing.T) {
+			v := &Validator{
+				c: fakeclient.NewClientBuilder().Build(),
+			}
+			err := v.ValidateDataPlaneClusterOptions("kong-dp", tc.opts)
+			if !tc.hasError {
+				require.NoError(t, err, tc.msg)
+			} else {
+				require.EqualError(t, err, tc.errMsg, tc.msg)
+			}
+		})
+	}
+}
@@ -49,3 +49,63 @@ func IsControlPlaneImageVersionSupported(image string) (bool, error) {
 
 	return imageVersion.GE(minimumControlPlaneVersion), nil
 }
+
+// controllerFeatureGates maps the feature gates of the ControlPlane controller
+// to the range of its versions supporting them.
+var controllerFeatureGates = map[string]semver.Range{
+	"GatewayAlpha":      semver.MustParseRange(">=2.6.0"),
+	"Knative":           semver.MustParseRange("<3.0.0"),
+	"CombinedRoutes":    semver.MustParseRange(">=2.4.0 <3.0.0"),
+	"ExpressionRoutes":  semver.MustParseRange(">=2.10.0"),
+	"CombinedServices":  semver.MustParseRange(">=2.11.0"),
+	"FillIDs":           semver.MustParseRange(">=3.0.0"),
+	"RewriteURIs":       semver.MustParseRange(">=3.0.0"),
+	"KongServiceFacade": semver.MustParseRange(">=3.1.0"),
+}
+
+// controllerOptions maps the options of the typed configuration of the
+// ControlPlane controller to the range of its versions supporting them.
+// The options which are not listed are supported by all the versions.
+var controllerOptions = map[string]semver.Range{
+	"konnect": semver.MustParseRange(">=2.9.0"),
+}
+
+// IsControllerFeatureGateKnown indicates whether the feature gate is known to
+// any version of the ControlPlane controller.
+func IsControllerFeatureGateKnown(gate string) bool {
+	_, ok := controllerFeatureGates[gate]
+	return ok
+}
+
+// IsControllerFeatureGateSupported indicates whether the known feature gate is
+// supported by the version of the given ControlPlane image.
+//
+// The version is only checked when the image tag is a semver compatible version,
+// e.g. development images are considered to support all the known feature gates.
+func IsControllerFeatureGateSupported(image, gate string) bool {
+	supported, ok := controllerFeatureGates[gate]
+	if !ok {
+		return false
+	}
+	imageVersion, err := FromImage(image)
+	if err != nil {
+		return true
+	}
+	return supported(imageVersion)
+}
+
+// IsControllerOptionSupported indicates whether the option of the typed
+// configuration of the ControlPlane controller is supported by the version of
+// the given ControlPlane image, following the same rules as
+// IsControllerFeatureGateSupported.
+func IsControllerOptionSupported(image, option string) bool {
+	supported, ok := controllerOptions[option]
+	if !ok {
+		return true
+	}
+	imageVersion, err := FromImage(image)
+	if err != nil {
+		return true
+	}
+	return supported(imageVersion)
+}
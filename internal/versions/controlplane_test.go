@@ -0,0 +1,36 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsControllerFeatureGateSupported(t *testing.T) {
+	testcases := []struct {
+		image     string
+		gate      string
+		supported bool
+	}{
+		{image: "kong/kubernetes-ingress-controller:2.10.4", gate: "ExpressionRoutes", supported: true},
+		{image: "kong/kubernetes-ingress-controller:2.9.3", gate: "ExpressionRoutes", supported: false},
+		{image: "kong/kubernetes-ingress-controller:2.10", gate: "CombinedServices", supported: false},
+		{image: "kong/kubernetes-ingress-controller:3.0.0", gate: "Knative", supported: false},
+		{image: "kong/kubernetes-ingress-controller:2.10.4", gate: "Unknown", supported: false},
+		{image: "kong/kubernetes-ingress-controller:main", gate: "CombinedServices", supported: true},
+		{image: "kong/kubernetes-ingress-controller:main", gate: "Unknown", supported: false},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.image+"/"+tc.gate, func(t *testing.T) {
+			require.Equal(t, tc.supported, IsControllerFeatureGateSupported(tc.image, tc.gate))
+		})
+	}
+}
+
+func TestIsControllerOptionSupported(t *testing.T) {
+	require.True(t, IsControllerOptionSupported("kong/kubernetes-ingress-controller:2.9.0", "konnect"))
+	require.False(t, IsControllerOptionSupported("kong/kubernetes-ingress-controller:2.8.1", "konnect"))
+	require.True(t, IsControllerOptionSupported("kong/kubernetes-ingress-controller:2.8.1", "syncPeriod"))
+}
@@ -21,9 +21,12 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/kong/gateway-operator/controllers"
 	"github.com/kong/gateway-operator/internal/manager"
 	"github.com/kong/gateway-operator/internal/manager/metadata"
+	k8sutils "github.com/kong/gateway-operator/internal/utils/kubernetes"
 )
 
 func main() {
@@ -37,6 +40,16 @@ func main() {
 		kubeconfigPath                     string
 		clusterCASecret                    string
 		clusterCASecretNamespace           string
+		clusterCARotateBefore              time.Duration
+		certificateValidity                time.Duration
+		certificateRenewBefore             time.Duration
+		clusterCAImported                  bool
+		certificateKeyAlgorithm            string
+		certificateKeySize                 int
+		certificateSignatureAlgorithm      string
+		certManagerIssuerName              string
+		certManagerIssuerKind              string
+		certManagerIssuerGroup             string
 		enableControllerGateway            bool
 		enableControllerControlPlane       bool
 		enableControllerDataPlane          bool
@@ -59,11 +72,31 @@ func main() {
 	flagSet.StringVar(&controllerName, "controller-name", "", "a controller name to use if other than the default, only needed for multi-tenancy")
 	flagSet.StringVar(&clusterCASecret, "cluster-ca-secret", "kong-operator-ca", "name of the Secret containing the cluster CA certificate")
 	flagSet.StringVar(&clusterCASecretNamespace, "cluster-ca-secret-namespace", "", "name of the namespace for Secret containing the cluster CA certificate")
+	flagSet.DurationVar(&clusterCARotateBefore, "cluster-ca-rotate-before", manager.DefaultConfig().ClusterCARotateBefore,
+		"how long before its expiry the cluster CA is rotated, along with all the certificates issued from it")
+	flagSet.DurationVar(&certificateValidity, "certificate-validity", manager.DefaultConfig().CertificateValidity,
+		"validity period of the certificates issued from the cluster CA for DataPlanes and ControlPlanes")
+	flagSet.DurationVar(&certificateRenewBefore, "certificate-renew-before", manager.DefaultConfig().CertificateRenewBefore,
+		"how long before their expiry the certificates issued for DataPlanes and ControlPlanes are renewed")
+	flagSet.BoolVar(&clusterCAImported, "cluster-ca-imported", false,
+		"use the cluster CA Secret provided by the user, with an RSA or ECDSA key and optionally its chain of intermediate CA certificates, instead of generating and rotating it")
+	flagSet.StringVar(&certificateKeyAlgorithm, "certificate-key-algorithm", string(manager.DefaultConfig().CertificateKeyAlgorithm),
+		"algorithm of the private keys of the certificates issued for DataPlanes and ControlPlanes (ECDSA or RSA)")
+	flagSet.IntVar(&certificateKeySize, "certificate-key-size", 0,
+		"size in bits of the private keys of the certificates issued for DataPlanes and ControlPlanes: 256 (default), 384 or 521 for ECDSA, 2048 (default), 3072 or 4096 for RSA")
+	flagSet.StringVar(&certificateSignatureAlgorithm, "certificate-signature-algorithm", "",
+		"algorithm the certificates issued for DataPlanes and ControlPlanes are signed with by the cluster CA, e.g. ECDSA-SHA384 or SHA256-RSA (defaults to SHA-256 with the algorithm of the cluster CA key)")
+	flagSet.StringVar(&certManagerIssuerName, "cert-manager-issuer-name", "",
+		"name of the cert-manager issuer the certificates of DataPlanes and ControlPlanes are requested from, instead of being issued from the cluster CA")
+	flagSet.StringVar(&certManagerIssuerKind, "cert-manager-issuer-kind", manager.DefaultConfig().CertManagerIssuerKind,
+		"kind of the cert-manager issuer set with --cert-manager-issuer-name")
+	flagSet.StringVar(&certManagerIssuerGroup, "cert-manager-issuer-group", manager.DefaultConfig().CertManagerIssuerGroup,
+		"API group of the cert-manager issuer set with --cert-manager-issuer-name")
 
 	flagSet.BoolVar(&enableControllerGateway, "enable-controller-gateway", true, "Enable the Gateway controller.")
 	flagSet.BoolVar(&enableControllerControlPlane, "enable-controller-controlplane", true, "Enable the ControlPlane controller.")
 	flagSet.BoolVar(&enableControllerDataPlane, "enable-controller-dataplane", true, "Enable the DataPlane controller.")
-	flagSet.BoolVar(&enableControllerDataPlaneBlueGreen, "enable-controller-dataplane-bluegreen", false, "Enable the DataPlane BlueGreen controller. Mutually exclusive with DataPlane controller.")
+	flagSet.BoolVar(&enableControllerDataPlaneBlueGreen, "enable-controller-dataplane-bluegreen", false, "Enable the DataPlane BlueGreen controller, which handles both Blue Green and Canary rollouts. Mutually exclusive with DataPlane controller.")
 	flagSet.BoolVar(&enableValidatingWebhook, "enable-validating-webhook", true, "Enable the validating webhook.")
 
 	flagSet.BoolVar(&version, "version", false, "Print version information")
@@ -74,11 +107,6 @@ func main() {
 		developmentModeEnabled = true
 	}
 
-	webhookCertDir := manager.DefaultConfig().WebhookCertDir
-	if certDir := os.Getenv("WEBHOOK_CERT_DIR"); certDir != "" { // TODO: clean env handling https://github.com/Kong/gateway-operator/issues/19
-		webhookCertDir = certDir
-	}
-
 	if developmentModeEnabled {
 		// if developmentModeEnabled is true, we are running the webhook locally,
 		// therefore enabling the validatingWebhook is ineffective and might also be problematic to handle.
@@ -133,6 +161,12 @@ func main() {
 		}
 	}
 
+	signatureAlgorithm, err := k8sutils.ParseSignatureAlgorithm(certificateSignatureAlgorithm)
+	if err != nil {
+		fmt.Printf("ERROR: invalid -certificate-signature-algorithm: %v\n", err)
+		os.Exit(1)
+	}
+
 	cfg := manager.Config{
 		DevelopmentMode:                     developmentModeEnabled,
 		MetricsAddr:                         metricsAddr,
@@ -146,13 +180,22 @@ func main() {
 		KubeconfigPath:                      kubeconfigPath,
 		ClusterCASecretName:                 clusterCASecret,
 		ClusterCASecretNamespace:            clusterCASecretNamespace,
+		ClusterCARotateBefore:               clusterCARotateBefore,
+		CertificateValidity:                 certificateValidity,
+		CertificateRenewBefore:              certificateRenewBefore,
+		ClusterCAImported:                   clusterCAImported,
+		CertificateKeyAlgorithm:             controllers.CertificateKeyAlgorithm(certificateKeyAlgorithm),
+		CertificateKeySize:                  certificateKeySize,
+		CertificateSignatureAlgorithm:       signatureAlgorithm,
+		CertManagerIssuerName:               certManagerIssuerName,
+		CertManagerIssuerKind:               certManagerIssuerKind,
+		CertManagerIssuerGroup:              certManagerIssuerGroup,
 		GatewayControllerEnabled:            enableControllerGateway,
 		ControlPlaneControllerEnabled:       enableControllerControlPlane,
 		DataPlaneControllerEnabled:          enableControllerDataPlane,
 		DataPlaneBlueGreenControllerEnabled: enableControllerDataPlaneBlueGreen,
 		ValidatingWebhookEnabled:            enableValidatingWebhook,
 		LoggerOpts:                          loggerOpts,
-		WebhookCertDir:                      webhookCertDir,
 		WebhookPort:                         manager.DefaultConfig().WebhookPort,
 	}
 
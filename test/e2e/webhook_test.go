@@ -40,7 +40,7 @@ func TestDataplaneValidatingWebhook(t *testing.T) {
 			errMsg: "DataPlane requires an image",
 		},
 		{
-			name: "database_postgres_not_supported",
+			name: "database_postgres_without_database_options",
 			dataplane: &operatorv1beta1.DataPlane{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: testNamespace.Name,
@@ -72,7 +72,7 @@ func TestDataplaneValidatingWebhook(t *testing.T) {
 				},
 			},
 
-			errMsg: "database backend postgres of DataPlane not supported currently",
+			errMsg: "database backend postgres of DataPlane requires the database.postgres options to be set",
 		},
 	}
 
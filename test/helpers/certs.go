@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -74,7 +75,8 @@ func CreateCA(t *testing.T) Cert {
 }
 
 // CreateCert creates a certificates using the provided CA and its private key.
-func CreateCert(t *testing.T, name string, caCert *x509.Certificate, caPrivKey *ecdsa.PrivateKey) Cert {
+// The certificate is valid for name and the provided additional DNS names.
+func CreateCert(t *testing.T, name string, caCert *x509.Certificate, caPrivKey *ecdsa.PrivateKey, dnsNames ...string) Cert {
 	certTemplate := &x509.Certificate{
 		SerialNumber: randomBigInt(t),
 		Subject: pkix.Name{
@@ -86,7 +88,7 @@ func CreateCert(t *testing.T, name string, caCert *x509.Certificate, caPrivKey *
 			PostalCode:    []string{"11111"},
 			CommonName:    name,
 		},
-		DNSNames:    []string{name},
+		DNSNames:    append([]string{name}, dnsNames...),
 		NotBefore:   time.Now(),
 		NotAfter:    time.Now().AddDate(1, 0, 0),
 		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
@@ -108,6 +110,51 @@ func CreateCert(t *testing.T, name string, caCert *x509.Certificate, caPrivKey *
 	}
 }
 
+// CreateIntermediateCA creates a CA certificate for the provided key, named after
+// name, which is issued by the provided parent CA certificate and its private key,
+// or self-signed when parent is nil. It returns the parsed certificate, which
+// holds its public key, and its PEM encoding.
+func CreateIntermediateCA(
+	t *testing.T,
+	name string,
+	key crypto.Signer,
+	parent *x509.Certificate,
+	parentKey crypto.Signer,
+) (*x509.Certificate, []byte) {
+	template := &x509.Certificate{
+		SerialNumber: randomBigInt(t),
+		Subject: pkix.Name{
+			Organization: []string{"Company, INC."},
+			Country:      []string{"US"},
+			CommonName:   name,
+		},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, encodeCertToPEM(t, der).Bytes()
+}
+
+// EncodePrivateKeyToPEM returns the PKCS #8 PEM encoding of the provided key.
+func EncodePrivateKeyToPEM(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: der,
+	})
+}
+
 func encodeKeyToPEM(t *testing.T, key *ecdsa.PrivateKey) *bytes.Buffer {
 	var buff bytes.Buffer
 	ecKey, err := x509.MarshalECPrivateKey(key)
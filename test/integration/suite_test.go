@@ -146,10 +146,6 @@ func startControllerManager() <-chan struct{} {
 	cfg.AnonymousReports = false
 	cfg.StartedCh = make(chan struct{})
 
-	if runWebhookTests {
-		cfg.WebhookCertDir = webhookCertDir
-	}
-
 	cfg.NewClientFunc = func(config *rest.Config, options client.Options) (client.Client, error) {
 		// always hijack and impersonate the system service account here so that the manager
 		// is testing the RBAC permissions we provide under config/rbac/. This helps alert us
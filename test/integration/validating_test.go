@@ -71,7 +71,7 @@ func testDataplaneReconcileValidation(t *testing.T, namespace *corev1.Namespace)
 		},
 
 		{
-			name: "reconciler:database_postgres_not_supported",
+			name: "reconciler:database_postgres_without_database_options",
 			dataplane: &operatorv1beta1.DataPlane{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: namespace.Name,
@@ -103,7 +103,7 @@ func testDataplaneReconcileValidation(t *testing.T, namespace *corev1.Namespace)
 				},
 			},
 			validatingOK:     false,
-			conditionMessage: "database backend postgres of DataPlane not supported currently",
+			conditionMessage: "database backend postgres of DataPlane requires the database.postgres options to be set",
 		},
 
 		{
@@ -285,7 +285,7 @@ func testDataplaneValidatingWebhook(t *testing.T, namespace *corev1.Namespace) {
 			errMsg: "",
 		},
 		{
-			name: "webhook:database_postgres_not_supported",
+			name: "webhook:database_postgres_without_database_options",
 			dataplane: &operatorv1beta1.DataPlane{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: namespace.Name,
@@ -316,7 +316,7 @@ func testDataplaneValidatingWebhook(t *testing.T, namespace *corev1.Namespace) {
 					},
 				},
 			},
-			errMsg: "database backend postgres of DataPlane not supported currently",
+			errMsg: "database backend postgres of DataPlane requires the database.postgres options to be set",
 		},
 		{
 			name: "webhook:database_xxx_not_supported",